@@ -18,6 +18,9 @@ package main
 
 import (
 	"knative.dev/pkg/injection/sharedmain"
+	"knative.dev/pkg/signals"
+	pkgwebhook "knative.dev/pkg/webhook"
+	"knative.dev/pkg/webhook/certificates"
 
 	"github.com/triggermesh/aws-event-sources/pkg/reconciler/awscodecommitsource"
 	"github.com/triggermesh/aws-event-sources/pkg/reconciler/awscognitoidentitysource"
@@ -27,10 +30,21 @@ import (
 	"github.com/triggermesh/aws-event-sources/pkg/reconciler/awskinesissource"
 	"github.com/triggermesh/aws-event-sources/pkg/reconciler/awssnssource"
 	"github.com/triggermesh/aws-event-sources/pkg/reconciler/awssqssource"
+	"github.com/triggermesh/aws-event-sources/pkg/webhook"
 )
 
 func main() {
-	sharedmain.Main("aws-event-sources-controller",
+	ctx := pkgwebhook.WithOptions(signals.NewContext(), pkgwebhook.Options{
+		ServiceName: "aws-event-sources-webhook",
+		Port:        pkgwebhook.PortFromEnv(8443),
+		SecretName:  "aws-event-sources-webhook-certs",
+	})
+
+	sharedmain.MainWithContext(ctx, "aws-event-sources-controller",
+		certificates.NewController,
+		webhook.NewDefaultingAdmissionController,
+		webhook.NewValidationAdmissionController,
+		webhook.NewConversionController,
 		awscodecommitsource.NewController,
 		awscognitoidentitysource.NewController,
 		awscognitouserpoolsource.NewController,
@@ -14,9 +14,11 @@ require (
 	github.com/cloudevents/sdk-go/v2 v2.2.0
 	github.com/google/go-cmp v0.5.2
 	github.com/kelseyhightower/envconfig v1.4.0
+	github.com/rogpeppe/go-internal v1.6.1 // indirect
 	github.com/stretchr/testify v1.5.1
 	go.opencensus.io v0.22.5
 	go.uber.org/zap v1.16.0
+	gomodules.xyz/jsonpatch/v2 v2.1.0
 	k8s.io/api v0.18.8
 	k8s.io/apimachinery v0.18.8
 	k8s.io/client-go v11.0.1-0.20190805182717-6502b5e7b1b5+incompatible
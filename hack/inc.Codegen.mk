@@ -11,6 +11,11 @@ API_GROUPS := sources/v1alpha1
 # generates e.g. "PKG/pkg/apis/sources/v1alpha1 PKG/pkg/apis/sources/v1alpha2"
 api-import-paths := $(foreach group,$(API_GROUPS),$(PKG)/pkg/apis/$(group))
 
+# List of API groups which are only served through conversion webhooks, and
+# for which only deepcopy funcs are generated
+CONVERTED_API_GROUPS := sources/v1beta1
+converted-api-import-paths := $(foreach group,$(CONVERTED_API_GROUPS),$(PKG)/pkg/apis/$(group))
+
 generators := deepcopy client lister informer injection
 
 .PHONY: codegen $(generators)
@@ -23,10 +28,10 @@ space :=
 space +=
 
 deepcopy:
-	@echo "+ Generating deepcopy funcs for $(API_GROUPS)"
+	@echo "+ Generating deepcopy funcs for $(API_GROUPS) $(CONVERTED_API_GROUPS)"
 	@go run k8s.io/code-generator/cmd/deepcopy-gen \
 		--go-header-file hack/boilerplate/boilerplate.go.txt \
-		--input-dirs $(subst $(space),$(comma),$(api-import-paths))
+		--input-dirs $(subst $(space),$(comma),$(api-import-paths) $(converted-api-import-paths))
 
 client:
 	@echo "+ Generating clientsets for $(API_GROUPS)"
@@ -19,6 +19,8 @@ package awscodecommitsource
 import (
 	"context"
 	"fmt"
+	"path"
+	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
@@ -27,9 +29,10 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/codecommit"
 	"github.com/aws/aws-sdk-go/service/codecommit/codecommitiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 
 	pkgadapter "knative.dev/eventing/pkg/adapter/v2"
 	"knative.dev/pkg/logging"
@@ -38,12 +41,6 @@ import (
 	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
 )
 
-var (
-	//syncTime       = 10
-	lastCommit     string
-	pullRequestIDs []*string //nolint:unused
-)
-
 const (
 	pushEventType = "push"
 	prEventType   = "pull_request"
@@ -53,22 +50,54 @@ const (
 // adapter.
 type envConfig struct {
 	pkgadapter.EnvConfig
+	common.SessionEnvConfig
+	common.HAEnvConfig
+
+	ARN           string   `envconfig:"ARN" required:"true"`
+	Branches      []string `envconfig:"BRANCHES"`
+	Tags          []string `envconfig:"TAGS"`
+	GitEventTypes string   `envconfig:"EVENT_TYPES" required:"true"`
 
-	ARN           string `envconfig:"ARN" required:"true"`
-	Branch        string `envconfig:"BRANCH" required:"true"`
-	GitEventTypes string `envconfig:"EVENT_TYPES" required:"true"`
+	// ARN of a SQS queue to consume EventBridge events from, instead of
+	// polling the CodeCommit API
+	QueueARN string `envconfig:"QUEUE_ARN"`
+
+	// Name of a ConfigMap in which the adapter's state is persisted
+	StateConfigMap string `envconfig:"STATE_CONFIGMAP"`
+
+	// Deprecated, superseded by BRANCHES
+	Branch string `envconfig:"BRANCH"`
 }
 
 // adapter implements the source's adapter.
 type adapter struct {
 	logger *zap.SugaredLogger
 
-	ccClient codecommitiface.CodeCommitAPI
-	ceClient cloudevents.Client
+	ccClient  codecommitiface.CodeCommitAPI
+	sqsClient sqsiface.SQSAPI
+	ceClient  cloudevents.Client
+
+	arn            arn.ARN
+	branchPatterns []string
+	tagPatterns    []string
+	gitEvents      string
 
-	arn       arn.ARN
-	branch    string
-	gitEvents string
+	// queue from which EventBridge events are consumed, if set
+	queueARN *arn.ARN
+
+	// last known heads of the observed branches, indexed by branch name
+	branchHeads map[string]string
+	// last known states of the observed PRs, indexed by PR ID
+	pullRequests map[string]*pullRequestState
+
+	// persists the adapter's state across restarts, if set
+	stateStore common.StateStore
+}
+
+// adapterState is the state of the adapter, as persisted in a StateStore.
+type adapterState struct {
+	BranchHeads  map[string]string            `json:"branchHeads"`
+	PullRequests map[string]*pullRequestState `json:"pullRequests"`
 }
 
 // NewEnvConfig returns an accessor for the source's adapter envConfig.
@@ -82,95 +111,159 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 
 	env := envAcc.(*envConfig)
 
+	var queueARN *arn.ARN
+	var sqsClient sqsiface.SQSAPI
+	if env.QueueARN != "" {
+		qARN := common.MustParseARN(env.QueueARN)
+		queueARN = &qARN
+
+		sqsClient = sqs.New(common.MustNewSession(env.SessionEnvConfig, aws.NewConfig().
+			WithRegion(qARN.Region),
+		))
+	}
+
 	arn := common.MustParseARN(env.ARN)
 
-	cfg := session.Must(session.NewSession(aws.NewConfig().
+	branchPatterns := env.Branches
+	if env.Branch != "" {
+		branchPatterns = append([]string{env.Branch}, branchPatterns...)
+	}
+	if len(branchPatterns) == 0 {
+		logger.Fatal("No Git branch to observe was provided")
+	}
+	for _, p := range branchPatterns {
+		if _, err := path.Match(p, ""); err != nil {
+			logger.Fatalw("Invalid branch pattern "+strconv.Quote(p), zap.Error(err))
+		}
+	}
+	for _, p := range env.Tags {
+		if _, err := path.Match(p, ""); err != nil {
+			logger.Fatalw("Invalid tag pattern "+strconv.Quote(p), zap.Error(err))
+		}
+	}
+
+	var stateStore common.StateStore
+	if env.StateConfigMap != "" {
+		stateStore = common.MustNewConfigMapStateStore(env.Namespace, env.StateConfigMap)
+	}
+
+	cfg := common.MustNewSession(env.SessionEnvConfig, aws.NewConfig().
 		WithRegion(arn.Region).
 		WithMaxRetries(5),
-	))
+	)
 
-	return &adapter{
+	a := &adapter{
 		logger: logger,
 
-		ccClient: codecommit.New(cfg),
-		ceClient: ceClient,
+		ccClient:  codecommit.New(cfg),
+		sqsClient: sqsClient,
+		ceClient:  ceClient,
+
+		arn:            arn,
+		branchPatterns: branchPatterns,
+		tagPatterns:    env.Tags,
+		gitEvents:      env.GitEventTypes,
 
-		arn:       arn,
-		branch:    env.Branch,
-		gitEvents: env.GitEventTypes,
+		queueARN: queueARN,
+
+		stateStore: stateStore,
 	}
+
+	return common.WithLeaderElection(a, env.HAEnvConfig, env.Namespace, logger)
 }
 
 // Start implements adapter.Adapter.
 func (a *adapter) Start(ctx context.Context) error {
-	if strings.Contains(a.gitEvents, pushEventType) {
-		a.logger.Info("Push events enabled")
+	if a.queueARN != nil {
+		queueURL, err := a.resolveQueueURL()
+		if err == nil {
+			a.logger.Info("Consuming EventBridge events from SQS queue at URL: " + queueURL)
+			a.runQueueConsumer(ctx, queueURL)
+			return nil
+		}
+
+		a.logger.Errorw("Unable to consume EventBridge events, falling back to polling the CodeCommit API",
+			"error", err)
+	}
+
+	if len(a.tagPatterns) > 0 {
+		a.logger.Warn("Tags are not observed while polling the CodeCommit API, which doesn't expose them")
+	}
 
-		branchInfo, err := a.ccClient.GetBranch(&codecommit.GetBranchInput{
-			RepositoryName: &a.arn.Resource,
-			BranchName:     &a.branch,
-		})
+	var state adapterState
+	if a.stateStore != nil {
+		found, err := a.stateStore.Load(ctx, &state)
 		if err != nil {
-			a.logger.Fatalw("Failed to retrieve branch info", "error", err)
+			a.logger.Fatalw("Failed to load persisted state", "error", err)
 		}
+		if found {
+			a.logger.Info("Resuming from persisted state")
+		}
+	}
 
-		lastCommit = *branchInfo.Branch.CommitId
+	if strings.Contains(a.gitEvents, pushEventType) {
+		a.logger.Info("Push events enabled")
+
+		if state.BranchHeads != nil {
+			a.branchHeads = make(map[string]string, len(state.BranchHeads))
+			for branch, head := range state.BranchHeads {
+				// the branch patterns may have changed since the state was persisted
+				if a.matchesBranch(branch) {
+					a.branchHeads[branch] = head
+				}
+			}
+		} else {
+			branchHeads, err := a.listBranchHeads()
+			if err != nil {
+				a.logger.Fatalw("Failed to retrieve branches info", "error", err)
+			}
+
+			a.branchHeads = branchHeads
+		}
 	}
 
 	if strings.Contains(a.gitEvents, prEventType) {
 		a.logger.Info("Pull Request events enabled")
 
-		// get pull request IDs
-		pullRequestsOutput, err := a.ccClient.ListPullRequests(&codecommit.ListPullRequestsInput{
-			RepositoryName: &a.arn.Resource,
-		})
-		if err != nil {
-			a.logger.Fatalw("Failed to retrieve list of pull requests", "error", err)
-		}
+		if state.PullRequests != nil {
+			a.pullRequests = state.PullRequests
+		} else {
+			a.pullRequests = make(map[string]*pullRequestState)
 
-		pullRequestIDs = pullRequestsOutput.PullRequestIds
+			if err := a.syncPullRequests(); err != nil {
+				a.logger.Fatalw("Failed to retrieve pull requests info", "error", err)
+			}
+		}
 	}
 
 	if !strings.Contains(a.gitEvents, pushEventType) && !strings.Contains(a.gitEvents, prEventType) {
 		a.logger.Fatalf("Failed to identify event types in %q. Valid values: (push,pull_request)", a.gitEvents)
 	}
 
-	processedPullRequests, err := a.preparePullRequests()
-	if err != nil {
-		a.logger.Errorw("Failed to process pull requests", "error", err)
-	}
+	a.saveState(ctx)
 
 	backoff := common.NewBackoff()
 
-	err = backoff.Run(ctx.Done(), func(ctx context.Context) (bool, error) {
+	err := backoff.Run(ctx.Done(), func(ctx context.Context) (bool, error) {
 		resetBackoff := false
 
+		// persist whatever progress was made, even partial
+		defer a.saveState(ctx)
+
 		if strings.Contains(a.gitEvents, pushEventType) {
-			err := a.processCommits()
+			err := a.processBranches()
 			if err != nil {
-				a.logger.Errorw("Failed to process commits", "error", err)
+				a.logger.Errorw("Failed to process branches", "error", err)
 				return resetBackoff, nil
 			}
 		}
 
 		if strings.Contains(a.gitEvents, prEventType) {
-			pullRequests, err := a.preparePullRequests()
+			err := a.processPullRequests()
 			if err != nil {
 				a.logger.Errorw("Failed to process pull requests", "error", err)
 				return resetBackoff, nil
 			}
-
-			pullRequests = removeOldPRs(processedPullRequests, pullRequests)
-
-			for _, pr := range pullRequests {
-				resetBackoff = true
-				err = a.sendEvent(pr)
-				if err != nil {
-					a.logger.Errorw("Failed to send PR event", "error", err)
-					return resetBackoff, nil
-				}
-				processedPullRequests = append(processedPullRequests, pr)
-			}
 		}
 		return resetBackoff, nil
 	})
@@ -178,81 +271,32 @@ func (a *adapter) Start(ctx context.Context) error {
 	return err
 }
 
-func (a *adapter) processCommits() error {
-	branchInfo, err := a.ccClient.GetBranch(&codecommit.GetBranchInput{
-		BranchName:     &a.branch,
-		RepositoryName: &a.arn.Resource,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to get branch info: %w", err)
+// saveState persists the adapter's current state, if a StateStore is set.
+func (a *adapter) saveState(ctx context.Context) {
+	if a.stateStore == nil {
+		return
 	}
 
-	commitOutput, err := a.ccClient.GetCommit(&codecommit.GetCommitInput{
-		CommitId:       branchInfo.Branch.CommitId,
-		RepositoryName: &a.arn.Resource,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to get commit info: %w", err)
-	}
-
-	if *commitOutput.Commit.CommitId == lastCommit {
-		return nil
+	state := &adapterState{
+		BranchHeads:  a.branchHeads,
+		PullRequests: a.pullRequests,
 	}
 
-	lastCommit = *commitOutput.Commit.CommitId
-
-	err = a.sendEvent(commitOutput.Commit)
-	if err != nil {
-		return fmt.Errorf("failed to send push event: %w", err)
+	if err := a.stateStore.Save(ctx, state); err != nil {
+		a.logger.Errorw("Failed to persist state", "error", err)
 	}
-
-	return nil
 }
 
-func (a *adapter) preparePullRequests() ([]*codecommit.PullRequest, error) {
-	pullRequests := []*codecommit.PullRequest{}
-
-	input := codecommit.ListPullRequestsInput{
-		RepositoryName: &a.arn.Resource,
-	}
-
-	// Get pull request IDs
-	pullRequestsOutput, err := a.ccClient.ListPullRequests(&input)
-	if err != nil {
-		return pullRequests, fmt.Errorf("failed to list PRs: %w", err)
-	}
-
-	for _, id := range pullRequestsOutput.PullRequestIds {
-		pri := codecommit.GetPullRequestInput{PullRequestId: id}
-
-		prInfo, err := a.ccClient.GetPullRequest(&pri)
-		if err != nil {
-			return pullRequests, fmt.Errorf("failed to get PR info: %w", err)
-		}
-
-		pullRequests = append(pullRequests, prInfo.PullRequest)
-	}
-
-	return pullRequests, nil
-}
-
-// sendEvent sends an event containing data about a git commit or PR
-func (a *adapter) sendEvent(codeCommitEvent interface{}) error {
+// sendEvent sends an event of the given type containing data about a git
+// branch or PR.
+func (a *adapter) sendEvent(eventType, subject string, codeCommitEvent interface{}) error {
 	a.logger.Info("Sending CodeCommit event")
 
 	event := cloudevents.NewEvent(cloudevents.VersionV1)
-	event.SetSubject(a.branch)
+	event.SetType(v1alpha1.AWSEventType(a.arn.Service, eventType))
+	event.SetSubject(subject)
 	event.SetSource(a.arn.String())
 
-	switch codeCommitEvent.(type) {
-	case *codecommit.PullRequest:
-		event.SetType(v1alpha1.AWSEventType(a.arn.Service, prEventType))
-	case *codecommit.Commit:
-		event.SetType(v1alpha1.AWSEventType(a.arn.Service, pushEventType))
-	default:
-		return fmt.Errorf("unknown CodeCommit event")
-	}
-
 	err := event.SetData(cloudevents.ApplicationJSON, codeCommitEvent)
 	if err != nil {
 		return fmt.Errorf("failed to set event data: %w", err)
@@ -263,25 +307,3 @@ func (a *adapter) sendEvent(codeCommitEvent interface{}) error {
 	}
 	return nil
 }
-
-func removeOldPRs(oldPrs, newPrs []*codecommit.PullRequest) []*codecommit.PullRequest {
-	dct := make(map[string]*codecommit.PullRequest)
-	for _, oldPR := range oldPrs {
-		dct[*oldPR.PullRequestId] = oldPR
-	}
-
-	res := make([]*codecommit.PullRequest, 0)
-
-	for _, newPR := range newPrs {
-		if v, exist := dct[*newPR.PullRequestId]; !exist {
-			res = append(res, newPR)
-			continue
-		} else {
-			if *newPR.PullRequestStatus == *v.PullRequestStatus {
-				continue
-			}
-			res = append(res, newPR)
-		}
-	}
-	return res
-}
@@ -17,49 +17,169 @@ limitations under the License.
 package awscodecommitsource
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/codecommit"
 	"github.com/aws/aws-sdk-go/service/codecommit/codecommitiface"
 
 	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
 	loggingtesting "knative.dev/pkg/logging/testing"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
 )
 
 type mockedClientForPush struct {
 	codecommitiface.CodeCommitAPI
-	GetBranchResp codecommit.GetBranchOutput
-	GetCommitResp codecommit.GetCommitOutput
-	GetBranchErr  error
-	GetCommitErr  error
+	// commits returned by GetCommit
+	Commits map[string]*codecommit.Commit
+	// heads of the branches returned by ListBranchesPages and GetBranch
+	BranchHeads     map[string]string
+	ListBranchesErr error
 }
 
 type mockedClientForPR struct {
 	codecommitiface.CodeCommitAPI
-	ListPRsResp codecommit.ListPullRequestsOutput
-	GetPRResp   codecommit.GetPullRequestOutput
-	ListPRsErr  error
-	GetPRErr    error
+	PullRequests map[string]*codecommit.PullRequest
+	Events       map[string][]*codecommit.PullRequestEvent
+	Comments     map[string][]*codecommit.Comment
+	ListPRsErr   error
 }
 
 func (m mockedClientForPush) GetBranch(in *codecommit.GetBranchInput) (*codecommit.GetBranchOutput, error) {
-	return &m.GetBranchResp, m.GetBranchErr
+	head, ok := m.BranchHeads[*in.BranchName]
+	if !ok {
+		return nil, awserr.New(codecommit.ErrCodeBranchDoesNotExistException, "branch not found", nil)
+	}
+	return &codecommit.GetBranchOutput{
+		Branch: &codecommit.BranchInfo{BranchName: in.BranchName, CommitId: &head},
+	}, nil
+}
+
+func (m mockedClientForPush) ListBranchesPages(in *codecommit.ListBranchesInput,
+	fn func(*codecommit.ListBranchesOutput, bool) bool) error {
+
+	if m.ListBranchesErr != nil {
+		return m.ListBranchesErr
+	}
+
+	branches := make([]*string, 0, len(m.BranchHeads))
+	for _, b := range sortedKeys(m.BranchHeads) {
+		branches = append(branches, aws.String(b))
+	}
+
+	fn(&codecommit.ListBranchesOutput{Branches: branches}, true)
+
+	return nil
 }
 
 func (m mockedClientForPush) GetCommit(in *codecommit.GetCommitInput) (*codecommit.GetCommitOutput, error) {
-	return &m.GetCommitResp, m.GetCommitErr
+	c, ok := m.Commits[*in.CommitId]
+	if !ok {
+		return nil, fmt.Errorf("commit %s not found", *in.CommitId)
+	}
+	return &codecommit.GetCommitOutput{Commit: c}, nil
+}
+
+// GetMergeOptions returns the source commit as the merge base when it is an
+// ancestor of the destination commit, and an arbitrary commit otherwise.
+func (m mockedClientForPush) GetMergeOptions(in *codecommit.GetMergeOptionsInput) (*codecommit.GetMergeOptionsOutput, error) {
+	base := "base"
+
+	queue := []string{*in.DestinationCommitSpecifier}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if id == *in.SourceCommitSpecifier {
+			base = id
+			break
+		}
+		if c, ok := m.Commits[id]; ok {
+			queue = append(queue, aws.StringValueSlice(c.Parents)...)
+		}
+	}
+
+	return &codecommit.GetMergeOptionsOutput{BaseCommitId: &base}, nil
 }
 
-func (m mockedClientForPR) ListPullRequests(in *codecommit.ListPullRequestsInput) (*codecommit.ListPullRequestsOutput, error) {
-	return &m.ListPRsResp, m.ListPRsErr
+func (m mockedClientForPush) GetDifferencesPages(in *codecommit.GetDifferencesInput,
+	fn func(*codecommit.GetDifferencesOutput, bool) bool) error {
+
+	fn(&codecommit.GetDifferencesOutput{
+		Differences: []*codecommit.Difference{{
+			AfterBlob:  &codecommit.BlobMetadata{Path: aws.String(*in.AfterCommitSpecifier + ".txt")},
+			ChangeType: aws.String(codecommit.ChangeTypeEnumA),
+		}},
+	}, true)
+
+	return nil
+}
+
+// ListPullRequestsPages returns the IDs of open PRs, one per page.
+func (m mockedClientForPR) ListPullRequestsPages(in *codecommit.ListPullRequestsInput,
+	fn func(*codecommit.ListPullRequestsOutput, bool) bool) error {
+
+	if m.ListPRsErr != nil {
+		return m.ListPRsErr
+	}
+
+	var ids []string
+	for id, pr := range m.PullRequests {
+		if *pr.PullRequestStatus == *in.PullRequestStatus {
+			ids = append(ids, id)
+		}
+	}
+	ids = dedupSorted(ids)
+
+	for i, id := range ids {
+		if !fn(&codecommit.ListPullRequestsOutput{PullRequestIds: aws.StringSlice([]string{id})}, i == len(ids)-1) {
+			break
+		}
+	}
+
+	return nil
 }
 
 func (m mockedClientForPR) GetPullRequest(in *codecommit.GetPullRequestInput) (*codecommit.GetPullRequestOutput, error) {
-	return &m.GetPRResp, m.GetPRErr
+	pr, ok := m.PullRequests[*in.PullRequestId]
+	if !ok {
+		return nil, fmt.Errorf("PR %s not found", *in.PullRequestId)
+	}
+	return &codecommit.GetPullRequestOutput{PullRequest: pr}, nil
+}
+
+func (m mockedClientForPR) GetComment(in *codecommit.GetCommentInput) (*codecommit.GetCommentOutput, error) {
+	return &codecommit.GetCommentOutput{Comment: &codecommit.Comment{CommentId: in.CommentId}}, nil
+}
+
+func (m mockedClientForPR) DescribePullRequestEventsPages(in *codecommit.DescribePullRequestEventsInput,
+	fn func(*codecommit.DescribePullRequestEventsOutput, bool) bool) error {
+
+	fn(&codecommit.DescribePullRequestEventsOutput{PullRequestEvents: m.Events[*in.PullRequestId]}, true)
+	return nil
+}
+
+func (m mockedClientForPR) GetCommentsForPullRequestPages(in *codecommit.GetCommentsForPullRequestInput,
+	fn func(*codecommit.GetCommentsForPullRequestOutput, bool) bool) error {
+
+	fn(&codecommit.GetCommentsForPullRequestOutput{
+		CommentsForPullRequestData: []*codecommit.CommentsForPullRequest{{
+			Comments: m.Comments[*in.PullRequestId],
+		}},
+	}, true)
+	return nil
 }
 
 func TestSendPREvent(t *testing.T) {
@@ -72,17 +192,26 @@ func TestSendPREvent(t *testing.T) {
 
 	pr := &codecommit.PullRequest{}
 	pr.SetPullRequestId("12345")
+	pr.SetPullRequestTargets([]*codecommit.PullRequestTarget{{
+		DestinationReference: aws.String("refs/heads/master"),
+	}})
+
+	prEvent := &pullRequestEvent{
+		PullRequest: pr,
+		Comment:     &codecommit.Comment{CommentId: aws.String("67890")},
+	}
 
-	err := a.sendEvent(pr)
+	err := a.sendEvent(v1alpha1.AWSCodeCommitPullRequestCommentedEventType, pullRequestSubject(pr), prEvent)
 	assert.NoError(t, err)
 
 	gotEvents := ceClient.Sent()
 	assert.Len(t, gotEvents, 1, "Expected 1 event, got %d", len(gotEvents))
+	assert.Equal(t, "master", gotEvents[0].Subject())
 
-	var gotData codecommit.PullRequest
+	var gotData pullRequestEvent
 	err = gotEvents[0].DataAs(&gotData)
 	assert.NoError(t, err)
-	assert.EqualValues(t, *pr, gotData, "Expected event %q, got %q", *pr, gotData)
+	assert.EqualValues(t, *prEvent, gotData, "Expected event %q, got %q", *prEvent, gotData)
 }
 
 func TestSendPushEvent(t *testing.T) {
@@ -93,196 +222,481 @@ func TestSendPushEvent(t *testing.T) {
 		ceClient: ceClient,
 	}
 
-	commit := &codecommit.Commit{}
-	commit.SetCommitId("12345")
+	push := &pushEvent{
+		Ref:    "master",
+		Before: "12344",
+		After:  "12345",
+		Commits: []*pushCommit{{
+			Commit:  &codecommit.Commit{CommitId: aws.String("12345")},
+			Changes: []*codecommit.Difference{},
+		}},
+	}
 
-	err := a.sendEvent(commit)
+	err := a.sendEvent(pushEventType, push.Ref, push)
 	assert.NoError(t, err)
 
 	gotEvents := ceClient.Sent()
 	assert.Len(t, gotEvents, 1, "Expected 1 event, got %d", len(gotEvents))
 
-	var gotData codecommit.Commit
+	var gotData pushEvent
 	err = gotEvents[0].DataAs(&gotData)
 	assert.NoError(t, err)
-	assert.EqualValues(t, *commit, gotData, "Expected event %q, got %q", *commit, gotData)
+	assert.EqualValues(t, *push, gotData, "Expected event %q, got %q", *push, gotData)
 }
 
-func TestProcessCommits(t *testing.T) {
-	lastCommit = "foo"
+func TestProcessBranches(t *testing.T) {
+	history := map[string]*codecommit.Commit{
+		"c1": {CommitId: aws.String("c1")},
+		"c2": {CommitId: aws.String("c2"), Parents: aws.StringSlice([]string{"c1"})},
+	}
 
-	testCases := []struct {
-		GetBranchResp codecommit.GetBranchOutput
-		GetCommitResp codecommit.GetCommitOutput
-		GetBranchErr  error
-		GetCommitErr  error
-		ErrMsg        *string
+	testCases := map[string]struct {
+		knownHeads   map[string]string
+		currentHeads map[string]string
+		listErr      error
+		expectEvents []string
+		expectErr    string
 	}{
-		{
-			GetBranchResp: codecommit.GetBranchOutput{},
-			GetBranchErr:  errors.New("fake get branch error"),
-			GetCommitErr:  nil,
-			ErrMsg:        aws.String("failed to get branch info: fake get branch error"),
+		"no change": {
+			knownHeads:   map[string]string{"master": "c1"},
+			currentHeads: map[string]string{"master": "c1", "unobserved": "c2"},
+			expectEvents: nil,
 		},
-		{
-			GetBranchResp: codecommit.GetBranchOutput{
-				Branch: &codecommit.BranchInfo{CommitId: aws.String("123")},
-			},
-			GetBranchErr: nil,
-			GetCommitErr: errors.New("fake get commit error"),
-			ErrMsg:       aws.String("failed to get commit info: fake get commit error"),
+		"branch updated": {
+			knownHeads:   map[string]string{"master": "c1"},
+			currentHeads: map[string]string{"master": "c2"},
+			expectEvents: []string{"com.amazon.codecommit.push master"},
 		},
-		{
-			GetBranchResp: codecommit.GetBranchOutput{
-				Branch: &codecommit.BranchInfo{CommitId: aws.String("123")},
+		"branches created and deleted": {
+			knownHeads:   map[string]string{"master": "c1", "release/v1": "c1"},
+			currentHeads: map[string]string{"master": "c1", "release/v2": "c2", "release/v3": "c2"},
+			expectEvents: []string{
+				"com.amazon.codecommit.branch_created release/v2",
+				"com.amazon.codecommit.branch_created release/v3",
+				"com.amazon.codecommit.branch_deleted release/v1",
 			},
-			GetCommitResp: codecommit.GetCommitOutput{
-				Commit: &codecommit.Commit{CommitId: aws.String("foo")},
-			},
-			GetBranchErr: nil,
-			GetCommitErr: nil,
-			ErrMsg:       nil,
 		},
-		{
-			GetBranchResp: codecommit.GetBranchOutput{
-				Branch: &codecommit.BranchInfo{CommitId: aws.String("123")},
-			},
-			GetCommitResp: codecommit.GetCommitOutput{
-				Commit: &codecommit.Commit{CommitId: aws.String("bar")},
-			},
-			GetBranchErr: nil,
-			GetCommitErr: nil,
-			ErrMsg:       nil,
+		"list error": {
+			knownHeads: map[string]string{"master": "c1"},
+			listErr:    errors.New("fake list branches error"),
+			expectErr:  "failed to list branches: fake list branches error",
 		},
 	}
 
-	for _, tt := range testCases {
-		ccClient := mockedClientForPush{
-			GetBranchResp: tt.GetBranchResp,
-			GetCommitResp: tt.GetCommitResp,
-			GetBranchErr:  tt.GetBranchErr,
-			GetCommitErr:  tt.GetCommitErr,
-		}
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			ceClient := adaptertest.NewTestClient()
+
+			a := &adapter{
+				logger: loggingtesting.TestLogger(t),
+				ccClient: mockedClientForPush{
+					Commits:         history,
+					BranchHeads:     tc.currentHeads,
+					ListBranchesErr: tc.listErr,
+				},
+				ceClient:       ceClient,
+				arn:            arn.ARN{Service: codecommit.ServiceName},
+				branchPatterns: []string{"master", "release/*"},
+				branchHeads:    tc.knownHeads,
+			}
+
+			err := a.processBranches()
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var gotEvents []string
+			for _, e := range ceClient.Sent() {
+				gotEvents = append(gotEvents, e.Type()+" "+e.Subject())
+			}
+			assert.Equal(t, tc.expectEvents, gotEvents)
+
+			expectHeads := make(map[string]string)
+			for b, h := range tc.currentHeads {
+				if a.matchesBranch(b) {
+					expectHeads[b] = h
+				}
+			}
+			assert.Equal(t, expectHeads, a.branchHeads)
+		})
+	}
+}
 
-		a := &adapter{
-			logger:   loggingtesting.TestLogger(t),
-			ccClient: ccClient,
-			ceClient: adaptertest.NewTestClient(),
+func TestMakePushEvent(t *testing.T) {
+	// history:
+	//
+	//   c1 - c2 - c3 - c5 (merge)
+	//          \       /
+	//           `- c4 -´
+	//     \
+	//      `- x1 (rewritten history)
+	//
+	//   l0 - l1 - ... - l149 (long linear history)
+	history := map[string]*codecommit.Commit{
+		"c1": {CommitId: aws.String("c1")},
+		"c2": {CommitId: aws.String("c2"), Parents: aws.StringSlice([]string{"c1"})},
+		"c3": {CommitId: aws.String("c3"), Parents: aws.StringSlice([]string{"c2"})},
+		"c4": {CommitId: aws.String("c4"), Parents: aws.StringSlice([]string{"c2"})},
+		"c5": {CommitId: aws.String("c5"), Parents: aws.StringSlice([]string{"c3", "c4"})},
+		"x1": {CommitId: aws.String("x1"), Parents: aws.StringSlice([]string{"c1"})},
+	}
+
+	const linearHistoryLen = 150
+	linearCommit := func(i int) string { return "l" + strconv.Itoa(i) }
+
+	history[linearCommit(0)] = &codecommit.Commit{CommitId: aws.String(linearCommit(0))}
+	for i := 1; i < linearHistoryLen; i++ {
+		history[linearCommit(i)] = &codecommit.Commit{
+			CommitId: aws.String(linearCommit(i)),
+			Parents:  aws.StringSlice([]string{linearCommit(i - 1)}),
 		}
+	}
 
-		err := a.processCommits()
-		if tt.ErrMsg == nil {
-			assert.NoError(t, err)
-		} else {
-			assert.EqualError(t, err, *tt.ErrMsg)
+	// most recent commits of the linear history, oldest first
+	linearCommits := func(n int) []string {
+		ids := make([]string, n)
+		for i := range ids {
+			ids[i] = linearCommit(linearHistoryLen - n + i)
 		}
+		return ids
+	}
+
+	testCases := map[string]struct {
+		before, after   string
+		expectCommits   []string
+		expectForced    bool
+		expectTruncated bool
+	}{
+		"fast-forward": {
+			before:        "c1",
+			after:         "c3",
+			expectCommits: []string{"c2", "c3"},
+			expectForced:  false,
+		},
+		"fast-forward with merge": {
+			before:        "c2",
+			after:         "c5",
+			expectCommits: []string{"c4", "c3", "c5"},
+			expectForced:  false,
+		},
+		"fast-forward with merge of a side branch": {
+			before:        "c3",
+			after:         "c5",
+			expectCommits: []string{"c4", "c5"},
+			expectForced:  false,
+		},
+		"force-push": {
+			before:        "c3",
+			after:         "x1",
+			expectCommits: []string{"x1"},
+			expectForced:  true,
+		},
+		"new branch": {
+			before:        "",
+			after:         "c2",
+			expectCommits: []string{"c1", "c2"},
+			expectForced:  false,
+		},
+		"truncated fast-forward": {
+			before:          linearCommit(0),
+			after:           linearCommit(linearHistoryLen - 1),
+			expectCommits:   linearCommits(maxPushCommits),
+			expectForced:    false,
+			expectTruncated: true,
+		},
+		"truncated force-push": {
+			before:          "c3",
+			after:           linearCommit(linearHistoryLen - 1),
+			expectCommits:   linearCommits(maxPushCommits),
+			expectForced:    true,
+			expectTruncated: true,
+		},
+	}
 
-		lastCommit = "foo"
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			a := &adapter{
+				logger:   loggingtesting.TestLogger(t),
+				ccClient: mockedClientForPush{Commits: history},
+			}
+
+			event, err := a.makePushEvent("master", tc.before, tc.after)
+			require.NoError(t, err)
+
+			assert.Equal(t, "master", event.Ref)
+			assert.Equal(t, tc.before, event.Before)
+			assert.Equal(t, tc.after, event.After)
+			assert.Equal(t, tc.expectForced, event.Forced)
+			assert.Equal(t, tc.expectTruncated, event.Truncated)
+
+			gotCommits := make([]string, len(event.Commits))
+			for i, c := range event.Commits {
+				gotCommits[i] = *c.CommitId
+
+				require.Len(t, c.Changes, 1)
+				assert.Equal(t, *c.CommitId+".txt", *c.Changes[0].AfterBlob.Path)
+			}
+			assert.Equal(t, tc.expectCommits, gotCommits)
+		})
 	}
 }
 
-func TestProcessPullRequest(t *testing.T) {
-	pullRequestIDs = []*string{aws.String("1")}
+func TestProcessPullRequests(t *testing.T) {
+	t0 := time.Date(2020, 12, 1, 10, 0, 0, 0, time.UTC)
+	at := func(min int) *time.Time {
+		t := t0.Add(time.Duration(min) * time.Minute)
+		return &t
+	}
 
-	testCases := []struct {
-		ListPRsResp codecommit.ListPullRequestsOutput
-		GetPRResp   codecommit.GetPullRequestOutput
-		ListPRsErr  error
-		GetPRErr    error
-		ErrMsg      *string
+	pullRequest := func(id, status string, lastActivity int) *codecommit.PullRequest {
+		return &codecommit.PullRequest{
+			PullRequestId:     aws.String(id),
+			PullRequestStatus: aws.String(status),
+			LastActivityDate:  at(lastActivity),
+			PullRequestTargets: []*codecommit.PullRequestTarget{{
+				DestinationReference: aws.String("refs/heads/master"),
+			}},
+		}
+	}
+
+	merged := func(pr *codecommit.PullRequest) *codecommit.PullRequest {
+		for _, t := range pr.PullRequestTargets {
+			t.MergeMetadata = &codecommit.MergeMetadata{IsMerged: aws.Bool(true)}
+		}
+		return pr
+	}
+
+	prEvent := func(typ string, date int) *codecommit.PullRequestEvent {
+		return &codecommit.PullRequestEvent{
+			PullRequestEventType: aws.String(typ),
+			EventDate:            at(date),
+		}
+	}
+
+	testCases := map[string]struct {
+		client       mockedClientForPR
+		knownPRs     map[string]*pullRequestState
+		expectEvents []string
+		expectPRs    []string
+		expectErr    string
 	}{
-		{
-			ListPRsErr: errors.New("fake list PR error"),
-			GetPRErr:   nil,
-			ErrMsg:     aws.String("failed to list PRs: fake list PR error"),
+		"no activity": {
+			client: mockedClientForPR{
+				PullRequests: map[string]*codecommit.PullRequest{
+					"1": pullRequest("1", codecommit.PullRequestStatusEnumOpen, 1),
+				},
+				Events: map[string][]*codecommit.PullRequestEvent{
+					"1": {prEvent(codecommit.PullRequestEventTypePullRequestCreated, 1)},
+				},
+			},
+			knownPRs: map[string]*pullRequestState{
+				"1": {LastActivity: *at(1), LastEvent: *at(1), LastComment: *at(1)},
+			},
+			expectEvents: nil,
+			expectPRs:    []string{"1"},
 		},
-		{
-			ListPRsResp: codecommit.ListPullRequestsOutput{
-				PullRequestIds: []*string{aws.String("1")},
+		"new PR with comment": {
+			client: mockedClientForPR{
+				PullRequests: map[string]*codecommit.PullRequest{
+					"1": pullRequest("1", codecommit.PullRequestStatusEnumOpen, 1),
+					"2": pullRequest("2", codecommit.PullRequestStatusEnumOpen, 3),
+				},
+				Events: map[string][]*codecommit.PullRequestEvent{
+					"2": {prEvent(codecommit.PullRequestEventTypePullRequestCreated, 2)},
+				},
+				Comments: map[string][]*codecommit.Comment{
+					"2": {{CommentId: aws.String("c1"), CreationDate: at(3)}},
+				},
+			},
+			knownPRs: map[string]*pullRequestState{
+				"1": {LastActivity: *at(1), LastEvent: *at(1), LastComment: *at(1)},
 			},
-			ListPRsErr: nil,
-			GetPRErr:   nil,
-			ErrMsg:     nil,
+			expectEvents: []string{
+				"com.amazon.codecommit.pull_request_created",
+				"com.amazon.codecommit.pull_request",
+				"com.amazon.codecommit.pull_request_commented",
+			},
+			expectPRs: []string{"1", "2"},
 		},
-		{
-			ListPRsResp: codecommit.ListPullRequestsOutput{
-				PullRequestIds: []*string{aws.String("2")},
+		"PR updated then merged": {
+			client: mockedClientForPR{
+				PullRequests: map[string]*codecommit.PullRequest{
+					"1": merged(pullRequest("1", codecommit.PullRequestStatusEnumClosed, 5)),
+				},
+				Events: map[string][]*codecommit.PullRequestEvent{
+					"1": {
+						prEvent(codecommit.PullRequestEventTypePullRequestCreated, 1),
+						prEvent(codecommit.PullRequestEventTypePullRequestSourceReferenceUpdated, 2),
+						prEvent(codecommit.PullRequestEventTypePullRequestApprovalRuleCreated, 2),
+						prEvent(codecommit.PullRequestEventTypePullRequestApprovalStateChanged, 3),
+						{
+							PullRequestEventType: aws.String(codecommit.PullRequestEventTypePullRequestMergeStateChanged),
+							EventDate:            at(4),
+							PullRequestMergedStateChangedEventMetadata: &codecommit.PullRequestMergedStateChangedEventMetadata{
+								MergeMetadata: &codecommit.MergeMetadata{IsMerged: aws.Bool(true)},
+							},
+						}, {
+							PullRequestEventType: aws.String(codecommit.PullRequestEventTypePullRequestStatusChanged),
+							EventDate:            at(5),
+							PullRequestStatusChangedEventMetadata: &codecommit.PullRequestStatusChangedEventMetadata{
+								PullRequestStatus: aws.String(codecommit.PullRequestStatusEnumClosed),
+							},
+						},
+					},
+				},
+			},
+			knownPRs: map[string]*pullRequestState{
+				"1": {LastActivity: *at(1), LastEvent: *at(1), LastComment: *at(1)},
 			},
-			GetPRResp: codecommit.GetPullRequestOutput{
-				PullRequest: &codecommit.PullRequest{},
+			expectEvents: []string{
+				"com.amazon.codecommit.pull_request_updated",
+				"com.amazon.codecommit.pull_request_approval_changed",
+				"com.amazon.codecommit.pull_request_merged",
 			},
-			ListPRsErr: nil,
-			GetPRErr:   errors.New("fake get PR error"),
-			ErrMsg:     aws.String("failed to get PR info: fake get PR error"),
+			expectPRs: []string{},
 		},
-		{
-			ListPRsResp: codecommit.ListPullRequestsOutput{
-				PullRequestIds: []*string{aws.String("2")},
+		"PR closed without merge": {
+			client: mockedClientForPR{
+				PullRequests: map[string]*codecommit.PullRequest{
+					"1": pullRequest("1", codecommit.PullRequestStatusEnumClosed, 2),
+				},
+				Events: map[string][]*codecommit.PullRequestEvent{
+					"1": {
+						prEvent(codecommit.PullRequestEventTypePullRequestCreated, 1),
+						{
+							PullRequestEventType: aws.String(codecommit.PullRequestEventTypePullRequestStatusChanged),
+							EventDate:            at(2),
+							PullRequestStatusChangedEventMetadata: &codecommit.PullRequestStatusChangedEventMetadata{
+								PullRequestStatus: aws.String(codecommit.PullRequestStatusEnumClosed),
+							},
+						},
+					},
+				},
 			},
-			GetPRResp: codecommit.GetPullRequestOutput{
-				PullRequest: &codecommit.PullRequest{},
+			knownPRs: map[string]*pullRequestState{
+				"1": {LastActivity: *at(1), LastEvent: *at(1), LastComment: *at(1)},
+			},
+			expectEvents: []string{
+				"com.amazon.codecommit.pull_request_closed",
+			},
+			expectPRs: []string{},
+		},
+		"list error": {
+			client: mockedClientForPR{
+				ListPRsErr: errors.New("fake list PR error"),
 			},
-			ListPRsErr: nil,
-			GetPRErr:   nil,
-			ErrMsg:     nil,
+			knownPRs:  map[string]*pullRequestState{},
+			expectErr: "failed to list PRs: fake list PR error",
 		},
-		{
-			ListPRsResp: codecommit.ListPullRequestsOutput{
-				PullRequestIds: []*string{aws.String("2")},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			ceClient := adaptertest.NewTestClient()
+
+			a := &adapter{
+				logger:       loggingtesting.TestLogger(t),
+				ccClient:     tc.client,
+				ceClient:     ceClient,
+				arn:          arn.ARN{Service: codecommit.ServiceName},
+				pullRequests: tc.knownPRs,
+			}
+
+			err := a.processPullRequests()
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var gotEvents []string
+			for _, e := range ceClient.Sent() {
+				gotEvents = append(gotEvents, e.Type())
+				assert.Equal(t, "master", e.Subject())
+			}
+			assert.Equal(t, tc.expectEvents, gotEvents)
+
+			gotPRs := make([]string, 0, len(a.pullRequests))
+			for id := range a.pullRequests {
+				gotPRs = append(gotPRs, id)
+			}
+			assert.Equal(t, tc.expectPRs, dedupSorted(gotPRs))
+		})
+	}
+}
+
+func TestStartResumesFromState(t *testing.T) {
+	t0 := time.Date(2020, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	store := &mockStateStore{
+		state: `{"branchHeads":{"master":"c1","unobserved":"c1"},` +
+			`"pullRequests":{"1":{"lastActivity":"` + t0.Format(time.RFC3339) + `"}}}`,
+	}
+
+	ceClient := adaptertest.NewTestClient()
+
+	a := &adapter{
+		logger: loggingtesting.TestLogger(t),
+		ccClient: mockedClientForPR{
+			// handles calls not implemented by mockedClientForPR
+			CodeCommitAPI: mockedClientForPush{
+				BranchHeads: map[string]string{"master": "c1"},
 			},
-			GetPRResp: codecommit.GetPullRequestOutput{
-				PullRequest: &codecommit.PullRequest{},
+			PullRequests: map[string]*codecommit.PullRequest{
+				"1": {
+					PullRequestId:     aws.String("1"),
+					PullRequestStatus: aws.String(codecommit.PullRequestStatusEnumOpen),
+					LastActivityDate:  &t0,
+				},
 			},
-			ListPRsErr: nil,
-			GetPRErr:   nil,
-			ErrMsg:     nil,
 		},
+		ceClient:       ceClient,
+		arn:            arn.ARN{Service: codecommit.ServiceName},
+		branchPatterns: []string{"master"},
+		gitEvents:      "push,pull_request",
+		stateStore:     store,
 	}
 
-	for _, tt := range testCases {
-		ccClient := mockedClientForPR{
-			ListPRsResp: tt.ListPRsResp,
-			GetPRResp:   tt.GetPRResp,
-			ListPRsErr:  tt.ListPRsErr,
-			GetPRErr:    tt.GetPRErr,
-		}
-		ceClient := adaptertest.NewTestClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
 
-		a := &adapter{
-			logger:   loggingtesting.TestLogger(t),
-			ccClient: ccClient,
-			ceClient: ceClient,
-		}
+	err := a.Start(ctx)
+	require.NoError(t, err)
 
-		_, err := a.preparePullRequests()
-		if tt.ErrMsg == nil {
-			assert.NoError(t, err)
-		} else {
-			assert.EqualError(t, err, *tt.ErrMsg)
-		}
+	assert.Empty(t, ceClient.Sent())
 
-		pullRequestIDs = []*string{aws.String("1")}
+	assert.Equal(t, map[string]string{"master": "c1"}, a.branchHeads)
+	if assert.Contains(t, a.pullRequests, "1") {
+		assert.True(t, t0.Equal(a.pullRequests["1"].LastActivity))
 	}
+
+	assert.JSONEq(t, `{"branchHeads":{"master":"c1"},"pullRequests":{"1":{`+
+		`"lastActivity":"`+t0.Format(time.RFC3339)+`",`+
+		`"lastEvent":"0001-01-01T00:00:00Z",`+
+		`"lastComment":"0001-01-01T00:00:00Z"}}}`,
+		store.state)
 }
 
-func TestRemoveOldPRs(t *testing.T) {
-	oldPRs := []*codecommit.PullRequest{
-		{PullRequestId: aws.String("1"), PullRequestStatus: aws.String("CREATED")},
-		{PullRequestId: aws.String("2"), PullRequestStatus: aws.String("CREATED")},
-	}
-	newPRs := []*codecommit.PullRequest{
-		{PullRequestId: aws.String("1"), PullRequestStatus: aws.String("CREATED")},
-		{PullRequestId: aws.String("2"), PullRequestStatus: aws.String("CLOSED")},
-		{PullRequestId: aws.String("3"), PullRequestStatus: aws.String("CREATED")},
-	}
+// mockStateStore is a common.StateStore which holds a serialized state in
+// memory.
+type mockStateStore struct {
+	state string
+}
 
-	expectedPRs := []*codecommit.PullRequest{
-		{PullRequestId: aws.String("2"), PullRequestStatus: aws.String("CLOSED")},
-		{PullRequestId: aws.String("3"), PullRequestStatus: aws.String("CREATED")},
+func (s *mockStateStore) Load(_ context.Context, v interface{}) (bool, error) {
+	if s.state == "" {
+		return false, nil
 	}
+	return true, json.Unmarshal([]byte(s.state), v)
+}
 
-	prs := removeOldPRs(oldPRs, newPRs)
-	t.Log(prs)
-	assert.Equal(t, 2, len(prs))
-	assert.Equal(t, expectedPRs, prs)
+func (s *mockStateStore) Save(_ context.Context, v interface{}) error {
+	b, err := json.Marshal(v)
+	s.state = string(b)
+	return err
 }
@@ -0,0 +1,184 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscodecommitsource
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/codecommit"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// branchRefPrefix is the prefix of the full name of a Git branch reference.
+const branchRefPrefix = "refs/heads/"
+
+// branchEvent is the payload of a branch creation or deletion event.
+type branchEvent struct {
+	// Name of the branch.
+	Ref string `json:"ref"`
+	// Commit ID of the branch head upon creation, or before deletion.
+	CommitID string `json:"commitId"`
+}
+
+// processBranches compares the current heads of the observed branches with
+// their last known heads, and sends an event for each branch that was created,
+// updated or deleted since the previous call.
+func (a *adapter) processBranches() error {
+	heads, err := a.listBranchHeads()
+	if err != nil {
+		return err
+	}
+
+	for _, branch := range sortedKeys(heads) {
+		head := heads[branch]
+
+		prevHead, known := a.branchHeads[branch]
+
+		switch {
+		case !known:
+			err := a.sendEvent(v1alpha1.AWSCodeCommitBranchCreatedEventType, branch,
+				&branchEvent{Ref: branch, CommitID: head})
+			if err != nil {
+				return fmt.Errorf("failed to send branch creation event: %w", err)
+			}
+
+		case head != prevHead:
+			event, err := a.makePushEvent(branch, prevHead, head)
+			if err != nil {
+				return err
+			}
+
+			if err := a.sendEvent(pushEventType, branch, event); err != nil {
+				return fmt.Errorf("failed to send push event: %w", err)
+			}
+		}
+
+		a.branchHeads[branch] = head
+	}
+
+	for _, branch := range sortedKeys(a.branchHeads) {
+		if _, exists := heads[branch]; exists {
+			continue
+		}
+
+		err := a.sendEvent(v1alpha1.AWSCodeCommitBranchDeletedEventType, branch,
+			&branchEvent{Ref: branch, CommitID: a.branchHeads[branch]})
+		if err != nil {
+			return fmt.Errorf("failed to send branch deletion event: %w", err)
+		}
+
+		delete(a.branchHeads, branch)
+	}
+
+	return nil
+}
+
+// listBranchHeads returns the commit IDs of the heads of all branches which
+// match the adapter's branch patterns, indexed by branch name.
+func (a *adapter) listBranchHeads() (map[string]string, error) {
+	var branches []string
+
+	err := a.ccClient.ListBranchesPages(&codecommit.ListBranchesInput{
+		RepositoryName: &a.arn.Resource,
+	}, func(page *codecommit.ListBranchesOutput, _ bool) bool {
+		for _, b := range page.Branches {
+			if a.matchesBranch(*b) {
+				branches = append(branches, *b)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	heads := make(map[string]string, len(branches))
+
+	for _, b := range branches {
+		branch := b
+
+		branchInfo, err := a.ccClient.GetBranch(&codecommit.GetBranchInput{
+			BranchName:     &branch,
+			RepositoryName: &a.arn.Resource,
+		})
+		if err != nil {
+			// the branch was deleted after it was listed
+			if awsErr := awserr.Error(nil); errors.As(err, &awsErr) &&
+				awsErr.Code() == codecommit.ErrCodeBranchDoesNotExistException {
+
+				continue
+			}
+			return nil, fmt.Errorf("failed to get branch info: %w", err)
+		}
+
+		heads[branch] = *branchInfo.Branch.CommitId
+	}
+
+	return heads, nil
+}
+
+// tagEvent is the payload of a tag creation, update or deletion event.
+type tagEvent struct {
+	// Name of the tag.
+	Ref string `json:"ref"`
+	// Commit ID the tag points to upon creation or after an update, or
+	// before deletion.
+	CommitID string `json:"commitId"`
+	// Commit ID the tag pointed to before an update.
+	OldCommitID string `json:"oldCommitId,omitempty"`
+}
+
+// matchesBranch returns whether the given branch name matches any of the
+// adapter's branch patterns.
+func (a *adapter) matchesBranch(branch string) bool {
+	for _, p := range a.branchPatterns {
+		// patterns are validated when the adapter is created
+		if match, _ := path.Match(p, branch); match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesTag returns whether the given tag name matches any of the adapter's
+// tag patterns.
+func (a *adapter) matchesTag(tag string) bool {
+	for _, p := range a.tagPatterns {
+		// patterns are validated when the adapter is created
+		if match, _ := path.Match(p, tag); match {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of the given map in lexical order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,309 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscodecommitsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codecommit"
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// EventBridge detail types of CodeCommit events.
+// https://docs.aws.amazon.com/codecommit/latest/userguide/monitoring-events.html
+const (
+	detailTypeRepositoryStateChange  = "CodeCommit Repository State Change"
+	detailTypePullRequestStateChange = "CodeCommit Pull Request State Change"
+	detailTypeCommentOnPullRequest   = "CodeCommit Comment on Pull Request"
+)
+
+const (
+	// Longest possible duration of a SQS long polling request.
+	maxLongPollingWaitTimeSeconds = 20
+	// Highest possible value for the MaxNumberOfMessages request parameter.
+	maxReceiveMsgBatchSize = 10
+	// Duration between calls to ReceiveMessage after a failed call.
+	receiveRetryPeriod = 3 * time.Second
+)
+
+// eventBridgeEvent is an event delivered by Amazon EventBridge.
+type eventBridgeEvent struct {
+	DetailType string          `json:"detail-type"`
+	Source     string          `json:"source"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// codeCommitEventDetail is the detail of a CodeCommit event delivered by
+// Amazon EventBridge. It contains the attributes of all supported detail
+// types.
+type codeCommitEventDetail struct {
+	Event          string `json:"event"`
+	RepositoryName string `json:"repositoryName"`
+
+	// repository state change
+	ReferenceType string `json:"referenceType"`
+	ReferenceName string `json:"referenceName"`
+	CommitID      string `json:"commitId"`
+	OldCommitID   string `json:"oldCommitId"`
+
+	// pull request state change, comment on pull request
+	PullRequestID     string `json:"pullRequestId"`
+	PullRequestStatus string `json:"pullRequestStatus"`
+	IsMerged          string `json:"isMerged"`
+	CommentID         string `json:"commentId"`
+}
+
+// resolveQueueURL returns the URL of the SQS queue from which EventBridge
+// events are consumed.
+func (a *adapter) resolveQueueURL() (string, error) {
+	out, err := a.sqsClient.GetQueueUrl(&sqs.GetQueueUrlInput{
+		QueueName:              &a.queueARN.Resource,
+		QueueOwnerAWSAccountId: &a.queueARN.AccountID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get URL of queue %q: %w", a.queueARN, err)
+	}
+
+	return *out.QueueUrl, nil
+}
+
+// runQueueConsumer receives EventBridge events from the SQS queue at the given
+// URL until ctx is cancelled. Messages are deleted from the queue only once
+// they have been successfully handled, otherwise they are delivered again
+// after their visibility timeout expires.
+func (a *adapter) runQueueConsumer(ctx context.Context, queueURL string) {
+	for {
+		out, err := a.sqsClient.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            &queueURL,
+			MaxNumberOfMessages: aws.Int64(maxReceiveMsgBatchSize),
+			WaitTimeSeconds:     aws.Int64(maxLongPollingWaitTimeSeconds),
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if err != nil {
+			a.logger.Errorw("Failed to receive messages from the queue", "error", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(receiveRetryPeriod):
+			}
+			continue
+		}
+
+		for _, msg := range out.Messages {
+			if err := a.handleQueueMessage(msg); err != nil {
+				a.logger.Errorw("Failed to handle message "+*msg.MessageId, "error", err)
+				continue
+			}
+
+			_, err := a.sqsClient.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      &queueURL,
+				ReceiptHandle: msg.ReceiptHandle,
+			})
+			if err != nil {
+				a.logger.Errorw("Failed to delete message "+*msg.MessageId, "error", err)
+			}
+		}
+	}
+}
+
+// handleQueueMessage translates the EventBridge event contained in the given
+// SQS message into a CloudEvent and sends it. Events which don't relate to
+// the observed repository, branches or event types are ignored.
+func (a *adapter) handleQueueMessage(msg *sqs.Message) error {
+	var e eventBridgeEvent
+	if err := json.Unmarshal([]byte(*msg.Body), &e); err != nil {
+		a.logger.Warnw("Ignoring message which isn't an EventBridge event", "messageId", *msg.MessageId)
+		return nil
+	}
+
+	if e.Source != "aws.codecommit" {
+		return nil
+	}
+
+	var d codeCommitEventDetail
+	if err := json.Unmarshal(e.Detail, &d); err != nil {
+		return fmt.Errorf("failed to deserialize event detail: %w", err)
+	}
+
+	if d.RepositoryName != a.arn.Resource {
+		return nil
+	}
+
+	switch e.DetailType {
+	case detailTypeRepositoryStateChange:
+		if !strings.Contains(a.gitEvents, pushEventType) {
+			return nil
+		}
+		return a.handleReferenceEvent(&d)
+
+	case detailTypePullRequestStateChange, detailTypeCommentOnPullRequest:
+		if !strings.Contains(a.gitEvents, prEventType) {
+			return nil
+		}
+		return a.handlePullRequestEvent(&d)
+	}
+
+	return nil
+}
+
+// handleReferenceEvent sends the CloudEvent corresponding to the given change
+// of a Git reference.
+func (a *adapter) handleReferenceEvent(d *codeCommitEventDetail) error {
+	switch d.ReferenceType {
+	case "branch":
+		if !a.matchesBranch(d.ReferenceName) {
+			return nil
+		}
+	case "tag":
+		if !a.matchesTag(d.ReferenceName) {
+			return nil
+		}
+		return a.handleTagEvent(d)
+	default:
+		return nil
+	}
+
+	branch := d.ReferenceName
+
+	switch d.Event {
+	case "referenceCreated":
+		err := a.sendEvent(v1alpha1.AWSCodeCommitBranchCreatedEventType, branch,
+			&branchEvent{Ref: branch, CommitID: d.CommitID})
+		if err != nil {
+			return fmt.Errorf("failed to send branch creation event: %w", err)
+		}
+
+	case "referenceDeleted":
+		err := a.sendEvent(v1alpha1.AWSCodeCommitBranchDeletedEventType, branch,
+			&branchEvent{Ref: branch, CommitID: d.OldCommitID})
+		if err != nil {
+			return fmt.Errorf("failed to send branch deletion event: %w", err)
+		}
+
+	case "referenceUpdated":
+		event, err := a.makePushEvent(branch, d.OldCommitID, d.CommitID)
+		if err != nil {
+			return err
+		}
+
+		if err := a.sendEvent(pushEventType, branch, event); err != nil {
+			return fmt.Errorf("failed to send push event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// handleTagEvent sends the CloudEvent corresponding to the given change of a
+// Git tag.
+func (a *adapter) handleTagEvent(d *codeCommitEventDetail) error {
+	tag := d.ReferenceName
+
+	var typ string
+	event := &tagEvent{Ref: tag}
+
+	switch d.Event {
+	case "referenceCreated":
+		typ = v1alpha1.AWSCodeCommitTagCreatedEventType
+		event.CommitID = d.CommitID
+	case "referenceUpdated":
+		typ = v1alpha1.AWSCodeCommitTagUpdatedEventType
+		event.CommitID = d.CommitID
+		event.OldCommitID = d.OldCommitID
+	case "referenceDeleted":
+		typ = v1alpha1.AWSCodeCommitTagDeletedEventType
+		event.CommitID = d.OldCommitID
+	default:
+		return nil
+	}
+
+	if err := a.sendEvent(typ, tag, event); err != nil {
+		return fmt.Errorf("failed to send tag event: %w", err)
+	}
+
+	return nil
+}
+
+// handlePullRequestEvent sends the CloudEvent corresponding to the given
+// change of a PR.
+func (a *adapter) handlePullRequestEvent(d *codeCommitEventDetail) error {
+	var typ string
+
+	switch d.Event {
+	case "pullRequestCreated":
+		typ = v1alpha1.AWSCodeCommitPullRequestCreatedEventType
+	case "pullRequestSourceBranchUpdated":
+		typ = v1alpha1.AWSCodeCommitPullRequestUpdatedEventType
+	case "pullRequestMergeStatusUpdated":
+		if strings.EqualFold(d.IsMerged, "true") {
+			typ = v1alpha1.AWSCodeCommitPullRequestMergedEventType
+		}
+	case "pullRequestStatusChanged":
+		if strings.EqualFold(d.PullRequestStatus, codecommit.PullRequestStatusEnumClosed) {
+			typ = v1alpha1.AWSCodeCommitPullRequestClosedEventType
+		}
+	case "pullRequestApprovalStateChanged":
+		typ = v1alpha1.AWSCodeCommitPullRequestApprovalChangedEventType
+	case "commentOnPullRequestCreated":
+		typ = v1alpha1.AWSCodeCommitPullRequestCommentedEventType
+	}
+
+	if typ == "" {
+		return nil
+	}
+
+	pr, err := a.getPullRequest(d.PullRequestID)
+	if err != nil {
+		return err
+	}
+
+	// merging a PR also closes it, in which case the merge event is the
+	// only one reported
+	if typ == v1alpha1.AWSCodeCommitPullRequestClosedEventType && isMerged(pr) {
+		return nil
+	}
+
+	event := &pullRequestEvent{
+		PullRequest: pr,
+	}
+
+	if d.CommentID != "" {
+		commentOutput, err := a.ccClient.GetComment(&codecommit.GetCommentInput{
+			CommentId: &d.CommentID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get comment info: %w", err)
+		}
+		event.Comment = commentOutput.Comment
+	}
+
+	return a.sendPullRequestEvent(typ, event)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscodecommitsource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/codecommit"
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	loggingtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestHandleQueueMessage(t *testing.T) {
+	const repo = "my-repo"
+
+	eventBridgeEvent := func(detailType, detail string) string {
+		return `{"version":"0","id":"01234567-0123-0123-0123-012345678901",` +
+			`"detail-type":"` + detailType + `","source":"aws.codecommit",` +
+			`"account":"123456789012","time":"2020-12-01T10:00:00Z","region":"us-fake-0",` +
+			`"resources":["arn:aws:codecommit:us-fake-0:123456789012:` + repo + `"],` +
+			`"detail":` + detail + `}`
+	}
+
+	client := mockedClientForPR{
+		CodeCommitAPI: mockedClientForPush{
+			Commits: map[string]*codecommit.Commit{
+				"c1": {CommitId: aws.String("c1")},
+				"c2": {CommitId: aws.String("c2"), Parents: aws.StringSlice([]string{"c1"})},
+			},
+		},
+		PullRequests: map[string]*codecommit.PullRequest{
+			"1": {
+				PullRequestId:     aws.String("1"),
+				PullRequestStatus: aws.String(codecommit.PullRequestStatusEnumClosed),
+				PullRequestTargets: []*codecommit.PullRequestTarget{{
+					DestinationReference: aws.String("refs/heads/master"),
+				}},
+			},
+			"2": {
+				PullRequestId:     aws.String("2"),
+				PullRequestStatus: aws.String(codecommit.PullRequestStatusEnumClosed),
+				PullRequestTargets: []*codecommit.PullRequestTarget{{
+					DestinationReference: aws.String("refs/heads/master"),
+					MergeMetadata:        &codecommit.MergeMetadata{IsMerged: aws.Bool(true)},
+				}},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		body          string
+		expectType    string
+		expectSubject string
+	}{
+		"branch updated": {
+			body: eventBridgeEvent(detailTypeRepositoryStateChange,
+				`{"event":"referenceUpdated","repositoryName":"`+repo+`","referenceType":"branch",`+
+					`"referenceName":"master","commitId":"c2","oldCommitId":"c1"}`),
+			expectType:    "com.amazon.codecommit.push",
+			expectSubject: "master",
+		},
+		"branch created": {
+			body: eventBridgeEvent(detailTypeRepositoryStateChange,
+				`{"event":"referenceCreated","repositoryName":"`+repo+`","referenceType":"branch",`+
+					`"referenceName":"master","commitId":"c2"}`),
+			expectType:    "com.amazon.codecommit.branch_created",
+			expectSubject: "master",
+		},
+		"unobserved branch": {
+			body: eventBridgeEvent(detailTypeRepositoryStateChange,
+				`{"event":"referenceCreated","repositoryName":"`+repo+`","referenceType":"branch",`+
+					`"referenceName":"feature","commitId":"c2"}`),
+		},
+		"tag created": {
+			body: eventBridgeEvent(detailTypeRepositoryStateChange,
+				`{"event":"referenceCreated","repositoryName":"`+repo+`","referenceType":"tag",`+
+					`"referenceName":"v1.0.0","commitId":"c2"}`),
+			expectType:    "com.amazon.codecommit.tag_created",
+			expectSubject: "v1.0.0",
+		},
+		"tag deleted": {
+			body: eventBridgeEvent(detailTypeRepositoryStateChange,
+				`{"event":"referenceDeleted","repositoryName":"`+repo+`","referenceType":"tag",`+
+					`"referenceName":"v1.0.0","oldCommitId":"c2"}`),
+			expectType:    "com.amazon.codecommit.tag_deleted",
+			expectSubject: "v1.0.0",
+		},
+		"unobserved tag": {
+			body: eventBridgeEvent(detailTypeRepositoryStateChange,
+				`{"event":"referenceCreated","repositoryName":"`+repo+`","referenceType":"tag",`+
+					`"referenceName":"master","commitId":"c2"}`),
+		},
+		"other repository": {
+			body: eventBridgeEvent(detailTypeRepositoryStateChange,
+				`{"event":"referenceCreated","repositoryName":"other-repo","referenceType":"branch",`+
+					`"referenceName":"master","commitId":"c2"}`),
+		},
+		"pull request merged": {
+			body: eventBridgeEvent(detailTypePullRequestStateChange,
+				`{"event":"pullRequestMergeStatusUpdated","repositoryNames":["`+repo+`"],`+
+					`"repositoryName":"`+repo+`","pullRequestId":"1","isMerged":"True"}`),
+			expectType:    "com.amazon.codecommit.pull_request_merged",
+			expectSubject: "master",
+		},
+		"pull request closed": {
+			body: eventBridgeEvent(detailTypePullRequestStateChange,
+				`{"event":"pullRequestStatusChanged","repositoryName":"`+repo+`",`+
+					`"pullRequestId":"1","pullRequestStatus":"Closed"}`),
+			expectType:    "com.amazon.codecommit.pull_request_closed",
+			expectSubject: "master",
+		},
+		"merged pull request closed": {
+			body: eventBridgeEvent(detailTypePullRequestStateChange,
+				`{"event":"pullRequestStatusChanged","repositoryName":"`+repo+`",`+
+					`"pullRequestId":"2","pullRequestStatus":"Closed"}`),
+		},
+		"pull request commented": {
+			body: eventBridgeEvent(detailTypeCommentOnPullRequest,
+				`{"event":"commentOnPullRequestCreated","repositoryName":"`+repo+`",`+
+					`"pullRequestId":"1","commentId":"abc"}`),
+			expectType:    "com.amazon.codecommit.pull_request_commented",
+			expectSubject: "master",
+		},
+		"not an EventBridge event": {
+			body: `hello world`,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			ceClient := adaptertest.NewTestClient()
+
+			a := &adapter{
+				logger:         loggingtesting.TestLogger(t),
+				ccClient:       client,
+				ceClient:       ceClient,
+				arn:            arn.ARN{Service: codecommit.ServiceName, Resource: repo},
+				branchPatterns: []string{"master"},
+				tagPatterns:    []string{"v*"},
+				gitEvents:      "push,pull_request",
+			}
+
+			msg := &sqs.Message{
+				MessageId: aws.String("00000000-0000-0000-0000-000000000001"),
+				Body:      &tc.body,
+			}
+
+			err := a.handleQueueMessage(msg)
+			require.NoError(t, err)
+
+			sent := ceClient.Sent()
+
+			if tc.expectType == "" {
+				assert.Empty(t, sent)
+				return
+			}
+
+			require.Len(t, sent, 1)
+			assert.Equal(t, tc.expectType, sent[0].Type())
+			assert.Equal(t, tc.expectSubject, sent[0].Subject())
+		})
+	}
+}
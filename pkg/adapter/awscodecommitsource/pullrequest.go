@@ -0,0 +1,326 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscodecommitsource
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codecommit"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// pullRequestState is the last known state of an observed PR.
+type pullRequestState struct {
+	// Date of the last activity on the PR.
+	LastActivity time.Time `json:"lastActivity"`
+	// Dates of the last PR event and comment which were sent.
+	LastEvent   time.Time `json:"lastEvent"`
+	LastComment time.Time `json:"lastComment"`
+}
+
+// pullRequestEvent is the payload of a PR event.
+type pullRequestEvent struct {
+	// Current state of the PR.
+	PullRequest *codecommit.PullRequest `json:"pullRequest"`
+	// Event which occurred on the PR, unless the event is a comment.
+	Event *codecommit.PullRequestEvent `json:"event,omitempty"`
+	// Comment added to the PR.
+	Comment *codecommit.Comment `json:"comment,omitempty"`
+
+	typ  string
+	date time.Time
+}
+
+// syncPullRequests records the current state of all open PRs, without sending
+// any event.
+func (a *adapter) syncPullRequests() error {
+	ids, err := a.listOpenPullRequests()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		pr, err := a.getPullRequest(id)
+		if err != nil {
+			return err
+		}
+
+		lastActivity := aws.TimeValue(pr.LastActivityDate)
+
+		a.pullRequests[id] = &pullRequestState{
+			LastActivity: lastActivity,
+			LastEvent:    lastActivity,
+			LastComment:  lastActivity,
+		}
+	}
+
+	return nil
+}
+
+// processPullRequests sends an event for each change which occurred on open
+// PRs, or on PRs that were open during the previous call, since that call.
+func (a *adapter) processPullRequests() error {
+	ids, err := a.listOpenPullRequests()
+	if err != nil {
+		return err
+	}
+
+	// PRs which were closed since the previous call are no longer listed,
+	// but may have events which haven't been sent yet
+	for id := range a.pullRequests {
+		ids = append(ids, id)
+	}
+
+	for _, id := range dedupSorted(ids) {
+		state, known := a.pullRequests[id]
+		if !known {
+			state = &pullRequestState{}
+		}
+
+		pr, err := a.getPullRequest(id)
+		if err != nil {
+			return err
+		}
+
+		lastActivity := aws.TimeValue(pr.LastActivityDate)
+
+		if known && !lastActivity.After(state.LastActivity) {
+			continue
+		}
+
+		events, err := a.pullRequestEvents(pr, state)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			if err := a.sendPullRequestEvent(e.typ, e); err != nil {
+				return err
+			}
+
+			if e.Comment != nil {
+				state.LastComment = e.date
+			} else {
+				state.LastEvent = e.date
+			}
+			a.pullRequests[id] = state
+		}
+
+		state.LastActivity = lastActivity
+
+		if aws.StringValue(pr.PullRequestStatus) == codecommit.PullRequestStatusEnumClosed {
+			delete(a.pullRequests, id)
+			continue
+		}
+
+		a.pullRequests[id] = state
+	}
+
+	return nil
+}
+
+// pullRequestEvents returns the events and comments which occurred on the
+// given PR after the dates recorded in its last known state, in chronological
+// order.
+func (a *adapter) pullRequestEvents(pr *codecommit.PullRequest, state *pullRequestState) ([]*pullRequestEvent, error) {
+	var events []*pullRequestEvent
+
+	err := a.ccClient.DescribePullRequestEventsPages(&codecommit.DescribePullRequestEventsInput{
+		PullRequestId: pr.PullRequestId,
+	}, func(page *codecommit.DescribePullRequestEventsOutput, _ bool) bool {
+		for _, e := range page.PullRequestEvents {
+			date := aws.TimeValue(e.EventDate)
+			if !date.After(state.LastEvent) {
+				continue
+			}
+
+			typ := pullRequestEventType(pr, e)
+			if typ == "" {
+				continue
+			}
+
+			events = append(events, &pullRequestEvent{
+				PullRequest: pr,
+				Event:       e,
+				typ:         typ,
+				date:        date,
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe events of PR %s: %w", *pr.PullRequestId, err)
+	}
+
+	err = a.ccClient.GetCommentsForPullRequestPages(&codecommit.GetCommentsForPullRequestInput{
+		PullRequestId:  pr.PullRequestId,
+		RepositoryName: &a.arn.Resource,
+	}, func(page *codecommit.GetCommentsForPullRequestOutput, _ bool) bool {
+		for _, data := range page.CommentsForPullRequestData {
+			for _, c := range data.Comments {
+				date := aws.TimeValue(c.CreationDate)
+				if !date.After(state.LastComment) || aws.BoolValue(c.Deleted) {
+					continue
+				}
+
+				events = append(events, &pullRequestEvent{
+					PullRequest: pr,
+					Comment:     c,
+					typ:         v1alpha1.AWSCodeCommitPullRequestCommentedEventType,
+					date:        date,
+				})
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments of PR %s: %w", *pr.PullRequestId, err)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].date.Before(events[j].date)
+	})
+
+	return events, nil
+}
+
+// sendPullRequestEvent sends a PR event of the given type. The creation of a PR
+// is additionally reported with the deprecated "pull_request" event type,
+// which payload is the PR itself.
+func (a *adapter) sendPullRequestEvent(typ string, e *pullRequestEvent) error {
+	subject := pullRequestSubject(e.PullRequest)
+
+	if err := a.sendEvent(typ, subject, e); err != nil {
+		return fmt.Errorf("failed to send PR event: %w", err)
+	}
+
+	if typ == v1alpha1.AWSCodeCommitPullRequestCreatedEventType {
+		if err := a.sendEvent(v1alpha1.AWSCodeCommitPullRequestEventType, subject, e.PullRequest); err != nil {
+			return fmt.Errorf("failed to send PR event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// pullRequestEventType returns the type of CloudEvent which corresponds to the
+// given event of the given PR, or an empty string if that type of PR event
+// isn't reported by the source.
+func pullRequestEventType(pr *codecommit.PullRequest, e *codecommit.PullRequestEvent) string {
+	switch aws.StringValue(e.PullRequestEventType) {
+	case codecommit.PullRequestEventTypePullRequestCreated:
+		return v1alpha1.AWSCodeCommitPullRequestCreatedEventType
+
+	case codecommit.PullRequestEventTypePullRequestSourceReferenceUpdated:
+		return v1alpha1.AWSCodeCommitPullRequestUpdatedEventType
+
+	case codecommit.PullRequestEventTypePullRequestMergeStateChanged:
+		if md := e.PullRequestMergedStateChangedEventMetadata; md != nil && md.MergeMetadata != nil &&
+			aws.BoolValue(md.MergeMetadata.IsMerged) {
+
+			return v1alpha1.AWSCodeCommitPullRequestMergedEventType
+		}
+
+	case codecommit.PullRequestEventTypePullRequestStatusChanged:
+		// merging a PR also closes it, in which case the merge event
+		// is the only one reported
+		if md := e.PullRequestStatusChangedEventMetadata; md != nil &&
+			aws.StringValue(md.PullRequestStatus) == codecommit.PullRequestStatusEnumClosed &&
+			!isMerged(pr) {
+
+			return v1alpha1.AWSCodeCommitPullRequestClosedEventType
+		}
+
+	case codecommit.PullRequestEventTypePullRequestApprovalStateChanged:
+		return v1alpha1.AWSCodeCommitPullRequestApprovalChangedEventType
+	}
+
+	return ""
+}
+
+// listOpenPullRequests returns the IDs of all open PRs.
+func (a *adapter) listOpenPullRequests() ([]string, error) {
+	var ids []string
+
+	err := a.ccClient.ListPullRequestsPages(&codecommit.ListPullRequestsInput{
+		RepositoryName:    &a.arn.Resource,
+		PullRequestStatus: aws.String(codecommit.PullRequestStatusEnumOpen),
+	}, func(page *codecommit.ListPullRequestsOutput, _ bool) bool {
+		ids = append(ids, aws.StringValueSlice(page.PullRequestIds)...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PRs: %w", err)
+	}
+
+	return ids, nil
+}
+
+// getPullRequest returns the PR with the given ID.
+func (a *adapter) getPullRequest(id string) (*codecommit.PullRequest, error) {
+	prInfo, err := a.ccClient.GetPullRequest(&codecommit.GetPullRequestInput{
+		PullRequestId: &id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR info: %w", err)
+	}
+
+	return prInfo.PullRequest, nil
+}
+
+// isMerged returns whether the given PR was merged.
+func isMerged(pr *codecommit.PullRequest) bool {
+	for _, t := range pr.PullRequestTargets {
+		if t.MergeMetadata != nil && aws.BoolValue(t.MergeMetadata.IsMerged) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pullRequestSubject returns the name of the branch targeted by the given PR,
+// for use as the subject of a PR event.
+func pullRequestSubject(pr *codecommit.PullRequest) string {
+	for _, t := range pr.PullRequestTargets {
+		if t.DestinationReference != nil {
+			return strings.TrimPrefix(*t.DestinationReference, branchRefPrefix)
+		}
+	}
+
+	return ""
+}
+
+// dedupSorted returns the given strings sorted and without duplicates.
+func dedupSorted(s []string) []string {
+	sort.Strings(s)
+
+	res := s[:0]
+	for _, v := range s {
+		if len(res) > 0 && v == res[len(res)-1] {
+			continue
+		}
+		res = append(res, v)
+	}
+
+	return res
+}
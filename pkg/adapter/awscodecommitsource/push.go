@@ -0,0 +1,353 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscodecommitsource
+
+import (
+	"container/heap"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/codecommit"
+)
+
+// maxPushCommits is the maximum number of commits listed in a single push
+// event. It bounds the number of API calls performed while walking the
+// ancestry of a branch head, e.g. after a force-push which rewrote a large
+// portion of the branch's history.
+const maxPushCommits = 100
+
+// maxWalkedCommits is the maximum number of commits retrieved while
+// determining the commits added by a single push, including the ancestors of
+// the previous head which are walked to exclude them from the push.
+const maxWalkedCommits = 4 * maxPushCommits
+
+// walkSlop is the number of commits walked after only ancestors of the
+// previous head remain to be walked, to compensate for commit dates which are
+// not monotonic, e.g. due to clock skew.
+const walkSlop = 5
+
+// pushEvent is the payload of a push event. It describes an update of a
+// branch head, similarly to the push webhooks of most git hosting services.
+type pushEvent struct {
+	// Name of the updated branch.
+	Ref string `json:"ref"`
+	// Commit IDs of the branch head before and after the update.
+	Before string `json:"before"`
+	After  string `json:"after"`
+	// Whether the update was not a fast-forward, i.e. the previous head
+	// is not an ancestor of the new head.
+	Forced bool `json:"forced"`
+	// Whether the list of commits was truncated to maxPushCommits.
+	Truncated bool `json:"truncated,omitempty"`
+	// Commits added to the branch by the update, oldest first.
+	Commits []*pushCommit `json:"commits"`
+}
+
+// pushCommit is a commit listed in a pushEvent, along with the files it
+// changed relative to its first parent.
+type pushCommit struct {
+	*codecommit.Commit
+	Changes []*codecommit.Difference `json:"changes"`
+}
+
+// makePushEvent returns a pushEvent describing the update of the given
+// branch's head from the commit 'before' to the commit 'after'.
+//
+// The listed commits are the ones reachable from the new head but not from the
+// previous head, similarly to 'git log before..after'. The update is
+// considered as forced when the previous head is not an ancestor of the new
+// head.
+func (a *adapter) makePushEvent(branch, before, after string) (*pushEvent, error) {
+	commits, found, truncated, err := a.walkCommits(after, before)
+	if err != nil {
+		return nil, err
+	}
+
+	forced := before != "" && !found
+	if before != "" && truncated {
+		// the walk may have been interrupted before reaching the
+		// previous head
+		if forced, err = a.isForcedUpdate(before, after); err != nil {
+			return nil, err
+		}
+	}
+
+	event := &pushEvent{
+		Ref:       branch,
+		Before:    before,
+		After:     after,
+		Forced:    forced,
+		Truncated: truncated,
+		Commits:   make([]*pushCommit, 0, len(commits)),
+	}
+
+	// commits were walked from the newest to the oldest
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+
+		changes, err := a.commitChanges(c)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Commits = append(event.Commits, &pushCommit{
+			Commit:  c,
+			Changes: changes,
+		})
+	}
+
+	return event, nil
+}
+
+// walkCommits returns the commits which are ancestors of the commit 'from'
+// (inclusive) but not of the commit 'exclude', from the newest to the oldest.
+// The returned boolean values indicate whether 'exclude' was found in the
+// ancestry of 'from' and whether the walk was interrupted after
+// maxPushCommits commits.
+//
+// Similarly to 'git rev-list', the ancestries of both commits are walked
+// together, newest commit first. Ancestors of 'exclude' are marked as
+// uninteresting, and the walk stops once only uninteresting commits remain to
+// be walked.
+func (a *adapter) walkCommits(from, exclude string) (commits []*codecommit.Commit, found, truncated bool, err error) {
+	w := newCommitWalk()
+	w.push(from, nil, false)
+	if exclude != "" {
+		w.push(exclude, nil, true)
+	}
+
+	var interesting []string
+	slop := walkSlop
+
+	for w.queue.Len() > 0 {
+		if w.everybodyUninteresting() {
+			if slop == 0 {
+				break
+			}
+			slop--
+		}
+
+		if len(w.commits) == maxWalkedCommits {
+			truncated = true
+			break
+		}
+
+		item := heap.Pop(&w.queue).(*walkItem)
+		id := item.id
+
+		commitOutput, err := a.ccClient.GetCommit(&codecommit.GetCommitInput{
+			CommitId:       &id,
+			RepositoryName: &a.arn.Resource,
+		})
+		if err != nil {
+			return nil, false, false, fmt.Errorf("failed to get commit info: %w", err)
+		}
+		c := commitOutput.Commit
+		w.commits[id] = c
+
+		uninteresting := w.uninteresting[id]
+		if !uninteresting {
+			interesting = append(interesting, id)
+		}
+
+		for _, p := range c.Parents {
+			if *p == exclude && !uninteresting {
+				found = true
+			}
+			if uninteresting {
+				w.markUninteresting(*p)
+			}
+			w.push(*p, c, uninteresting)
+		}
+	}
+
+	for _, id := range interesting {
+		if w.uninteresting[id] {
+			continue
+		}
+		if len(commits) == maxPushCommits {
+			truncated = true
+			break
+		}
+		commits = append(commits, w.commits[id])
+	}
+
+	return commits, found, truncated, nil
+}
+
+// isForcedUpdate returns whether the commit 'before' is not an ancestor of the
+// commit 'after', based on the merge base of both commits.
+func (a *adapter) isForcedUpdate(before, after string) (bool, error) {
+	out, err := a.ccClient.GetMergeOptions(&codecommit.GetMergeOptionsInput{
+		RepositoryName:             &a.arn.Resource,
+		SourceCommitSpecifier:      &before,
+		DestinationCommitSpecifier: &after,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get merge base of commits %s and %s: %w", before, after, err)
+	}
+
+	return *out.BaseCommitId != before, nil
+}
+
+// commitWalk holds the state of a walk through the ancestry of commits.
+type commitWalk struct {
+	queue walkQueue
+	seq   int
+
+	// commits which were queued, retrieved, or are known ancestors of
+	// the excluded commit
+	queued        map[string]struct{}
+	commits       map[string]*codecommit.Commit
+	uninteresting map[string]bool
+}
+
+// newCommitWalk returns an initialized commitWalk.
+func newCommitWalk() *commitWalk {
+	return &commitWalk{
+		queued:        make(map[string]struct{}),
+		commits:       make(map[string]*codecommit.Commit),
+		uninteresting: make(map[string]bool),
+	}
+}
+
+// push queues the given commit for retrieval, unless it was already queued.
+// Commits are ordered by the date of their child, if known.
+func (w *commitWalk) push(id string, child *codecommit.Commit, uninteresting bool) {
+	if uninteresting {
+		w.uninteresting[id] = true
+	}
+
+	if _, ok := w.queued[id]; ok {
+		return
+	}
+	w.queued[id] = struct{}{}
+
+	var date int64
+	if child != nil {
+		date = commitDate(child)
+	}
+
+	w.seq++
+	heap.Push(&w.queue, &walkItem{id: id, date: date, seq: w.seq})
+}
+
+// markUninteresting marks the given commit and all of its already retrieved
+// ancestors as uninteresting.
+func (w *commitWalk) markUninteresting(id string) {
+	stack := []string{id}
+
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if w.uninteresting[id] {
+			if _, retrieved := w.commits[id]; !retrieved {
+				continue
+			}
+		}
+		w.uninteresting[id] = true
+
+		if c, retrieved := w.commits[id]; retrieved {
+			for _, p := range c.Parents {
+				if !w.uninteresting[*p] {
+					stack = append(stack, *p)
+				}
+			}
+		}
+	}
+}
+
+// everybodyUninteresting returns whether all queued commits are uninteresting.
+func (w *commitWalk) everybodyUninteresting() bool {
+	for _, item := range w.queue {
+		if !w.uninteresting[item.id] {
+			return false
+		}
+	}
+	return true
+}
+
+// walkItem is a commit queued for retrieval.
+type walkItem struct {
+	id   string
+	date int64
+	seq  int
+}
+
+// walkQueue is a priority queue of commits, newest first, then in the order in
+// which they were queued.
+type walkQueue []*walkItem
+
+var _ heap.Interface = (*walkQueue)(nil)
+
+func (q walkQueue) Len() int { return len(q) }
+func (q walkQueue) Less(i, j int) bool {
+	if q[i].date != q[j].date {
+		return q[i].date > q[j].date
+	}
+	return q[i].seq < q[j].seq
+}
+func (q walkQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *walkQueue) Push(x interface{}) { *q = append(*q, x.(*walkItem)) }
+func (q *walkQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
+
+// commitDate returns the date of the given commit as a Unix timestamp, or 0 if
+// it is unknown. CodeCommit formats dates as "<timestamp> <timezone offset>".
+func commitDate(c *codecommit.Commit) int64 {
+	if c.Committer == nil || c.Committer.Date == nil {
+		return 0
+	}
+
+	ts, err := strconv.ParseInt(strings.SplitN(*c.Committer.Date, " ", 2)[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return ts
+}
+
+// commitChanges returns the files changed by the given commit relative to its
+// first parent, or all the files of the commit if it has no parent.
+func (a *adapter) commitChanges(c *codecommit.Commit) ([]*codecommit.Difference, error) {
+	input := &codecommit.GetDifferencesInput{
+		RepositoryName:       &a.arn.Resource,
+		AfterCommitSpecifier: c.CommitId,
+	}
+	if len(c.Parents) > 0 {
+		input.BeforeCommitSpecifier = c.Parents[0]
+	}
+
+	changes := make([]*codecommit.Difference, 0)
+
+	err := a.ccClient.GetDifferencesPages(input, func(page *codecommit.GetDifferencesOutput, _ bool) bool {
+		changes = append(changes, page.Differences...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get differences of commit %s: %w", *c.CommitId, err)
+	}
+
+	return changes, nil
+}
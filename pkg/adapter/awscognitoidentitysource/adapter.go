@@ -19,6 +19,7 @@ package awscognitoidentitysource
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"go.uber.org/zap"
 
@@ -26,7 +27,6 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cognitoidentity"
 	"github.com/aws/aws-sdk-go/service/cognitoidentity/cognitoidentityiface"
 	"github.com/aws/aws-sdk-go/service/cognitosync"
@@ -43,6 +43,9 @@ import (
 // adapter.
 type envConfig struct {
 	pkgadapter.EnvConfig
+	common.RedactionEnvConfig
+	common.SessionEnvConfig
+	common.HAEnvConfig
 
 	ARN string `envconfig:"ARN" required:"true"`
 }
@@ -57,6 +60,12 @@ type adapter struct {
 
 	arn            arn.ARN
 	identityPoolID string
+
+	// redacts sensitive records from events, if set
+	redactor *common.Redactor
+
+	// last known states of all datasets, indexed by datasetKey
+	datasets map[string]*datasetState
 }
 
 // NewEnvConfig returns an accessor for the source's adapter envConfig.
@@ -72,12 +81,17 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 
 	arn := common.MustParseARN(env.ARN)
 
-	cfg := session.Must(session.NewSession(aws.NewConfig().
+	redactor, err := common.NewRedactor(&env.RedactionEnvConfig)
+	if err != nil {
+		logger.Fatalw("Invalid redaction policy", zap.Error(err))
+	}
+
+	cfg := common.MustNewSession(env.SessionEnvConfig, aws.NewConfig().
 		WithRegion(arn.Region).
 		WithMaxRetries(5),
-	))
+	)
 
-	return &adapter{
+	a := &adapter{
 		logger: logger,
 
 		cgnIdentityClient: cognitoidentity.New(cfg),
@@ -86,7 +100,11 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 
 		arn:            arn,
 		identityPoolID: common.MustParseCognitoIdentityResource(arn.Resource),
+
+		redactor: redactor,
 	}
+
+	return common.WithLeaderElection(a, env.HAEnvConfig, env.Namespace, logger)
 }
 
 // Start implements adapter.Adapter.
@@ -96,31 +114,20 @@ func (a *adapter) Start(ctx context.Context) error {
 	backoff := common.NewBackoff()
 
 	err := backoff.Run(ctx.Done(), func(ctx context.Context) (bool, error) {
-		resetBackoff := false
-		identities, err := a.getIdentities()
-		if err != nil {
-			a.logger.Error(err)
+		if a.datasets == nil {
+			if err := a.syncDatasets(); err != nil {
+				a.logger.Errorf("Failed to list datasets: %v", err)
+			}
+			return false, nil
 		}
 
-		datasets, err := a.getDatasets(identities)
+		changed, err := a.processDatasets()
 		if err != nil {
-			a.logger.Error(err)
+			a.logger.Errorf("Failed to process datasets: %v", err)
 		}
 
-		for _, dataset := range datasets {
-			resetBackoff = true
-			records, err := a.getRecords(dataset)
-			if err != nil {
-				a.logger.Error(err)
-				continue
-			}
-
-			err = a.sendCognitoEvent(dataset, records)
-			if err != nil {
-				a.logger.Errorf("SendCloudEvent failed: %v", err)
-			}
-		}
-		return resetBackoff, nil
+		// we have modified datasets - reset backoff duration
+		return changed, nil
 	})
 
 	return err
@@ -130,7 +137,7 @@ func (a *adapter) getIdentities() ([]*cognitoidentity.IdentityDescription, error
 	identities := []*cognitoidentity.IdentityDescription{}
 
 	listIdentitiesInput := cognitoidentity.ListIdentitiesInput{
-		MaxResults:     aws.Int64(1),
+		MaxResults:     aws.Int64(maxIdentitiesPageSize),
 		IdentityPoolId: &a.identityPoolID,
 	}
 
@@ -178,33 +185,46 @@ func (a *adapter) getDatasets(identities []*cognitoidentity.IdentityDescription)
 	return datasets, nil
 }
 
-func (a *adapter) getRecords(dataset *cognitosync.Dataset) ([]*cognitosync.Record, error) {
+// getRecords returns the records of the given dataset which were modified
+// after the given sync count, along with the current sync count of the
+// dataset. A limit of 0 means no limit.
+func (a *adapter) getRecords(dataset *cognitosync.Dataset, lastSyncCount int64,
+	limit int64) ([]*cognitosync.Record, int64, error) {
+
 	records := []*cognitosync.Record{}
+	var syncCount int64
 
 	input := cognitosync.ListRecordsInput{
 		DatasetName:    dataset.DatasetName,
 		IdentityId:     dataset.IdentityId,
 		IdentityPoolId: &a.identityPoolID,
+		LastSyncCount:  &lastSyncCount,
+	}
+	if limit > 0 {
+		input.MaxResults = &limit
 	}
 
 	for {
 		recordsOutput, err := a.cgnSyncClient.ListRecords(&input)
 		if err != nil {
-			return records, err
+			return records, syncCount, err
 		}
 
 		records = append(records, recordsOutput.Records...)
+		syncCount = aws.Int64Value(recordsOutput.DatasetSyncCount)
 
 		input.NextToken = recordsOutput.NextToken
-		if recordsOutput.NextToken == nil {
+		if recordsOutput.NextToken == nil || limit > 0 {
 			break
 		}
 	}
 
-	return records, nil
+	return records, syncCount, nil
 }
 
-func (a *adapter) sendCognitoEvent(dataset *cognitosync.Dataset, records []*cognitosync.Record) error {
+// sendCognitoEvent sends an event about a change of the given dataset, which
+// resulted in the given sync count.
+func (a *adapter) sendCognitoEvent(dataset *cognitosync.Dataset, records []*cognitosync.Record, syncCount int64) error {
 	a.logger.Info("Processing Dataset: ", *dataset.DatasetName)
 
 	data := &CognitoIdentitySyncEvent{
@@ -215,17 +235,18 @@ func (a *adapter) sendCognitoEvent(dataset *cognitosync.Dataset, records []*cogn
 		LastModifiedBy:   dataset.LastModifiedBy,
 		LastModifiedDate: dataset.LastModifiedDate,
 		NumRecords:       dataset.NumRecords,
+		SyncCount:        &syncCount,
 		EventType:        aws.String("SyncTrigger"),
 		Region:           &a.arn.Region,
 		IdentityPoolID:   &a.identityPoolID,
-		DatasetRecords:   records,
+		DatasetRecords:   redactRecords(a.redactor, records),
 	}
 
 	event := cloudevents.NewEvent(cloudevents.VersionV1)
 	event.SetType(v1alpha1.AWSEventType(a.arn.Service, v1alpha1.AWSCognitoIdentityGenericEventType))
 	event.SetSubject(*dataset.DatasetName)
 	event.SetSource(a.arn.String())
-	event.SetID(*dataset.IdentityId)
+	event.SetID(datasetKey(dataset) + "/" + strconv.FormatInt(syncCount, 10))
 	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
 		return fmt.Errorf("failed to set event data: %w", err)
 	}
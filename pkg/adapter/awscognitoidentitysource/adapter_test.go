@@ -126,7 +126,7 @@ func TestGetRecords(t *testing.T) {
 		listRecordsOutputError: errors.New("fake ListRecords error"),
 	}
 
-	records, err := a.getRecords(&dataset)
+	records, _, err := a.getRecords(&dataset, 0, 0)
 	assert.Error(t, err)
 	assert.Equal(t, 0, len(records))
 
@@ -137,7 +137,7 @@ func TestGetRecords(t *testing.T) {
 		listRecordsOutputError: nil,
 	}
 
-	records, err = a.getRecords(&dataset)
+	records, _, err = a.getRecords(&dataset, 0, 0)
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(records))
 }
@@ -157,13 +157,14 @@ func TestSendCognitoEvent(t *testing.T) {
 	}
 	records := []*cognitosync.Record{}
 
-	err := a.sendCognitoEvent(&dataset, records)
+	err := a.sendCognitoEvent(&dataset, records, 3)
 	assert.NoError(t, err)
 
 	gotEvents := ceClient.Sent()
 	assert.Len(t, gotEvents, 1, "Expected 1 event, got %d", len(gotEvents))
+	assert.Equal(t, "3234234/foo/3", gotEvents[0].ID())
 
-	wantData := `{"CreationDate":null,"DataStorage":null,"DatasetName":"foo","IdentityID":"3234234","LastModifiedBy":null,"LastModifiedDate":null,"NumRecords":null,"EventType":"SyncTrigger","Region":"","IdentityPoolID":"fooPool","DatasetRecords":[]}`
+	wantData := `{"CreationDate":null,"DataStorage":null,"DatasetName":"foo","IdentityID":"3234234","LastModifiedBy":null,"LastModifiedDate":null,"NumRecords":null,"SyncCount":3,"EventType":"SyncTrigger","Region":"","IdentityPoolID":"fooPool","DatasetRecords":[]}`
 	gotData := string(gotEvents[0].Data())
 	assert.EqualValues(t, wantData, gotData, "Expected event %q, got %q", wantData, gotData)
 }
@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitoidentitysource
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitosync"
+)
+
+// Highest possible value for the MaxResults parameter of ListIdentities
+// requests.
+const maxIdentitiesPageSize = 60
+
+// datasetState is the last known state of a dataset.
+type datasetState struct {
+	lastModifiedDate time.Time
+	syncCount        int64
+}
+
+// syncDatasets records the current state of all datasets of the identity
+// pool, without sending any event.
+func (a *adapter) syncDatasets() error {
+	datasets, err := a.listAllDatasets()
+	if err != nil {
+		return err
+	}
+
+	states := make(map[string]*datasetState, len(datasets))
+
+	for _, ds := range datasets {
+		// a single record is enough to read the dataset's sync count
+		_, syncCount, err := a.getRecords(ds, 0, 1)
+		if err != nil {
+			return fmt.Errorf("failed to list records of dataset %q: %w", datasetKey(ds), err)
+		}
+
+		states[datasetKey(ds)] = &datasetState{
+			lastModifiedDate: aws.TimeValue(ds.LastModifiedDate),
+			syncCount:        syncCount,
+		}
+	}
+
+	a.datasets = states
+
+	return nil
+}
+
+// processDatasets sends an event for each dataset of the identity pool which
+// was created or modified since the previous call. Only the records which
+// were modified since the last known sync count of each dataset are
+// included. It returns whether any event was sent.
+func (a *adapter) processDatasets() (bool /*changed*/, error) {
+	datasets, err := a.listAllDatasets()
+	if err != nil {
+		return false, err
+	}
+
+	current := make(map[string]struct{}, len(datasets))
+
+	var changed bool
+
+	for _, ds := range datasets {
+		key := datasetKey(ds)
+		current[key] = struct{}{}
+
+		lastModifiedDate := aws.TimeValue(ds.LastModifiedDate)
+
+		prev, known := a.datasets[key]
+		if known && !lastModifiedDate.After(prev.lastModifiedDate) {
+			continue
+		}
+
+		var lastSyncCount int64
+		if known {
+			lastSyncCount = prev.syncCount
+		}
+
+		records, syncCount, err := a.getRecords(ds, lastSyncCount, 0)
+		if err != nil {
+			return changed, fmt.Errorf("failed to list records of dataset %q: %w", key, err)
+		}
+
+		// the dataset was modified without any change to its records
+		// (e.g. merged), or a change was already reported
+		if known && syncCount <= prev.syncCount {
+			prev.lastModifiedDate = lastModifiedDate
+			continue
+		}
+
+		if err := a.sendCognitoEvent(ds, records, syncCount); err != nil {
+			return changed, fmt.Errorf("failed to send event for dataset %q: %w", key, err)
+		}
+		changed = true
+
+		a.datasets[key] = &datasetState{
+			lastModifiedDate: lastModifiedDate,
+			syncCount:        syncCount,
+		}
+	}
+
+	for key := range a.datasets {
+		if _, exists := current[key]; !exists {
+			delete(a.datasets, key)
+		}
+	}
+
+	return changed, nil
+}
+
+// listAllDatasets returns the datasets of all identities of the identity pool.
+func (a *adapter) listAllDatasets() ([]*cognitosync.Dataset, error) {
+	identities, err := a.getIdentities()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	datasets, err := a.getDatasets(identities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list datasets: %w", err)
+	}
+
+	return datasets, nil
+}
+
+// datasetKey returns a key which uniquely identifies the given dataset within
+// the identity pool.
+func datasetKey(ds *cognitosync.Dataset) string {
+	return aws.StringValue(ds.IdentityId) + "/" + aws.StringValue(ds.DatasetName)
+}
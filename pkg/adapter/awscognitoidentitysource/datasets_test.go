@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitoidentitysource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentity"
+	"github.com/aws/aws-sdk-go/service/cognitosync"
+	"github.com/aws/aws-sdk-go/service/cognitosync/cognitosynciface"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	loggingtesting "knative.dev/pkg/logging/testing"
+)
+
+// mockedClientForDatasets returns the given datasets of the identity "id1",
+// indexed by dataset name.
+type mockedClientForDatasets struct {
+	cognitosynciface.CognitoSyncAPI
+	datasets map[string]*mockDataset
+}
+
+type mockDataset struct {
+	lastModified time.Time
+	// records indexed by key
+	records map[string]*cognitosync.Record
+}
+
+func (m *mockedClientForDatasets) ListDatasets(in *cognitosync.ListDatasetsInput) (*cognitosync.ListDatasetsOutput, error) {
+	out := &cognitosync.ListDatasetsOutput{}
+	for name, ds := range m.datasets {
+		out.Datasets = append(out.Datasets, &cognitosync.Dataset{
+			IdentityId:       in.IdentityId,
+			DatasetName:      aws.String(name),
+			LastModifiedDate: aws.Time(ds.lastModified),
+		})
+	}
+	return out, nil
+}
+
+func (m *mockedClientForDatasets) ListRecords(in *cognitosync.ListRecordsInput) (*cognitosync.ListRecordsOutput, error) {
+	ds := m.datasets[*in.DatasetName]
+
+	out := &cognitosync.ListRecordsOutput{
+		DatasetSyncCount: aws.Int64(0),
+	}
+	for _, r := range ds.records {
+		if *r.SyncCount > *out.DatasetSyncCount {
+			out.DatasetSyncCount = r.SyncCount
+		}
+		if *r.SyncCount > *in.LastSyncCount {
+			out.Records = append(out.Records, r)
+		}
+	}
+	return out, nil
+}
+
+func TestProcessDatasets(t *testing.T) {
+	t0 := time.Unix(0, 0)
+	t1 := time.Unix(1, 0)
+
+	record := func(key string, syncCount int64) *cognitosync.Record {
+		return &cognitosync.Record{Key: aws.String(key), SyncCount: aws.Int64(syncCount)}
+	}
+
+	ceClient := adaptertest.NewTestClient()
+
+	syncClient := &mockedClientForDatasets{
+		datasets: map[string]*mockDataset{
+			"ds1": {lastModified: t0, records: map[string]*cognitosync.Record{"a": record("a", 1), "b": record("b", 2)}},
+		},
+	}
+
+	a := &adapter{
+		logger:         loggingtesting.TestLogger(t),
+		ceClient:       ceClient,
+		identityPoolID: "fooPool",
+		cgnIdentityClient: mockedCognitoIdentityClient{
+			listIdentitiesOutput: cognitoidentity.ListIdentitiesOutput{
+				Identities: []*cognitoidentity.IdentityDescription{{IdentityId: aws.String("id1")}},
+			},
+		},
+		cgnSyncClient: syncClient,
+	}
+
+	require.NoError(t, a.syncDatasets())
+
+	// no change
+
+	changed, err := a.processDatasets()
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, ceClient.Sent())
+
+	// modified dataset and new dataset
+
+	syncClient.datasets["ds1"].lastModified = t1
+	syncClient.datasets["ds1"].records["b"] = record("b", 3)
+	syncClient.datasets["ds2"] = &mockDataset{
+		lastModified: t1,
+		records:      map[string]*cognitosync.Record{"c": record("c", 1)},
+	}
+
+	changed, err = a.processDatasets()
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	sent := ceClient.Sent()
+	require.Len(t, sent, 2)
+
+	events := make(map[string]*CognitoIdentitySyncEvent, len(sent))
+	for _, e := range sent {
+		data := &CognitoIdentitySyncEvent{}
+		require.NoError(t, e.DataAs(data))
+		events[e.ID()] = data
+	}
+
+	require.Contains(t, events, "id1/ds1/3")
+	require.Len(t, events["id1/ds1/3"].DatasetRecords, 1, "Expected only the modified record")
+	assert.Equal(t, "b", *events["id1/ds1/3"].DatasetRecords[0].Key)
+
+	require.Contains(t, events, "id1/ds2/1")
+	assert.Len(t, events["id1/ds2/1"].DatasetRecords, 1)
+
+	// modification without change to the records
+
+	ceClient.Reset()
+	syncClient.datasets["ds1"].lastModified = t1.Add(time.Second)
+
+	changed, err = a.processDatasets()
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, ceClient.Sent())
+
+	// deleted dataset
+
+	delete(syncClient.datasets, "ds2")
+
+	_, err = a.processDatasets()
+	require.NoError(t, err)
+	assert.NotContains(t, a.datasets, "id1/ds2")
+}
@@ -31,8 +31,11 @@ type CognitoIdentitySyncEvent struct {
 	LastModifiedBy   *string
 	LastModifiedDate *time.Time
 	NumRecords       *int64
+	SyncCount        *int64
 	EventType        *string
 	Region           *string
 	IdentityPoolID   *string
-	DatasetRecords   []*cognitosync.Record
+	// Records which were modified by the change. Deleted records have a
+	// nil value.
+	DatasetRecords []*cognitosync.Record
 }
@@ -0,0 +1,52 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitoidentitysource
+
+import (
+	"github.com/aws/aws-sdk-go/service/cognitosync"
+
+	"github.com/triggermesh/aws-event-sources/pkg/adapter/common"
+)
+
+// redactRecords returns a copy of the given dataset records with the
+// redaction policy of the given Redactor applied to them. Records are matched
+// by key. Deleted records, which have no value, are left untouched.
+func redactRecords(r *common.Redactor, records []*cognitosync.Record) []*cognitosync.Record {
+	if r == nil {
+		return records
+	}
+
+	redacted := make([]*cognitosync.Record, 0, len(records))
+
+	for _, rec := range records {
+		if rec.Key == nil || rec.Value == nil {
+			redacted = append(redacted, rec)
+			continue
+		}
+
+		v, keep := r.Redact(*rec.Key, *rec.Value)
+		if !keep {
+			continue
+		}
+
+		redactedRec := *rec
+		redactedRec.Value = &v
+		redacted = append(redacted, &redactedRec)
+	}
+
+	return redacted
+}
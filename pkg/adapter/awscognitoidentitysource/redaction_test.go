@@ -0,0 +1,57 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitoidentitysource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitosync"
+
+	"github.com/triggermesh/aws-event-sources/pkg/adapter/common"
+)
+
+func TestRedactRecords(t *testing.T) {
+	redactor, err := common.NewRedactor(&common.RedactionEnvConfig{
+		RedactDrop: []string{"token"},
+		RedactMask: []string{"phone"},
+	})
+	require.NoError(t, err)
+
+	records := []*cognitosync.Record{
+		{Key: aws.String("token"), Value: aws.String("abcdef")},
+		{Key: aws.String("phone"), Value: aws.String("+15555550100")},
+		{Key: aws.String("phone")},
+		{Key: aws.String("theme"), Value: aws.String("dark")},
+	}
+
+	redacted := redactRecords(redactor, records)
+
+	assert.Equal(t, []*cognitosync.Record{
+		{Key: aws.String("phone"), Value: aws.String("********0100")},
+		{Key: aws.String("phone")},
+		{Key: aws.String("theme"), Value: aws.String("dark")},
+	}, redacted)
+
+	// the original records must be left untouched
+	assert.Equal(t, "+15555550100", *records[1].Value)
+
+	assert.Equal(t, records, redactRecords(nil, records))
+}
@@ -18,17 +18,17 @@ package awscognitouserpoolsource
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
 	"go.uber.org/zap"
-	"k8s.io/apimachinery/pkg/util/uuid"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
 
@@ -43,8 +43,19 @@ import (
 // adapter.
 type envConfig struct {
 	pkgadapter.EnvConfig
+	common.RedactionEnvConfig
+	common.SessionEnvConfig
+	common.HAEnvConfig
 
 	ARN string `envconfig:"ARN" required:"true"`
+
+	// Name of a ConfigMap in which the adapter's state is persisted
+	StateConfigMap string `envconfig:"STATE_CONFIGMAP"`
+
+	// Whether changes to the members of groups are reported
+	TrackGroups bool `envconfig:"TRACK_GROUPS"`
+	// Whether auth events are reported (requires advanced security)
+	TrackAuthEvents bool `envconfig:"TRACK_AUTH_EVENTS"`
 }
 
 // adapter implements the source's adapter.
@@ -56,6 +67,17 @@ type adapter struct {
 
 	arn        arn.ARN
 	userPoolID string
+
+	trackGroups     bool
+	trackAuthEvents bool
+
+	// redacts sensitive user attributes from events, if set
+	redactor *common.Redactor
+
+	state adapterState
+
+	// persists the adapter's state across restarts, if set
+	stateStore common.StateStore
 }
 
 // NewEnvConfig returns an accessor for the source's adapter envConfig.
@@ -71,12 +93,22 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 
 	arn := common.MustParseARN(env.ARN)
 
-	cfg := session.Must(session.NewSession(aws.NewConfig().
+	redactor, err := common.NewRedactor(&env.RedactionEnvConfig)
+	if err != nil {
+		logger.Fatalw("Invalid redaction policy", zap.Error(err))
+	}
+
+	var stateStore common.StateStore
+	if env.StateConfigMap != "" {
+		stateStore = common.MustNewConfigMapStateStore(env.Namespace, env.StateConfigMap)
+	}
+
+	cfg := common.MustNewSession(env.SessionEnvConfig, aws.NewConfig().
 		WithRegion(arn.Region).
 		WithMaxRetries(5),
-	))
+	)
 
-	return &adapter{
+	a := &adapter{
 		logger: logger,
 
 		cgnIdentityClient: cognitoidentityprovider.New(cfg),
@@ -84,82 +116,121 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 
 		arn:        arn,
 		userPoolID: common.MustParseCognitoUserPoolResource(arn.Resource),
+
+		trackGroups:     env.TrackGroups,
+		trackAuthEvents: env.TrackAuthEvents,
+
+		redactor: redactor,
+
+		stateStore: stateStore,
 	}
+
+	return common.WithLeaderElection(a, env.HAEnvConfig, env.Namespace, logger)
 }
 
 // Start implements adapter.Adapter.
 func (a *adapter) Start(ctx context.Context) error {
 	a.logger.Infof("Listening to AWS Cognito User Pool: %s", a.userPoolID)
 
-	var latestTimestamp time.Time
+	if a.stateStore != nil {
+		found, err := a.stateStore.Load(ctx, &a.state)
+		if err != nil {
+			a.logger.Errorf("Failed to load persisted state: %v", err)
+			return err
+		}
+		if found {
+			a.logger.Info("Resuming from persisted state")
+		}
+	}
 
 	backoff := common.NewBackoff()
 
 	err := backoff.Run(ctx.Done(), func(ctx context.Context) (bool, error) {
-		resetBackoff := false
-		users, err := a.listUsers()
-		if err != nil {
-			a.logger.Errorf("Cognito ListUsers failed: %v", err)
-			return resetBackoff, err
-		}
-
-		users, latestTimestamp = filterByTimestamp(users, latestTimestamp)
+		defer a.saveState(ctx)
 
-		for _, user := range users {
-			// we have new users - reset backoff duration
-			resetBackoff = true
-			err := a.sendCognitoEvent(user)
-			if err != nil {
-				a.logger.Errorf("Failed to send cloudevent: %v", err)
+		if a.state.Users == nil {
+			if err := a.syncUsers(); err != nil {
+				a.logger.Errorf("Cognito ListUsers failed: %v", err)
+				return false, err
 			}
 		}
-		return resetBackoff, nil
+
+		changed, err := a.poll()
+		if err != nil {
+			a.logger.Errorf("Failed to process user pool changes: %v", err)
+		}
+
+		// we have new changes - reset backoff duration
+		return changed, nil
 	})
 
 	return err
 }
 
-func (a *adapter) listUsers() ([]*cognitoidentityprovider.UserType, error) {
-	input := &cognitoidentityprovider.ListUsersInput{
-		UserPoolId: &a.userPoolID,
-	}
-	output, err := a.cgnIdentityClient.ListUsers(input)
+// poll sends events about all changes which occurred on the user pool since
+// the previous call. The groups and auth events of the pool are recorded
+// without sending any event the first time they are tracked. It returns
+// whether any event was sent.
+func (a *adapter) poll() (bool /*changed*/, error) {
+	changed, err := a.processUsers()
 	if err != nil {
-		return nil, err
+		return changed, err
 	}
-	if output == nil {
-		return nil, fmt.Errorf("cognito ListUsers response is nil")
-	}
-	return output.Users, nil
-}
 
-func filterByTimestamp(users []*cognitoidentityprovider.UserType, latestTimestamp time.Time) ([]*cognitoidentityprovider.UserType, time.Time) {
-	var newUsers []*cognitoidentityprovider.UserType
-	newLatestTimestamp := latestTimestamp
-	for _, user := range users {
-		// Get latest modification timestamp from users list
-		// and store it in temporary variable
-		if user.UserLastModifiedDate.After(newLatestTimestamp) {
-			newLatestTimestamp = *user.UserLastModifiedDate
+	if a.trackGroups {
+		if a.state.Groups == nil {
+			if err := a.syncGroups(); err != nil {
+				return changed, err
+			}
+		} else {
+			c, err := a.processGroups()
+			changed = changed || c
+			if err != nil {
+				return changed, err
+			}
 		}
-		// latest.isZero() true in first iteration - do not send already existing users.
-		// Also, do not send user object if it was not modified after our latest timestamp mark.
-		// (we use "not after" because "before" will be always false for the last created user)
-		if latestTimestamp.IsZero() || !user.UserLastModifiedDate.After(latestTimestamp) {
-			continue
+	}
+
+	if a.trackAuthEvents {
+		if a.state.AuthEvents == nil {
+			a.syncAuthEvents()
+		} else {
+			c, err := a.processAuthEvents()
+			changed = changed || c
+			if err != nil {
+				return changed, err
+			}
 		}
-		newUsers = append(newUsers, user)
 	}
-	return newUsers, newLatestTimestamp
+
+	return changed, nil
+}
+
+// saveState persists the adapter's current state, if a StateStore is set.
+func (a *adapter) saveState(ctx context.Context) {
+	if a.stateStore == nil {
+		return
+	}
+
+	if err := a.stateStore.Save(ctx, &a.state); err != nil {
+		a.logger.Errorf("Failed to persist state: %v", err)
+	}
 }
 
-func (a *adapter) sendCognitoEvent(user *cognitoidentityprovider.UserType) error {
+// sendCognitoEvent sends an event of the given type and ID about the user
+// pool.
+func (a *adapter) sendCognitoEvent(eventType, id string, data interface{}) error {
 	event := cloudevents.NewEvent(cloudevents.VersionV1)
 	event.SetSubject(a.userPoolID)
 	event.SetSource(a.arn.String())
-	event.SetID(string(uuid.NewUUID()))
-	event.SetType(v1alpha1.AWSEventType(a.arn.Service, v1alpha1.AWSCognitoUserPoolGenericEventType))
-	if err := event.SetData(cloudevents.ApplicationJSON, user); err != nil {
+	event.SetID(id)
+	event.SetType(v1alpha1.AWSEventType(a.arn.Service, eventType))
+
+	if r, ok := data.(redactable); ok {
+		r.redact(a.redactor)
+	}
+
+	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
 		return fmt.Errorf("failed to set event data: %w", err)
 	}
 
@@ -168,3 +239,22 @@ func (a *adapter) sendCognitoEvent(user *cognitoidentityprovider.UserType) error
 	}
 	return nil
 }
+
+// eventID returns a deterministic event ID derived from the given values, so
+// that a change which is reported again (e.g. after a failure to deliver it)
+// can be de-duplicated by consumers.
+func eventID(values ...string) string {
+	h := sha256.New()
+	for _, v := range values {
+		_, _ = h.Write([]byte(v))
+		_, _ = h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// timeKey returns a representation of the given time which is suitable for
+// the derivation of event IDs.
+func timeKey(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
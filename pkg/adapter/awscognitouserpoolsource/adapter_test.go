@@ -18,117 +18,267 @@ package awscognitouserpoolsource
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
 
 	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
 	loggingtesting "knative.dev/pkg/logging/testing"
+
+	"github.com/triggermesh/aws-event-sources/pkg/adapter/common"
 )
 
+// mockedCognitoUserPoolClient returns the given users, one page per element.
 type mockedCognitoUserPoolClient struct {
 	cognitoidentityprovideriface.CognitoIdentityProviderAPI
-	listUsersOutput cognitoidentityprovider.ListUsersOutput
+	pages [][]*cognitoidentityprovider.UserType
 }
 
-func (m mockedCognitoUserPoolClient) ListUsers(in *cognitoidentityprovider.ListUsersInput) (*cognitoidentityprovider.ListUsersOutput, error) {
-	return &m.listUsersOutput, nil
+func (m mockedCognitoUserPoolClient) ListUsersPages(in *cognitoidentityprovider.ListUsersInput,
+	fn func(*cognitoidentityprovider.ListUsersOutput, bool) bool) error {
+
+	for i, users := range m.pages {
+		if !fn(&cognitoidentityprovider.ListUsersOutput{Users: users}, i == len(m.pages)-1) {
+			break
+		}
+	}
+	return nil
 }
 
 func TestListUsers(t *testing.T) {
-	user := cognitoidentityprovider.UserType{
-		UserLastModifiedDate: aws.Time(time.Now()),
-	}
+	user1 := newUser("user1", time.Unix(0, 0), true, "CONFIRMED", nil)
+	user2 := newUser("user2", time.Unix(0, 0), true, "CONFIRMED", nil)
 
 	a := &adapter{
-		userPoolID: "userpool/fooPool",
+		userPoolID: "fooPool",
 		logger:     loggingtesting.TestLogger(t),
 		ceClient:   adaptertest.NewTestClient(),
 		cgnIdentityClient: mockedCognitoUserPoolClient{
-			listUsersOutput: cognitoidentityprovider.ListUsersOutput{
-				Users: []*cognitoidentityprovider.UserType{
-					&user,
-				},
+			pages: [][]*cognitoidentityprovider.UserType{
+				{user1},
+				{user2},
 			},
 		},
 	}
 
 	users, err := a.listUsers()
 	assert.NoError(t, err)
-	assert.Equal(t, []*cognitoidentityprovider.UserType{&user}, users)
+	assert.Equal(t, []*cognitoidentityprovider.UserType{user1, user2}, users)
+}
+
+func TestProcessUsers(t *testing.T) {
+	t0 := time.Unix(0, 0)
+	t1 := time.Unix(1, 0)
+
+	initialUsers := []*cognitoidentityprovider.UserType{
+		newUser("alice", t0, true, "CONFIRMED", map[string]string{"email": "alice@example.com"}),
+		newUser("bob", t0, true, "UNCONFIRMED", nil),
+	}
+
+	testCases := map[string]struct {
+		users         []*cognitoidentityprovider.UserType
+		expectEvents  []string
+		expectChanges map[string]*attributeChange
+	}{
+		"no change": {
+			users: initialUsers,
+		},
+		"user created": {
+			users: append(initialUsers[:2:2],
+				newUser("carol", t1, true, "CONFIRMED", nil),
+			),
+			expectEvents: []string{"com.amazon.cognito-idp.user_created"},
+		},
+		"user deleted": {
+			users:        initialUsers[:1],
+			expectEvents: []string{"com.amazon.cognito-idp.user_deleted"},
+		},
+		"user disabled": {
+			users: []*cognitoidentityprovider.UserType{
+				initialUsers[0],
+				newUser("bob", t1, false, "UNCONFIRMED", nil),
+			},
+			expectEvents: []string{"com.amazon.cognito-idp.user_disabled"},
+		},
+		"user confirmed": {
+			users: []*cognitoidentityprovider.UserType{
+				initialUsers[0],
+				newUser("bob", t1, true, "CONFIRMED", nil),
+			},
+			expectEvents: []string{"com.amazon.cognito-idp.user_status_changed"},
+		},
+		"user attributes updated": {
+			users: []*cognitoidentityprovider.UserType{
+				newUser("alice", t1, true, "CONFIRMED", map[string]string{
+					"email": "alice@example.org",
+					"name":  "Alice",
+				}),
+				initialUsers[1],
+			},
+			expectEvents: []string{"com.amazon.cognito-idp.user_updated"},
+			expectChanges: map[string]*attributeChange{
+				"email": {Old: aws.String("alice@example.com"), New: aws.String("alice@example.org")},
+				"name":  {New: aws.String("Alice")},
+			},
+		},
+		"modification without change": {
+			users: []*cognitoidentityprovider.UserType{
+				newUser("alice", t1, true, "CONFIRMED", map[string]string{"email": "alice@example.com"}),
+				initialUsers[1],
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			ceClient := adaptertest.NewTestClient()
+
+			client := &mockedCognitoUserPoolClient{
+				pages: [][]*cognitoidentityprovider.UserType{initialUsers},
+			}
+
+			a := &adapter{
+				logger:            loggingtesting.TestLogger(t),
+				ceClient:          ceClient,
+				cgnIdentityClient: client,
+				arn:               arn.ARN{Service: cognitoidentityprovider.ServiceName},
+				userPoolID:        "fooPool",
+			}
+
+			require.NoError(t, a.syncUsers())
+			assert.Equal(t, t0, a.state.HighWaterMark)
+
+			client.pages = [][]*cognitoidentityprovider.UserType{tc.users}
+
+			changed, err := a.processUsers()
+			require.NoError(t, err)
+
+			sent := ceClient.Sent()
+			assert.Equal(t, len(sent) > 0, changed)
+
+			gotEvents := make([]string, len(sent))
+			for i, e := range sent {
+				gotEvents[i] = e.Type()
+			}
+			if tc.expectEvents == nil {
+				assert.Empty(t, gotEvents)
+			} else {
+				assert.Equal(t, tc.expectEvents, gotEvents)
+			}
+
+			if tc.expectChanges != nil {
+				var data userEvent
+				require.NoError(t, sent[0].DataAs(&data))
+				assert.Equal(t, tc.expectChanges, data.Changes)
+			}
+
+			assert.Len(t, a.state.Users, len(tc.users))
+
+			// processing the same users again must not yield any event
+			ceClient.Reset()
+			_, err = a.processUsers()
+			require.NoError(t, err)
+			assert.Empty(t, ceClient.Sent())
+		})
+	}
 }
 
-func TestFilterByTimestamp(t *testing.T) {
-	now := time.Unix(0, 0)
+func TestProcessUsersFromPersistedState(t *testing.T) {
+	t0 := time.Unix(0, 0)
+	t1 := time.Unix(1, 0)
+
+	client := &mockedCognitoUserPoolClient{
+		pages: [][]*cognitoidentityprovider.UserType{{
+			newUser("alice", t0, true, "CONFIRMED", map[string]string{"email": "alice@example.com"}),
+		}},
+	}
 
-	// existing user
-	user1 := cognitoidentityprovider.UserType{
-		UserLastModifiedDate: &now,
+	a := &adapter{
+		logger:            loggingtesting.TestLogger(t),
+		ceClient:          adaptertest.NewTestClient(),
+		cgnIdentityClient: client,
+		arn:               arn.ARN{Service: cognitoidentityprovider.ServiceName},
+		userPoolID:        "fooPool",
 	}
 
-	// we must get 0 new users
-	users, timestamp := filterByTimestamp([]*cognitoidentityprovider.UserType{
-		&user1,
-	}, now)
-	assert.Len(t, users, 0)
+	require.NoError(t, a.syncUsers())
+
+	persisted, err := json.Marshal(&a.state)
+	require.NoError(t, err)
+	assert.NotContains(t, string(persisted), "alice@example.com", "Attribute values must not be persisted")
 
-	// new user
-	user2 := cognitoidentityprovider.UserType{
-		UserLastModifiedDate: aws.Time(time.Unix(0, 1)),
+	// simulate a restart of the adapter
+
+	ceClient := adaptertest.NewTestClient()
+
+	a = &adapter{
+		logger:            loggingtesting.TestLogger(t),
+		ceClient:          ceClient,
+		cgnIdentityClient: client,
+		arn:               arn.ARN{Service: cognitoidentityprovider.ServiceName},
+		userPoolID:        "fooPool",
 	}
+	require.NoError(t, json.Unmarshal(persisted, &a.state))
 
-	// we must get user2 only
-	users, timestamp = filterByTimestamp([]*cognitoidentityprovider.UserType{
-		&user1,
-		&user2,
-	}, timestamp)
-	assert.Equal(t, []*cognitoidentityprovider.UserType{&user2}, users)
+	client.pages = [][]*cognitoidentityprovider.UserType{{
+		newUser("alice", t1, true, "CONFIRMED", map[string]string{"email": "alice@example.org"}),
+	}}
 
-	// repeat filtering, we must ignore user2
-	users, _ = filterByTimestamp([]*cognitoidentityprovider.UserType{
-		&user1,
-		&user2,
-	}, timestamp)
-	assert.Len(t, users, 0)
+	_, err = a.processUsers()
+	require.NoError(t, err)
+
+	sent := ceClient.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "com.amazon.cognito-idp.user_updated", sent[0].Type())
+
+	var data userEvent
+	require.NoError(t, sent[0].DataAs(&data))
+	assert.Nil(t, data.Changes, "Previous attribute values are unknown")
 }
 
 func TestSendCognitoEvent(t *testing.T) {
 	ceClient := adaptertest.NewTestClient()
 
-	user := cognitoidentityprovider.UserType{
-		UserLastModifiedDate: aws.Time(time.Now().UTC()),
-	}
+	user := newUser("user1", time.Now().UTC(), true, "CONFIRMED", nil)
 
 	a := &adapter{
-		userPoolID:        "userpool/fooPool",
+		userPoolID:        "fooPool",
 		logger:            loggingtesting.TestLogger(t),
 		ceClient:          ceClient,
 		cgnIdentityClient: mockedCognitoUserPoolClient{},
+		arn:               arn.ARN{Service: cognitoidentityprovider.ServiceName},
 	}
 
-	err := a.sendCognitoEvent(&user)
+	err := a.sendCognitoEvent("user_created", "some-id", &userEvent{User: user})
 	assert.NoError(t, err)
 
 	events := ceClient.Sent()
-	assert.Len(t, events, 1)
+	require.Len(t, events, 1)
+	assert.Equal(t, "com.amazon.cognito-idp.user_created", events[0].Type())
+	assert.Equal(t, "some-id", events[0].ID())
+	assert.Equal(t, "fooPool", events[0].Subject())
 
-	var gotUser cognitoidentityprovider.UserType
-	err = events[0].DataAs(&gotUser)
+	var gotEvent userEvent
+	err = events[0].DataAs(&gotEvent)
 	assert.NoError(t, err)
 	// make sure that we get what we sent
-	assert.Equal(t, user, gotUser)
+	assert.Equal(t, user, gotEvent.User)
 }
 
 func TestStart(t *testing.T) {
 	const testTimeout = 2 * time.Second
 
 	a := &adapter{
-		userPoolID:        "userpool/fooPool",
+		userPoolID:        "fooPool",
 		logger:            loggingtesting.TestLogger(t),
 		ceClient:          adaptertest.NewTestClient(),
 		cgnIdentityClient: mockedCognitoUserPoolClient{},
@@ -160,3 +310,120 @@ func TestStart(t *testing.T) {
 		assert.NoError(t, err, "Receiver returned an error")
 	}
 }
+
+// newUser returns a user with the given properties.
+func newUser(name string, mod time.Time, enabled bool, status string,
+	attrs map[string]string) *cognitoidentityprovider.UserType {
+
+	u := &cognitoidentityprovider.UserType{
+		Username:             aws.String(name),
+		UserLastModifiedDate: aws.Time(mod),
+		Enabled:              aws.Bool(enabled),
+		UserStatus:           aws.String(status),
+	}
+
+	for k, v := range attrs {
+		u.Attributes = append(u.Attributes, &cognitoidentityprovider.AttributeType{
+			Name:  aws.String(k),
+			Value: aws.String(v),
+		})
+	}
+
+	return u
+}
+
+func TestSendCognitoEventRedacted(t *testing.T) {
+	ceClient := adaptertest.NewTestClient()
+
+	redactor, err := common.NewRedactor(&common.RedactionEnvConfig{
+		RedactDrop: []string{"custom:*"},
+		RedactMask: []string{"email"},
+	})
+	require.NoError(t, err)
+
+	a := &adapter{
+		userPoolID: "fooPool",
+		logger:     loggingtesting.TestLogger(t),
+		ceClient:   ceClient,
+		arn:        arn.ARN{Service: cognitoidentityprovider.ServiceName},
+		redactor:   redactor,
+	}
+
+	user := newUser("user1", time.Now().UTC(), true, "CONFIRMED", map[string]string{
+		"email":      "jane@example.com",
+		"custom:ssn": "123-45-6789",
+	})
+
+	event := &userEvent{
+		User: user,
+		Changes: map[string]*attributeChange{
+			"email":      {Old: aws.String("jane@example.org"), New: aws.String("jane@example.com")},
+			"custom:ssn": {New: aws.String("123-45-6789")},
+		},
+	}
+
+	err = a.sendCognitoEvent("user_updated", "some-id", event)
+	require.NoError(t, err)
+
+	sent := ceClient.Sent()
+	require.Len(t, sent, 1)
+
+	var gotEvent userEvent
+	require.NoError(t, sent[0].DataAs(&gotEvent))
+
+	require.Len(t, gotEvent.User.Attributes, 1)
+	assert.Equal(t, "email", *gotEvent.User.Attributes[0].Name)
+	assert.Equal(t, "************.com", *gotEvent.User.Attributes[0].Value)
+
+	assert.Equal(t, map[string]*attributeChange{
+		"email": {Old: aws.String("************.org"), New: aws.String("************.com")},
+	}, gotEvent.Changes)
+
+	// the original user must be left untouched
+	assert.Len(t, user.Attributes, 2)
+}
+
+func TestSendAuthEventRedacted(t *testing.T) {
+	ceClient := adaptertest.NewTestClient()
+
+	redactor, err := common.NewRedactor(&common.RedactionEnvConfig{
+		RedactDrop: []string{"eventContextData:city"},
+		RedactMask: []string{"eventContextData:ipAddress"},
+	})
+	require.NoError(t, err)
+
+	a := &adapter{
+		userPoolID: "fooPool",
+		logger:     loggingtesting.TestLogger(t),
+		ceClient:   ceClient,
+		arn:        arn.ARN{Service: cognitoidentityprovider.ServiceName},
+		redactor:   redactor,
+	}
+
+	e := &cognitoidentityprovider.AuthEventType{
+		EventId: aws.String("event-1"),
+		EventContextData: &cognitoidentityprovider.EventContextDataType{
+			IpAddress:  aws.String("192.0.2.123"),
+			City:       aws.String("Springfield"),
+			DeviceName: aws.String("Chrome, Linux"),
+		},
+	}
+
+	err = a.sendCognitoEvent("auth_event", "event-1", &authEvent{Username: "user1", Event: e})
+	require.NoError(t, err)
+
+	sent := ceClient.Sent()
+	require.Len(t, sent, 1)
+
+	var gotEvent authEvent
+	require.NoError(t, sent[0].DataAs(&gotEvent))
+
+	gotCtx := gotEvent.Event.EventContextData
+	require.NotNil(t, gotCtx)
+	assert.Equal(t, "*******.123", aws.StringValue(gotCtx.IpAddress))
+	assert.Nil(t, gotCtx.City)
+	assert.Equal(t, "Chrome, Linux", aws.StringValue(gotCtx.DeviceName))
+
+	// the original event must be left untouched
+	assert.Equal(t, "192.0.2.123", *e.EventContextData.IpAddress)
+}
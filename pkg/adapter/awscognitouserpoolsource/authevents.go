@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitouserpoolsource
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// Highest possible value for the MaxResults parameter of
+// AdminListUserAuthEvents requests.
+const maxAuthEventsPageSize = 60
+
+// authEventsUsersPerPoll is the maximum number of users whose auth events are
+// listed during a single poll. Auth events can only be listed for each user
+// individually, so the users of large pools are checked in turns over
+// multiple polls to stay within the request quotas of the Cognito API.
+const authEventsUsersPerPoll = 20
+
+// authEvent is the payload of an auth event.
+type authEvent struct {
+	Username string                                 `json:"username"`
+	Event    *cognitoidentityprovider.AuthEventType `json:"event"`
+}
+
+// syncAuthEvents records the current date as the date after which auth events
+// are reported, without sending any event.
+func (a *adapter) syncAuthEvents() {
+	a.state.AuthEventsSince = time.Now().UTC()
+	a.state.AuthEvents = make(map[string]time.Time)
+}
+
+// processAuthEvents sends an event for each auth event (sign-in, sign-up,
+// forgotten password) of known users which occurred since the previous call
+// for that user. At most authEventsUsersPerPoll users are processed per call,
+// starting after the user which was processed last. It returns whether any
+// event was sent.
+func (a *adapter) processAuthEvents() (bool /*changed*/, error) {
+	var changed bool
+
+	for _, username := range a.nextAuthEventsUsers() {
+		after := a.state.AuthEventsSince
+		if mark := a.state.AuthEvents[username]; mark.After(after) {
+			after = mark
+		}
+
+		events, err := a.listAuthEvents(username, after)
+		if err != nil {
+			return changed, err
+		}
+
+		// events are listed from the most recent to the oldest
+		for i := len(events) - 1; i >= 0; i-- {
+			e := events[i]
+
+			typ := v1alpha1.AWSCognitoUserPoolAuthEventType
+			data := &authEvent{Username: username, Event: e}
+
+			if err := a.sendCognitoEvent(typ, *e.EventId, data); err != nil {
+				return changed, fmt.Errorf("failed to send auth event %q of user %q: %w", *e.EventId, username, err)
+			}
+
+			a.state.AuthEvents[username] = aws.TimeValue(e.CreationDate)
+			changed = true
+		}
+
+		a.state.AuthEventsCursor = username
+	}
+
+	for username := range a.state.AuthEvents {
+		if _, known := a.state.Users[username]; !known {
+			delete(a.state.AuthEvents, username)
+		}
+	}
+
+	return changed, nil
+}
+
+// nextAuthEventsUsers returns the usernames of the next known users whose auth
+// events should be listed, in alphabetical order, starting after the user
+// which was processed last and wrapping around.
+func (a *adapter) nextAuthEventsUsers() []string {
+	usernames := a.knownUsernames()
+
+	if len(usernames) <= authEventsUsersPerPoll {
+		return usernames
+	}
+
+	start := sort.SearchStrings(usernames, a.state.AuthEventsCursor)
+	if start < len(usernames) && usernames[start] == a.state.AuthEventsCursor {
+		start++
+	}
+
+	next := make([]string, authEventsUsersPerPoll)
+	for i := range next {
+		next[i] = usernames[(start+i)%len(usernames)]
+	}
+
+	return next
+}
+
+// listAuthEvents returns the auth events of the given user which were created
+// after the given date, from the most recent to the oldest.
+func (a *adapter) listAuthEvents(username string, after time.Time) ([]*cognitoidentityprovider.AuthEventType, error) {
+	var events []*cognitoidentityprovider.AuthEventType
+
+	err := a.cgnIdentityClient.AdminListUserAuthEventsPages(&cognitoidentityprovider.AdminListUserAuthEventsInput{
+		UserPoolId: &a.userPoolID,
+		Username:   &username,
+		MaxResults: aws.Int64(maxAuthEventsPageSize),
+	}, func(page *cognitoidentityprovider.AdminListUserAuthEventsOutput, _ bool) bool {
+		for _, e := range page.AuthEvents {
+			if !aws.TimeValue(e.CreationDate).After(after) {
+				return false
+			}
+
+			events = append(events, e)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auth events of user %q: %w", username, err)
+	}
+
+	return events, nil
+}
+
+// knownUsernames returns the usernames of all known users, sorted
+// alphabetically.
+func (a *adapter) knownUsernames() []string {
+	usernames := make([]string, 0, len(a.state.Users))
+	for username := range a.state.Users {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	return usernames
+}
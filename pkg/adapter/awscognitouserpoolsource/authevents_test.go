@@ -0,0 +1,186 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitouserpoolsource
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	loggingtesting "knative.dev/pkg/logging/testing"
+)
+
+// mockedClientForAuthEvents returns the given auth events, indexed by
+// username and sorted from the oldest to the most recent.
+type mockedClientForAuthEvents struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	events map[string][]*cognitoidentityprovider.AuthEventType
+
+	// usernames of all AdminListUserAuthEvents requests
+	requests []string
+}
+
+func (m *mockedClientForAuthEvents) AdminListUserAuthEventsPages(in *cognitoidentityprovider.AdminListUserAuthEventsInput,
+	fn func(*cognitoidentityprovider.AdminListUserAuthEventsOutput, bool) bool) error {
+
+	m.requests = append(m.requests, *in.Username)
+
+	events := m.events[*in.Username]
+	pageSize := int(*in.MaxResults)
+
+	// the API returns events from the most recent to the oldest
+	var page []*cognitoidentityprovider.AuthEventType
+	for i := len(events) - 1; i >= 0; i-- {
+		page = append(page, events[i])
+
+		if len(page) == pageSize || i == 0 {
+			if !fn(&cognitoidentityprovider.AdminListUserAuthEventsOutput{AuthEvents: page}, i == 0) {
+				break
+			}
+			page = nil
+		}
+	}
+
+	return nil
+}
+
+func TestProcessAuthEvents(t *testing.T) {
+	newAuthEvent := func(sec int64) *cognitoidentityprovider.AuthEventType {
+		return &cognitoidentityprovider.AuthEventType{
+			EventId:      aws.String("event-" + strconv.FormatInt(sec, 10)),
+			EventType:    aws.String(cognitoidentityprovider.EventTypeSignIn),
+			CreationDate: aws.Time(time.Unix(sec, 0)),
+		}
+	}
+
+	ceClient := adaptertest.NewTestClient()
+
+	client := &mockedClientForAuthEvents{
+		events: map[string][]*cognitoidentityprovider.AuthEventType{
+			"alice": {newAuthEvent(1), newAuthEvent(2)},
+		},
+	}
+
+	a := &adapter{
+		logger:            loggingtesting.TestLogger(t),
+		ceClient:          ceClient,
+		cgnIdentityClient: client,
+		arn:               arn.ARN{Service: cognitoidentityprovider.ServiceName},
+		userPoolID:        "fooPool",
+	}
+
+	a.state.Users = map[string]*userSnapshot{
+		"alice": {},
+		"bob":   {},
+	}
+
+	a.syncAuthEvents()
+	assert.Empty(t, a.state.AuthEvents)
+	assert.False(t, a.state.AuthEventsSince.IsZero())
+
+	// pretend the existing events occurred before the sync
+	a.state.AuthEventsSince = time.Unix(2, 0)
+
+	// more events than fit in a page
+
+	for i := int64(3); i <= 3+maxAuthEventsPageSize; i++ {
+		client.events["alice"] = append(client.events["alice"], newAuthEvent(i))
+	}
+	client.events["bob"] = []*cognitoidentityprovider.AuthEventType{newAuthEvent(10)}
+
+	changed, err := a.processAuthEvents()
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	sent := ceClient.Sent()
+	require.Len(t, sent, maxAuthEventsPageSize+1+1)
+
+	// events are sent from the oldest to the most recent, with the ID of
+	// the auth event
+	assert.Equal(t, "event-3", sent[0].ID())
+	assert.Equal(t, "event-"+strconv.Itoa(3+maxAuthEventsPageSize), sent[maxAuthEventsPageSize].ID())
+	assert.Equal(t, "event-10", sent[len(sent)-1].ID())
+	assert.Equal(t, "com.amazon.cognito-idp.auth_event", sent[0].Type())
+
+	var data authEvent
+	require.NoError(t, sent[0].DataAs(&data))
+	assert.Equal(t, "alice", data.Username)
+
+	// processing the same events again must not yield any event
+
+	ceClient.Reset()
+	changed, err = a.processAuthEvents()
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, ceClient.Sent())
+
+	// marks of deleted users are dropped
+
+	delete(a.state.Users, "bob")
+	_, err = a.processAuthEvents()
+	require.NoError(t, err)
+	assert.NotContains(t, a.state.AuthEvents, "bob")
+}
+
+func TestProcessAuthEventsInTurns(t *testing.T) {
+	const numUsers = authEventsUsersPerPoll + 5
+
+	client := &mockedClientForAuthEvents{
+		events: make(map[string][]*cognitoidentityprovider.AuthEventType, numUsers),
+	}
+
+	a := &adapter{
+		logger:            loggingtesting.TestLogger(t),
+		ceClient:          adaptertest.NewTestClient(),
+		cgnIdentityClient: client,
+		arn:               arn.ARN{Service: cognitoidentityprovider.ServiceName},
+		userPoolID:        "fooPool",
+	}
+
+	a.state.Users = make(map[string]*userSnapshot, numUsers)
+	for i := 0; i < numUsers; i++ {
+		a.state.Users[fmt.Sprintf("user%02d", i)] = &userSnapshot{}
+	}
+
+	a.syncAuthEvents()
+
+	_, err := a.processAuthEvents()
+	require.NoError(t, err)
+	require.Len(t, client.requests, authEventsUsersPerPoll)
+	assert.Equal(t, "user00", client.requests[0])
+	assert.Equal(t, fmt.Sprintf("user%02d", authEventsUsersPerPoll-1), a.state.AuthEventsCursor)
+
+	// the next poll resumes after the last processed user and wraps around
+
+	client.requests = nil
+
+	_, err = a.processAuthEvents()
+	require.NoError(t, err)
+	require.Len(t, client.requests, authEventsUsersPerPoll)
+	assert.Equal(t, fmt.Sprintf("user%02d", authEventsUsersPerPoll), client.requests[0])
+	assert.Equal(t, "user00", client.requests[numUsers-authEventsUsersPerPoll])
+}
@@ -0,0 +1,269 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitouserpoolsource
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// groupSnapshot is the last known state of a group of the pool.
+type groupSnapshot struct {
+	// Usernames of the group's members, sorted alphabetically.
+	Members []string `json:"members"`
+	// Creation date of the group, which distinguishes a group from a
+	// previously deleted group with the same name.
+	Created time.Time `json:"created"`
+	// Number of times changes to the group's members were reported. It is
+	// part of the IDs of membership events, so that recurring changes
+	// (e.g. a user added to a group, removed, then added again) yield
+	// distinct events, while a change which is reported again after a
+	// failure yields the same event.
+	Revision int `json:"revision"`
+}
+
+// groupMembershipEvent is the payload of a group membership event.
+type groupMembershipEvent struct {
+	Group *cognitoidentityprovider.GroupType `json:"group"`
+	// Member which was added or removed. Only the username is set for
+	// removed members which are no longer known to the adapter.
+	User *cognitoidentityprovider.UserType `json:"user"`
+}
+
+// groupState is the current state of a group of the pool.
+type groupState struct {
+	group   *cognitoidentityprovider.GroupType
+	members map[string]*cognitoidentityprovider.UserType
+}
+
+// syncGroups records the current members of all groups of the pool, without
+// sending any event.
+func (a *adapter) syncGroups() error {
+	groups, err := a.listGroups()
+	if err != nil {
+		return err
+	}
+
+	a.state.Groups = make(map[string]*groupSnapshot, len(groups))
+
+	for name, g := range groups {
+		a.state.Groups[name] = &groupSnapshot{
+			Members: sortedUsernames(g.members),
+			Created: aws.TimeValue(g.group.CreationDate),
+		}
+	}
+
+	return nil
+}
+
+// processGroups sends an event for each user which was added to or removed
+// from a group of the pool since the previous call. Deleting a group counts
+// as removing all of its members. It returns whether any event was sent.
+func (a *adapter) processGroups() (bool /*changed*/, error) {
+	groups, err := a.listGroups()
+	if err != nil {
+		return false, err
+	}
+
+	names := make([]string, 0, len(groups)+len(a.state.Groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	for name := range a.state.Groups {
+		if _, exists := groups[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changed bool
+
+	for _, name := range names {
+		cur, exists := groups[name]
+		if !exists {
+			cur = &groupState{
+				group: &cognitoidentityprovider.GroupType{GroupName: aws.String(name)},
+			}
+		}
+
+		prev := a.state.Groups[name]
+
+		// a group which was deleted and created again since the previous
+		// call is a different group
+		if prev != nil && exists && !prev.Created.Equal(aws.TimeValue(cur.group.CreationDate)) {
+			if err := a.sendMembershipEvents(cur.group, prev, nil, prev.Members); err != nil {
+				return changed, err
+			}
+			changed = changed || len(prev.Members) > 0
+			prev = nil
+		}
+
+		if prev == nil {
+			prev = &groupSnapshot{
+				Created: aws.TimeValue(cur.group.CreationDate),
+			}
+		}
+
+		added, removed := diffMembers(prev.Members, sortedUsernames(cur.members))
+
+		addedUsers := make([]*cognitoidentityprovider.UserType, len(added))
+		for i, username := range added {
+			addedUsers[i] = cur.members[username]
+		}
+
+		if err := a.sendMembershipEvents(cur.group, prev, addedUsers, removed); err != nil {
+			return changed, err
+		}
+
+		revision := prev.Revision
+		if len(added) > 0 || len(removed) > 0 {
+			revision++
+			changed = true
+		}
+
+		if !exists {
+			delete(a.state.Groups, name)
+			continue
+		}
+
+		a.state.Groups[name] = &groupSnapshot{
+			Members:  sortedUsernames(cur.members),
+			Created:  prev.Created,
+			Revision: revision,
+		}
+	}
+
+	return changed, nil
+}
+
+// sendMembershipEvents sends an event for each of the given members which
+// were added to or removed from the given group.
+func (a *adapter) sendMembershipEvents(g *cognitoidentityprovider.GroupType, prev *groupSnapshot,
+	added []*cognitoidentityprovider.UserType, removed []string) error {
+
+	groupName := *g.GroupName
+	groupKey := []string{groupName, timeKey(prev.Created), strconv.Itoa(prev.Revision)}
+
+	for _, u := range added {
+		typ := v1alpha1.AWSCognitoUserPoolGroupMemberAddedEventType
+		id := eventID(append([]string{typ, *u.Username}, groupKey...)...)
+
+		if err := a.sendCognitoEvent(typ, id, &groupMembershipEvent{Group: g, User: u}); err != nil {
+			return fmt.Errorf("failed to send event for member %q of group %q: %w", *u.Username, groupName, err)
+		}
+	}
+
+	for _, username := range removed {
+		u := &cognitoidentityprovider.UserType{Username: aws.String(username)}
+		if s, known := a.state.Users[username]; known {
+			u = userOf(username, s)
+		}
+
+		typ := v1alpha1.AWSCognitoUserPoolGroupMemberRemovedEventType
+		id := eventID(append([]string{typ, username}, groupKey...)...)
+
+		if err := a.sendCognitoEvent(typ, id, &groupMembershipEvent{Group: g, User: u}); err != nil {
+			return fmt.Errorf("failed to send event for member %q of group %q: %w", username, groupName, err)
+		}
+	}
+
+	return nil
+}
+
+// diffMembers returns the usernames which are only contained in cur (added),
+// and the ones which are only contained in prev (removed). Both input slices
+// must be sorted.
+func diffMembers(prev, cur []string) (added, removed []string) {
+	i, j := 0, 0
+
+	for i < len(prev) || j < len(cur) {
+		switch {
+		case j == len(cur) || (i < len(prev) && prev[i] < cur[j]):
+			removed = append(removed, prev[i])
+			i++
+		case i == len(prev) || cur[j] < prev[i]:
+			added = append(added, cur[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+
+	return added, removed
+}
+
+// listGroups returns all groups of the pool along with their members, indexed
+// by group name.
+func (a *adapter) listGroups() (map[string]*groupState, error) {
+	var groups []*cognitoidentityprovider.GroupType
+
+	err := a.cgnIdentityClient.ListGroupsPages(&cognitoidentityprovider.ListGroupsInput{
+		UserPoolId: &a.userPoolID,
+	}, func(page *cognitoidentityprovider.ListGroupsOutput, _ bool) bool {
+		groups = append(groups, page.Groups...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	states := make(map[string]*groupState, len(groups))
+
+	for _, g := range groups {
+		members := make(map[string]*cognitoidentityprovider.UserType)
+
+		err := a.cgnIdentityClient.ListUsersInGroupPages(&cognitoidentityprovider.ListUsersInGroupInput{
+			UserPoolId: &a.userPoolID,
+			GroupName:  g.GroupName,
+		}, func(page *cognitoidentityprovider.ListUsersInGroupOutput, _ bool) bool {
+			for _, u := range page.Users {
+				members[*u.Username] = u
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members of group %q: %w", *g.GroupName, err)
+		}
+
+		states[*g.GroupName] = &groupState{
+			group:   g,
+			members: members,
+		}
+	}
+
+	return states, nil
+}
+
+// sortedUsernames returns the keys of the given map of users, sorted
+// alphabetically.
+func sortedUsernames(users map[string]*cognitoidentityprovider.UserType) []string {
+	usernames := make([]string, 0, len(users))
+	for username := range users {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	return usernames
+}
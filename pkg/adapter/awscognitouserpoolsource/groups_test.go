@@ -0,0 +1,208 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitouserpoolsource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	loggingtesting "knative.dev/pkg/logging/testing"
+)
+
+// mockedClientForGroups returns the given groups and their members, indexed
+// by group name.
+type mockedClientForGroups struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	groups  map[string]time.Time
+	members map[string][]string
+}
+
+func (m *mockedClientForGroups) ListGroupsPages(in *cognitoidentityprovider.ListGroupsInput,
+	fn func(*cognitoidentityprovider.ListGroupsOutput, bool) bool) error {
+
+	out := &cognitoidentityprovider.ListGroupsOutput{}
+	for name, created := range m.groups {
+		out.Groups = append(out.Groups, &cognitoidentityprovider.GroupType{
+			GroupName:    aws.String(name),
+			CreationDate: aws.Time(created),
+		})
+	}
+
+	fn(out, true)
+	return nil
+}
+
+func (m *mockedClientForGroups) ListUsersInGroupPages(in *cognitoidentityprovider.ListUsersInGroupInput,
+	fn func(*cognitoidentityprovider.ListUsersInGroupOutput, bool) bool) error {
+
+	out := &cognitoidentityprovider.ListUsersInGroupOutput{}
+	for _, username := range m.members[*in.GroupName] {
+		out.Users = append(out.Users, newUser(username, time.Unix(0, 0), true, "CONFIRMED", nil))
+	}
+
+	fn(out, true)
+	return nil
+}
+
+func TestProcessGroups(t *testing.T) {
+	t0 := time.Unix(0, 0)
+	t1 := time.Unix(1, 0)
+
+	type membershipChange struct {
+		typ   string
+		group string
+		user  string
+	}
+
+	testCases := map[string]struct {
+		groups  map[string]time.Time
+		members map[string][]string
+		expect  []membershipChange
+	}{
+		"no change": {
+			groups:  map[string]time.Time{"admins": t0, "devs": t0},
+			members: map[string][]string{"admins": {"alice"}, "devs": {"alice", "bob"}},
+		},
+		"members added and removed": {
+			groups:  map[string]time.Time{"admins": t0, "devs": t0},
+			members: map[string][]string{"admins": {"alice", "bob"}, "devs": {"bob"}},
+			expect: []membershipChange{
+				{"com.amazon.cognito-idp.group_member_added", "admins", "bob"},
+				{"com.amazon.cognito-idp.group_member_removed", "devs", "alice"},
+			},
+		},
+		"group created": {
+			groups:  map[string]time.Time{"admins": t0, "devs": t0, "ops": t1},
+			members: map[string][]string{"admins": {"alice"}, "devs": {"alice", "bob"}, "ops": {"bob"}},
+			expect: []membershipChange{
+				{"com.amazon.cognito-idp.group_member_added", "ops", "bob"},
+			},
+		},
+		"group deleted": {
+			groups:  map[string]time.Time{"devs": t0},
+			members: map[string][]string{"devs": {"alice", "bob"}},
+			expect: []membershipChange{
+				{"com.amazon.cognito-idp.group_member_removed", "admins", "alice"},
+			},
+		},
+		"group recreated": {
+			groups:  map[string]time.Time{"admins": t1, "devs": t0},
+			members: map[string][]string{"admins": {"bob"}, "devs": {"alice", "bob"}},
+			expect: []membershipChange{
+				{"com.amazon.cognito-idp.group_member_removed", "admins", "alice"},
+				{"com.amazon.cognito-idp.group_member_added", "admins", "bob"},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			ceClient := adaptertest.NewTestClient()
+
+			client := &mockedClientForGroups{
+				groups:  map[string]time.Time{"admins": t0, "devs": t0},
+				members: map[string][]string{"admins": {"alice"}, "devs": {"alice", "bob"}},
+			}
+
+			a := &adapter{
+				logger:            loggingtesting.TestLogger(t),
+				ceClient:          ceClient,
+				cgnIdentityClient: client,
+				arn:               arn.ARN{Service: cognitoidentityprovider.ServiceName},
+				userPoolID:        "fooPool",
+			}
+
+			require.NoError(t, a.syncGroups())
+
+			client.groups = tc.groups
+			client.members = tc.members
+
+			changed, err := a.processGroups()
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.expect) > 0, changed)
+
+			sent := ceClient.Sent()
+
+			var got []membershipChange
+			for _, e := range sent {
+				var data groupMembershipEvent
+				require.NoError(t, e.DataAs(&data))
+				got = append(got, membershipChange{e.Type(), *data.Group.GroupName, *data.User.Username})
+			}
+			assert.Equal(t, tc.expect, got)
+
+			// processing the same groups again must not yield any event
+			ceClient.Reset()
+			_, err = a.processGroups()
+			require.NoError(t, err)
+			assert.Empty(t, ceClient.Sent())
+		})
+	}
+}
+
+func TestProcessGroupsRecurringChange(t *testing.T) {
+	ceClient := adaptertest.NewTestClient()
+
+	client := &mockedClientForGroups{
+		groups:  map[string]time.Time{"admins": time.Unix(0, 0)},
+		members: map[string][]string{"admins": {"alice"}},
+	}
+
+	a := &adapter{
+		logger:            loggingtesting.TestLogger(t),
+		ceClient:          ceClient,
+		cgnIdentityClient: client,
+		arn:               arn.ARN{Service: cognitoidentityprovider.ServiceName},
+		userPoolID:        "fooPool",
+	}
+
+	require.NoError(t, a.syncGroups())
+
+	// remove, add, then remove "alice" again
+
+	for _, members := range [][]string{nil, {"alice"}, nil} {
+		client.members["admins"] = members
+		_, err := a.processGroups()
+		require.NoError(t, err)
+	}
+
+	sent := ceClient.Sent()
+	require.Len(t, sent, 3)
+
+	assert.Equal(t, sent[0].Type(), sent[2].Type())
+	assert.NotEqual(t, sent[0].ID(), sent[2].ID(), "Expected distinct IDs for distinct changes")
+}
+
+func TestDiffMembers(t *testing.T) {
+	added, removed := diffMembers(
+		[]string{"alice", "bob", "dave"},
+		[]string{"bob", "carol", "erin"},
+	)
+
+	assert.Equal(t, []string{"carol", "erin"}, added)
+	assert.Equal(t, []string{"alice", "dave"}, removed)
+}
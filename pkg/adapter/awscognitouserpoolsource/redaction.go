@@ -0,0 +1,140 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitouserpoolsource
+
+import (
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+
+	"github.com/triggermesh/aws-event-sources/pkg/adapter/common"
+)
+
+// redactable is implemented by event payloads which contain user attributes.
+type redactable interface {
+	// redact applies the redaction policy of the given Redactor to the
+	// payload.
+	redact(*common.Redactor)
+}
+
+var (
+	_ redactable = (*userEvent)(nil)
+	_ redactable = (*groupMembershipEvent)(nil)
+	_ redactable = (*authEvent)(nil)
+)
+
+// Prefix of the attribute names under which the context data of auth events
+// (IP address, device, location) is redacted, e.g.
+// "eventContextData:ipAddress".
+const eventContextDataAttrPrefix = "eventContextData:"
+
+// redact implements redactable.
+func (e *userEvent) redact(r *common.Redactor) {
+	if r == nil {
+		return
+	}
+
+	e.User = redactUser(r, e.User)
+
+	for name, c := range e.Changes {
+		if c.Old != nil {
+			v, keep := r.Redact(name, *c.Old)
+			if !keep {
+				delete(e.Changes, name)
+				continue
+			}
+			c.Old = &v
+		}
+		if c.New != nil {
+			v, keep := r.Redact(name, *c.New)
+			if !keep {
+				delete(e.Changes, name)
+				continue
+			}
+			c.New = &v
+		}
+	}
+}
+
+// redact implements redactable.
+func (e *groupMembershipEvent) redact(r *common.Redactor) {
+	if r == nil {
+		return
+	}
+
+	e.User = redactUser(r, e.User)
+}
+
+// redact implements redactable.
+func (e *authEvent) redact(r *common.Redactor) {
+	if r == nil || e.Event == nil || e.Event.EventContextData == nil {
+		return
+	}
+
+	redactedEvent := *e.Event
+	redactedCtx := *e.Event.EventContextData
+
+	for name, v := range map[string]**string{
+		"ipAddress":  &redactedCtx.IpAddress,
+		"deviceName": &redactedCtx.DeviceName,
+		"city":       &redactedCtx.City,
+		"country":    &redactedCtx.Country,
+		"timezone":   &redactedCtx.Timezone,
+	} {
+		if *v == nil {
+			continue
+		}
+
+		redacted, keep := r.Redact(eventContextDataAttrPrefix+name, **v)
+		if !keep {
+			*v = nil
+			continue
+		}
+		*v = &redacted
+	}
+
+	redactedEvent.EventContextData = &redactedCtx
+	e.Event = &redactedEvent
+}
+
+// redactUser returns a copy of the given user with the redaction policy of
+// the given Redactor applied to its attributes.
+func redactUser(r *common.Redactor, u *cognitoidentityprovider.UserType) *cognitoidentityprovider.UserType {
+	if u == nil {
+		return nil
+	}
+
+	redacted := *u
+	redacted.Attributes = make([]*cognitoidentityprovider.AttributeType, 0, len(u.Attributes))
+
+	for _, attr := range u.Attributes {
+		if attr.Name == nil || attr.Value == nil {
+			redacted.Attributes = append(redacted.Attributes, attr)
+			continue
+		}
+
+		v, keep := r.Redact(*attr.Name, *attr.Value)
+		if !keep {
+			continue
+		}
+
+		redacted.Attributes = append(redacted.Attributes, &cognitoidentityprovider.AttributeType{
+			Name:  attr.Name,
+			Value: &v,
+		})
+	}
+
+	return &redacted
+}
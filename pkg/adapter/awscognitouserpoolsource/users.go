@@ -0,0 +1,358 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitouserpoolsource
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// userSnapshot is the last known state of a user of the pool.
+//
+// The values of the user's attributes may contain personal information, so
+// only their digest is persisted. The values themselves are kept in memory to
+// describe the changes of attributes, and are unknown for snapshots restored
+// from a persisted state.
+type userSnapshot struct {
+	Attributes       map[string]string `json:"-"`
+	AttributesDigest string            `json:"attributesDigest"`
+	Enabled          bool              `json:"enabled"`
+	Status           string            `json:"status"`
+	LastModified     time.Time         `json:"lastModified"`
+}
+
+// userEvent is the payload of a user event.
+type userEvent struct {
+	// Current state of the user, or last known state if the user was
+	// deleted.
+	User *cognitoidentityprovider.UserType `json:"user"`
+	// Attributes which changed, for user update events. Omitted when the
+	// previous values of the attributes are unknown, e.g. after a restart
+	// of the adapter.
+	Changes map[string]*attributeChange `json:"changes,omitempty"`
+	// Previous status of the user, for status change events.
+	PreviousStatus string `json:"previousStatus,omitempty"`
+}
+
+// attributeChange describes the change of a user attribute. A nil value
+// indicates that the attribute was either added or removed.
+type attributeChange struct {
+	Old *string `json:"old"`
+	New *string `json:"new"`
+}
+
+// typedUserEvent associates a userEvent with its event type.
+type typedUserEvent struct {
+	typ  string
+	data *userEvent
+}
+
+// syncUsers records the current state of all users of the pool, without
+// sending any event.
+func (a *adapter) syncUsers() error {
+	users, err := a.listUsers()
+	if err != nil {
+		return err
+	}
+
+	a.state.Users = make(map[string]*userSnapshot, len(users))
+
+	for _, u := range users {
+		a.state.Users[*u.Username] = snapshotOf(u)
+
+		if mod := aws.TimeValue(u.UserLastModifiedDate); mod.After(a.state.HighWaterMark) {
+			a.state.HighWaterMark = mod
+		}
+	}
+
+	return nil
+}
+
+// processUsers sends an event for each change which occurred on the users of
+// the pool since the previous call.
+//
+// Only users modified since the last recorded modification date (high-water
+// mark) are compared with their last known state. Deleted users are detected
+// by comparing the list of current users with the list of known users. It
+// returns whether any event was sent.
+func (a *adapter) processUsers() (bool /*changed*/, error) {
+	users, err := a.listUsers()
+	if err != nil {
+		return false, err
+	}
+
+	var changed bool
+
+	current := make(map[string]struct{}, len(users))
+	highWaterMark := a.state.HighWaterMark
+
+	for _, u := range users {
+		username := *u.Username
+		current[username] = struct{}{}
+
+		mod := aws.TimeValue(u.UserLastModifiedDate)
+		if mod.After(highWaterMark) {
+			highWaterMark = mod
+		}
+
+		prev, known := a.state.Users[username]
+
+		// modification dates may not be precise enough to use a
+		// strict comparison, so users modified at the exact date of
+		// the high-water mark are compared too
+		if known && mod.Before(a.state.HighWaterMark) {
+			continue
+		}
+
+		snapshot := snapshotOf(u)
+
+		for _, e := range diffUser(u, prev, snapshot) {
+			id := eventID(e.typ, username, timeKey(mod))
+			if err := a.sendCognitoEvent(e.typ, id, e.data); err != nil {
+				return changed, fmt.Errorf("failed to send event for user %q: %w", username, err)
+			}
+			changed = true
+		}
+
+		a.state.Users[username] = snapshot
+	}
+
+	deleted := make([]string, 0)
+	for username := range a.state.Users {
+		if _, exists := current[username]; !exists {
+			deleted = append(deleted, username)
+		}
+	}
+	sort.Strings(deleted)
+
+	for _, username := range deleted {
+		snapshot := a.state.Users[username]
+
+		event := &userEvent{
+			User: userOf(username, snapshot),
+		}
+
+		typ := v1alpha1.AWSCognitoUserPoolUserDeletedEventType
+		id := eventID(typ, username, timeKey(snapshot.LastModified))
+
+		if err := a.sendCognitoEvent(typ, id, event); err != nil {
+			return changed, fmt.Errorf("failed to send event for user %q: %w", username, err)
+		}
+		changed = true
+
+		delete(a.state.Users, username)
+	}
+
+	a.state.HighWaterMark = highWaterMark
+
+	return changed, nil
+}
+
+// diffUser returns the events which describe the changes between the
+// previous and current states of the given user. A nil previous state
+// indicates a new user.
+func diffUser(u *cognitoidentityprovider.UserType, prev, cur *userSnapshot) []*typedUserEvent {
+	if prev == nil {
+		return []*typedUserEvent{{
+			typ:  v1alpha1.AWSCognitoUserPoolUserCreatedEventType,
+			data: &userEvent{User: u},
+		}}
+	}
+
+	var events []*typedUserEvent
+
+	if cur.Enabled != prev.Enabled {
+		typ := v1alpha1.AWSCognitoUserPoolUserDisabledEventType
+		if cur.Enabled {
+			typ = v1alpha1.AWSCognitoUserPoolUserEnabledEventType
+		}
+
+		events = append(events, &typedUserEvent{
+			typ:  typ,
+			data: &userEvent{User: u},
+		})
+	}
+
+	if cur.Status != prev.Status {
+		events = append(events, &typedUserEvent{
+			typ: v1alpha1.AWSCognitoUserPoolUserStatusChangedEventType,
+			data: &userEvent{
+				User:           u,
+				PreviousStatus: prev.Status,
+			},
+		})
+	}
+
+	if cur.AttributesDigest != prev.AttributesDigest {
+		var changes map[string]*attributeChange
+		if prev.Attributes != nil {
+			changes = diffAttributes(prev.Attributes, cur.Attributes)
+		}
+
+		events = append(events, &typedUserEvent{
+			typ: v1alpha1.AWSCognitoUserPoolUserUpdatedEventType,
+			data: &userEvent{
+				User:    u,
+				Changes: changes,
+			},
+		})
+	}
+
+	return events
+}
+
+// diffAttributes returns the attributes which differ between prev and cur,
+// indexed by attribute name.
+func diffAttributes(prev, cur map[string]string) map[string]*attributeChange {
+	changes := make(map[string]*attributeChange)
+
+	for name, oldVal := range prev {
+		oldVal := oldVal
+
+		newVal, exists := cur[name]
+		switch {
+		case !exists:
+			changes[name] = &attributeChange{Old: &oldVal}
+		case newVal != oldVal:
+			newVal := newVal
+			changes[name] = &attributeChange{Old: &oldVal, New: &newVal}
+		}
+	}
+
+	for name, newVal := range cur {
+		newVal := newVal
+
+		if _, exists := prev[name]; !exists {
+			changes[name] = &attributeChange{New: &newVal}
+		}
+	}
+
+	return changes
+}
+
+// listUsers returns all users of the pool.
+func (a *adapter) listUsers() ([]*cognitoidentityprovider.UserType, error) {
+	var users []*cognitoidentityprovider.UserType
+
+	err := a.cgnIdentityClient.ListUsersPages(&cognitoidentityprovider.ListUsersInput{
+		UserPoolId: &a.userPoolID,
+	}, func(page *cognitoidentityprovider.ListUsersOutput, _ bool) bool {
+		users = append(users, page.Users...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// snapshotOf returns a snapshot of the given user's state.
+func snapshotOf(u *cognitoidentityprovider.UserType) *userSnapshot {
+	attrs := make(map[string]string, len(u.Attributes))
+	for _, attr := range u.Attributes {
+		attrs[aws.StringValue(attr.Name)] = aws.StringValue(attr.Value)
+	}
+
+	return &userSnapshot{
+		Attributes:       attrs,
+		AttributesDigest: attributesDigest(attrs),
+		Enabled:          aws.BoolValue(u.Enabled),
+		Status:           aws.StringValue(u.UserStatus),
+		LastModified:     aws.TimeValue(u.UserLastModifiedDate),
+	}
+}
+
+// attributesDigest returns a digest of the given user attributes, which allows
+// detecting changes of attributes without retaining their values.
+//
+// Because all users have a randomly generated "sub" attribute, the values of
+// other attributes can not be recovered from the digest by enumerating likely
+// values.
+func attributesDigest(attrs map[string]string) string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(attrs[name]))
+		h.Write([]byte{0})
+	}
+
+	// a truncated digest is sufficient to detect changes and reduces the
+	// size of the persisted state
+	return base64.RawStdEncoding.EncodeToString(h.Sum(nil)[:16])
+}
+
+// userOf returns a user object reconstructed from the given snapshot. Its
+// attributes are empty if they are unknown.
+func userOf(username string, s *userSnapshot) *cognitoidentityprovider.UserType {
+	names := make([]string, 0, len(s.Attributes))
+	for name := range s.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]*cognitoidentityprovider.AttributeType, len(names))
+	for i, name := range names {
+		attrs[i] = &cognitoidentityprovider.AttributeType{
+			Name:  aws.String(name),
+			Value: aws.String(s.Attributes[name]),
+		}
+	}
+
+	return &cognitoidentityprovider.UserType{
+		Username:             &username,
+		Attributes:           attrs,
+		Enabled:              &s.Enabled,
+		UserStatus:           &s.Status,
+		UserLastModifiedDate: &s.LastModified,
+	}
+}
+
+// adapterState is the state of the adapter, as persisted in a StateStore.
+type adapterState struct {
+	// Most recent modification date of all known users.
+	HighWaterMark time.Time `json:"highWaterMark"`
+	// Last known states of all users, indexed by username.
+	Users map[string]*userSnapshot `json:"users"`
+	// Last known states of all groups, indexed by group name. Only
+	// tracked when group tracking is enabled.
+	Groups map[string]*groupSnapshot `json:"groups,omitempty"`
+	// Creation date of the most recent known auth event of each user,
+	// indexed by username. Only tracked when auth events tracking is
+	// enabled.
+	AuthEvents map[string]time.Time `json:"authEvents,omitempty"`
+	// Date at which auth events tracking started. Earlier auth events are
+	// never reported.
+	AuthEventsSince time.Time `json:"authEventsSince,omitempty"`
+	// Username of the last user whose auth events were listed.
+	AuthEventsCursor string `json:"authEventsCursor,omitempty"`
+}
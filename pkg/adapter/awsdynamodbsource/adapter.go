@@ -29,7 +29,6 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
@@ -51,6 +50,8 @@ const (
 // adapter.
 type envConfig struct {
 	pkgadapter.EnvConfig
+	common.SessionEnvConfig
+	common.HAEnvConfig
 
 	ARN string `envconfig:"ARN" required:"true"`
 }
@@ -65,7 +66,9 @@ type adapter struct {
 
 	arn arn.ARN
 
-	// tracker for running records processors
+	shards common.ShardOwnership
+
+	// tracker for running records processors (shard ID -> cancel func)
 	processors sync.Map
 	wg         sync.WaitGroup
 
@@ -86,9 +89,9 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 
 	arn := common.MustParseARN(env.ARN)
 
-	cfg := session.Must(session.NewSession(aws.NewConfig().
+	cfg := common.MustNewSession(env.SessionEnvConfig, aws.NewConfig().
 		WithRegion(arn.Region),
-	))
+	)
 
 	return &adapter{
 		logger: logger,
@@ -98,6 +101,8 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 		ceClient:       ceClient,
 
 		arn: arn,
+
+		shards: common.NewShardOwnership(env.HAEnvConfig, env.Namespace, logger),
 	}
 }
 
@@ -105,6 +110,8 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 func (a *adapter) Start(ctx context.Context) error {
 	a.logger.Info("Starting collection of DynamoDB records for table ", a.arn)
 
+	go a.shards.Run(ctx)
+
 	t := time.NewTimer(0)
 	defer t.Stop()
 
@@ -114,6 +121,11 @@ loop:
 		case <-ctx.Done():
 			break loop
 
+		case <-a.shards.Changed():
+			if a.lastStreamARN != nil {
+				a.syncRecordsProcessors(ctx, a.lastStreamARN)
+			}
+
 		case <-t.C:
 			streamARN, err := a.getLatestStreamARN(ctx)
 			if err != nil {
@@ -165,11 +177,13 @@ func (a *adapter) getLatestStreamARN(ctx context.Context) (*string, error) {
 	return table.Table.LatestStreamArn, nil
 }
 
-// recheckStream ensures a records processor is running for each of the stream's shards.
+// recheckStream ensures a records processor is running for each of the
+// stream's shards owned by the current replica.
 func (a *adapter) recheckStream(ctx context.Context, streamARN *string) error {
 	a.logger.Debug("Checking stream for new shards")
 
 	var lastEvaluatedShardID *string
+	var shardIDs []string
 
 	for {
 		stream, err := a.dyndbStrClient.DescribeStreamWithContext(ctx, &dynamodbstreams.DescribeStreamInput{
@@ -197,7 +211,7 @@ func (a *adapter) recheckStream(ctx context.Context, streamARN *string) error {
 		}
 
 		for _, s := range stream.StreamDescription.Shards {
-			a.ensureRecordsProcessor(ctx, streamARN, s.ShardId)
+			shardIDs = append(shardIDs, *s.ShardId)
 		}
 
 		lastEvaluatedShardID = stream.StreamDescription.LastEvaluatedShardId
@@ -209,12 +223,38 @@ func (a *adapter) recheckStream(ctx context.Context, streamARN *string) error {
 		}
 	}
 
+	a.shards.SetShards(shardIDs)
+	a.syncRecordsProcessors(ctx, streamARN)
+
 	return nil
 }
 
-// ensureRecordsProcessor ensures a records processor is running for the given shard.
-func (a *adapter) ensureRecordsProcessor(ctx context.Context, streamARN *string, shardID *string) {
-	if _, running := a.processors.LoadOrStore(*shardID, struct{}{}); running {
+// syncRecordsProcessors ensures a records processor is running for each shard
+// owned by the current replica, and stops the processors of other shards.
+func (a *adapter) syncRecordsProcessors(ctx context.Context, streamARN *string) {
+	owned := a.shards.Owned()
+
+	a.processors.Range(func(shardID, cancel interface{}) bool {
+		if _, isOwned := owned[shardID.(string)]; !isOwned {
+			a.logger.Info("Stopping records processor for shard ID ", shardID)
+			cancel.(context.CancelFunc)()
+		}
+		return true
+	})
+
+	for shardID, checkpoint := range owned {
+		a.ensureRecordsProcessor(ctx, streamARN, aws.String(shardID), checkpoint)
+	}
+}
+
+// ensureRecordsProcessor ensures a records processor is running for the given
+// shard. The processor starts after the given checkpointed sequence number if
+// it is not empty.
+func (a *adapter) ensureRecordsProcessor(ctx context.Context, streamARN *string, shardID *string, checkpoint string) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	if _, running := a.processors.LoadOrStore(*shardID, cancel); running {
+		cancel()
 		a.logger.Debug("Record processor already running for shard ID ", *shardID)
 		return
 	}
@@ -222,12 +262,15 @@ func (a *adapter) ensureRecordsProcessor(ctx context.Context, streamARN *string,
 	a.wg.Add(1)
 
 	go func() {
+		// a stopped processor remains registered until it returns, so
+		// that a shard is never read by two processors at once
 		defer a.processors.Delete(*shardID)
+		defer cancel()
 		defer a.wg.Done()
 
 		a.logger.Info("Starting records processor for shard ID ", *shardID)
 
-		if err := a.runRecordsProcessor(ctx, streamARN, shardID); err != nil {
+		if err := a.runRecordsProcessor(ctx, streamARN, shardID, checkpoint); err != nil {
 			a.logger.Errorw("Records processor for shard ID "+*shardID+" returned with error", zap.Error(err))
 			return
 		}
@@ -237,12 +280,18 @@ func (a *adapter) ensureRecordsProcessor(ctx context.Context, streamARN *string,
 }
 
 // runRecordsProcessor runs a records processor for the given shard.
-func (a *adapter) runRecordsProcessor(ctx context.Context, streamARN *string, shardID *string) error {
-	si, err := a.dyndbStrClient.GetShardIteratorWithContext(ctx, &dynamodbstreams.GetShardIteratorInput{
+func (a *adapter) runRecordsProcessor(ctx context.Context, streamARN *string, shardID *string, checkpoint string) error {
+	siInput := &dynamodbstreams.GetShardIteratorInput{
 		StreamArn:         streamARN,
 		ShardId:           shardID,
 		ShardIteratorType: aws.String(dynamodbstreams.ShardIteratorTypeLatest),
-	})
+	}
+	if checkpoint != "" {
+		siInput.ShardIteratorType = aws.String(dynamodbstreams.ShardIteratorTypeAfterSequenceNumber)
+		siInput.SequenceNumber = aws.String(checkpoint)
+	}
+
+	si, err := a.dyndbStrClient.GetShardIteratorWithContext(ctx, siInput)
 	if err != nil {
 		return fmt.Errorf("getting shard iterator for shard ID %s: %w", *shardID, err)
 	}
@@ -285,6 +334,10 @@ loop:
 				if err := a.sendDynamoDBEvent(r); err != nil {
 					return fmt.Errorf("sending CloudEvent: %w", err)
 				}
+
+				if r.Dynamodb != nil && r.Dynamodb.SequenceNumber != nil {
+					a.shards.Checkpoint(*shardID, *r.Dynamodb.SequenceNumber)
+				}
 			}
 
 			currentShardIter = r.NextShardIterator
@@ -35,6 +35,8 @@ import (
 
 	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
 	loggingtesting "knative.dev/pkg/logging/testing"
+
+	"github.com/triggermesh/aws-event-sources/pkg/adapter/common"
 )
 
 const (
@@ -66,6 +68,7 @@ func TestAdapter(t *testing.T) {
 		dyndbStrClient: strClient,
 		arn:            makeARN(tTableArnResource),
 		ceClient:       ceClient,
+		shards:         common.AllShards(),
 	}
 
 	testCtx, testCancel := context.WithTimeout(context.Background(), testTimeout)
@@ -163,7 +166,7 @@ func (c *standardMockDynamoDBStreamsClient) DescribeStreamWithContext(context.Co
 
 	for id := range c.shards {
 		shards = append(shards, &dynamodbstreams.Shard{
-			ShardId: id,
+			ShardId: aws.String(id),
 		})
 	}
 
@@ -183,7 +186,7 @@ func (c *standardMockDynamoDBStreamsClient) GetShardIteratorWithContext(_ contex
 	in *dynamodbstreams.GetShardIteratorInput, _ ...request.Option) (*dynamodbstreams.GetShardIteratorOutput, error) {
 
 	return &dynamodbstreams.GetShardIteratorOutput{
-		ShardIterator: c.shards[in.ShardId][0].name,
+		ShardIterator: c.shards[*in.ShardId][0].name,
 	}, nil
 }
 
@@ -225,7 +228,7 @@ func (c *standardMockDynamoDBStreamsClient) GetRecordsWithContext(_ context.Cont
 //    \_ [] shard iterator
 //           \_ [] record
 //
-type mockShards map[ /*shard id*/ *string][]*mockShardIterator
+type mockShards map[ /*shard id*/ string][]*mockShardIterator
 type mockShardIterator struct {
 	name    *string
 	records []*dynamodbstreams.Record
@@ -236,7 +239,7 @@ func makeMockShards(n, itersPerShard int) mockShards {
 	shards := make(mockShards, n)
 
 	for i := 0; i < n; i++ {
-		id := aws.String(fmt.Sprintf(tShardIDPrefix+"%03d", i+1))
+		id := fmt.Sprintf(tShardIDPrefix+"%03d", i+1)
 		shards[id] = makeMockIterators(itersPerShard, i+1)
 	}
 
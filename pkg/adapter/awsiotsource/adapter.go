@@ -0,0 +1,382 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsiotsource
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+
+	pkgadapter "knative.dev/eventing/pkg/adapter/v2"
+	"knative.dev/pkg/logging"
+
+	"github.com/triggermesh/aws-event-sources/pkg/adapter/common"
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+const (
+	// Port of the AWS IoT Core endpoints for MQTT over TLS with client
+	// certificate authentication.
+	defaultMQTTPort = "8883"
+
+	// Interval at which the server is pinged in the absence of other
+	// traffic. The connection is considered broken if nothing is received
+	// from the server within 1.5 times this interval.
+	keepAlive = 30 * time.Second
+	// Maximum duration of a connection attempt.
+	connectTimeout = 10 * time.Second
+	// Highest QoS requested for subscriptions (at least once delivery).
+	subscriptionQoS = 1
+)
+
+// envConfig is a set parameters sourced from the environment for the source's
+// adapter.
+type envConfig struct {
+	pkgadapter.EnvConfig
+
+	ARN string `envconfig:"ARN" required:"true"`
+
+	// Host name of the AWS IoT Core data endpoint, optionally followed
+	// by a port
+	Endpoint string `envconfig:"THING_SHADOW_ENDPOINT" required:"true"`
+	// MQTT topic filters to subscribe to
+	Topics []string `envconfig:"TOPICS" required:"true"`
+	// MQTT client identifier, defaults to "<namespace>.<name>"
+	ClientID string `envconfig:"CLIENT_ID"`
+
+	// PEM-encoded root CA, client certificate and client private key
+	RootCA      string `envconfig:"ROOT_CA"`
+	Certificate string `envconfig:"CERTIFICATE" required:"true"`
+	PrivateKey  string `envconfig:"PRIVATE_KEY" required:"true"`
+}
+
+// adapter implements the source's adapter.
+type adapter struct {
+	logger *zap.SugaredLogger
+
+	ceClient cloudevents.Client
+
+	arn      arn.ARN
+	topics   []string
+	clientID string
+
+	// dials the MQTT server
+	dial func(context.Context) (net.Conn, error)
+
+	// Unix time (nanoseconds) at which the last packet was received from
+	// the MQTT server
+	lastActivity int64
+
+	// whether a MQTT session was established at least once
+	hadSession bool
+
+	// IDs of the CloudEvents sent for QoS 1 messages which haven't been
+	// acknowledged yet. Only accessed by the goroutine which receives
+	// messages, and retained across connections of a same session.
+	inFlight map[uint16]string /*packet ID -> event ID*/
+}
+
+// NewEnvConfig returns an accessor for the source's adapter envConfig.
+func NewEnvConfig() pkgadapter.EnvConfigAccessor {
+	return &envConfig{}
+}
+
+// NewAdapter returns a constructor for the source's adapter.
+func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClient cloudevents.Client) pkgadapter.Adapter {
+	logger := logging.FromContext(ctx)
+
+	env := envAcc.(*envConfig)
+
+	addr := env.Endpoint
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, defaultMQTTPort)
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		logger.Fatalw("Invalid endpoint "+env.Endpoint, zap.Error(err))
+	}
+
+	tlsCfg, err := newTLSConfig(host, []byte(env.RootCA), []byte(env.Certificate), []byte(env.PrivateKey))
+	if err != nil {
+		logger.Fatalw("Unable to load TLS configuration", zap.Error(err))
+	}
+
+	clientID := env.ClientID
+	if clientID == "" {
+		clientID = env.Namespace + "." + env.Name
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: connectTimeout},
+		Config:    tlsCfg,
+	}
+
+	return &adapter{
+		logger: logger,
+
+		ceClient: ceClient,
+
+		arn:      common.MustParseARN(env.ARN),
+		topics:   env.Topics,
+		clientID: clientID,
+
+		dial: func(ctx context.Context) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", addr)
+		},
+	}
+}
+
+// newTLSConfig returns a TLS configuration for mutual authentication with
+// the given server, using the given PEM-encoded client certificate and key.
+// The server certificate is verified against the given root CA, or against
+// the system's root CAs if rootCA is empty.
+func newTLSConfig(serverName string, rootCA, cert, key []byte) (*tls.Config, error) {
+	clientCert, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("loading client certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		ServerName:   serverName,
+		Certificates: []tls.Certificate{clientCert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if len(rootCA) > 0 {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(rootCA) {
+			return nil, errors.New("no valid certificate found in root CA")
+		}
+	}
+
+	return cfg, nil
+}
+
+// Start implements adapter.Adapter.
+func (a *adapter) Start(ctx context.Context) error {
+	a.logger.Infof("Subscribing to AWS IoT topics %q as client %q", a.topics, a.clientID)
+
+	backoff := common.NewBackoff()
+
+	return backoff.Run(ctx.Done(), func(context.Context) (bool, error) {
+		// reset the backoff duration if the session was established,
+		// so that reconnections after a long-lived session are quick
+		return a.runSession(ctx), nil
+	})
+}
+
+// runSession connects to the MQTT server, subscribes to the source's topics
+// and sends the received messages as CloudEvents until either ctx is
+// cancelled or the connection breaks. It returns whether the subscription
+// was successful.
+//
+// Sessions are persistent: messages are acknowledged only after they have
+// been successfully sent, and the connection is closed when sending fails,
+// so that the server delivers them again once the adapter reconnects.
+func (a *adapter) runSession(ctx context.Context) bool /*subscribed*/ {
+	conn, err := a.dial(ctx)
+	if err != nil {
+		a.logger.Errorw("Unable to connect to the MQTT server", zap.Error(err))
+		return false
+	}
+
+	c := newMQTTConn(conn)
+	defer c.Close()
+
+	sessionPresent, err := c.connect(a.clientID, false, keepAlive, connectTimeout)
+	if err != nil {
+		a.logger.Errorw("Unable to establish MQTT session", zap.Error(err))
+		return false
+	}
+
+	// the server may discard sessions, e.g. after they expire, in which
+	// case messages published while the adapter was disconnected are lost
+	if a.hadSession && !sessionPresent {
+		a.logger.Warn("The MQTT server didn't resume the previous session, " +
+			"messages published while the adapter was disconnected may have been lost")
+	}
+	a.hadSession = true
+
+	// messages in flight in a discarded session are never redelivered
+	if !sessionPresent {
+		a.inFlight = nil
+	}
+
+	subID, err := c.subscribe(a.topics, subscriptionQoS)
+	if err != nil {
+		a.logger.Errorw("Unable to subscribe to topics", zap.Error(err))
+		return false
+	}
+
+	a.touch()
+
+	var subscribed int32
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- a.receive(ctx, c, subID, &subscribed)
+	}()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = c.disconnect()
+			_ = c.Close()
+			<-errCh
+			return atomic.LoadInt32(&subscribed) == 1
+
+		case err := <-errCh:
+			a.logger.Errorw("MQTT session terminated", zap.Error(err))
+			return atomic.LoadInt32(&subscribed) == 1
+
+		case <-ticker.C:
+			if time.Since(time.Unix(0, atomic.LoadInt64(&a.lastActivity))) > keepAlive*3/2 {
+				a.logger.Error("MQTT server is unresponsive, closing connection")
+				_ = c.Close()
+				<-errCh
+				return atomic.LoadInt32(&subscribed) == 1
+			}
+
+			if err := c.ping(); err != nil {
+				a.logger.Errorw("Failed to ping MQTT server", zap.Error(err))
+			}
+		}
+	}
+}
+
+// receive reads packets from the given MQTT connection until an error occurs,
+// and handles them. It sets subscribed to 1 once the SUBACK packet for the
+// given SUBSCRIBE packet ID was received.
+func (a *adapter) receive(ctx context.Context, c *mqttConn, subID uint16, subscribed *int32) error {
+	for {
+		p, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+
+		a.touch()
+
+		switch p.typ {
+		case packetPublish:
+			msg, err := parsePublish(p)
+			if err != nil {
+				return fmt.Errorf("malformed PUBLISH packet: %w", err)
+			}
+
+			if err := a.sendMQTTEvent(ctx, msg); err != nil {
+				return fmt.Errorf("failed to send message from topic %q: %w", msg.topic, err)
+			}
+
+			if msg.qos > 0 {
+				if err := c.puback(msg.packetID); err != nil {
+					return fmt.Errorf("failed to acknowledge message: %w", err)
+				}
+				delete(a.inFlight, msg.packetID)
+			}
+
+		case packetSuback:
+			id, codes, err := parseSuback(p)
+			if err != nil {
+				return fmt.Errorf("malformed SUBACK packet: %w", err)
+			}
+			if id != subID {
+				continue
+			}
+
+			for i, rc := range codes {
+				if rc == subackFailure && i < len(a.topics) {
+					return fmt.Errorf("subscription to topic filter %q was refused", a.topics[i])
+				}
+			}
+
+			a.logger.Info("Subscribed to topics")
+			atomic.StoreInt32(subscribed, 1)
+		}
+	}
+}
+
+// touch records the current time as the time of the last activity on the
+// MQTT connection.
+func (a *adapter) touch() {
+	atomic.StoreInt64(&a.lastActivity, time.Now().UnixNano())
+}
+
+// sendMQTTEvent sends the given MQTT message as a CloudEvent.
+func (a *adapter) sendMQTTEvent(ctx context.Context, msg *mqttMessage) error {
+	event := cloudevents.NewEvent(cloudevents.VersionV1)
+	event.SetID(a.messageID(msg))
+	event.SetType(v1alpha1.AWSEventType(a.arn.Service, v1alpha1.AWSIoTMessageEventType))
+	event.SetSource(a.arn.String())
+	event.SetSubject(msg.topic)
+
+	contentType := "application/octet-stream"
+	if json.Valid(msg.payload) {
+		contentType = cloudevents.ApplicationJSON
+	}
+
+	if err := event.SetData(contentType, msg.payload); err != nil {
+		return fmt.Errorf("failed to set event data: %w", err)
+	}
+
+	if result := a.ceClient.Send(ctx, event); !cloudevents.IsACK(result) {
+		return result
+	}
+	return nil
+}
+
+// messageID returns the ID of the CloudEvent which represents the given MQTT
+// message.
+//
+// Every message receives a random ID. Packet identifiers are reused as soon
+// as a message is acknowledged, so they don't identify messages. However, a
+// QoS 1 message which is redelivered (DUP flag) before being acknowledged
+// reuses the ID of the CloudEvent sent for its previous delivery.
+func (a *adapter) messageID(msg *mqttMessage) string {
+	if msg.qos == 0 {
+		return string(uuid.NewUUID())
+	}
+
+	if id, isInFlight := a.inFlight[msg.packetID]; isInFlight && msg.dup {
+		return id
+	}
+
+	if a.inFlight == nil {
+		a.inFlight = make(map[uint16]string)
+	}
+
+	id := string(uuid.NewUUID())
+	a.inFlight[msg.packetID] = id
+
+	return id
+}
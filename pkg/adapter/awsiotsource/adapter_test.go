@@ -0,0 +1,278 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsiotsource
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	loggingtesting "knative.dev/pkg/logging/testing"
+)
+
+var tARN = arn.ARN{
+	Partition: "aws",
+	Service:   "iot",
+	Region:    "us-west-2",
+	AccountID: "123456789012",
+	Resource:  "topic/foo",
+}
+
+func TestSendMQTTEvent(t *testing.T) {
+	testCases := map[string]struct {
+		payload           []byte
+		expectContentType string
+	}{
+		"JSON payload": {
+			payload:           []byte(`{"temperature":21}`),
+			expectContentType: cloudevents.ApplicationJSON,
+		},
+		"Binary payload": {
+			payload:           []byte{0xDE, 0xAD, 0xBE, 0xEF},
+			expectContentType: "application/octet-stream",
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			ceClient := adaptertest.NewTestClient()
+
+			a := &adapter{
+				logger:   loggingtesting.TestLogger(t),
+				ceClient: ceClient,
+				arn:      tARN,
+			}
+
+			err := a.sendMQTTEvent(context.Background(), &mqttMessage{
+				topic:   "things/thing1/telemetry",
+				payload: tc.payload,
+			})
+			require.NoError(t, err)
+
+			events := ceClient.Sent()
+			require.Len(t, events, 1)
+
+			e := events[0]
+			assert.Equal(t, cloudevents.VersionV1, e.SpecVersion())
+			assert.Equal(t, "com.amazon.iot.message", e.Type())
+			assert.Equal(t, tARN.String(), e.Source())
+			assert.Equal(t, "things/thing1/telemetry", e.Subject())
+			assert.Equal(t, tc.expectContentType, e.DataContentType())
+			assert.Equal(t, tc.payload, e.Data())
+		})
+	}
+}
+
+func TestMessageID(t *testing.T) {
+	a := &adapter{
+		clientID: "test-client",
+	}
+
+	msg := func(qos byte, packetID uint16, dup bool) *mqttMessage {
+		return &mqttMessage{
+			topic:    "things/thing1/telemetry",
+			payload:  []byte("hello"),
+			qos:      qos,
+			packetID: packetID,
+			dup:      dup,
+		}
+	}
+
+	id := a.messageID(msg(1, 7, false))
+
+	// redeliveries of an unacknowledged QoS 1 message share the same ID
+	assert.Equal(t, id, a.messageID(msg(1, 7, true)))
+
+	// a packet identifier reused without the DUP flag is a new message
+	newID := a.messageID(msg(1, 7, false))
+	assert.NotEqual(t, id, newID)
+	assert.Equal(t, newID, a.messageID(msg(1, 7, true)))
+
+	// acknowledged messages are no longer in flight
+	delete(a.inFlight, 7)
+	assert.NotEqual(t, newID, a.messageID(msg(1, 7, true)))
+
+	// QoS 0 messages are never redelivered
+	assert.NotEqual(t, a.messageID(msg(0, 0, false)), a.messageID(msg(0, 0, false)))
+}
+
+func TestReceiveReusedPacketID(t *testing.T) {
+	client, server := net.Pipe()
+
+	ceClient := adaptertest.NewTestClient()
+
+	a := &adapter{
+		logger:   loggingtesting.TestLogger(t),
+		ceClient: ceClient,
+		arn:      tARN,
+		clientID: "test-client",
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		var subscribed int32
+		errCh <- a.receive(context.Background(), newMQTTConn(client), 1, &subscribed)
+	}()
+
+	broker := newMQTTConn(server)
+
+	// the same packet identifier is used for two distinct messages with
+	// identical topics and payloads, once the first one is acknowledged
+	for i := 0; i < 2; i++ {
+		body := appendUint16(appendString(nil, "things/thing1/telemetry"), 7)
+		body = append(body, `{"heartbeat":true}`...)
+		require.NoError(t, broker.writePacket(packetPublish, 1<<1, body))
+
+		p, err := broker.readPacket()
+		require.NoError(t, err)
+		assert.Equal(t, packetPuback, p.typ)
+	}
+
+	_ = broker.Close()
+	assert.Error(t, <-errCh)
+
+	events := ceClient.Sent()
+	require.Len(t, events, 2)
+	assert.NotEqual(t, events[0].ID(), events[1].ID())
+	assert.Empty(t, a.inFlight)
+}
+
+func TestRunSession(t *testing.T) {
+	topics := []string{"foo", "things/+/telemetry"}
+
+	testCases := map[string]struct {
+		subackCodes      []byte
+		expectSubscribed bool
+		expectEvents     int
+	}{
+		"Subscription accepted": {
+			subackCodes:      []byte{0x01, 0x01},
+			expectSubscribed: true,
+			expectEvents:     1,
+		},
+		"Subscription refused": {
+			subackCodes:      []byte{0x01, subackFailure},
+			expectSubscribed: false,
+			expectEvents:     0,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			client, server := net.Pipe()
+
+			ceClient := adaptertest.NewTestClient()
+
+			a := &adapter{
+				logger:   loggingtesting.TestLogger(t),
+				ceClient: ceClient,
+				arn:      tARN,
+				topics:   topics,
+				clientID: "test-client",
+				dial: func(context.Context) (net.Conn, error) {
+					return client, nil
+				},
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			brokerCh := make(chan []*mqttPacket, 1)
+			go func() {
+				brokerCh <- runFakeBroker(server, tc.subackCodes, cancel)
+			}()
+
+			subscribed := a.runSession(ctx)
+			assert.Equal(t, tc.expectSubscribed, subscribed)
+
+			received := <-brokerCh
+			require.True(t, len(received) >= 2, "Expected at least CONNECT and SUBSCRIBE packets")
+
+			assert.Equal(t, packetConnect, received[0].typ)
+			assert.Equal(t, packetSubscribe, received[1].typ)
+
+			expectSubscribe := appendUint16(nil, 1)
+			for _, topic := range topics {
+				expectSubscribe = append(appendString(expectSubscribe, topic), subscriptionQoS)
+			}
+			assert.Equal(t, expectSubscribe, received[1].body)
+
+			assert.Len(t, ceClient.Sent(), tc.expectEvents)
+
+			if tc.expectSubscribed {
+				require.Len(t, received, 4)
+				assert.Equal(t, packetPuback, received[2].typ)
+				assert.Equal(t, appendUint16(nil, 7), received[2].body)
+				assert.Equal(t, packetDisconnect, received[3].typ)
+			}
+		})
+	}
+}
+
+// runFakeBroker handles a MQTT session on the given connection. It accepts
+// the connection, acknowledges the subscription with the given return codes
+// and, if the subscription succeeds, publishes a QoS 1 message and calls stop
+// once that message is acknowledged. It returns all packets received from
+// the client until the connection is closed.
+func runFakeBroker(conn net.Conn, subackCodes []byte, stop func()) []*mqttPacket {
+	c := newMQTTConn(conn)
+	defer c.Close()
+
+	var received []*mqttPacket
+
+	for {
+		p, err := c.readPacket()
+		if err != nil {
+			return received
+		}
+		received = append(received, p)
+
+		switch p.typ {
+		case packetConnect:
+			_ = c.writePacket(packetConnack, 0, []byte{0x00, 0x00})
+
+		case packetSubscribe:
+			_ = c.writePacket(packetSuback, 0, append(p.body[:2:2], subackCodes...))
+
+			for _, rc := range subackCodes {
+				if rc == subackFailure {
+					return received
+				}
+			}
+
+			body := appendUint16(appendString(nil, "things/thing1/telemetry"), 7)
+			body = append(body, `{"temperature":21}`...)
+			_ = c.writePacket(packetPublish, 1<<1, body)
+
+		case packetPuback:
+			stop()
+
+		case packetDisconnect:
+			return received
+		}
+	}
+}
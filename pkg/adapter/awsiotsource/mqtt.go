@@ -0,0 +1,353 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsiotsource
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// This file implements the subset of the MQTT 3.1.1 protocol which is
+// required to receive messages from AWS IoT Core as a subscriber.
+// http://docs.oasis-open.org/mqtt/mqtt/v3.1.1/mqtt-v3.1.1.html
+
+// MQTT control packet types.
+const (
+	packetConnect    byte = 1
+	packetConnack    byte = 2
+	packetPublish    byte = 3
+	packetPuback     byte = 4
+	packetSubscribe  byte = 8
+	packetSuback     byte = 9
+	packetPingreq    byte = 12
+	packetPingresp   byte = 13
+	packetDisconnect byte = 14
+)
+
+const (
+	// Protocol level of MQTT 3.1.1.
+	protocolLevel311 byte = 4
+	// CONNECT flag which requests a clean session.
+	connectFlagCleanSession byte = 0x02
+	// CONNACK flag which indicates that the server resumed a previous
+	// session of the client.
+	connackFlagSessionPresent byte = 0x01
+	// SUBACK return code which indicates a failed subscription.
+	subackFailure byte = 0x80
+)
+
+// Largest value which can be encoded in the Remaining Length field of a
+// packet's fixed header.
+const maxRemainingLength = 268435455
+
+// maxPacketSize is the size of the largest packet accepted from the server.
+// AWS IoT Core limits the size of message payloads to 128 KB, the extra room
+// accommodates topic names and other header fields.
+const maxPacketSize = 256 * 1024
+
+// connackReturnCodes are the descriptions of the CONNACK return codes which
+// indicate that a connection was refused.
+var connackReturnCodes = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// mqttPacket is a MQTT control packet.
+type mqttPacket struct {
+	typ   byte
+	flags byte
+	body  []byte
+}
+
+// mqttMessage is an application message received in a PUBLISH packet.
+type mqttMessage struct {
+	topic    string
+	payload  []byte
+	qos      byte
+	packetID uint16
+	// whether the message may have been delivered before
+	dup bool
+}
+
+// mqttConn is a MQTT connection to a server.
+type mqttConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	// serializes writes of packets
+	wmu sync.Mutex
+
+	lastPacketID uint16
+}
+
+// newMQTTConn returns a MQTT connection which communicates over the given
+// network connection.
+func newMQTTConn(conn net.Conn) *mqttConn {
+	return &mqttConn{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+	}
+}
+
+// Close closes the underlying network connection.
+func (c *mqttConn) Close() error {
+	return c.conn.Close()
+}
+
+// connect initiates a MQTT session and waits for the server to acknowledge
+// it. Unless a clean session is requested, the server resumes the previous
+// session of the client, if any, and delivers again the messages the client
+// didn't acknowledge. The returned boolean indicates whether a previous
+// session was resumed.
+func (c *mqttConn) connect(clientID string, cleanSession bool, keepAlive, timeout time.Duration) (bool /*sessionPresent*/, error) {
+	var flags byte
+	if cleanSession {
+		flags |= connectFlagCleanSession
+	}
+
+	var body []byte
+	body = appendString(body, "MQTT")
+	body = append(body, protocolLevel311, flags)
+	body = appendUint16(body, uint16(keepAlive/time.Second))
+	body = appendString(body, clientID)
+
+	if err := c.writePacket(packetConnect, 0, body); err != nil {
+		return false, fmt.Errorf("sending CONNECT: %w", err)
+	}
+
+	if err := c.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return false, err
+	}
+	defer func() { _ = c.conn.SetReadDeadline(time.Time{}) }()
+
+	p, err := c.readPacket()
+	if err != nil {
+		return false, fmt.Errorf("reading CONNACK: %w", err)
+	}
+	if p.typ != packetConnack || len(p.body) != 2 {
+		return false, fmt.Errorf("expected CONNACK, received packet of type %d", p.typ)
+	}
+
+	if rc := p.body[1]; rc != 0 {
+		desc, ok := connackReturnCodes[rc]
+		if !ok {
+			desc = "unknown return code " + fmt.Sprint(rc)
+		}
+		return false, fmt.Errorf("connection refused: %s", desc)
+	}
+
+	return p.body[0]&connackFlagSessionPresent != 0, nil
+}
+
+// subscribe sends a SUBSCRIBE packet for the given topic filters, requesting
+// the given maximum QoS, and returns the ID of that packet. The corresponding
+// SUBACK packet is received asynchronously.
+func (c *mqttConn) subscribe(filters []string, qos byte) (uint16, error) {
+	id := c.nextPacketID()
+
+	body := appendUint16(nil, id)
+	for _, f := range filters {
+		body = appendString(body, f)
+		body = append(body, qos)
+	}
+
+	// bits 3,2,1,0 of the fixed header of a SUBSCRIBE packet are reserved
+	// and must be set to 0,0,1,0
+	if err := c.writePacket(packetSubscribe, 0x02, body); err != nil {
+		return 0, fmt.Errorf("sending SUBSCRIBE: %w", err)
+	}
+
+	return id, nil
+}
+
+// puback acknowledges the QoS 1 PUBLISH packet with the given ID.
+func (c *mqttConn) puback(packetID uint16) error {
+	return c.writePacket(packetPuback, 0, appendUint16(nil, packetID))
+}
+
+// ping sends a PINGREQ packet.
+func (c *mqttConn) ping() error {
+	return c.writePacket(packetPingreq, 0, nil)
+}
+
+// disconnect sends a DISCONNECT packet.
+func (c *mqttConn) disconnect() error {
+	return c.writePacket(packetDisconnect, 0, nil)
+}
+
+// nextPacketID returns a non-zero packet identifier.
+func (c *mqttConn) nextPacketID() uint16 {
+	c.lastPacketID++
+	if c.lastPacketID == 0 {
+		c.lastPacketID = 1
+	}
+	return c.lastPacketID
+}
+
+// writePacket writes a control packet to the connection.
+func (c *mqttConn) writePacket(typ, flags byte, body []byte) error {
+	if len(body) > maxRemainingLength {
+		return errors.New("packet too large")
+	}
+
+	buf := make([]byte, 0, 5+len(body))
+	buf = append(buf, typ<<4|flags&0x0F)
+	buf = appendRemainingLength(buf, len(body))
+	buf = append(buf, body...)
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+// readPacket reads the next control packet from the connection.
+func (c *mqttConn) readPacket() (*mqttPacket, error) {
+	header, err := c.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := readRemainingLength(c.r)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxPacketSize {
+		return nil, fmt.Errorf("packet of %d bytes exceeds the maximum size of %d bytes", length, maxPacketSize)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return nil, err
+	}
+
+	return &mqttPacket{
+		typ:   header >> 4,
+		flags: header & 0x0F,
+		body:  body,
+	}, nil
+}
+
+// parsePublish parses the application message contained in the given PUBLISH
+// packet.
+func parsePublish(p *mqttPacket) (*mqttMessage, error) {
+	msg := &mqttMessage{
+		qos: p.flags >> 1 & 0x03,
+		dup: p.flags>>3&0x01 == 1,
+	}
+
+	topic, rest, err := readString(p.body)
+	if err != nil {
+		return nil, fmt.Errorf("reading topic name: %w", err)
+	}
+	msg.topic = topic
+
+	if msg.qos > 0 {
+		if len(rest) < 2 {
+			return nil, errors.New("missing packet identifier")
+		}
+		msg.packetID = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+
+	msg.payload = rest
+
+	return msg, nil
+}
+
+// parseSuback returns the packet identifier and return codes contained in the
+// given SUBACK packet.
+func parseSuback(p *mqttPacket) (uint16, []byte, error) {
+	if len(p.body) < 2 {
+		return 0, nil, errors.New("missing packet identifier")
+	}
+	return binary.BigEndian.Uint16(p.body), p.body[2:], nil
+}
+
+// appendRemainingLength appends the variable length encoding of the given
+// Remaining Length to buf.
+func appendRemainingLength(buf []byte, length int) []byte {
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if length == 0 {
+			return buf
+		}
+	}
+}
+
+// readRemainingLength reads a variable length encoded Remaining Length.
+func readRemainingLength(r io.ByteReader) (int, error) {
+	var length int
+	multiplier := 1
+
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		length += int(b&0x7F) * multiplier
+		if b&0x80 == 0 {
+			return length, nil
+		}
+		multiplier *= 128
+	}
+
+	return 0, errors.New("malformed remaining length")
+}
+
+// appendString appends a length-prefixed UTF-8 string to buf.
+func appendString(buf []byte, s string) []byte {
+	buf = appendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
+
+// appendUint16 appends a big-endian 16-bit integer to buf.
+func appendUint16(buf []byte, v uint16) []byte {
+	return append(buf, byte(v>>8), byte(v))
+}
+
+// readString reads a length-prefixed UTF-8 string from buf and returns the
+// remaining bytes.
+func readString(buf []byte) (string, []byte, error) {
+	if len(buf) < 2 {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+
+	length := int(binary.BigEndian.Uint16(buf))
+	buf = buf[2:]
+	if len(buf) < length {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+
+	return string(buf[:length]), buf[length:], nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsiotsource
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemainingLength(t *testing.T) {
+	testCases := map[int][]byte{
+		0:                  {0x00},
+		127:                {0x7F},
+		128:                {0x80, 0x01},
+		16383:              {0xFF, 0x7F},
+		16384:              {0x80, 0x80, 0x01},
+		2097151:            {0xFF, 0xFF, 0x7F},
+		2097152:            {0x80, 0x80, 0x80, 0x01},
+		maxRemainingLength: {0xFF, 0xFF, 0xFF, 0x7F},
+	}
+
+	for length, encoded := range testCases {
+		//nolint:scopelint
+		t.Run(strconv.Itoa(length), func(t *testing.T) {
+			assert.Equal(t, encoded, appendRemainingLength(nil, length))
+
+			decoded, err := readRemainingLength(bytes.NewReader(encoded))
+			require.NoError(t, err)
+			assert.Equal(t, length, decoded)
+		})
+	}
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := readRemainingLength(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x01}))
+		assert.Error(t, err)
+	})
+}
+
+func TestParsePublish(t *testing.T) {
+	testCases := map[string]struct {
+		packet    *mqttPacket
+		expectMsg *mqttMessage
+		expectErr bool
+	}{
+		"QoS 0": {
+			packet: &mqttPacket{
+				typ:  packetPublish,
+				body: append(appendString(nil, "a/b"), "hello"...),
+			},
+			expectMsg: &mqttMessage{
+				topic:   "a/b",
+				payload: []byte("hello"),
+			},
+		},
+		"QoS 1": {
+			packet: &mqttPacket{
+				typ:   packetPublish,
+				flags: 1 << 1,
+				body:  append(appendUint16(appendString(nil, "a/b"), 42), "hello"...),
+			},
+			expectMsg: &mqttMessage{
+				topic:    "a/b",
+				payload:  []byte("hello"),
+				qos:      1,
+				packetID: 42,
+			},
+		},
+		"QoS 1 redelivery": {
+			packet: &mqttPacket{
+				typ:   packetPublish,
+				flags: 1<<3 | 1<<1,
+				body:  append(appendUint16(appendString(nil, "a/b"), 42), "hello"...),
+			},
+			expectMsg: &mqttMessage{
+				topic:    "a/b",
+				payload:  []byte("hello"),
+				qos:      1,
+				packetID: 42,
+				dup:      true,
+			},
+		},
+		"Missing packet ID": {
+			packet: &mqttPacket{
+				typ:   packetPublish,
+				flags: 1 << 1,
+				body:  append(appendString(nil, "a/b"), 0x00),
+			},
+			expectErr: true,
+		},
+		"Truncated topic": {
+			packet: &mqttPacket{
+				typ:  packetPublish,
+				body: []byte{0x00, 0x05, 'a'},
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			msg, err := parsePublish(tc.packet)
+
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectMsg, msg)
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	testCases := map[string]struct {
+		flags                byte
+		returnCode           byte
+		expectSessionPresent bool
+		expectErr            string
+	}{
+		"Accepted": {
+			returnCode: 0,
+		},
+		"Accepted with session present": {
+			flags:                connackFlagSessionPresent,
+			returnCode:           0,
+			expectSessionPresent: true,
+		},
+		"Not authorized": {
+			returnCode: 5,
+			expectErr:  "connection refused: not authorized",
+		},
+		"Unknown return code": {
+			returnCode: 42,
+			expectErr:  "connection refused: unknown return code 42",
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+
+			srv := newMQTTConn(server)
+			defer srv.Close()
+
+			connectCh := make(chan *mqttPacket, 1)
+
+			go func() {
+				p, err := srv.readPacket()
+				if err != nil {
+					return
+				}
+				connectCh <- p
+				_ = srv.writePacket(packetConnack, 0, []byte{tc.flags, tc.returnCode})
+			}()
+
+			sessionPresent, err := newMQTTConn(client).connect("my-client", false, 30*time.Second, time.Second)
+
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectSessionPresent, sessionPresent)
+
+			p := <-connectCh
+			assert.Equal(t, packetConnect, p.typ)
+
+			expectBody := appendString(nil, "MQTT")
+			expectBody = append(expectBody, protocolLevel311, 0x00, 0x00, 30)
+			expectBody = appendString(expectBody, "my-client")
+			assert.Equal(t, expectBody, p.body)
+		})
+	}
+}
+
+func TestReadPacketTooLarge(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		// fixed header of a PUBLISH packet which announces a body larger
+		// than the maximum packet size
+		header := appendRemainingLength([]byte{packetPublish << 4}, maxPacketSize+1)
+		_, _ = server.Write(header)
+	}()
+
+	_, err := newMQTTConn(client).readPacket()
+	assert.EqualError(t, err, "packet of 262145 bytes exceeds the maximum size of 262144 bytes")
+}
@@ -28,7 +28,6 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/kinesis"
 	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
 
@@ -43,6 +42,8 @@ import (
 // adapter.
 type envConfig struct {
 	pkgadapter.EnvConfig
+	common.SessionEnvConfig
+	common.HAEnvConfig
 
 	ARN string `envconfig:"ARN" required:"true"`
 }
@@ -54,6 +55,8 @@ type adapter struct {
 	knsClient kinesisiface.KinesisAPI
 	ceClient  cloudevents.Client
 
+	shards common.ShardOwnership
+
 	arn    arn.ARN
 	stream string
 }
@@ -71,10 +74,10 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 
 	arn := common.MustParseARN(env.ARN)
 
-	cfg := session.Must(session.NewSession(aws.NewConfig().
+	cfg := common.MustNewSession(env.SessionEnvConfig, aws.NewConfig().
 		WithRegion(arn.Region).
 		WithMaxRetries(5),
-	))
+	)
 
 	return &adapter{
 		logger: logger,
@@ -82,6 +85,8 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 		knsClient: kinesis.New(cfg),
 		ceClient:  ceClient,
 
+		shards: common.NewShardOwnership(env.HAEnvConfig, env.Namespace, logger),
+
 		arn:    arn,
 		stream: common.MustParseKinesisResource(arn.Resource),
 	}
@@ -101,23 +106,40 @@ func (a *adapter) Start(ctx context.Context) error {
 
 	a.logger.Infof("Connected to Kinesis stream: %s", *streamARN)
 
-	// Obtain records inputs for different shards
-	inputs := a.getRecordsInputs(myStream.StreamDescription.Shards)
+	shardIDs := make([]string, 0, len(myStream.StreamDescription.Shards))
+	for _, shard := range myStream.StreamDescription.Shards {
+		shardIDs = append(shardIDs, *shard.ShardId)
+	}
+	a.shards.SetShards(shardIDs)
+
+	go a.shards.Run(ctx)
+
+	// Obtain records inputs for the shards owned by this replica
+	inputs := a.getRecordsInputs(a.shards.Owned())
 
 	backoff := common.NewBackoff()
 
 	err = backoff.Run(ctx.Done(), func(ctx context.Context) (bool, error) {
+		select {
+		case <-a.shards.Changed():
+			a.syncInputs(inputs)
+		default:
+		}
+
 		resetBackoff := false
 		records, err := a.processInputs(inputs)
 		if err != nil {
 			a.logger.Errorw("There were errors during inputs processing", zap.Error(err))
 		}
 
-		for _, record := range records {
-			resetBackoff = true
-			err = a.sendKinesisRecord(record)
-			if err != nil {
-				a.logger.Errorw("Failed to send cloudevent", zap.Error(err))
+		for shardID, shardRecords := range records {
+			for _, record := range shardRecords {
+				resetBackoff = true
+				err = a.sendKinesisRecord(record)
+				if err != nil {
+					a.logger.Errorw("Failed to send cloudevent", zap.Error(err))
+				}
+				a.shards.Checkpoint(shardID, *record.SequenceNumber)
 			}
 		}
 		return resetBackoff, nil
@@ -126,60 +148,89 @@ func (a *adapter) Start(ctx context.Context) error {
 	return err
 }
 
-func (a *adapter) getRecordsInputs(shards []*kinesis.Shard) []kinesis.GetRecordsInput {
-	inputs := []kinesis.GetRecordsInput{}
+// getRecordsInputs returns records inputs for the given shards. Shards are
+// read after their checkpointed sequence number if any, or from their latest
+// record otherwise.
+func (a *adapter) getRecordsInputs(shards map[string]string /*shard ID -> sequence number*/) map[string]*kinesis.GetRecordsInput {
+	inputs := make(map[string]*kinesis.GetRecordsInput, len(shards))
 
-	// Kinesis stream might have several shards and each of them had "LATEST" Iterator.
-	for _, shard := range shards {
-		// Obtain starting Shard Iterator. This is needed to not process already processed records
-		myShardIterator, err := a.knsClient.GetShardIterator(&kinesis.GetShardIteratorInput{
-			ShardId:           shard.ShardId,
-			ShardIteratorType: aws.String("LATEST"),
+	for shardID, seq := range shards {
+		iterInput := &kinesis.GetShardIteratorInput{
+			ShardId:           aws.String(shardID),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeLatest),
 			StreamName:        &a.stream,
-		})
+		}
+		if seq != "" {
+			iterInput.ShardIteratorType = aws.String(kinesis.ShardIteratorTypeAfterSequenceNumber)
+			iterInput.StartingSequenceNumber = aws.String(seq)
+		}
 
+		// Obtain starting Shard Iterator. This is needed to not process already processed records
+		myShardIterator, err := a.knsClient.GetShardIterator(iterInput)
 		if err != nil {
 			a.logger.Errorw("Failed to get shard iterator", zap.Error(err))
 			continue
 		}
 
-		// set records output limit. Should not be more than 10000, othervise panics
-		input := kinesis.GetRecordsInput{
+		inputs[shardID] = &kinesis.GetRecordsInput{
 			ShardIterator: myShardIterator.ShardIterator,
 		}
-
-		inputs = append(inputs, input)
 	}
 
 	return inputs
 }
 
-func (a *adapter) processInputs(inputs []kinesis.GetRecordsInput) ([]*kinesis.Record, error) {
+// syncInputs adds records inputs for newly owned shards and removes the inputs
+// of shards which are no longer owned.
+func (a *adapter) syncInputs(inputs map[string]*kinesis.GetRecordsInput) {
+	owned := a.shards.Owned()
+
+	for shardID := range inputs {
+		if _, isOwned := owned[shardID]; !isOwned {
+			a.logger.Info("Stopping consumption of shard ", shardID)
+			delete(inputs, shardID)
+		}
+	}
+
+	newShards := make(map[string]string)
+	for shardID, seq := range owned {
+		if _, exists := inputs[shardID]; !exists {
+			a.logger.Info("Starting consumption of shard ", shardID)
+			newShards[shardID] = seq
+		}
+	}
+
+	for shardID, input := range a.getRecordsInputs(newShards) {
+		inputs[shardID] = input
+	}
+}
+
+// processInputs gets records from the given inputs and replaces each of them
+// with an input for the next batch of records.
+func (a *adapter) processInputs(inputs map[string]*kinesis.GetRecordsInput) (map[string][]*kinesis.Record, error) {
 	var errs []error
-	records := []*kinesis.Record{}
+	records := make(map[string][]*kinesis.Record, len(inputs))
 
-	for i, input := range inputs {
-		input := input
+	for shardID, input := range inputs {
+		// the iterator of a closed shard becomes nil once all its
+		// records have been read
+		if input.ShardIterator == nil {
+			continue
+		}
 
-		recordsOutput, err := a.knsClient.GetRecords(&input)
+		recordsOutput, err := a.knsClient.GetRecords(input)
 		if err != nil {
 			errs = append(errs, err)
 			continue
 		}
 
-		records = append(records, recordsOutput.Records...)
-
-		// remove old input
-		inputs = append(inputs[:i], inputs[i+1:]...)
+		records[shardID] = recordsOutput.Records
 
-		// generate new input
-		input = kinesis.GetRecordsInput{
+		// generate new input so that next iteration begins with the
+		// new shard iterator
+		inputs[shardID] = &kinesis.GetRecordsInput{
 			ShardIterator: recordsOutput.NextShardIterator,
 		}
-
-		// add newly generated input to the slice
-		// so that new iteration would begin with new sharIterator
-		inputs = append(inputs, input)
 	}
 
 	return records, utilerrors.NewAggregate(errs)
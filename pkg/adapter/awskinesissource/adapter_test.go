@@ -48,6 +48,10 @@ func (m mockedGetRecords) GetRecords(in *kinesis.GetRecordsInput) (*kinesis.GetR
 }
 
 func (m mockedGetShardIterator) GetShardIterator(in *kinesis.GetShardIteratorInput) (*kinesis.GetShardIteratorOutput, error) {
+	if in.StartingSequenceNumber != nil && *in.ShardIteratorType != kinesis.ShardIteratorTypeAfterSequenceNumber {
+		return nil, errors.New("starting sequence number requires iterator type " +
+			kinesis.ShardIteratorTypeAfterSequenceNumber)
+	}
 	return &m.Resp, m.err
 }
 
@@ -76,12 +80,15 @@ func TestProcessInputs(t *testing.T) {
 		err: nil,
 	}
 
-	inputs := []kinesis.GetRecordsInput{
-		{},
+	inputs := map[string]*kinesis.GetRecordsInput{
+		"1": {ShardIterator: aws.String("iterator")},
+		"2": {ShardIterator: nil},
 	}
 
-	_, err := a.processInputs(inputs)
+	gotRecords, err := a.processInputs(inputs)
 	assert.NoError(t, err)
+	assert.Equal(t, map[string][]*kinesis.Record{"1": records}, gotRecords, "Expected no read from closed shard")
+	assert.Equal(t, "nextIterator", *inputs["1"].ShardIterator)
 
 	const errMsg = "fake error"
 
@@ -104,12 +111,13 @@ func TestGetRecordsInputs(t *testing.T) {
 		err:  nil,
 	}
 
-	shards := []*kinesis.Shard{
-		{ShardId: aws.String("1")},
+	shards := map[string]string{
+		"1": "",
+		"2": "12345",
 	}
 
 	inputs := a.getRecordsInputs(shards)
-	assert.Equal(t, 1, len(inputs))
+	assert.Equal(t, 2, len(inputs))
 
 	a.knsClient = mockedGetShardIterator{
 		Resp: kinesis.GetShardIteratorOutput{},
@@ -31,7 +31,6 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 
@@ -46,6 +45,7 @@ import (
 // adapter.
 type envConfig struct {
 	pkgadapter.EnvConfig
+	common.SessionEnvConfig
 
 	ARN string `required:"true"`
 }
@@ -73,10 +73,10 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 
 	arn := common.MustParseARN(env.ARN)
 
-	cfg := session.Must(session.NewSession(aws.NewConfig().
+	cfg := common.MustNewSession(env.SessionEnvConfig, aws.NewConfig().
 		WithRegion(arn.Region).
 		WithMaxRetries(5),
-	))
+	)
 
 	return &adapter{
 		logger: logger,
@@ -18,7 +18,10 @@ package awssqssource
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -29,7 +32,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 
@@ -45,12 +48,36 @@ const (
 	logfieldMsgIDs = "msgIDs"
 )
 
+// Error code returned by the SQS API when the caller isn't authorized to
+// perform a request.
+const codeAccessDenied = "AccessDenied"
+
 // envConfig is a set parameters sourced from the environment for the source's
 // adapter.
 type envConfig struct {
 	pkgadapter.EnvConfig
+	common.SessionEnvConfig
 
 	ARN string `envconfig:"ARN" required:"true"`
+
+	// URL of the queue, in case it can not be resolved from the ARN
+	QueueURL string `envconfig:"QUEUE_URL"`
+
+	// Unwrap notifications forwarded by other AWS services
+	DecodeNotifications bool `envconfig:"DECODE_NOTIFICATIONS"`
+
+	// Maximum number of events sent to the sink in a single batch, and
+	// maximum time to wait for a batch to fill up. Events are sent
+	// individually unless BatchSize is greater than 1.
+	BatchSize   int           `envconfig:"BATCH_SIZE" default:"1"`
+	BatchWindow time.Duration `envconfig:"BATCH_WINDOW" default:"1s"`
+
+	// Time given to the adapter to handle buffered messages upon termination
+	ShutdownGracePeriod time.Duration `envconfig:"SHUTDOWN_GRACE_PERIOD" default:"30s"`
+
+	// Interval at which the backlog of the queue is reported as a metric,
+	// for consumption by an autoscaler. Disabled when zero.
+	BacklogPollInterval time.Duration `envconfig:"BACKLOG_POLL_INTERVAL"`
 }
 
 // adapter implements the source's adapter.
@@ -60,15 +87,26 @@ type adapter struct {
 	mt *pkgadapter.MetricTag
 	sr *statsReporter
 
-	sqsClient sqsiface.SQSAPI
-	ceClient  cloudevents.Client
+	sqsClient   sqsiface.SQSAPI
+	ceClient    cloudevents.Client
+	batchSender eventBatchSender
 
-	arn arn.ARN
+	arn      arn.ARN
+	queueURL string
+
+	decodeNotifications bool
 
 	processQueue chan *sqs.Message
 	deleteQueue  chan *sqs.Message
 
 	deletePeriod time.Duration
+
+	batchSize   int
+	batchWindow time.Duration
+
+	shutdownGracePeriod time.Duration
+
+	backlogPollInterval time.Duration
 }
 
 // NewEnvConfig returns an accessor for the source's adapter envConfig.
@@ -92,9 +130,9 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 
 	arn := common.MustParseARN(env.ARN)
 
-	cfg := session.Must(session.NewSession(aws.NewConfig().
+	cfg := common.MustNewSession(env.SessionEnvConfig, aws.NewConfig().
 		WithRegion(arn.Region),
-	))
+	)
 
 	// allocate generous buffer sizes to limit blocking on surges of new
 	// messages coming from receivers
@@ -102,6 +140,11 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 	queueBufferSizeProcess := maxReceiveMsgBatchSize * runtime.GOMAXPROCS(-1) * batchSizePerProc
 	queueBufferSizeDelete := queueBufferSizeProcess
 
+	batchSender, err := newHTTPBatchSender(env)
+	if err != nil {
+		logger.Fatalw("Unable to create sender of batched events", zap.Error(err))
+	}
+
 	sr := mustNewStatsReporter(mt)
 	sr.reportQueueCapacityProcess(queueBufferSizeProcess)
 	sr.reportQueueCapacityDelete(queueBufferSizeDelete)
@@ -112,33 +155,70 @@ func NewAdapter(ctx context.Context, envAcc pkgadapter.EnvConfigAccessor, ceClie
 		mt: mt,
 		sr: sr,
 
-		sqsClient: sqs.New(cfg),
-		ceClient:  ceClient,
+		sqsClient:   sqs.New(cfg),
+		ceClient:    ceClient,
+		batchSender: batchSender,
+
+		arn:      arn,
+		queueURL: env.QueueURL,
 
-		arn: arn,
+		decodeNotifications: env.DecodeNotifications,
 
 		processQueue: make(chan *sqs.Message, queueBufferSizeProcess),
 		deleteQueue:  make(chan *sqs.Message, queueBufferSizeDelete),
 
 		deletePeriod: maxDeleteMsgPeriod,
+
+		batchSize:   env.BatchSize,
+		batchWindow: env.BatchWindow,
+
+		shutdownGracePeriod: env.ShutdownGracePeriod,
+
+		backlogPollInterval: env.BacklogPollInterval,
 	}
 }
 
 // Start implements adapter.Adapter.
 func (a *adapter) Start(ctx context.Context) error {
-	url, err := a.queueLookup(a.arn.Resource)
+	queueURL, err := a.resolveQueueURL()
 	if err != nil {
 		a.logger.Errorw("Unable to find URL of SQS queue "+a.arn.Resource, zap.Error(err))
 		return err
 	}
 
-	queueURL := *url.QueueUrl
+	if err := a.checkQueueAccess(ctx, queueURL); err != nil {
+		a.logger.Errorw("Unable to access SQS queue at URL "+queueURL, zap.Error(err))
+		return err
+	}
+
 	a.logger.Infof("Listening to SQS queue at URL: %s", queueURL)
 
-	msgCtx, cancel := context.WithCancel(pkgadapter.ContextWithMetricTag(ctx, a.mt))
-	defer cancel()
+	// Receivers stop as soon as the adapter is asked to terminate, while
+	// processors and deleters keep going until all buffered messages have
+	// been handled, so their contexts aren't derived from ctx.
+	rcvCtx, rcvCancel := context.WithCancel(ctx)
+	defer rcvCancel()
+
+	procCtx, procCancel := context.WithCancel(pkgadapter.ContextWithMetricTag(context.Background(), a.mt))
+	defer procCancel()
 
-	var wg sync.WaitGroup
+	var rcvWg, procWg, delWg sync.WaitGroup
+
+	if a.backlogPollInterval > 0 {
+		rcvWg.Add(1)
+		go func() {
+			defer rcvWg.Done()
+			a.runBacklogReporter(rcvCtx, queueURL)
+		}()
+	}
+
+	var unprocessedMu sync.Mutex
+	var unprocessed []*sqs.Message
+
+	runMessagesProcessor := a.runMessagesProcessor
+	if a.batchSize > 1 {
+		runMessagesProcessor = a.runBatchMessagesProcessor
+	}
 
 	// This event source spends most of its time waiting for the network,
 	// so we can run more than one of each receiver|processor|deleter for
@@ -150,42 +230,131 @@ func (a *adapter) Start(ctx context.Context) error {
 		// based on the current amount of messages being processed to
 		// optimize costs generated by ReceiveMessage API requests.
 		// https://github.com/triggermesh/aws-event-sources/issues/227
-		wg.Add(1)
+		rcvWg.Add(1)
 		go func() {
-			defer wg.Done()
-			a.runMessagesReceiver(msgCtx, queueURL)
+			defer rcvWg.Done()
+			a.runMessagesReceiver(rcvCtx, queueURL)
 		}()
 
-		wg.Add(1)
+		procWg.Add(1)
 		go func() {
-			defer wg.Done()
-			a.runMessagesProcessor(msgCtx)
+			defer procWg.Done()
+			msgs := runMessagesProcessor(procCtx)
+
+			unprocessedMu.Lock()
+			unprocessed = append(unprocessed, msgs...)
+			unprocessedMu.Unlock()
 		}()
 
-		wg.Add(1)
+		delWg.Add(1)
 		go func() {
-			defer wg.Done()
-			a.runMessagesDeleter(msgCtx, queueURL)
+			defer delWg.Done()
+			a.runMessagesDeleter(queueURL)
 		}()
 	}
 
 	<-ctx.Done()
-	cancel()
 
-	a.logger.Info("Waiting for message handlers to terminate")
-	wg.Wait()
+	// Termination sequence, bounded by the configured grace period:
+	//  1. receivers are stopped, so that no new message gets buffered
+	//  2. processors handle buffered messages until the drain period
+	//     expires, after which the remaining messages are released back to
+	//     the queue, to be received again by another instance of the adapter
+	//  3. deleters flush their deletion buffers
+
+	a.logger.Info("Waiting for message receivers to terminate")
+	rcvWg.Wait()
+	close(a.processQueue)
+
+	// reserve some of the grace period for the final calls to the SQS API
+	drainPeriod := a.shutdownGracePeriod - deleteRequestTimeout
+	if drainPeriod < 0 {
+		drainPeriod = 0
+	}
+	drainTimer := time.AfterFunc(drainPeriod, procCancel)
+	defer drainTimer.Stop()
+
+	a.logger.Infow("Waiting for message processors to handle buffered messages",
+		zap.Duration("timeout", drainPeriod))
+	procWg.Wait()
+	close(a.deleteQueue)
+
+	if l := len(unprocessed); l > 0 {
+		a.logger.Infow("Releasing "+strconv.Itoa(l)+" unprocessed message(s)",
+			zap.Array(logfieldMsgIDs, messageList(unprocessed)))
+
+		relCtx, relCancel := context.WithTimeout(context.Background(), deleteRequestTimeout)
+		defer relCancel()
+
+		if err := releaseMessages(relCtx, a.sqsClient, queueURL, unprocessed); err != nil {
+			// NOTE: messages which could not be released become
+			// visible again after their visibility timeout expires.
+			a.logger.Errorw("Failed to release unprocessed messages", zap.Error(err))
+		}
+	}
+
+	a.logger.Info("Waiting for message deleters to terminate")
+	delWg.Wait()
 
 	return nil
 }
 
-// queueLookup finds the URL for a given queue name in the user's env.
-// Needs to be an exact match to queue name and queue must be unique name in the AWS account.
+// resolveQueueURL returns the URL of the SQS queue, either as explicitly
+// provided in the adapter's configuration, or as looked up from the queue ARN.
+func (a *adapter) resolveQueueURL() (string /*url*/, error) {
+	if a.queueURL != "" {
+		return a.queueURL, nil
+	}
+
+	url, err := a.queueLookup(a.arn.Resource)
+	if err != nil {
+		return "", queueAccessError(a.arn.String(), err)
+	}
+
+	return *url.QueueUrl, nil
+}
+
+// queueLookup finds the URL for a given queue name in the AWS account
+// referenced in the queue ARN, which is not necessarily the account of the
+// caller.
 func (a *adapter) queueLookup(queueName string) (*sqs.GetQueueUrlOutput, error) {
 	return a.sqsClient.GetQueueUrl(&sqs.GetQueueUrlInput{
-		QueueName: &queueName,
+		QueueName:              &queueName,
+		QueueOwnerAWSAccountId: &a.arn.AccountID,
 	})
 }
 
+// checkQueueAccess verifies that the queue at the given URL exists and that
+// its attributes are readable with the adapter's credentials.
+func (a *adapter) checkQueueAccess(ctx context.Context, queueURL string) error {
+	_, err := a.sqsClient.GetQueueAttributesWithContext(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       &queueURL,
+		AttributeNames: aws.StringSlice([]string{sqs.QueueAttributeNameQueueArn}),
+	})
+	if err != nil {
+		return queueAccessError(queueURL, err)
+	}
+
+	return nil
+}
+
+// queueAccessError wraps an error returned by the SQS API while accessing the
+// given queue into an error that describes the most likely causes.
+func queueAccessError(queue string, err error) error {
+	if awsErr := awserr.Error(nil); errors.As(err, &awsErr) {
+		switch awsErr.Code() {
+		case sqs.ErrCodeQueueDoesNotExist:
+			return fmt.Errorf("queue %q does not exist, or its access policy doesn't allow the "+
+				"caller to access it: %w", queue, err)
+		case codeAccessDenied:
+			return fmt.Errorf("access to queue %q was denied, the IAM policy of the caller or the "+
+				"access policy of the queue may be missing some permissions: %w", queue, err)
+		}
+	}
+
+	return fmt.Errorf("failed to access queue %q: %w", queue, err)
+}
+
 // prettifyBatchResultErrors returns a pretty string representing a list of
 // batch failures.
 func prettifyBatchResultErrors(errs []*sqs.BatchResultErrorEntry) string {
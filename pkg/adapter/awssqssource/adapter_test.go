@@ -19,6 +19,7 @@ package awssqssource
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -96,6 +97,8 @@ func TestAdapter(t *testing.T) {
 				deleteQueue:  make(chan *sqs.Message, tc.queueBufSize),
 
 				deletePeriod: 5 * time.Millisecond,
+
+				shutdownGracePeriod: deleteRequestTimeout + testTimeout,
 			}
 
 			testCtx, testCancel := context.WithTimeout(context.Background(), testTimeout)
@@ -155,6 +158,73 @@ func TestAdapter(t *testing.T) {
 	}
 }
 
+func TestProcessorReturnsUnprocessedMessages(t *testing.T) {
+	const numMsgs = 5
+
+	ceCli := adaptertest.NewTestClient()
+
+	mt := &pkgadapter.MetricTag{}
+
+	a := adapter{
+		logger: loggingtesting.TestLogger(t),
+
+		mt: mt,
+		sr: mustNewStatsReporter(mt),
+
+		ceClient: ceCli,
+
+		arn: makeARN(tQueueArnResource),
+
+		processQueue: make(chan *sqs.Message, numMsgs),
+		deleteQueue:  make(chan *sqs.Message, numMsgs),
+	}
+
+	msgs := makeMockMessages(numMsgs)
+	for _, msg := range msgs {
+		a.processQueue <- msg
+	}
+	close(a.processQueue)
+
+	// simulates the expiration of the drain period
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	unprocessed := a.runMessagesProcessor(ctx)
+
+	assert.Equal(t, msgs, unprocessed)
+	assert.Empty(t, ceCli.Sent(), "Unprocessed messages were sent")
+	assert.Empty(t, a.deleteQueue, "Unprocessed messages were queued for deletion")
+}
+
+func TestReleaseMessages(t *testing.T) {
+	const numMsgs = 25
+
+	msgs := makeMockMessages(numMsgs)
+
+	sqsCli := &standardMockSQSClient{
+		inFlightMsgs: append([]*sqs.Message(nil), msgs...),
+	}
+
+	err := releaseMessages(context.Background(), sqsCli, tQueueURL, msgs)
+	assert.NoError(t, err)
+
+	assert.Len(t, sqsCli.availMsgs, numMsgs, "Not all messages were released")
+	assert.Empty(t, sqsCli.inFlightMsgs, "Found unreleased in-flight messages")
+}
+
+func TestQueueBacklog(t *testing.T) {
+	const numMsgs = 7
+
+	sqsCli := &standardMockSQSClient{
+		availMsgs:    makeMockMessages(numMsgs),
+		inFlightMsgs: makeMockMessages(2),
+	}
+
+	backlog, err := queueBacklog(context.Background(), sqsCli, tQueueURL)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(numMsgs), backlog)
+}
+
 // makeARN returns a fake SQS ARN for the given resource.
 func makeARN(resource string) arn.ARN {
 	return arn.ARN{
@@ -184,6 +254,25 @@ func (*standardMockSQSClient) GetQueueUrl(*sqs.GetQueueUrlInput) (*sqs.GetQueueU
 	}, nil
 }
 
+func (c *standardMockSQSClient) GetQueueAttributesWithContext(_ aws.Context, //nolint:golint,stylecheck
+	in *sqs.GetQueueAttributesInput, _ ...request.Option) (*sqs.GetQueueAttributesOutput, error) {
+
+	c.Lock()
+	defer c.Unlock()
+
+	attrs := make(map[string]*string)
+
+	for _, name := range aws.StringValueSlice(in.AttributeNames) {
+		if name == sqs.QueueAttributeNameApproximateNumberOfMessages {
+			attrs[name] = aws.String(strconv.Itoa(len(c.availMsgs)))
+		}
+	}
+
+	return &sqs.GetQueueAttributesOutput{
+		Attributes: attrs,
+	}, nil
+}
+
 func (c *standardMockSQSClient) ReceiveMessageWithContext(_ context.Context,
 	in *sqs.ReceiveMessageInput, _ ...request.Option) (*sqs.ReceiveMessageOutput, error) {
 
@@ -236,6 +325,37 @@ func (c *standardMockSQSClient) DeleteMessageBatchWithContext(_ context.Context,
 	return &sqs.DeleteMessageBatchOutput{}, nil
 }
 
+func (c *standardMockSQSClient) ChangeMessageVisibilityBatchWithContext(_ context.Context,
+	in *sqs.ChangeMessageVisibilityBatchInput, _ ...request.Option) (*sqs.ChangeMessageVisibilityBatchOutput, error) {
+
+	c.Lock()
+	defer c.Unlock()
+
+	if n := len(in.Entries); n > maxChangeVisibilityBatchSize {
+		return nil, fmt.Errorf("too many entries in batch: %d", n)
+	}
+
+	released := make(map[ /*msg ID*/ string]struct{}, len(in.Entries))
+	for _, e := range in.Entries {
+		if *e.VisibilityTimeout == 0 {
+			released[*e.Id] = struct{}{}
+		}
+	}
+
+	// make released messages available again
+	oldInFlightMsgs := c.inFlightMsgs
+	c.inFlightMsgs = c.inFlightMsgs[:0]
+	for _, msg := range oldInFlightMsgs {
+		if _, ok := released[*msg.MessageId]; ok {
+			c.availMsgs = append(c.availMsgs, msg)
+			continue
+		}
+		c.inFlightMsgs = append(c.inFlightMsgs, msg)
+	}
+
+	return &sqs.ChangeMessageVisibilityBatchOutput{}, nil
+}
+
 // makeMockMessages returns a set of mocked Messages.
 func makeMockMessages(n int) []*sqs.Message {
 	const receiptHandle = "dHJpZ2dlcm1lc2g="
@@ -311,3 +431,61 @@ func TestDeleteMessageBatchWithContext(t *testing.T) {
 	assert.EqualValues(t, expect, sqsClient.inFlightMsgs)
 	assert.Equal(t, len(in.Entries), sqsClient.totalDeleted)
 }
+
+func TestResolveQueueURL(t *testing.T) {
+	const explicitQueueURL = "https://vpce-0123.sqs.us-fake-0.vpce.amazonaws.com/210987654321/MyQueue"
+
+	testCases := map[string]struct {
+		queueURL  string
+		expectURL string
+	}{
+		"lookup from ARN": {
+			queueURL:  "",
+			expectURL: tQueueURL,
+		},
+		"explicit URL": {
+			queueURL:  explicitQueueURL,
+			expectURL: explicitQueueURL,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			sqsCli := &lookupRecorderMockSQSClient{}
+
+			a := adapter{
+				sqsClient: sqsCli,
+				arn:       makeARN(tQueueArnResource),
+				queueURL:  tc.queueURL,
+			}
+
+			url, err := a.resolveQueueURL()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectURL, url)
+
+			if tc.queueURL != "" {
+				assert.Nil(t, sqsCli.lookupInput, "Unexpected queue lookup")
+				return
+			}
+
+			if assert.NotNil(t, sqsCli.lookupInput, "Expected queue lookup") {
+				assert.Equal(t, tQueueArnResource, *sqsCli.lookupInput.QueueName)
+				assert.Equal(t, "123456789012", *sqsCli.lookupInput.QueueOwnerAWSAccountId)
+			}
+		})
+	}
+}
+
+// lookupRecorderMockSQSClient is a mocked SQS client which records the input
+// of queue URL lookups.
+type lookupRecorderMockSQSClient struct {
+	standardMockSQSClient
+
+	lookupInput *sqs.GetQueueUrlInput
+}
+
+func (c *lookupRecorderMockSQSClient) GetQueueUrl(in *sqs.GetQueueUrlInput) (*sqs.GetQueueUrlOutput, error) { //nolint:golint,stylecheck
+	c.lookupInput = in
+	return c.standardMockSQSClient.GetQueueUrl(in)
+}
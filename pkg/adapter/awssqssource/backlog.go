@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2019-2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssqssource
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+// Calls to GetQueueAttributes are cancelled when they exceed this duration.
+const backlogRequestTimeout = 5 * time.Second
+
+// runBacklogReporter periodically reports the approximate number of visible
+// messages in the SQS queue, until the given context is cancelled.
+//
+// The reported value is exported as a metric, which an autoscaler can use to
+// adjust the number of replicas of the adapter. Because every replica reports
+// the same value, the metric should be aggregated with "max", not "sum".
+func (a *adapter) runBacklogReporter(ctx context.Context, queueURL string) {
+	t := time.NewTicker(a.backlogPollInterval)
+	defer t.Stop()
+
+	for {
+		if backlog, err := queueBacklog(ctx, a.sqsClient, queueURL); err != nil {
+			a.logger.Warnw("Unable to read the backlog of the queue", zap.Error(err))
+		} else {
+			a.sr.reportQueueBacklog(backlog)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// queueBacklog returns the approximate number of messages available for
+// retrieval from the SQS queue at the given URL.
+func queueBacklog(ctx context.Context, cli sqsiface.SQSAPI, queueURL string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, backlogRequestTimeout)
+	defer cancel()
+
+	attrName := sqs.QueueAttributeNameApproximateNumberOfMessages
+
+	out, err := cli.GetQueueAttributesWithContext(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       &queueURL,
+		AttributeNames: aws.StringSlice([]string{attrName}),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("getting attributes of queue: %w", err)
+	}
+
+	val, ok := out.Attributes[attrName]
+	if !ok || val == nil {
+		return 0, fmt.Errorf("queue attribute %q is missing from response", attrName)
+	}
+
+	backlog, err := strconv.ParseInt(*val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing value of queue attribute %q: %w", attrName, err)
+	}
+
+	return backlog, nil
+}
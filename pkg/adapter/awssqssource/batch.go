@@ -0,0 +1,298 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssqssource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opencensus.io/plugin/ochttp"
+	"go.uber.org/zap"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	pkgadapter "knative.dev/eventing/pkg/adapter/v2"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/tracing/propagation/tracecontextb3"
+)
+
+// Media type of CloudEvents encoded in batched mode.
+// https://github.com/cloudevents/spec/blob/v1.0/json-format.md#4-json-batch-format
+const mediaTypeCloudEventsBatch = "application/cloudevents-batch+json"
+
+// eventBatchSender sends batches of CloudEvents to an event sink.
+type eventBatchSender interface {
+	// SendBatch sends the given events to the event sink in a single
+	// request. The batch is either acknowledged or rejected as a whole.
+	SendBatch(ctx context.Context, events []*cloudevents.Event) cloudevents.Result
+}
+
+// httpBatchSender sends batches of CloudEvents to an event sink over HTTP.
+//
+// The CloudEvents client of the adapter doesn't support the batched content
+// mode, so the sender replicates its configuration: CloudEvent overrides,
+// tracing and sink timeout.
+type httpBatchSender struct {
+	sink        string
+	client      *http.Client
+	ceOverrides *duckv1.CloudEventOverrides
+}
+
+var _ eventBatchSender = (*httpBatchSender)(nil)
+
+// newHTTPBatchSender returns a httpBatchSender for the sink defined in the
+// given adapter configuration.
+func newHTTPBatchSender(env pkgadapter.EnvConfigAccessor) (*httpBatchSender, error) {
+	ceOverrides, err := env.GetCloudEventOverrides()
+	if err != nil {
+		return nil, fmt.Errorf("reading CloudEvent overrides: %w", err)
+	}
+
+	client := &http.Client{
+		Transport: &ochttp.Transport{
+			Propagation: tracecontextb3.TraceContextEgress,
+		},
+	}
+	if sinkTimeout := env.GetSinktimeout(); sinkTimeout > 0 {
+		client.Timeout = time.Duration(sinkTimeout) * time.Second
+	}
+
+	return &httpBatchSender{
+		sink:        env.GetSink(),
+		client:      client,
+		ceOverrides: ceOverrides,
+	}, nil
+}
+
+// SendBatch implements eventBatchSender.
+func (s *httpBatchSender) SendBatch(ctx context.Context, events []*cloudevents.Event) cloudevents.Result {
+	if s.ceOverrides != nil && len(s.ceOverrides.Extensions) > 0 {
+		overridden := make([]*cloudevents.Event, len(events))
+		for i, event := range events {
+			e := event.Clone()
+			for n, v := range s.ceOverrides.Extensions {
+				e.SetExtension(n, v)
+			}
+			overridden[i] = &e
+		}
+		events = overridden
+	}
+
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("serializing batch of events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.sink, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", mediaTypeCloudEventsBatch)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// drain the response body to allow the connection to be reused
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return cehttp.NewResult(resp.StatusCode, "sink responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// eventBatch accumulates the CloudEvents generated from SQS messages until
+// they are sent to the event sink as a single batch.
+type eventBatch struct {
+	events []*cloudevents.Event
+	msgs   []*sqs.Message
+	// index of the first event of each message
+	offsets []int
+}
+
+// add appends the events generated from the given message to the batch.
+func (b *eventBatch) add(msg *sqs.Message, events []*cloudevents.Event) {
+	b.offsets = append(b.offsets, len(b.events))
+	b.events = append(b.events, events...)
+	b.msgs = append(b.msgs, msg)
+}
+
+// eventsOf returns the events generated from the i-th message of the batch.
+func (b *eventBatch) eventsOf(i int) []*cloudevents.Event {
+	end := len(b.events)
+	if i+1 < len(b.offsets) {
+		end = b.offsets[i+1]
+	}
+	return b.events[b.offsets[i]:end]
+}
+
+// reset empties the batch, retaining the allocated memory for reuse.
+func (b *eventBatch) reset() {
+	for i := range b.events {
+		b.events[i] = nil
+	}
+	for i := range b.msgs {
+		b.msgs[i] = nil
+	}
+	b.events = b.events[:0]
+	b.msgs = b.msgs[:0]
+	b.offsets = b.offsets[:0]
+}
+
+// A batch message processor processes SQS messages like a regular message
+// processor, except that the resulting CloudEvents are accumulated and sent to
+// the event sink in batches. A batch is sent once it has reached the configured
+// size or once the batch window, which starts with the first message of the
+// batch, expires, whichever happens first.
+// Messages are deleted only after the events generated from them have been
+// acknowledged by the sink. When the sink rejects a batch, its events are sent
+// again individually, so that only the messages whose events are rejected get
+// redelivered. Events generated from a single SQS message are always part of
+// the same batch.
+func (a *adapter) runBatchMessagesProcessor(ctx context.Context) (unprocessed []*sqs.Message) {
+	var batch eventBatch
+
+	// the timer only runs while the batch contains messages
+	t := time.NewTimer(a.batchWindow)
+	stopTimer(t)
+	defer t.Stop()
+
+	handleBatch := func() {
+		stopTimer(t)
+
+		if len(batch.msgs) == 0 {
+			return
+		}
+
+		defer batch.reset()
+
+		if ctx.Err() != nil {
+			unprocessed = append(unprocessed, batch.msgs...)
+			return
+		}
+
+		a.logger.Debugw("Sending batch of "+strconv.Itoa(len(batch.events))+" event(s)",
+			zap.Array(logfieldMsgIDs, messageList(batch.msgs)))
+
+		start := time.Now()
+		result := a.batchSender.SendBatch(ctx, batch.events)
+		a.sr.reportSinkSendLatency(time.Since(start))
+
+		if cloudevents.IsACK(result) {
+			for _, msg := range batch.msgs {
+				a.deleteQueue <- msg
+				a.sr.reportMessageEnqueuedDeleteCount()
+			}
+			return
+		}
+
+		a.sr.reportSinkSendFailure(responseCode(result))
+
+		if ctx.Err() != nil {
+			unprocessed = append(unprocessed, batch.msgs...)
+			return
+		}
+
+		a.logger.Warnw("Sink rejected batch of events, sending events individually", zap.Error(result),
+			zap.Array(logfieldMsgIDs, messageList(batch.msgs)))
+
+		for i, msg := range batch.msgs {
+			if err := a.sendEvents(ctx, batch.eventsOf(i)); err != nil {
+				if ctx.Err() != nil {
+					unprocessed = append(unprocessed, batch.msgs[i:]...)
+					return
+				}
+
+				a.logger.Errorw("Failed to send event to the sink", zap.Error(err),
+					zap.String(logfieldMsgID, *msg.MessageId))
+
+				continue
+			}
+
+			a.deleteQueue <- msg
+			a.sr.reportMessageEnqueuedDeleteCount()
+		}
+	}
+
+	for {
+		select {
+		case <-t.C:
+			handleBatch()
+
+		case msg, ok := <-a.processQueue:
+			if !ok {
+				// always flush current batch upon termination
+				handleBatch()
+				return unprocessed
+			}
+
+			a.sr.reportMessageDequeuedProcessCount()
+
+			a.logger.Debugw("Processing message", zap.String(logfieldMsgID, *msg.MessageId))
+
+			events, err := a.makeEvents(msg)
+			if err != nil {
+				a.logger.Errorw("Failed to convert message to CloudEvent", zap.Error(err),
+					zap.String(logfieldMsgID, *msg.MessageId))
+
+				continue
+			}
+
+			// events from a single message are never split across
+			// batches, so a batch may exceed the configured size
+			// when a message translates to multiple events
+			if l := len(batch.events); l > 0 && l+len(events) > a.batchSize {
+				handleBatch()
+			}
+
+			if len(batch.msgs) == 0 {
+				t.Reset(a.batchWindow)
+			}
+
+			batch.add(msg, events)
+
+			if len(batch.events) >= a.batchSize {
+				handleBatch()
+			}
+		}
+	}
+}
+
+// stopTimer stops the given timer and drains its channel, so that the timer
+// can be safely reset.
+func stopTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssqssource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	pkgadapter "knative.dev/eventing/pkg/adapter/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	loggingtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestHTTPBatchSender(t *testing.T) {
+	var gotContentType string
+	var gotEvents []cloudevents.Event
+
+	respCode := http.StatusAccepted
+
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&gotEvents); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(respCode)
+	}))
+	defer sink.Close()
+
+	s, err := newHTTPBatchSender(&envConfig{
+		EnvConfig: pkgadapter.EnvConfig{
+			Sink:        sink.URL,
+			CEOverrides: `{"extensions":{"testext":"testval"}}`,
+		},
+	})
+	require.NoError(t, err)
+
+	events := makeTestEvents(3)
+
+	result := s.SendBatch(context.Background(), events)
+	assert.True(t, cloudevents.IsACK(result), "Batch wasn't acknowledged: %v", result)
+
+	assert.Equal(t, mediaTypeCloudEventsBatch, gotContentType)
+	require.Len(t, gotEvents, len(events))
+	for i := range events {
+		assert.Equal(t, events[i].ID(), gotEvents[i].ID())
+		assert.Equal(t, "testval", gotEvents[i].Extensions()["testext"], "CloudEvent overrides weren't applied")
+		assert.NotContains(t, events[i].Extensions(), "testext", "Original event was mutated")
+	}
+
+	respCode = http.StatusServiceUnavailable
+
+	result = s.SendBatch(context.Background(), events)
+	assert.False(t, cloudevents.IsACK(result), "Batch was acknowledged")
+	assert.Equal(t, http.StatusServiceUnavailable, responseCode(result))
+}
+
+func TestBatchMessagesProcessor(t *testing.T) {
+	const numMsgs = 7
+	const batchSize = 3
+
+	testCases := map[string]struct {
+		sendErr           error
+		rejectMsgIDs      map[string]bool
+		expectDeleted     int
+		expectSingleSends int
+	}{
+		"sink acknowledges batches": {
+			sendErr:       nil,
+			expectDeleted: numMsgs,
+		},
+		"sink rejects batches, acknowledges individual events": {
+			sendErr:           errors.New("rejected"),
+			expectDeleted:     numMsgs,
+			expectSingleSends: numMsgs,
+		},
+		"sink rejects batches, rejects some individual events": {
+			sendErr:           errors.New("rejected"),
+			rejectMsgIDs:      map[string]bool{tMsgIDPrefix + "001": true, tMsgIDPrefix + "005": true},
+			expectDeleted:     numMsgs - 2,
+			expectSingleSends: numMsgs,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			sender := &mockBatchSender{err: tc.sendErr}
+			ceCli := &rejectingCEClient{
+				Client:    adaptertest.NewTestClient(),
+				rejectIDs: tc.rejectMsgIDs,
+			}
+
+			mt := &pkgadapter.MetricTag{}
+
+			a := adapter{
+				logger: loggingtesting.TestLogger(t),
+
+				mt: mt,
+				sr: mustNewStatsReporter(mt),
+
+				ceClient:    ceCli,
+				batchSender: sender,
+
+				arn: makeARN(tQueueArnResource),
+
+				processQueue: make(chan *sqs.Message, numMsgs),
+				deleteQueue:  make(chan *sqs.Message, numMsgs),
+
+				batchSize: batchSize,
+				// long enough to never expire during the test
+				batchWindow: time.Minute,
+			}
+
+			for _, msg := range makeMockMessages(numMsgs) {
+				a.processQueue <- msg
+			}
+			close(a.processQueue)
+
+			unprocessed := a.runBatchMessagesProcessor(context.Background())
+			assert.Empty(t, unprocessed)
+
+			expectBatchSizes := []int{3, 3, 1}
+			if assert.Len(t, sender.batches, len(expectBatchSizes)) {
+				for i, size := range expectBatchSizes {
+					assert.Len(t, sender.batches[i], size)
+				}
+			}
+
+			assert.Len(t, a.deleteQueue, tc.expectDeleted)
+			assert.Equal(t, tc.expectSingleSends, ceCli.sendCount)
+		})
+	}
+}
+
+func TestBatchWindow(t *testing.T) {
+	const batchWindow = 20 * time.Millisecond
+
+	sender := &mockBatchSender{}
+
+	mt := &pkgadapter.MetricTag{}
+
+	a := adapter{
+		logger: loggingtesting.TestLogger(t),
+
+		mt: mt,
+		sr: mustNewStatsReporter(mt),
+
+		batchSender: sender,
+
+		arn: makeARN(tQueueArnResource),
+
+		processQueue: make(chan *sqs.Message),
+		deleteQueue:  make(chan *sqs.Message, 1),
+
+		batchSize:   10,
+		batchWindow: batchWindow,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.runBatchMessagesProcessor(context.Background())
+	}()
+
+	// the batch window starts with the first buffered message, not when
+	// the processor starts
+	time.Sleep(2 * batchWindow)
+
+	start := time.Now()
+	a.processQueue <- makeMockMessages(1)[0]
+
+	select {
+	case <-a.deleteQueue:
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(batchWindow),
+			"Batch was sent before the end of the batch window")
+	case <-time.After(10 * batchWindow):
+		t.Fatal("Timeout waiting for batch to be sent")
+	}
+
+	close(a.processQueue)
+	<-done
+}
+
+// mockBatchSender is a eventBatchSender which records the batches it is
+// requested to send.
+type mockBatchSender struct {
+	err     error
+	batches [][]*cloudevents.Event
+}
+
+func (s *mockBatchSender) SendBatch(_ context.Context, events []*cloudevents.Event) cloudevents.Result {
+	s.batches = append(s.batches, append([]*cloudevents.Event(nil), events...))
+	return s.err
+}
+
+// rejectingCEClient is a CloudEvents client which rejects the events with
+// the given IDs and counts the events it is requested to send.
+type rejectingCEClient struct {
+	cloudevents.Client
+	rejectIDs map[string]bool
+	sendCount int
+}
+
+func (c *rejectingCEClient) Send(ctx context.Context, event cloudevents.Event) cloudevents.Result {
+	c.sendCount++
+	if c.rejectIDs[event.ID()] {
+		return errors.New("rejected")
+	}
+	return c.Client.Send(ctx, event)
+}
+
+// makeTestEvents returns a set of CloudEvents.
+func makeTestEvents(n int) []*cloudevents.Event {
+	msgs := makeMockMessages(n)
+	arn := makeARN(tQueueArnResource)
+
+	events := make([]*cloudevents.Event, n)
+	for i, msg := range msgs {
+		event, err := makeSQSEvent(&arn, msg)
+		if err != nil {
+			panic(err)
+		}
+		events[i] = event
+	}
+
+	return events
+}
@@ -0,0 +1,224 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssqssource
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// snsNotification is the JSON envelope of a SNS notification delivered to a
+// SQS queue.
+// https://docs.aws.amazon.com/sns/latest/dg/sns-message-and-json-formats.html#http-notification-json
+type snsNotification struct {
+	Type      string    `json:"Type"`
+	MessageID string    `json:"MessageId"`
+	TopicArn  string    `json:"TopicArn"`
+	Subject   string    `json:"Subject"`
+	Message   string    `json:"Message"`
+	Timestamp time.Time `json:"Timestamp"`
+}
+
+// s3EventNotification is the JSON payload of a S3 event notification.
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/notification-content-structure.html
+type s3EventNotification struct {
+	Records []json.RawMessage `json:"Records"`
+}
+
+// s3EventRecord contains the attributes of a S3 event notification record
+// which are relevant to the CloudEvent context.
+type s3EventRecord struct {
+	EventSource string    `json:"eventSource"`
+	EventTime   time.Time `json:"eventTime"`
+	EventName   string    `json:"eventName"`
+	S3          struct {
+		Bucket struct {
+			Name string `json:"name"`
+			Arn  string `json:"arn"`
+		} `json:"bucket"`
+		Object struct {
+			Key string `json:"key"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+// eventBridgeEvent is the JSON envelope of an EventBridge event.
+// https://docs.aws.amazon.com/eventbridge/latest/userguide/aws-events.html
+type eventBridgeEvent struct {
+	ID         string          `json:"id"`
+	DetailType string          `json:"detail-type"`
+	Source     string          `json:"source"`
+	Time       time.Time       `json:"time"`
+	Resources  []string        `json:"resources"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// decodeNotification attempts to recognize a notification forwarded by another
+// AWS service (SNS, S3, EventBridge) in the body of the given SQS message, and
+// returns the CloudEvents this notification translates to.
+// A nil slice is returned if the message body isn't a known notification.
+func decodeNotification(msg *sqs.Message) ([]*cloudevents.Event, error) {
+	if msg.Body == nil {
+		return nil, nil
+	}
+	return decodeNotificationBody(*msg.MessageId, []byte(*msg.Body))
+}
+
+// decodeNotificationBody attempts to recognize a known notification format in
+// the given payload. The given ID is used as a base for generating unique
+// event IDs for payloads that don't carry an ID of their own.
+func decodeNotificationBody(id string, body []byte) ([]*cloudevents.Event, error) {
+	if !json.Valid(body) {
+		return nil, nil
+	}
+
+	if n := (snsNotification{}); json.Unmarshal(body, &n) == nil && n.Type == "Notification" && n.TopicArn != "" {
+		// SNS topics are commonly used to fan out notifications from
+		// other services, in which case the inner notification
+		// describes the event more accurately than its envelope.
+		events, err := decodeNotificationBody(n.MessageID, []byte(n.Message))
+		if err != nil || events != nil {
+			return events, err
+		}
+
+		event, err := makeSNSEvent(&n)
+		if err != nil {
+			return nil, err
+		}
+		return []*cloudevents.Event{event}, nil
+	}
+
+	if n := (s3EventNotification{}); json.Unmarshal(body, &n) == nil && len(n.Records) > 0 {
+		return makeS3Events(id, n.Records)
+	}
+
+	if e := (eventBridgeEvent{}); json.Unmarshal(body, &e) == nil && e.ID != "" && e.DetailType != "" && e.Source != "" {
+		event, err := makeEventBridgeEvent(&e)
+		if err != nil {
+			return nil, err
+		}
+		return []*cloudevents.Event{event}, nil
+	}
+
+	return nil, nil
+}
+
+// makeSNSEvent returns a CloudEvent representing a SNS notification.
+func makeSNSEvent(n *snsNotification) (*cloudevents.Event, error) {
+	event := cloudevents.NewEvent()
+	event.SetType(v1alpha1.AWSEventType(sns.ServiceName, v1alpha1.AWSSNSGenericEventType))
+	event.SetSource(n.TopicArn)
+	event.SetID(n.MessageID)
+	event.SetTime(n.Timestamp)
+	if n.Subject != "" {
+		event.SetSubject(n.Subject)
+	}
+
+	contentType := cloudevents.TextPlain
+	if json.Valid([]byte(n.Message)) {
+		contentType = cloudevents.ApplicationJSON
+	}
+
+	if err := event.SetData(contentType, []byte(n.Message)); err != nil {
+		return nil, fmt.Errorf("setting CloudEvent data: %w", err)
+	}
+
+	return &event, nil
+}
+
+// makeS3Events returns CloudEvents representing the records of a S3 event
+// notification, or nil if the records do not originate from S3.
+func makeS3Events(id string, records []json.RawMessage) ([]*cloudevents.Event, error) {
+	events := make([]*cloudevents.Event, 0, len(records))
+
+	for i, rawRecord := range records {
+		// records which can not be parsed as S3 event records are
+		// assumed to belong to an arbitrary JSON payload, which is
+		// forwarded as a generic SQS event
+		var r s3EventRecord
+		if err := json.Unmarshal(rawRecord, &r); err != nil || r.EventSource != "aws:s3" {
+			return nil, nil
+		}
+
+		// e.g. "ObjectCreated:Put" -> "objectcreated"
+		typ := strings.ToLower(strings.SplitN(r.EventName, ":", 2)[0])
+
+		eventID := id
+		if len(records) > 1 {
+			eventID += "-" + strconv.Itoa(i)
+		}
+
+		event := cloudevents.NewEvent()
+		event.SetType(v1alpha1.AWSEventType(s3.ServiceName, typ))
+		event.SetSource(r.S3.Bucket.Arn)
+		event.SetSubject(r.S3.Object.Key)
+		event.SetID(eventID)
+		event.SetTime(r.EventTime)
+		if err := event.SetData(cloudevents.ApplicationJSON, []byte(rawRecord)); err != nil {
+			return nil, fmt.Errorf("setting CloudEvent data: %w", err)
+		}
+
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
+// makeEventBridgeEvent returns a CloudEvent representing an EventBridge event.
+func makeEventBridgeEvent(e *eventBridgeEvent) (*cloudevents.Event, error) {
+	// e.g. "EC2 Instance State-change Notification" -> "ec2_instance_state_change_notification"
+	detailType := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(e.DetailType))
+
+	// Events emitted by AWS services have a source of the form
+	// "aws.<service>", while custom events have an arbitrary source.
+	typ := e.Source + "." + detailType
+	if svc := strings.TrimPrefix(e.Source, "aws."); svc != e.Source {
+		typ = v1alpha1.AWSEventType(svc, detailType)
+	}
+
+	source := e.Source
+	if len(e.Resources) > 0 {
+		source = e.Resources[0]
+	}
+
+	event := cloudevents.NewEvent()
+	event.SetType(typ)
+	event.SetSource(source)
+	event.SetID(e.ID)
+	event.SetTime(e.Time)
+	if err := event.SetData(cloudevents.ApplicationJSON, []byte(e.Detail)); err != nil {
+		return nil, fmt.Errorf("setting CloudEvent data: %w", err)
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssqssource
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+const (
+	tS3Record1 = `{"eventSource":"aws:s3","eventTime":"2020-12-01T10:00:00.000Z","eventName":"ObjectCreated:Put",` +
+		`"s3":{"bucket":{"name":"mybucket","arn":"arn:aws:s3:::mybucket"},"object":{"key":"file1.txt"}}}`
+	tS3Record2 = `{"eventSource":"aws:s3","eventTime":"2020-12-01T10:00:01.000Z","eventName":"ObjectRemoved:Delete",` +
+		`"s3":{"bucket":{"name":"mybucket","arn":"arn:aws:s3:::mybucket"},"object":{"key":"file2.txt"}}}`
+
+	tS3Notification = `{"Records":[` + tS3Record1 + `,` + tS3Record2 + `]}`
+
+	tEventBridgeEvent = `{"version":"0","id":"6a7e8feb-b491-4cf7-a9f1-bf3703467718",` +
+		`"detail-type":"EC2 Instance State-change Notification","source":"aws.ec2","account":"123456789012",` +
+		`"time":"2020-12-01T10:00:00Z","region":"us-fake-0",` +
+		`"resources":["arn:aws:ec2:us-fake-0:123456789012:instance/i-1234567890abcdef0"],` +
+		`"detail":{"instance-id":"i-1234567890abcdef0","state":"terminated"}}`
+)
+
+func TestDecodeNotification(t *testing.T) {
+	const msgID = "00000000-0000-0000-0000-000000000001"
+
+	snsNotification := func(message, subject string) string {
+		n := map[string]string{
+			"Type":      "Notification",
+			"MessageId": "11111111-1111-1111-1111-111111111111",
+			"TopicArn":  "arn:aws:sns:us-fake-0:123456789012:MyTopic",
+			"Message":   message,
+			"Timestamp": "2020-12-01T10:00:00.000Z",
+		}
+		if subject != "" {
+			n["Subject"] = subject
+		}
+
+		b, err := json.Marshal(n)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	type expectEvent struct {
+		typ, source, subject, id, data string
+	}
+
+	testCases := map[string]struct {
+		body   string
+		expect []expectEvent
+	}{
+		"not a notification": {
+			body:   `{"hello":"world"}`,
+			expect: nil,
+		},
+		"not JSON": {
+			body:   `hello world`,
+			expect: nil,
+		},
+		"arbitrary records": {
+			body:   `{"Records":["a",1]}`,
+			expect: nil,
+		},
+		"records with invalid time": {
+			body:   `{"Records":[{"eventSource":"aws:s3","eventTime":"yesterday"}]}`,
+			expect: nil,
+		},
+		"SNS notification": {
+			body: snsNotification(`{"hello":"world"}`, "greeting"),
+			expect: []expectEvent{{
+				typ:     "com.amazon.sns.notification",
+				source:  "arn:aws:sns:us-fake-0:123456789012:MyTopic",
+				subject: "greeting",
+				id:      "11111111-1111-1111-1111-111111111111",
+				data:    `{"hello":"world"}`,
+			}},
+		},
+		"S3 notification": {
+			body: tS3Notification,
+			expect: []expectEvent{{
+				typ:     "com.amazon.s3.objectcreated",
+				source:  "arn:aws:s3:::mybucket",
+				subject: "file1.txt",
+				id:      msgID + "-0",
+				data:    tS3Record1,
+			}, {
+				typ:     "com.amazon.s3.objectremoved",
+				source:  "arn:aws:s3:::mybucket",
+				subject: "file2.txt",
+				id:      msgID + "-1",
+				data:    tS3Record2,
+			}},
+		},
+		"S3 notification via SNS": {
+			body: snsNotification(`{"Records":[`+tS3Record1+`]}`, ""),
+			expect: []expectEvent{{
+				typ:     "com.amazon.s3.objectcreated",
+				source:  "arn:aws:s3:::mybucket",
+				subject: "file1.txt",
+				id:      "11111111-1111-1111-1111-111111111111",
+				data:    tS3Record1,
+			}},
+		},
+		"EventBridge event": {
+			body: tEventBridgeEvent,
+			expect: []expectEvent{{
+				typ:    "com.amazon.ec2.ec2_instance_state_change_notification",
+				source: "arn:aws:ec2:us-fake-0:123456789012:instance/i-1234567890abcdef0",
+				id:     "6a7e8feb-b491-4cf7-a9f1-bf3703467718",
+				data:   `{"instance-id":"i-1234567890abcdef0","state":"terminated"}`,
+			}},
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			msg := &sqs.Message{
+				MessageId: aws.String(msgID),
+				Body:      aws.String(tc.body),
+			}
+
+			events, err := decodeNotification(msg)
+			require.NoError(t, err)
+
+			if tc.expect == nil {
+				assert.Nil(t, events)
+				return
+			}
+
+			require.Len(t, events, len(tc.expect))
+
+			for i, e := range tc.expect {
+				assert.Equal(t, e.typ, events[i].Type())
+				assert.Equal(t, e.source, events[i].Source())
+				assert.Equal(t, e.subject, events[i].Subject())
+				assert.Equal(t, e.id, events[i].ID())
+				assert.JSONEq(t, e.data, string(events[i].Data()))
+			}
+		})
+	}
+}
@@ -47,10 +47,12 @@ const (
 // processed. It does this by accumulating references of SQS messages into a
 // deletion buffer until this buffer has reached its capacity or until a timer
 // expires, whichever happens first.
-func (a *adapter) runMessagesDeleter(ctx context.Context, queueURL string) {
+// It returns once deleteQueue is closed and drained.
+func (a *adapter) runMessagesDeleter(queueURL string) {
 	delMsgBuf := make(messageDeleteBuffer, maxDeleteMsgBatchSize)
 
 	t := time.NewTimer(a.deletePeriod)
+	defer t.Stop()
 
 	// calling this function blocks the processing of received messages by
 	// this deleter temporarily
@@ -63,12 +65,18 @@ func (a *adapter) runMessagesDeleter(ctx context.Context, queueURL string) {
 
 		a.logger.Debugw("Deleting messages", zap.Array(logfieldMsgIDs, delMsgBuf))
 
-		if err := deleteMessages(ctx, a.sqsClient, queueURL, delMsgBuf); err != nil {
+		if err := deleteMessages(context.Background(), a.sqsClient, queueURL, delMsgBuf); err != nil {
 			// NOTE(antoineco): If the batch deletion fails, SQS
 			// will re-add those messages to the queue after the
 			// visibility timeout has expired, causing a
 			// re-delivery (at-least-once delivery).
 			a.logger.Errorw("Failed to delete messages from the SQS queue", zap.Error(err))
+
+			failed := len(delMsgBuf)
+			if bErr := (*batchDeleteError)(nil); errors.As(err, &bErr) {
+				failed = len(bErr.failed)
+			}
+			a.sr.reportMessageDeleteFailures(failed)
 		}
 
 		// reuse the same buffer to avoid new allocations
@@ -79,17 +87,16 @@ func (a *adapter) runMessagesDeleter(ctx context.Context, queueURL string) {
 
 	for {
 		select {
-		case <-ctx.Done():
-			// always flush current message buffer upon termination
-			ctx = context.Background()
-			handleDeletion()
-
-			return
-
 		case <-t.C:
 			handleDeletion()
 
-		case msg := <-a.deleteQueue:
+		case msg, ok := <-a.deleteQueue:
+			if !ok {
+				// always flush current message buffer upon termination
+				handleDeletion()
+				return
+			}
+
 			a.sr.reportMessageDequeuedDeleteCount()
 
 			delMsgBuf[*msg.MessageId] = *msg.ReceiptHandle
@@ -138,8 +145,19 @@ func deleteMessages(ctx context.Context, cli sqsiface.SQSAPI, queueURL string, m
 		return err
 	}
 	if len(out.Failed) > 0 {
-		return errors.New(prettifyBatchResultErrors(out.Failed))
+		return &batchDeleteError{failed: out.Failed}
 	}
 
 	return nil
 }
+
+// batchDeleteError is returned when some of the messages from a batch could
+// not be deleted.
+type batchDeleteError struct {
+	failed []*sqs.BatchResultErrorEntry
+}
+
+// Error implements the error interface.
+func (e *batchDeleteError) Error() string {
+	return prettifyBatchResultErrors(e.failed)
+}
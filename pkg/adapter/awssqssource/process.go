@@ -19,10 +19,12 @@ package awssqssource
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
 
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/sqs"
@@ -32,32 +34,71 @@ import (
 
 // A message processor processes SQS messages (sends as CloudEvent)
 // sequentially, as soon as they are written to processQueue.
-func (a *adapter) runMessagesProcessor(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
+// It returns once processQueue is closed and drained, along with the messages
+// it couldn't process because ctx was cancelled.
+func (a *adapter) runMessagesProcessor(ctx context.Context) (unprocessed []*sqs.Message) {
+	for msg := range a.processQueue {
+		a.sr.reportMessageDequeuedProcessCount()
+
+		if ctx.Err() != nil {
+			unprocessed = append(unprocessed, msg)
+			continue
+		}
 
-		case msg := <-a.processQueue:
-			a.sr.reportMessageDequeuedProcessCount()
+		a.logger.Debugw("Processing message", zap.String(logfieldMsgID, *msg.MessageId))
 
-			a.logger.Debugw("Processing message", zap.String(logfieldMsgID, *msg.MessageId))
+		events, err := a.makeEvents(msg)
+		if err != nil {
+			a.logger.Errorw("Failed to convert message to CloudEvent", zap.Error(err),
+				zap.String(logfieldMsgID, *msg.MessageId))
 
-			if err := sendSQSEvent(ctx, a.ceClient, &a.arn, msg); err != nil {
-				a.logger.Errorw("Failed to send event to the sink", zap.Error(err),
-					zap.String(logfieldMsgID, *msg.MessageId))
+			continue
+		}
 
+		if err := a.sendEvents(ctx, events); err != nil {
+			if ctx.Err() != nil {
+				unprocessed = append(unprocessed, msg)
 				continue
 			}
 
-			a.deleteQueue <- msg
-			a.sr.reportMessageEnqueuedDeleteCount()
+			a.logger.Errorw("Failed to send event to the sink", zap.Error(err),
+				zap.String(logfieldMsgID, *msg.MessageId))
+
+			continue
 		}
+
+		a.deleteQueue <- msg
+		a.sr.reportMessageEnqueuedDeleteCount()
 	}
+
+	return unprocessed
 }
 
-// sendSQSEvent sends a single SQS message as a CloudEvent to the event sink.
-func sendSQSEvent(ctx context.Context, cli cloudevents.Client, arn *arn.ARN, msg *sqs.Message) error {
+// makeEvents returns the CloudEvents to send to the event sink for the given
+// SQS message. Notifications forwarded by other AWS services are unwrapped
+// when the adapter is configured to decode them, in which case a single
+// message may result in multiple events.
+func (a *adapter) makeEvents(msg *sqs.Message) ([]*cloudevents.Event, error) {
+	if a.decodeNotifications {
+		events, err := decodeNotification(msg)
+		if err != nil {
+			return nil, fmt.Errorf("decoding notification: %w", err)
+		}
+		if events != nil {
+			return events, nil
+		}
+	}
+
+	event, err := makeSQSEvent(&a.arn, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*cloudevents.Event{event}, nil
+}
+
+// makeSQSEvent returns a CloudEvent representing a single SQS message.
+func makeSQSEvent(arn *arn.ARN, msg *sqs.Message) (*cloudevents.Event, error) {
 	// TODO: work on CE attributes contract
 	subject, exist := msg.Attributes[sqs.MessageSystemAttributeNameSenderId]
 	if !exist {
@@ -70,11 +111,35 @@ func sendSQSEvent(ctx context.Context, cli cloudevents.Client, arn *arn.ARN, msg
 	event.SetSource(arn.String())
 	event.SetID(*msg.MessageId)
 	if err := event.SetData(cloudevents.ApplicationJSON, msg); err != nil {
-		return fmt.Errorf("setting CloudEvent data: %w", err)
+		return nil, fmt.Errorf("setting CloudEvent data: %w", err)
 	}
 
-	if result := cli.Send(ctx, event); !cloudevents.IsACK(result) {
-		return result
+	return &event, nil
+}
+
+// sendEvents sends the given CloudEvents to the event sink sequentially. It
+// returns as soon as the sink fails to acknowledge one of them, in which case
+// the SQS message they originate from is redelivered later.
+func (a *adapter) sendEvents(ctx context.Context, events []*cloudevents.Event) error {
+	for _, event := range events {
+		start := time.Now()
+		result := a.ceClient.Send(ctx, *event)
+		a.sr.reportSinkSendLatency(time.Since(start))
+
+		if !cloudevents.IsACK(result) {
+			a.sr.reportSinkSendFailure(responseCode(result))
+			return result
+		}
 	}
 	return nil
 }
+
+// responseCode returns the HTTP response code contained in the given result,
+// or 0 if the result doesn't contain any HTTP response.
+func responseCode(result cloudevents.Result) int {
+	var httpResult *cehttp.Result
+	if cloudevents.ResultAs(result, &httpResult) {
+		return httpResult.StatusCode
+	}
+	return 0
+}
@@ -59,6 +59,12 @@ func (a *adapter) runMessagesReceiver(ctx context.Context, queueURL string) {
 		case <-t.C:
 			messages, err := receiveMessages(ctx, a.sqsClient, queueURL)
 			if err != nil {
+				if ctx.Err() != nil {
+					// request was interrupted by termination
+					return
+				}
+
+				a.sr.reportReceiveError()
 				a.logger.Errorw("Failed to get messages from the SQS queue", zap.Error(err))
 				t.Reset(1 * time.Second)
 				continue
@@ -75,7 +81,13 @@ func (a *adapter) runMessagesReceiver(ctx context.Context, queueURL string) {
 					zap.Array(logfieldMsgID, messageList(messages)))
 			}
 
+			receivedAt := time.Now()
+
 			for _, msg := range messages {
+				if sentAt, ok := sentTimestamp(msg); ok {
+					a.sr.reportMessageAgeAtReceive(receivedAt.Sub(sentAt))
+				}
+
 				a.processQueue <- msg
 				a.sr.reportMessageEnqueuedProcessCount()
 			}
@@ -113,3 +125,20 @@ func receiveMessages(ctx context.Context, cli sqsiface.SQSAPI, queueURL string)
 
 	return resp.Messages, nil
 }
+
+// sentTimestamp returns the time at which the given message was sent to the
+// queue, if the SentTimestamp attribute was received along with it.
+func sentTimestamp(msg *sqs.Message) (time.Time, bool) {
+	ts, ok := msg.Attributes[sqs.MessageSystemAttributeNameSentTimestamp]
+	if !ok || ts == nil {
+		return time.Time{}, false
+	}
+
+	// epoch time in milliseconds
+	msec, err := strconv.ParseInt(*ts, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, msec*int64(time.Millisecond)), true
+}
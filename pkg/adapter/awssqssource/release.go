@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssqssource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+const (
+	// Highest possible number of entries in a ChangeMessageVisibilityBatch
+	// request.
+	// https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_ChangeMessageVisibilityBatch.html
+	maxChangeVisibilityBatchSize = 10
+
+	// Calls to ChangeMessageVisibilityBatch are cancelled when they exceed
+	// this duration.
+	changeVisibilityRequestTimeout = 5 * time.Second
+)
+
+// releaseMessages makes the given messages immediately visible to other
+// consumers of the SQS queue by resetting their visibility timeout, instead of
+// waiting for it to expire.
+func releaseMessages(ctx context.Context, cli sqsiface.SQSAPI, queueURL string, msgs []*sqs.Message) error {
+	var errs []error
+
+	for start := 0; start < len(msgs); start += maxChangeVisibilityBatchSize {
+		end := start + maxChangeVisibilityBatchSize
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+
+		if err := resetVisibility(ctx, cli, queueURL, msgs[start:end]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return fmt.Errorf("%d batches of messages could not be released. First error: %w", len(errs), errs[0])
+	}
+}
+
+// resetVisibility sets the visibility timeout of a batch of messages to zero.
+func resetVisibility(ctx context.Context, cli sqsiface.SQSAPI, queueURL string, msgs []*sqs.Message) error {
+	entries := make([]*sqs.ChangeMessageVisibilityBatchRequestEntry, len(msgs))
+	for i, msg := range msgs {
+		entries[i] = &sqs.ChangeMessageVisibilityBatchRequestEntry{
+			Id:                msg.MessageId,
+			ReceiptHandle:     msg.ReceiptHandle,
+			VisibilityTimeout: aws.Int64(0),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, changeVisibilityRequestTimeout)
+	defer cancel()
+
+	out, err := cli.ChangeMessageVisibilityBatchWithContext(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+		QueueUrl: &queueURL,
+		Entries:  entries,
+	})
+	if err != nil {
+		return err
+	}
+	if len(out.Failed) > 0 {
+		return errors.New(prettifyBatchResultErrors(out.Failed))
+	}
+
+	return nil
+}
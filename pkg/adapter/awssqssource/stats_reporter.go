@@ -19,6 +19,8 @@ package awssqssource
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
@@ -36,12 +38,19 @@ const (
 	metricNameMsgDequeuedProcessCount = "message_dequeued_process_count"
 	metricNameMsgEnqueuedDeleteCount  = "message_enqueued_delete_count"
 	metricNameMsgDequeuedDeleteCount  = "message_dequeued_delete_count"
+	metricNameMsgAgeAtReceive         = "message_age_at_receive"
+	metricNameSinkSendLatency         = "sink_send_latency"
+	metricNameSinkSendFailureCount    = "sink_send_failure_count"
+	metricNameMsgDeleteFailureCount   = "message_delete_failure_count"
+	metricNameReceiveErrorCount       = "receive_error_count"
+	metricNameQueueBacklog            = "approximate_number_of_messages_visible"
 )
 
 var (
 	tagKeyResourceGroup = tag.MustNewKey(metricskey.LabelResourceGroup)
 	tagKeyNamespace     = tag.MustNewKey(metricskey.LabelNamespaceName)
 	tagKeyName          = tag.MustNewKey(metricskey.LabelName)
+	tagKeyResponseCode  = tag.MustNewKey(metricskey.LabelResponseCode)
 )
 
 // queueCapacityProcessM records the capacity of the processing queue.
@@ -90,6 +99,53 @@ var msgDequeuedDeleteCountM = stats.Int64(
 	stats.UnitDimensionless,
 )
 
+// msgAgeAtReceiveM records the time elapsed between the moment SQS messages
+// were sent to the queue and the moment they were received by the source.
+var msgAgeAtReceiveM = stats.Int64(
+	metricNameMsgAgeAtReceive,
+	"Time SQS messages spent in the queue before being received",
+	stats.UnitMilliseconds,
+)
+
+// sinkSendLatencyM records the time it takes to deliver events to the sink.
+var sinkSendLatencyM = stats.Int64(
+	metricNameSinkSendLatency,
+	"Time it takes to deliver an event to the sink",
+	stats.UnitMilliseconds,
+)
+
+// sinkSendFailureCountM records the number of events that could not be
+// delivered to the sink.
+var sinkSendFailureCountM = stats.Int64(
+	metricNameSinkSendFailureCount,
+	"Number of events that could not be delivered to the sink",
+	stats.UnitDimensionless,
+)
+
+// msgDeleteFailureCountM records the number of SQS messages that could not be
+// deleted from the queue.
+var msgDeleteFailureCountM = stats.Int64(
+	metricNameMsgDeleteFailureCount,
+	"Number of SQS messages that could not be deleted from the queue",
+	stats.UnitDimensionless,
+)
+
+// receiveErrorCountM records the number of failed requests to the
+// ReceiveMessage API.
+var receiveErrorCountM = stats.Int64(
+	metricNameReceiveErrorCount,
+	"Number of failed requests to the SQS ReceiveMessage API",
+	stats.UnitDimensionless,
+)
+
+// queueBacklogM records the approximate number of messages available for
+// retrieval from the SQS queue.
+var queueBacklogM = stats.Int64(
+	metricNameQueueBacklog,
+	"Approximate number of messages available for retrieval from the SQS queue",
+	stats.UnitDimensionless,
+)
+
 // mustRegisterStatsView registers an OpenCensus stats view for the source's
 // metrics and panics in case of error.
 func mustRegisterStatsView() {
@@ -136,6 +192,43 @@ func mustRegisterStatsView() {
 			Aggregation: view.Count(),
 			TagKeys:     tagKeys,
 		},
+		&view.View{
+			Measure:     msgAgeAtReceiveM,
+			Description: msgAgeAtReceiveM.Description(),
+			// 1ms to ~14 days (maximum retention period of SQS messages)
+			Aggregation: view.Distribution(metrics.Buckets125(1, 2e9)...),
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Measure:     sinkSendLatencyM,
+			Description: sinkSendLatencyM.Description(),
+			Aggregation: view.Distribution(metrics.Buckets125(1, 100000)...), // 1ms to 100s
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Measure:     sinkSendFailureCountM,
+			Description: sinkSendFailureCountM.Description(),
+			Aggregation: view.Count(),
+			TagKeys:     append(tagKeys, tagKeyResponseCode),
+		},
+		&view.View{
+			Measure:     msgDeleteFailureCountM,
+			Description: msgDeleteFailureCountM.Description(),
+			Aggregation: view.Sum(),
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Measure:     receiveErrorCountM,
+			Description: receiveErrorCountM.Description(),
+			Aggregation: view.Count(),
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Measure:     queueBacklogM,
+			Description: queueBacklogM.Description(),
+			Aggregation: view.LastValue(),
+			TagKeys:     tagKeys,
+		},
 	)
 	if err != nil {
 		panic(fmt.Errorf("error registering OpenCensus stats view: %w", err))
@@ -194,3 +287,40 @@ func (r *statsReporter) reportMessageEnqueuedDeleteCount() {
 func (r *statsReporter) reportMessageDequeuedDeleteCount() {
 	metrics.Record(r.tagsCtx, msgDequeuedDeleteCountM.M(1))
 }
+
+// reportMessageAgeAtReceive records a value for msgAgeAtReceiveM.
+func (r *statsReporter) reportMessageAgeAtReceive(age time.Duration) {
+	metrics.Record(r.tagsCtx, msgAgeAtReceiveM.M(age.Milliseconds()))
+}
+
+// reportSinkSendLatency records a value for sinkSendLatencyM.
+func (r *statsReporter) reportSinkSendLatency(latency time.Duration) {
+	metrics.Record(r.tagsCtx, sinkSendLatencyM.M(latency.Milliseconds()))
+}
+
+// reportSinkSendFailure increments sinkSendFailureCountM for the given HTTP
+// response code. A response code of 0 indicates that no response was received
+// from the sink.
+func (r *statsReporter) reportSinkSendFailure(responseCode int) {
+	ctx, err := tag.New(r.tagsCtx, tag.Insert(tagKeyResponseCode, strconv.Itoa(responseCode)))
+	if err != nil {
+		ctx = r.tagsCtx
+	}
+	metrics.Record(ctx, sinkSendFailureCountM.M(1))
+}
+
+// reportMessageDeleteFailures increases msgDeleteFailureCountM by the given
+// number of messages.
+func (r *statsReporter) reportMessageDeleteFailures(count int) {
+	metrics.Record(r.tagsCtx, msgDeleteFailureCountM.M(int64(count)))
+}
+
+// reportReceiveError increments receiveErrorCountM.
+func (r *statsReporter) reportReceiveError() {
+	metrics.Record(r.tagsCtx, receiveErrorCountM.M(1))
+}
+
+// reportQueueBacklog sets the value of queueBacklogM.
+func (r *statsReporter) reportQueueBacklog(backlog int64) {
+	metrics.Record(r.tagsCtx, queueBacklogM.M(backlog))
+}
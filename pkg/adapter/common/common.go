@@ -19,16 +19,10 @@ package common
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/arn"
-)
 
-const (
-	expectCognitoUserPoolResourceFmt = "userpool/${UserPoolId}"
-	expectCognitoIdentityResourceFmt = "identitypool/${IdentityPoolId}"
-	expectDynamoDBResourceFmt        = "table/${TableName}"
-	expectKinesisResourceFmt         = "stream/${StreamName}"
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
 )
 
 // MustParseARN parses an ARN and panics in case of error.
@@ -43,7 +37,7 @@ func MustParseARN(arnStr string) arn.ARN {
 // MustParseCognitoIdentityResource parses the resource segment of a Cognito
 // Identity Pool ARN and panics in case of error.
 func MustParseCognitoIdentityResource(resource string) string /*identityPoolId*/ {
-	elements, err := parseResource(resource, expectCognitoIdentityResourceFmt)
+	elements, err := apis.ParseResource(resource, apis.CognitoIdentityResourceFormat)
 	if err != nil {
 		panic(err)
 	}
@@ -53,7 +47,7 @@ func MustParseCognitoIdentityResource(resource string) string /*identityPoolId*/
 // MustParseDynamoDBResource parses the resource segment of a DynamoDB ARN and
 // panics in case of error.
 func MustParseDynamoDBResource(resource string) string /*table*/ {
-	elements, err := parseResource(resource, expectDynamoDBResourceFmt)
+	elements, err := apis.ParseResource(resource, apis.DynamoDBResourceFormat)
 	if err != nil {
 		panic(err)
 	}
@@ -63,7 +57,7 @@ func MustParseDynamoDBResource(resource string) string /*table*/ {
 // MustParseKinesisResource parses the resource segment of a Kinesis ARN and
 // panics in case of error.
 func MustParseKinesisResource(resource string) string /*stream*/ {
-	elements, err := parseResource(resource, expectKinesisResourceFmt)
+	elements, err := apis.ParseResource(resource, apis.KinesisResourceFormat)
 	if err != nil {
 		panic(err)
 	}
@@ -73,54 +67,9 @@ func MustParseKinesisResource(resource string) string /*stream*/ {
 // MustParseCognitoUserPoolResource parses the resource segment of a Cognito User Pool
 // ARN and panics in case of error.
 func MustParseCognitoUserPoolResource(resource string) string {
-	elements, err := parseResource(resource, expectCognitoUserPoolResourceFmt)
+	elements, err := apis.ParseResource(resource, apis.CognitoUserPoolResourceFormat)
 	if err != nil {
 		panic(err)
 	}
 	return elements[0]
 }
-
-// parseResource parses the resource segment of a ARN and panics in case of
-// error. A ARN resource should have the format "key1/val1/key2/val2/...".
-func parseResource(resource, expectFormat string) ([]string, error) {
-	expectElements := strings.Split(expectFormat, "/")
-
-	sections := strings.Split(resource, "/")
-	if len(sections) != len(expectElements) {
-		return nil, newParseResourceError(expectFormat, resource)
-	}
-
-	// exclude keys, only count values
-	elements := make([]string, 0, len(expectElements)/2)
-
-	for i, sec := range sections {
-		// assert equality of keys (even indexes), we want them to
-		// match the expected format unconditionally
-		if i%2 == 0 {
-			if sec != expectElements[i] {
-				return nil, newParseResourceError(expectFormat, resource)
-			}
-			continue
-		}
-		elements = append(elements, sec)
-	}
-
-	return elements, nil
-}
-
-type parseResourceError struct {
-	expectedFormat string
-	gotInput       string
-}
-
-func newParseResourceError(expect, got string) error {
-	return &parseResourceError{
-		expectedFormat: expect,
-		gotInput:       got,
-	}
-}
-
-// Error implements the error interface.
-func (e *parseResourceError) Error() string {
-	return fmt.Sprintf("resource segment of ARN %q does not match expected format %q", e.gotInput, e.expectedFormat)
-}
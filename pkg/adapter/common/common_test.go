@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
 )
 
 func TestMustParseARN(t *testing.T) {
@@ -55,71 +57,6 @@ func TestMustParseARN(t *testing.T) {
 	}
 }
 
-func TestMustParseResource(t *testing.T) {
-	testCases := map[string]struct {
-		input     string
-		fmt       string
-		expectErr bool
-		expect    []string
-	}{
-		"input matches format, single element": {
-			input:  "key/some-value",
-			fmt:    "key/val",
-			expect: []string{"some-value"},
-		},
-		"input matches format, multiple elements": {
-			input:  "key1/some-value/key2/some-other-value",
-			fmt:    "key1/val/key2/val",
-			expect: []string{"some-value", "some-other-value"},
-		},
-		"only keys matter in format": {
-			input:  "key1/some-value/key2/some-other-value",
-			fmt:    "key1//key2/",
-			expect: []string{"some-value", "some-other-value"},
-		},
-		"odd number of elements yields values only": {
-			input:  "key1/some-value/key2/some-other-value/key3",
-			fmt:    "key1/val/key2/val/key3",
-			expect: []string{"some-value", "some-other-value"},
-		},
-		"empty input": {
-			input:     "",
-			fmt:       "key/val",
-			expectErr: true,
-		},
-		"more elements than format expects": {
-			input:     "key1/some-value/key2",
-			fmt:       "key1/val",
-			expectErr: true,
-		},
-		"empty format": {
-			input:     "key1/some-value/key2",
-			fmt:       "",
-			expectErr: true,
-		},
-		"non-matching key": {
-			input:     "some-key/some-value",
-			fmt:       "key/val",
-			expectErr: true,
-		},
-	}
-
-	for name, tc := range testCases {
-		//nolint:scopelint
-		t.Run(name, func(t *testing.T) {
-			out, err := parseResource(tc.input, tc.fmt)
-
-			assert.Equal(t, tc.expect, out)
-
-			if tc.expectErr {
-				assert.EqualError(t, err, newParseResourceError(tc.fmt, tc.input).Error())
-			} else {
-				assert.NoError(t, err)
-			}
-		})
-	}
-}
-
 func TestMustParseCognitoIdentityResource(t *testing.T) {
 	testCases := map[string]struct {
 		input       string
@@ -146,7 +83,7 @@ func TestMustParseCognitoIdentityResource(t *testing.T) {
 
 			if tc.expectPanic {
 				assert.PanicsWithError(t,
-					newParseResourceError(expectCognitoIdentityResourceFmt, tc.input).Error(), testFn)
+					parseResourceErrMsg(apis.CognitoIdentityResourceFormat, tc.input), testFn)
 			} else {
 				assert.NotPanics(t, testFn)
 			}
@@ -182,7 +119,7 @@ func TestMustParseCognitoUserPoolResource(t *testing.T) {
 
 			if tc.expectPanic {
 				assert.PanicsWithError(t,
-					newParseResourceError(expectCognitoUserPoolResourceFmt, tc.input).Error(), testFn)
+					parseResourceErrMsg(apis.CognitoUserPoolResourceFormat, tc.input), testFn)
 			} else {
 				assert.NotPanics(t, testFn)
 			}
@@ -218,7 +155,7 @@ func TestMustParseDynamoDBResource(t *testing.T) {
 
 			if tc.expectPanic {
 				assert.PanicsWithError(t,
-					newParseResourceError(expectDynamoDBResourceFmt, tc.input).Error(), testFn)
+					parseResourceErrMsg(apis.DynamoDBResourceFormat, tc.input), testFn)
 			} else {
 				assert.NotPanics(t, testFn)
 			}
@@ -254,7 +191,7 @@ func TestMustParseKinesisResource(t *testing.T) {
 
 			if tc.expectPanic {
 				assert.PanicsWithError(t,
-					newParseResourceError(expectKinesisResourceFmt, tc.input).Error(), testFn)
+					parseResourceErrMsg(apis.KinesisResourceFormat, tc.input), testFn)
 			} else {
 				assert.NotPanics(t, testFn)
 			}
@@ -263,3 +200,10 @@ func TestMustParseKinesisResource(t *testing.T) {
 		})
 	}
 }
+
+// parseResourceErrMsg returns the message of the error returned when parsing
+// the given ARN resource with the given format.
+func parseResourceErrMsg(format, resource string) string {
+	_, err := apis.ParseResource(resource, format)
+	return err.Error()
+}
@@ -0,0 +1,54 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// AssumeRoleEnvConfig is a set of parameters sourced from the environment
+// which describe an IAM role to assume on top of the adapter's credentials.
+//
+// The credentials themselves (access key, web identity, or ambient
+// credentials) are resolved by the default credential chain of the AWS SDK.
+type AssumeRoleEnvConfig struct {
+	AssumeRoleARN         string `envconfig:"AWS_ASSUME_ROLE_ARN"`
+	AssumeRoleExternalID  string `envconfig:"AWS_ASSUME_ROLE_EXTERNAL_ID"`
+	AssumeRoleSessionName string `envconfig:"AWS_ASSUME_ROLE_SESSION_NAME"`
+}
+
+// WithAssumedRole returns a copy of the given session which authenticates as
+// the IAM role described in the given config. The session is returned as is
+// if the config doesn't describe any role.
+func WithAssumedRole(sess *session.Session, cfg AssumeRoleEnvConfig) *session.Session {
+	if cfg.AssumeRoleARN == "" {
+		return sess
+	}
+
+	return sess.Copy(aws.NewConfig().WithCredentials(
+		stscreds.NewCredentials(sess, cfg.AssumeRoleARN, func(p *stscreds.AssumeRoleProvider) {
+			if cfg.AssumeRoleExternalID != "" {
+				p.ExternalID = &cfg.AssumeRoleExternalID
+			}
+			if cfg.AssumeRoleSessionName != "" {
+				p.RoleSessionName = cfg.AssumeRoleSessionName
+			}
+		}),
+	))
+}
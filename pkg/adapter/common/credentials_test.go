@@ -0,0 +1,53 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestWithAssumedRole(t *testing.T) {
+	staticCreds := credentials.NewStaticCredentials("key", "secret", "")
+
+	sess, err := session.NewSession(aws.NewConfig().
+		WithRegion("us-west-2").
+		WithCredentials(staticCreds),
+	)
+	require.NoError(t, err)
+
+	t.Run("no role", func(t *testing.T) {
+		s := WithAssumedRole(sess, AssumeRoleEnvConfig{})
+		assert.Same(t, sess, s)
+	})
+
+	t.Run("with role", func(t *testing.T) {
+		s := WithAssumedRole(sess, AssumeRoleEnvConfig{
+			AssumeRoleARN:        "arn:aws:iam::123456789012:role/my-role",
+			AssumeRoleExternalID: "my-external-id",
+		})
+		assert.NotSame(t, sess, s)
+		assert.NotSame(t, staticCreds, s.Config.Credentials)
+		assert.Equal(t, "us-west-2", *s.Config.Region)
+	})
+}
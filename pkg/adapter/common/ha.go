@@ -0,0 +1,188 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	coordinationclientv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	pkgadapter "knative.dev/eventing/pkg/adapter/v2"
+	"knative.dev/pkg/injection"
+)
+
+// HAEnvConfig is a set of parameters sourced from the environment which
+// configure the coordination of multiple replicas of an adapter.
+type HAEnvConfig struct {
+	// Name of the Lease through which replicas coordinate. High
+	// availability is disabled when empty.
+	HALease string `envconfig:"HA_LEASE"`
+	// Duration after which a replica which stopped renewing its Leases is
+	// considered gone, and its work is taken over by another replica.
+	HALeaseDuration time.Duration `envconfig:"HA_LEASE_DURATION" default:"15s"`
+}
+
+// errLeadershipLost is returned by an adapter which lost the leadership while
+// it was running.
+var errLeadershipLost = errors.New("lost the leadership of the adapter's replicas")
+
+// leaderElectedAdapter is an adapter which runs only in the replica elected as
+// leader.
+type leaderElectedAdapter struct {
+	pkgadapter.Adapter
+
+	logger   *zap.SugaredLogger
+	cli      coordinationclientv1.LeasesGetter
+	env      HAEnvConfig
+	ns       string
+	identity string
+}
+
+// WithLeaderElection wraps the given adapter so that, among all of its
+// replicas, it starts only in the replica which holds the Lease referenced in
+// the given configuration. The adapter is returned as is if high availability
+// is disabled.
+//
+// It panics if no valid Kubernetes client configuration can be found.
+func WithLeaderElection(a pkgadapter.Adapter, env HAEnvConfig, namespace string,
+	logger *zap.SugaredLogger) pkgadapter.Adapter {
+
+	if env.HALease == "" {
+		return a
+	}
+
+	return &leaderElectedAdapter{
+		Adapter:  a,
+		logger:   logger,
+		cli:      mustNewKubeClient().CoordinationV1(),
+		env:      env,
+		ns:       namespace,
+		identity: mustGetIdentity(),
+	}
+}
+
+// Start implements adapter.Adapter.
+//
+// The wrapped adapter is stopped and an error is returned when the replica
+// loses the Lease, so that the replica restarts in a clean state and becomes a
+// standby.
+func (a *leaderElectedAdapter) Start(ctx context.Context) error {
+	type result struct {
+		err  error
+		lost bool
+	}
+
+	started := make(chan struct{})
+	done := make(chan result, 1)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Namespace: a.ns,
+				Name:      a.env.HALease,
+			},
+			Client: a.cli,
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: a.identity,
+			},
+		},
+		LeaseDuration:   a.env.HALeaseDuration,
+		RenewDeadline:   a.env.HALeaseDuration * 2 / 3,
+		RetryPeriod:     a.env.HALeaseDuration / 5,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				close(started)
+				a.logger.Info("Acquired Lease ", a.env.HALease, ", starting adapter")
+
+				err := a.Adapter.Start(ctx)
+				done <- result{err: err, lost: ctx.Err() != nil}
+
+				// stop renewing the Lease if the adapter
+				// returned on its own
+				cancel()
+			},
+			OnStoppedLeading: func() {
+				a.logger.Info("Released Lease ", a.env.HALease)
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating leader elector: %w", err)
+	}
+
+	a.logger.Info("Waiting to acquire Lease ", a.env.HALease, " as ", a.identity)
+
+	le.Run(runCtx)
+
+	select {
+	case <-started:
+	default:
+		// the context was cancelled before the Lease was acquired
+		return nil
+	}
+
+	res := <-done
+
+	switch {
+	case res.err != nil:
+		return res.err
+	case ctx.Err() != nil:
+		return nil
+	case res.lost:
+		return errLeadershipLost
+	}
+
+	return nil
+}
+
+// mustNewKubeClient returns a Kubernetes client which uses the in-cluster
+// configuration or the configuration file referenced by the KUBECONFIG
+// environment variable. It panics if no valid configuration can be found.
+func mustNewKubeClient() kubernetes.Interface {
+	cfg, err := injection.GetRESTConfig("", os.Getenv("KUBECONFIG"))
+	if err != nil {
+		panic(fmt.Errorf("getting Kubernetes client configuration: %w", err))
+	}
+
+	return kubernetes.NewForConfigOrDie(cfg)
+}
+
+// mustGetIdentity returns the identity of the current replica in Leases. It
+// panics if this identity can not be determined.
+func mustGetIdentity() string {
+	// the host name of a Pod is its name
+	id, err := os.Hostname()
+	if err != nil {
+		panic(fmt.Errorf("getting host name: %w", err))
+	}
+
+	return id
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	loggingtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestWithLeaderElectionDisabled(t *testing.T) {
+	a := &testAdapter{}
+	assert.Same(t, a, WithLeaderElection(a, HAEnvConfig{}, "test-ns", loggingtesting.TestLogger(t)))
+}
+
+func TestLeaderElectedAdapter(t *testing.T) {
+	const ns = "test-ns"
+	const lease = "test-lease"
+
+	errAdapter := errors.New("adapter error")
+
+	cli := fake.NewSimpleClientset()
+
+	inner := &testAdapter{
+		started: make(chan struct{}),
+		stop:    make(chan struct{}),
+		err:     errAdapter,
+	}
+
+	a := &leaderElectedAdapter{
+		Adapter:  inner,
+		logger:   loggingtesting.TestLogger(t),
+		cli:      cli.CoordinationV1(),
+		env:      HAEnvConfig{HALease: lease, HALeaseDuration: time.Second},
+		ns:       ns,
+		identity: "replica-1",
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- a.Start(context.Background())
+	}()
+
+	select {
+	case <-inner.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for adapter to start")
+	}
+
+	l, err := cli.CoordinationV1().Leases(ns).Get(context.Background(), lease, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "replica-1", *l.Spec.HolderIdentity)
+
+	close(inner.stop)
+
+	select {
+	case err := <-errCh:
+		assert.Equal(t, errAdapter, err, "Expected error of the adapter to be returned")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for Start to return")
+	}
+}
+
+// testAdapter is an adapter which runs until it is stopped.
+type testAdapter struct {
+	started chan struct{}
+	stop    chan struct{}
+	err     error
+}
+
+func (a *testAdapter) Start(ctx context.Context) error {
+	close(a.started)
+
+	select {
+	case <-ctx.Done():
+	case <-a.stop:
+	}
+
+	return a.err
+}
@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// RedactionEnvConfig is a set of parameters sourced from the environment
+// which describe a redaction policy. It is meant to be embedded inside the
+// envConfig of adapters.
+type RedactionEnvConfig struct {
+	// Names of attributes to drop
+	RedactDrop []string `envconfig:"REDACT_DROP"`
+	// Names of attributes to hash
+	RedactHash []string `envconfig:"REDACT_HASH"`
+	// Key of the HMAC used to hash attributes
+	RedactHashKey string `envconfig:"REDACT_HASH_KEY"`
+	// Names of attributes to mask
+	RedactMask []string `envconfig:"REDACT_MASK"`
+}
+
+// Number of trailing characters which remain visible in masked values, and
+// minimum length of a value for these characters to remain visible.
+const (
+	maskVisibleSuffixLen = 4
+	maskMinPartialLen    = 2 * maskVisibleSuffixLen
+)
+
+// maskChar is the character which replaces masked characters.
+const maskChar = '*'
+
+// Redactor redacts the values of named attributes, such as the attributes of
+// a Cognito user. Attribute names are matched against shell patterns (e.g.
+// "custom:*"). When an attribute matches multiple patterns, dropping takes
+// precedence over hashing, which takes precedence over masking.
+//
+// A nil *Redactor leaves all attributes untouched.
+type Redactor struct {
+	drop    []string
+	hash    []string
+	hashKey []byte
+	mask    []string
+}
+
+// NewRedactor returns a Redactor which applies the redaction policy described
+// by the given environment config, or nil if the policy is empty.
+func NewRedactor(env *RedactionEnvConfig) (*Redactor, error) {
+	if len(env.RedactDrop) == 0 && len(env.RedactHash) == 0 && len(env.RedactMask) == 0 {
+		return nil, nil
+	}
+
+	if len(env.RedactHash) > 0 && env.RedactHashKey == "" {
+		return nil, errors.New("a key is required to hash attributes")
+	}
+
+	for _, patterns := range [][]string{env.RedactDrop, env.RedactHash, env.RedactMask} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid attribute name pattern %q: %w", p, err)
+			}
+		}
+	}
+
+	return &Redactor{
+		drop:    env.RedactDrop,
+		hash:    env.RedactHash,
+		hashKey: []byte(env.RedactHashKey),
+		mask:    env.RedactMask,
+	}, nil
+}
+
+// Redact returns the redacted value of the attribute with the given name. The
+// returned boolean is false if the attribute should be dropped.
+func (r *Redactor) Redact(name, value string) (string, bool /*keep*/) {
+	if r == nil {
+		return value, true
+	}
+
+	switch {
+	case matchesAny(r.drop, name):
+		return "", false
+	case matchesAny(r.hash, name):
+		return r.hashValue(value), true
+	case matchesAny(r.mask, name):
+		return maskValue(value), true
+	}
+
+	return value, true
+}
+
+// hashValue returns the hex-encoded HMAC-SHA256 of the given value. Equal
+// values yield equal hashes, which allows consumers to correlate events
+// without knowing the original values.
+func (r *Redactor) hashValue(value string) string {
+	mac := hmac.New(sha256.New, r.hashKey)
+	_, _ = mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// maskValue replaces all characters of the given value with a mask
+// character. The last few characters of long enough values remain visible.
+func maskValue(value string) string {
+	runes := []rune(value)
+
+	visible := 0
+	if len(runes) >= maskMinPartialLen {
+		visible = maskVisibleSuffixLen
+	}
+
+	var b strings.Builder
+	b.Grow(len(value))
+
+	for i, r := range runes {
+		if i < len(runes)-visible {
+			r = maskChar
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// matchesAny returns whether the given name matches any of the given shell
+// patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRedactor(t *testing.T) {
+	r, err := NewRedactor(&RedactionEnvConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, r, "Expected no Redactor for an empty policy")
+
+	_, err = NewRedactor(&RedactionEnvConfig{RedactHash: []string{"email"}})
+	assert.Error(t, err, "Expected an error for a missing hash key")
+
+	_, err = NewRedactor(&RedactionEnvConfig{RedactMask: []string{"custom:["}})
+	assert.Error(t, err, "Expected an error for an invalid pattern")
+}
+
+func TestRedact(t *testing.T) {
+	r, err := NewRedactor(&RedactionEnvConfig{
+		RedactDrop:    []string{"custom:*", "address"},
+		RedactHash:    []string{"email", "address"},
+		RedactHashKey: "s3cr3t",
+		RedactMask:    []string{"phone_number", "name"},
+	})
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		name, value string
+		expectValue string
+		expectKeep  bool
+	}{
+		"dropped by pattern": {
+			name:       "custom:ssn",
+			value:      "123-45-6789",
+			expectKeep: false,
+		},
+		"drop takes precedence": {
+			name:       "address",
+			value:      "1 Main St",
+			expectKeep: false,
+		},
+		"hashed": {
+			name:  "email",
+			value: "jane@example.com",
+			// echo -n jane@example.com | openssl dgst -sha256 -hmac s3cr3t
+			expectValue: "0cb9dc046165e8bd6e78f4d24534a85afa06dc41870b333cf8206396a7bea1fb",
+			expectKeep:  true,
+		},
+		"masked with visible suffix": {
+			name:        "phone_number",
+			value:       "+15555550100",
+			expectValue: "********0100",
+			expectKeep:  true,
+		},
+		"masked entirely": {
+			name:        "name",
+			value:       "Jane",
+			expectValue: "****",
+			expectKeep:  true,
+		},
+		"untouched": {
+			name:        "locale",
+			value:       "en-US",
+			expectValue: "en-US",
+			expectKeep:  true,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			value, keep := r.Redact(tc.name, tc.value)
+			assert.Equal(t, tc.expectKeep, keep)
+			if tc.expectKeep {
+				assert.Equal(t, tc.expectValue, value)
+			}
+		})
+	}
+}
+
+func TestRedactNil(t *testing.T) {
+	var r *Redactor
+
+	value, keep := r.Redact("email", "jane@example.com")
+	assert.True(t, keep)
+	assert.Equal(t, "jane@example.com", value)
+}
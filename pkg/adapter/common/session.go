@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/triggermesh/aws-event-sources/pkg/awsendpoint"
+)
+
+// SessionEnvConfig is a set of parameters sourced from the environment which
+// configure the AWS sessions of adapters.
+type SessionEnvConfig struct {
+	AssumeRoleEnvConfig
+	EndpointEnvConfig
+}
+
+// EndpointEnvConfig is a set of parameters sourced from the environment
+// which describe custom endpoints of AWS APIs.
+type EndpointEnvConfig struct {
+	EndpointURLs EndpointURLs `envconfig:"AWS_ENDPOINT_URLS"`
+	// PEM-encoded CA certificates
+	EndpointCABundle  string `envconfig:"AWS_ENDPOINT_CA_BUNDLE"`
+	EndpointPathStyle bool   `envconfig:"AWS_ENDPOINT_PATH_STYLE"`
+}
+
+// EndpointURLs contains the URLs of custom endpoints of AWS APIs, keyed by
+// the ID of their service endpoint (e.g. "streams.dynamodb").
+type EndpointURLs map[string]string
+
+// Decode implements envconfig.Decoder.
+//
+// URLs are encoded as a JSON object, because they contain characters which
+// envconfig uses as separators in map values.
+func (u *EndpointURLs) Decode(value string) error {
+	return json.Unmarshal([]byte(value), (*map[string]string)(u))
+}
+
+// MustNewSession returns an AWS session created from the given config and
+// the parameters sourced from the environment, and panics in case of error.
+func MustNewSession(env SessionEnvConfig, cfg *aws.Config) *session.Session {
+	sess := WithAssumedRole(session.Must(session.NewSession(cfg)), env.AssumeRoleEnvConfig)
+
+	return session.Must(awsendpoint.WithEndpoints(sess, awsendpoint.Config{
+		URLs:      env.EndpointURLs,
+		CABundle:  env.EndpointCABundle,
+		PathStyle: env.EndpointPathStyle,
+	}))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+func TestMustNewSession(t *testing.T) {
+	staticCreds := credentials.NewStaticCredentials("key", "secret", "")
+
+	newConfig := func() *aws.Config {
+		return aws.NewConfig().
+			WithRegion("us-west-2").
+			WithCredentials(staticCreds)
+	}
+
+	t.Run("default endpoints", func(t *testing.T) {
+		sess := MustNewSession(SessionEnvConfig{}, newConfig())
+
+		assert.Equal(t, "https://sqs.us-west-2.amazonaws.com", sqs.New(sess).Endpoint)
+		assert.Same(t, http.DefaultClient, sess.Config.HTTPClient)
+	})
+
+	t.Run("custom endpoints", func(t *testing.T) {
+		sess := MustNewSession(SessionEnvConfig{
+			EndpointEnvConfig: EndpointEnvConfig{
+				EndpointURLs: EndpointURLs{
+					"dynamodb":         "http://localhost:4566",
+					"streams.dynamodb": "http://localhost:4570",
+				},
+				EndpointPathStyle: true,
+			},
+		}, newConfig())
+
+		assert.Nil(t, sess.Config.Endpoint)
+		assert.True(t, aws.BoolValue(sess.Config.S3ForcePathStyle))
+		assert.Same(t, staticCreds, sess.Config.Credentials)
+		assert.Same(t, http.DefaultClient, sess.Config.HTTPClient)
+
+		assert.Equal(t, "http://localhost:4566", dynamodb.New(sess).Endpoint)
+		assert.Equal(t, "http://localhost:4570", dynamodbstreams.New(sess).Endpoint)
+		assert.Equal(t, "https://sqs.us-west-2.amazonaws.com", sqs.New(sess).Endpoint)
+	})
+
+	t.Run("invalid CA bundle", func(t *testing.T) {
+		assert.Panics(t, func() {
+			_ = MustNewSession(SessionEnvConfig{
+				EndpointEnvConfig: EndpointEnvConfig{
+					EndpointURLs:     EndpointURLs{"sqs": "https://localhost:4566"},
+					EndpointCABundle: "not a certificate",
+				},
+			}, newConfig())
+		})
+	})
+}
+
+func TestEndpointURLsDecode(t *testing.T) {
+	var urls EndpointURLs
+
+	err := urls.Decode(`{"dynamodb":"https://vpce-1.dynamodb.us-west-2.vpce.amazonaws.com",` +
+		`"streams.dynamodb":"https://streams.dynamodb-fips.us-west-2.amazonaws.com"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, EndpointURLs{
+		"dynamodb":         "https://vpce-1.dynamodb.us-west-2.vpce.amazonaws.com",
+		"streams.dynamodb": "https://streams.dynamodb-fips.us-west-2.amazonaws.com",
+	}, urls)
+
+	assert.Error(t, urls.Decode("dynamodb:https://localhost:4566"))
+}
@@ -0,0 +1,646 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	coordinationclientv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+
+	"knative.dev/pkg/kmeta"
+)
+
+// ShardOwnership distributes the shards of a stream among the replicas of an
+// adapter, so that each shard is consumed by exactly one replica at a time.
+type ShardOwnership interface {
+	// Run maintains the ownership of shards until the given context is
+	// cancelled.
+	Run(ctx context.Context)
+	// SetShards sets the IDs of all the shards of the stream.
+	SetShards(ids []string)
+	// Owned returns the shards owned by the current replica, mapped to
+	// their last checkpointed sequence number, if any.
+	Owned() map[string]string /*shard ID -> sequence number*/
+	// Checkpoint records the sequence number of the last record processed
+	// from the given shard, so that another replica can resume after this
+	// record if it takes over the shard.
+	Checkpoint(shardID, seq string)
+	// Changed returns a channel which receives a value whenever the set of
+	// owned shards changes.
+	Changed() <-chan struct{}
+}
+
+// NewShardOwnership returns a ShardOwnership which coordinates the replicas
+// of an adapter through the Kubernetes Leases referenced in the given
+// configuration, or which owns all shards if high availability is disabled.
+//
+// It panics if no valid Kubernetes client configuration can be found.
+func NewShardOwnership(env HAEnvConfig, namespace string, logger *zap.SugaredLogger) ShardOwnership {
+	if env.HALease == "" {
+		return AllShards()
+	}
+
+	return newLeaseShardOwnership(mustNewKubeClient().CoordinationV1().Leases(namespace),
+		env.HALease, mustGetIdentity(), env.HALeaseDuration, clock.RealClock{}, logger)
+}
+
+// allShards is a ShardOwnership which owns all shards.
+type allShards struct {
+	mu     sync.RWMutex
+	shards []string
+}
+
+var _ ShardOwnership = (*allShards)(nil)
+
+// AllShards returns a ShardOwnership which owns all shards, for adapters which
+// run as a single replica.
+func AllShards() ShardOwnership {
+	return &allShards{}
+}
+
+// Run implements ShardOwnership.
+func (*allShards) Run(context.Context) {}
+
+// SetShards implements ShardOwnership.
+func (o *allShards) SetShards(ids []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.shards = ids
+}
+
+// Owned implements ShardOwnership.
+func (o *allShards) Owned() map[string]string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	owned := make(map[string]string, len(o.shards))
+	for _, id := range o.shards {
+		owned[id] = ""
+	}
+
+	return owned
+}
+
+// Checkpoint implements ShardOwnership.
+func (*allShards) Checkpoint(string, string) {}
+
+// Changed implements ShardOwnership.
+func (*allShards) Changed() <-chan struct{} {
+	// the set of owned shards only changes when SetShards is called
+	return nil
+}
+
+// Labels and annotations of the Leases managed by leaseShardOwnership.
+const (
+	haGroupLabel = "sources.triggermesh.io/ha-group"
+	haRoleLabel  = "sources.triggermesh.io/ha-role"
+
+	haRoleMember = "member"
+	haRoleShard  = "shard"
+
+	shardIDAnnotation    = "sources.triggermesh.io/shard-id"
+	checkpointAnnotation = "sources.triggermesh.io/checkpoint"
+)
+
+// leaseShardOwnership is a ShardOwnership which uses Kubernetes Leases to
+// distribute shards evenly among replicas.
+//
+// Each replica holds a membership Lease, which allows replicas to count each
+// other, and one Lease per owned shard. Replicas which own more shards than
+// their share release them one at a time, and replicas which own less acquire
+// free or expired ones, until the ownership is balanced.
+type leaseShardOwnership struct {
+	logger *zap.SugaredLogger
+
+	cli      coordinationclientv1.LeaseInterface
+	clock    clock.Clock
+	group    string
+	identity string
+	duration time.Duration
+
+	mu          sync.Mutex
+	shards      []string
+	owned       map[string]time.Time /*shard ID -> last renewal*/
+	checkpoints map[string]string    /*shard ID -> sequence number*/
+
+	changed chan struct{}
+}
+
+var _ ShardOwnership = (*leaseShardOwnership)(nil)
+
+// newLeaseShardOwnership returns a leaseShardOwnership which manages Leases
+// in the given group, on behalf of the replica with the given identity.
+func newLeaseShardOwnership(cli coordinationclientv1.LeaseInterface, group, identity string,
+	duration time.Duration, clk clock.Clock, logger *zap.SugaredLogger) *leaseShardOwnership {
+
+	return &leaseShardOwnership{
+		logger:      logger,
+		cli:         cli,
+		clock:       clk,
+		group:       group,
+		identity:    identity,
+		duration:    duration,
+		owned:       make(map[string]time.Time),
+		checkpoints: make(map[string]string),
+		changed:     make(chan struct{}, 1),
+	}
+}
+
+// Run implements ShardOwnership.
+//
+// Leases are synchronized three times per Lease duration. All owned Leases are
+// released when the context is cancelled, so that other replicas can take
+// over without waiting for these Leases to expire.
+func (o *leaseShardOwnership) Run(ctx context.Context) {
+	for {
+		if err := o.sync(ctx); err != nil {
+			o.logger.Errorw("Failed to synchronize shard Leases", zap.Error(err))
+		}
+
+		// a failed synchronization leaves the set of owned shards
+		// untouched, so shards which can no longer be renewed must be
+		// dropped separately
+		o.dropStaleShards()
+
+		select {
+		case <-ctx.Done():
+			o.release()
+			return
+		case <-o.clock.After(o.duration / 3):
+		}
+	}
+}
+
+// SetShards implements ShardOwnership.
+func (o *leaseShardOwnership) SetShards(ids []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.shards = append(o.shards[:0], ids...)
+	sort.Strings(o.shards)
+}
+
+// Owned implements ShardOwnership.
+func (o *leaseShardOwnership) Owned() map[string]string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	owned := make(map[string]string, len(o.owned))
+	for id := range o.owned {
+		owned[id] = o.checkpoints[id]
+	}
+
+	return owned
+}
+
+// Checkpoint implements ShardOwnership.
+func (o *leaseShardOwnership) Checkpoint(shardID, seq string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, isOwned := o.owned[shardID]; isOwned {
+		o.checkpoints[shardID] = seq
+	}
+}
+
+// Changed implements ShardOwnership.
+func (o *leaseShardOwnership) Changed() <-chan struct{} {
+	return o.changed
+}
+
+// sync renews the Leases of the current replica and rebalances the ownership
+// of shards.
+func (o *leaseShardOwnership) sync(ctx context.Context) error {
+	now := o.clock.Now()
+
+	if err := o.renewMembership(ctx, now); err != nil {
+		return err
+	}
+
+	leases, err := o.cli.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{haGroupLabel: o.group}).String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	members := 0
+	shardLeases := make(map[string]*coordinationv1.Lease)
+
+	for i := range leases.Items {
+		l := &leases.Items[i]
+
+		switch l.Labels[haRoleLabel] {
+		case haRoleMember:
+			switch {
+			case !o.expired(l, now):
+				members++
+			case o.expiredFor(l, now) > 10*o.duration:
+				// replica is long gone
+				if err := o.cli.Delete(ctx, l.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+					o.logger.Warnw("Failed to delete membership Lease "+l.Name, zap.Error(err))
+				}
+			}
+		case haRoleShard:
+			shardLeases[l.Annotations[shardIDAnnotation]] = l
+		}
+	}
+
+	if members == 0 {
+		// our own membership Lease can't be observed yet
+		members = 1
+	}
+
+	o.mu.Lock()
+	shards := append([]string(nil), o.shards...)
+	checkpoints := make(map[string]string, len(o.checkpoints))
+	for id, seq := range o.checkpoints {
+		checkpoints[id] = seq
+	}
+	prevOwned := make(map[string]time.Time, len(o.owned))
+	for id, t := range o.owned {
+		prevOwned[id] = t
+	}
+	o.mu.Unlock()
+
+	if len(shards) > 0 {
+		o.collectShardLeases(ctx, shards, shardLeases, now)
+	}
+
+	target := (len(shards) + members - 1) / members
+
+	owned := make(map[string]time.Time, target)
+
+	// renew owned shards
+	for _, id := range shards {
+		l := shardLeases[id]
+		if l == nil || !o.isHolder(l) {
+			continue
+		}
+
+		o.setShardLease(l, o.identity, checkpoints[id], now, false)
+		if _, err := o.cli.Update(ctx, l, metav1.UpdateOptions{}); err != nil {
+			if renewed, isOwned := prevOwned[id]; isOwned && !apierrors.IsConflict(err) &&
+				now.Sub(renewed) < o.renewDeadline() {
+
+				owned[id] = renewed
+			}
+			o.logger.Warnw("Failed to renew Lease of shard "+id, zap.Error(err))
+			continue
+		}
+
+		owned[id] = now
+	}
+
+	// release one excess shard per round, so that ownership converges
+	// without oscillating
+	if len(owned) > target {
+		id := lastKey(owned)
+
+		l := shardLeases[id]
+		o.setShardLease(l, "", checkpoints[id], now, false)
+		if _, err := o.cli.Update(ctx, l, metav1.UpdateOptions{}); err != nil {
+			o.logger.Warnw("Failed to release Lease of shard "+id, zap.Error(err))
+		} else {
+			o.logger.Info("Released shard ", id)
+			delete(owned, id)
+		}
+	}
+
+	// acquire free or expired shards
+	for _, id := range shards {
+		if _, isOwned := owned[id]; isOwned {
+			continue
+		}
+
+		l := shardLeases[id]
+
+		// shards which nobody acquired for a long time are
+		// acquired regardless of the target, in case replicas don't
+		// agree on the number of members
+		neglected := l != nil && o.expiredFor(l, now) > 2*o.duration
+		if len(owned) >= target && !neglected {
+			continue
+		}
+
+		switch {
+		case l == nil:
+			l = &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: o.shardLeaseName(id),
+					Labels: map[string]string{
+						haGroupLabel: o.group,
+						haRoleLabel:  haRoleShard,
+					},
+					Annotations: map[string]string{
+						shardIDAnnotation: id,
+					},
+				},
+			}
+			o.setShardLease(l, o.identity, "", now, true)
+
+			if _, err := o.cli.Create(ctx, l, metav1.CreateOptions{}); err != nil {
+				if !apierrors.IsAlreadyExists(err) {
+					o.logger.Warnw("Failed to create Lease of shard "+id, zap.Error(err))
+				}
+				continue
+			}
+
+		case o.expired(l, now):
+			o.setShardLease(l, o.identity, l.Annotations[checkpointAnnotation], now, true)
+
+			// a conflict means that another replica acquired the
+			// Lease first
+			if _, err := o.cli.Update(ctx, l, metav1.UpdateOptions{}); err != nil {
+				if !apierrors.IsConflict(err) {
+					o.logger.Warnw("Failed to acquire Lease of shard "+id, zap.Error(err))
+				}
+				continue
+			}
+
+		default:
+			continue
+		}
+
+		o.logger.Info("Acquired shard ", id)
+		owned[id] = now
+		if seq := l.Annotations[checkpointAnnotation]; seq != "" {
+			checkpoints[id] = seq
+		}
+	}
+
+	o.mu.Lock()
+	changed := !sameKeys(owned, o.owned)
+	o.owned = owned
+	for id := range o.checkpoints {
+		if _, isOwned := owned[id]; !isOwned {
+			delete(o.checkpoints, id)
+		}
+	}
+	for id := range owned {
+		// local checkpoints may have advanced during the sync
+		if _, isSet := o.checkpoints[id]; !isSet && checkpoints[id] != "" {
+			o.checkpoints[id] = checkpoints[id]
+		}
+	}
+	o.mu.Unlock()
+
+	if changed {
+		o.notifyChanged()
+	}
+
+	return nil
+}
+
+// dropStaleShards stops considering owned the shards which Leases weren't
+// renewed within the renew deadline, e.g. because the Kubernetes API is
+// unreachable, so that their processing stops before other replicas acquire
+// them.
+func (o *leaseShardOwnership) dropStaleShards() {
+	now := o.clock.Now()
+
+	o.mu.Lock()
+	changed := false
+	for id, renewed := range o.owned {
+		if now.Sub(renewed) < o.renewDeadline() {
+			continue
+		}
+
+		o.logger.Warn("Lost ownership of shard ", id)
+		delete(o.owned, id)
+		delete(o.checkpoints, id)
+		changed = true
+	}
+	o.mu.Unlock()
+
+	if changed {
+		o.notifyChanged()
+	}
+}
+
+// notifyChanged signals a change of the set of owned shards, unless a signal
+// is already pending.
+func (o *leaseShardOwnership) notifyChanged() {
+	select {
+	case o.changed <- struct{}{}:
+	default:
+	}
+}
+
+// collectShardLeases deletes the Leases of shards which no longer exist and
+// have not been held for a long time, such as shards of DynamoDB streams,
+// which get rotated every few hours.
+func (o *leaseShardOwnership) collectShardLeases(ctx context.Context, shards []string,
+	shardLeases map[string]*coordinationv1.Lease, now time.Time) {
+
+	exist := make(map[string]struct{}, len(shards))
+	for _, id := range shards {
+		exist[id] = struct{}{}
+	}
+
+	for id, l := range shardLeases {
+		if _, exists := exist[id]; exists || o.expiredFor(l, now) <= 10*o.duration {
+			continue
+		}
+
+		if err := o.cli.Delete(ctx, l.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			o.logger.Warnw("Failed to delete Lease of shard "+id, zap.Error(err))
+		}
+		delete(shardLeases, id)
+	}
+}
+
+// renewMembership creates or renews the membership Lease of the current
+// replica.
+func (o *leaseShardOwnership) renewMembership(ctx context.Context, now time.Time) error {
+	name := o.memberLeaseName()
+
+	l, err := o.cli.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		l = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					haGroupLabel: o.group,
+					haRoleLabel:  haRoleMember,
+				},
+			},
+		}
+		setLease(l, o.identity, o.duration, now, true)
+
+		_, err = o.cli.Create(ctx, l, metav1.CreateOptions{})
+
+	case err == nil:
+		setLease(l, o.identity, o.duration, now, false)
+
+		_, err = o.cli.Update(ctx, l, metav1.UpdateOptions{})
+	}
+
+	return err
+}
+
+// release releases all the Leases held by the current replica.
+func (o *leaseShardOwnership) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), o.duration)
+	defer cancel()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.clock.Now()
+
+	for id := range o.owned {
+		l, err := o.cli.Get(ctx, o.shardLeaseName(id), metav1.GetOptions{})
+		if err != nil || !o.isHolder(l) {
+			continue
+		}
+
+		o.setShardLease(l, "", o.checkpoints[id], now, false)
+		if _, err := o.cli.Update(ctx, l, metav1.UpdateOptions{}); err != nil {
+			o.logger.Warnw("Failed to release Lease of shard "+id, zap.Error(err))
+		}
+	}
+	o.owned = make(map[string]time.Time)
+
+	err := o.cli.Delete(ctx, o.memberLeaseName(), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		o.logger.Warnw("Failed to delete membership Lease", zap.Error(err))
+	}
+}
+
+// renewDeadline returns the duration after which a shard which couldn't be
+// renewed is no longer considered owned, so that its processing stops before
+// the Lease expires and gets acquired by another replica.
+func (o *leaseShardOwnership) renewDeadline() time.Duration {
+	return o.duration * 2 / 3
+}
+
+// isHolder returns whether the given Lease is held by the current replica.
+func (o *leaseShardOwnership) isHolder(l *coordinationv1.Lease) bool {
+	return l.Spec.HolderIdentity != nil && *l.Spec.HolderIdentity == o.identity
+}
+
+// expired returns whether the given Lease is free or has expired.
+func (o *leaseShardOwnership) expired(l *coordinationv1.Lease, now time.Time) bool {
+	if l.Spec.HolderIdentity == nil || *l.Spec.HolderIdentity == "" {
+		return true
+	}
+	return o.expiredFor(l, now) > 0
+}
+
+// expiredFor returns the duration since the given Lease expired or was
+// released. The returned value is negative if the Lease hasn't expired yet.
+func (o *leaseShardOwnership) expiredFor(l *coordinationv1.Lease, now time.Time) time.Duration {
+	if l.Spec.RenewTime == nil {
+		return now.Sub(time.Time{})
+	}
+
+	expiry := l.Spec.RenewTime.Time
+	if l.Spec.HolderIdentity != nil && *l.Spec.HolderIdentity != "" {
+		expiry = expiry.Add(o.duration)
+	}
+
+	return now.Sub(expiry)
+}
+
+// memberLeaseName returns the name of the membership Lease of the current
+// replica.
+func (o *leaseShardOwnership) memberLeaseName() string {
+	return kmeta.ChildName(o.group+"-", o.identity)
+}
+
+// shardLeaseName returns the name of the Lease of the given shard.
+//
+// Shard IDs are hashed because they are not guaranteed to be valid object
+// names.
+func (o *leaseShardOwnership) shardLeaseName(shardID string) string {
+	h := sha256.Sum256([]byte(shardID))
+	return kmeta.ChildName(o.group+"-shard-", hex.EncodeToString(h[:8]))
+}
+
+// setShardLease sets the holder and checkpoint of the given shard Lease.
+func (o *leaseShardOwnership) setShardLease(l *coordinationv1.Lease, holder, checkpoint string,
+	now time.Time, acquire bool) {
+
+	setLease(l, holder, o.duration, now, acquire)
+
+	if checkpoint != "" {
+		if l.Annotations == nil {
+			l.Annotations = make(map[string]string, 1)
+		}
+		l.Annotations[checkpointAnnotation] = checkpoint
+	}
+}
+
+// setLease sets the holder and renewal time of the given Lease.
+func setLease(l *coordinationv1.Lease, holder string, duration time.Duration, now time.Time, acquire bool) {
+	renewTime := metav1.NewMicroTime(now)
+
+	secs := int32(duration / time.Second)
+
+	l.Spec.HolderIdentity = &holder
+	l.Spec.RenewTime = &renewTime
+	l.Spec.LeaseDurationSeconds = &secs
+
+	if acquire {
+		l.Spec.AcquireTime = &renewTime
+
+		var transitions int32
+		if l.Spec.LeaseTransitions != nil {
+			transitions = *l.Spec.LeaseTransitions + 1
+		}
+		l.Spec.LeaseTransitions = &transitions
+	}
+}
+
+// lastKey returns the greatest key of the given map.
+func lastKey(m map[string]time.Time) string {
+	var last string
+	for k := range m {
+		if k > last {
+			last = k
+		}
+	}
+	return last
+}
+
+// sameKeys returns whether the given maps have the same set of keys.
+func sameKeys(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
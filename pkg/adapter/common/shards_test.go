@@ -0,0 +1,172 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	loggingtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestAllShards(t *testing.T) {
+	o := AllShards()
+
+	assert.Empty(t, o.Owned())
+
+	o.SetShards([]string{"a", "b"})
+	assert.Equal(t, map[string]string{"a": "", "b": ""}, o.Owned())
+	assert.Nil(t, o.Changed())
+}
+
+func TestLeaseShardOwnership(t *testing.T) {
+	const ns = "test-ns"
+	const group = "test-group"
+	const duration = 15 * time.Second
+
+	ctx := context.Background()
+
+	cli := fake.NewSimpleClientset().CoordinationV1().Leases(ns)
+	clk := clock.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := loggingtesting.TestLogger(t)
+
+	shards := []string{"shard-a", "shard-b", "shard-c", "shard-d"}
+
+	r1 := newLeaseShardOwnership(cli, group, "replica-1", duration, clk, logger)
+	r1.SetShards(shards)
+	r2 := newLeaseShardOwnership(cli, group, "replica-2", duration, clk, logger)
+	r2.SetShards(shards)
+
+	syncAll := func() {
+		t.Helper()
+		require.NoError(t, r1.sync(ctx))
+		require.NoError(t, r2.sync(ctx))
+		clk.Step(duration / 3)
+	}
+
+	// a single replica owns all shards
+
+	require.NoError(t, r1.sync(ctx))
+	assert.Len(t, r1.Owned(), len(shards))
+	assert.Len(t, r1.Changed(), 1, "Expected a change notification")
+	<-r1.Changed()
+
+	// shards get distributed evenly once a second replica joins
+
+	for i := 0; i < len(shards); i++ {
+		syncAll()
+	}
+
+	owned1, owned2 := r1.Owned(), r2.Owned()
+	assert.Len(t, owned1, 2)
+	assert.Len(t, owned2, 2)
+	for id := range owned1 {
+		assert.NotContains(t, owned2, id, "Expected shard to be owned by a single replica")
+	}
+
+	// checkpoints are persisted in Leases
+
+	var ckptShard string
+	for id := range owned2 {
+		ckptShard = id
+		break
+	}
+	r2.Checkpoint(ckptShard, "42")
+	syncAll()
+
+	// shards of a replica which stops renewing its Leases fail over to
+	// the remaining replica, and resume from the last checkpoint
+
+	clk.Step(duration + time.Second)
+	require.NoError(t, r1.sync(ctx))
+
+	owned1 = r1.Owned()
+	assert.Len(t, owned1, len(shards))
+	assert.Equal(t, "42", owned1[ckptShard])
+
+	// shards are released on shutdown
+
+	r1.release()
+	assert.Empty(t, r1.Owned())
+
+	leases, err := cli.List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for _, l := range leases.Items {
+		if l.Labels[haRoleLabel] == haRoleShard {
+			assert.Empty(t, *l.Spec.HolderIdentity, "Expected shard Lease to be released")
+		}
+	}
+
+	require.NoError(t, r2.sync(ctx))
+	assert.Len(t, r2.Owned(), len(shards))
+}
+
+func TestLeaseShardOwnershipAPIFailure(t *testing.T) {
+	const ns = "test-ns"
+	const group = "test-group"
+	const duration = 15 * time.Second
+
+	ctx := context.Background()
+
+	cs := fake.NewSimpleClientset()
+	clk := clock.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := loggingtesting.TestLogger(t)
+
+	shards := []string{"shard-a", "shard-b"}
+
+	r := newLeaseShardOwnership(cs.CoordinationV1().Leases(ns), group, "replica-1", duration, clk, logger)
+	r.SetShards(shards)
+
+	require.NoError(t, r.sync(ctx))
+	r.dropStaleShards()
+	assert.Len(t, r.Owned(), len(shards))
+	<-r.Changed()
+
+	// the Kubernetes API becomes unreachable
+
+	cs.PrependReactor("*", "leases", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("fake error")
+	})
+
+	// shards remain owned until the renew deadline
+
+	clk.Step(duration / 3)
+	require.Error(t, r.sync(ctx))
+	r.dropStaleShards()
+	assert.Len(t, r.Owned(), len(shards))
+	assert.Empty(t, r.Changed(), "Unexpected change notification")
+
+	// shards are no longer owned once the renew deadline has passed,
+	// even though they couldn't be synchronized
+
+	clk.Step(duration / 3)
+	require.Error(t, r.sync(ctx))
+	r.dropStaleShards()
+	assert.Empty(t, r.Owned())
+	assert.Len(t, r.Changed(), 1, "Expected a change notification")
+}
@@ -0,0 +1,155 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// StateStore persists the state of an adapter, so that the adapter can resume
+// where it left off after a restart.
+type StateStore interface {
+	// Load decodes the persisted state into the value pointed to by v. The
+	// returned boolean is false if no state was persisted yet.
+	Load(ctx context.Context, v interface{}) (bool /*found*/, error)
+	// Save persists the given state.
+	Save(ctx context.Context, v interface{}) error
+}
+
+// stateConfigMapKey is the key of the ConfigMap's data under which the state
+// is persisted.
+const stateConfigMapKey = "state"
+
+// maxStateSize is the maximum size of a serialized state. The size of a
+// ConfigMap is limited to 1 MiB, including its metadata.
+const maxStateSize = 1000 * 1024
+
+// configMapStateStore is a StateStore which persists the state of an adapter
+// as JSON inside a Kubernetes ConfigMap.
+type configMapStateStore struct {
+	cli  corev1client.ConfigMapInterface
+	name string
+
+	// last state which was loaded or saved, used to skip writes of
+	// unchanged states
+	last []byte
+}
+
+var _ StateStore = (*configMapStateStore)(nil)
+
+// NewConfigMapStateStore returns a StateStore which persists state inside the
+// ConfigMap with the given name.
+func NewConfigMapStateStore(cli corev1client.ConfigMapInterface, name string) StateStore {
+	return &configMapStateStore{
+		cli:  cli,
+		name: name,
+	}
+}
+
+// MustNewConfigMapStateStore returns a StateStore which persists state inside
+// the ConfigMap with the given namespace and name, using the in-cluster
+// Kubernetes configuration or the configuration file referenced by the
+// KUBECONFIG environment variable. It panics if no valid configuration can be
+// found.
+func MustNewConfigMapStateStore(namespace, name string) StateStore {
+	cli := mustNewKubeClient().CoreV1().ConfigMaps(namespace)
+
+	return NewConfigMapStateStore(cli, name)
+}
+
+// Load implements StateStore.
+func (s *configMapStateStore) Load(ctx context.Context, v interface{}) (bool, error) {
+	cm, err := s.cli.Get(ctx, s.name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("getting ConfigMap %q: %w", s.name, err)
+	}
+
+	data, ok := cm.Data[stateConfigMapKey]
+	if !ok {
+		return false, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return false, fmt.Errorf("deserializing state from ConfigMap %q: %w", s.name, err)
+	}
+
+	s.last = []byte(data)
+
+	return true, nil
+}
+
+// Save implements StateStore.
+func (s *configMapStateStore) Save(ctx context.Context, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("serializing state: %w", err)
+	}
+
+	if bytes.Equal(data, s.last) {
+		return nil
+	}
+
+	if len(data) > maxStateSize {
+		return fmt.Errorf("serialized state of %d bytes exceeds the maximum size of %d bytes "+
+			"which can be stored in ConfigMap %q", len(data), maxStateSize, s.name)
+	}
+
+	cm, err := s.cli.Get(ctx, s.name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: s.name,
+			},
+			Data: map[string]string{
+				stateConfigMapKey: string(data),
+			},
+		}
+
+		if _, err := s.cli.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("creating ConfigMap %q: %w", s.name, err)
+		}
+
+	case err != nil:
+		return fmt.Errorf("getting ConfigMap %q: %w", s.name, err)
+
+	default:
+		if cm.Data == nil {
+			cm.Data = make(map[string]string, 1)
+		}
+		cm.Data[stateConfigMapKey] = string(data)
+
+		if _, err := s.cli.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("updating ConfigMap %q: %w", s.name, err)
+		}
+	}
+
+	s.last = data
+
+	return nil
+}
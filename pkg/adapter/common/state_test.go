@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestConfigMapStateStore(t *testing.T) {
+	const ns = "test-ns"
+	const name = "test-state"
+
+	type testState struct {
+		Marks map[string]int `json:"marks"`
+	}
+
+	ctx := context.Background()
+
+	cli := fake.NewSimpleClientset()
+	cmCli := cli.CoreV1().ConfigMaps(ns)
+
+	s := NewConfigMapStateStore(cmCli, name)
+
+	var st testState
+
+	found, err := s.Load(ctx, &st)
+	require.NoError(t, err)
+	assert.False(t, found, "Expected no persisted state")
+
+	// first save creates the ConfigMap
+
+	st.Marks = map[string]int{"a": 1}
+	require.NoError(t, s.Save(ctx, &st))
+
+	cm, err := cmCli.Get(ctx, name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"marks":{"a":1}}`, cm.Data[stateConfigMapKey])
+
+	// saving an unchanged state is a no-op
+
+	cli.ClearActions()
+	require.NoError(t, s.Save(ctx, &st))
+	assert.Empty(t, cli.Actions())
+
+	// subsequent saves update the ConfigMap
+
+	st.Marks["b"] = 2
+	require.NoError(t, s.Save(ctx, &st))
+
+	var updated bool
+	for _, a := range cli.Actions() {
+		if _, ok := a.(k8stesting.UpdateAction); ok {
+			updated = true
+		}
+	}
+	assert.True(t, updated, "Expected ConfigMap to be updated")
+
+	// state is read back by a new store
+
+	var gotSt testState
+
+	found, err = NewConfigMapStateStore(cmCli, name).Load(ctx, &gotSt)
+	require.NoError(t, err)
+	assert.True(t, found, "Expected a persisted state")
+	assert.Equal(t, st, gotSt)
+
+	// states which exceed the size of a ConfigMap are rejected
+
+	cli.ClearActions()
+
+	for i := 0; i < maxStateSize/8; i++ {
+		st.Marks[strconv.Itoa(i)] = i
+	}
+	assert.Error(t, s.Save(ctx, &st))
+	assert.Empty(t, cli.Actions())
+}
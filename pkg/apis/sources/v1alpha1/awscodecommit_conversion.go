@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	pkgapis "knative.dev/pkg/apis"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1beta1"
+)
+
+// awsCodeCommitDeprecatedFields contains the attributes of a v1alpha1
+// AWSCodeCommitSource which were removed from later API versions.
+type awsCodeCommitDeprecatedFields struct {
+	// Branch is prepended to the list of branches in later API versions.
+	Branch string `json:"branch,omitempty"`
+}
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSCodeCommitSource) ConvertTo(ctx context.Context, to pkgapis.Convertible) error {
+	switch sink := to.(type) {
+	case *v1beta1.AWSCodeCommitSource:
+		spec := s.Spec.DeepCopy()
+
+		sink.ObjectMeta = *s.ObjectMeta.DeepCopy()
+
+		err := setDeprecatedFields(&sink.ObjectMeta, &awsCodeCommitDeprecatedFields{
+			Branch: spec.Branch,
+		})
+		if err != nil {
+			return err
+		}
+
+		var eventTypes []v1beta1.AWSCodeCommitEventType
+		if spec.EventTypes != nil {
+			eventTypes = make([]v1beta1.AWSCodeCommitEventType, len(spec.EventTypes))
+			for i, typ := range spec.EventTypes {
+				eventTypes[i] = v1beta1.AWSCodeCommitEventType(typ)
+			}
+		}
+
+		sink.Spec = v1beta1.AWSCodeCommitSourceSpec{
+			SourceSpec: spec.SourceSpec,
+			ARN:        spec.ARN,
+			Auth: v1beta1.AWSAuth{
+				Credentials: credentialsToV1beta1(&spec.Credentials),
+			},
+			Endpoint:         endpointToV1beta1(spec.Endpoint),
+			Branches:         spec.BranchPatterns(),
+			Tags:             spec.Tags,
+			EventTypes:       eventTypes,
+			QueueARN:         spec.QueueARN,
+			StateConfigMap:   spec.StateConfigMap,
+			HighAvailability: (*v1beta1.HighAvailability)(spec.HighAvailability),
+			AdapterOverrides: (*v1beta1.AdapterOverrides)(spec.AdapterOverrides),
+		}
+		sink.Status = statusToV1beta1(&s.Status)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported conversion to %T", sink)
+	}
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSCodeCommitSource) ConvertFrom(ctx context.Context, from pkgapis.Convertible) error {
+	switch source := from.(type) {
+	case *v1beta1.AWSCodeCommitSource:
+		if source.Spec.Auth.ClientCertificate != nil {
+			return errUnsupportedAuth("AWSCodeCommitSource", "a client certificate")
+		}
+
+		spec := source.Spec.DeepCopy()
+
+		s.ObjectMeta = *source.ObjectMeta.DeepCopy()
+
+		var deprecated awsCodeCommitDeprecatedFields
+		if err := popDeprecatedFields(&s.ObjectMeta, &deprecated); err != nil {
+			return err
+		}
+
+		branches := spec.Branches
+		if deprecated.Branch != "" && len(branches) > 0 && branches[0] == deprecated.Branch {
+			branches = branches[1:]
+			if len(branches) == 0 {
+				branches = nil
+			}
+		} else {
+			deprecated.Branch = ""
+		}
+
+		var eventTypes []string
+		if spec.EventTypes != nil {
+			eventTypes = make([]string, len(spec.EventTypes))
+			for i, typ := range spec.EventTypes {
+				eventTypes[i] = string(typ)
+			}
+		}
+
+		s.Spec = AWSCodeCommitSourceSpec{
+			SourceSpec:       spec.SourceSpec,
+			ARN:              spec.ARN,
+			Branch:           deprecated.Branch,
+			Branches:         branches,
+			Tags:             spec.Tags,
+			EventTypes:       eventTypes,
+			QueueARN:         spec.QueueARN,
+			StateConfigMap:   spec.StateConfigMap,
+			Credentials:      credentialsFromV1beta1(spec.Auth.Credentials),
+			Endpoint:         endpointFromV1beta1(spec.Endpoint),
+			HighAvailability: (*HighAvailability)(spec.HighAvailability),
+			AdapterOverrides: (*AdapterOverrides)(spec.AdapterOverrides),
+		}
+		s.Status = statusFromV1beta1(&source.Status)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported conversion from %T", source)
+	}
+}
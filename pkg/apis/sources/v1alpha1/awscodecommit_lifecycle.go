@@ -19,8 +19,10 @@ package v1alpha1
 import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
-	"knative.dev/pkg/apis"
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
 )
 
 // GetGroupVersionKind implements kmeta.OwnerRefable.
@@ -29,8 +31,8 @@ func (s *AWSCodeCommitSource) GetGroupVersionKind() schema.GroupVersionKind {
 }
 
 // GetConditionSet implements duckv1.KRShaped.
-func (s *AWSCodeCommitSource) GetConditionSet() apis.ConditionSet {
-	return eventSourceConditionSet
+func (s *AWSCodeCommitSource) GetConditionSet() pkgapis.ConditionSet {
+	return awsEventSourceConditionSet
 }
 
 // GetStatus implements duckv1.KRShaped.
@@ -51,17 +53,99 @@ func (s *AWSCodeCommitSource) GetStatusManager() *EventSourceStatusManager {
 	}
 }
 
+// GetCredentials implements AWSAuthenticatedSource.
+func (s *AWSCodeCommitSource) GetCredentials() *AWSSecurityCredentials {
+	return &s.Spec.Credentials
+}
+
+// GetEndpoint implements AWSAuthenticatedSource.
+func (s *AWSCodeCommitSource) GetEndpoint() *AWSEndpoint {
+	return s.Spec.Endpoint
+}
+
+// GetARN implements AWSAuthenticatedSource.
+func (s *AWSCodeCommitSource) GetARN() apis.ARN {
+	return s.Spec.ARN
+}
+
+// Types of events emitted by the source upon the creation and deletion of a
+// branch, when push events are enabled.
+const (
+	AWSCodeCommitBranchCreatedEventType = "branch_created"
+	AWSCodeCommitBranchDeletedEventType = "branch_deleted"
+)
+
+// Types of events emitted by the source upon the creation, update and deletion
+// of a tag, when push events are enabled and tags are observed.
+const (
+	AWSCodeCommitTagCreatedEventType = "tag_created"
+	AWSCodeCommitTagUpdatedEventType = "tag_updated"
+	AWSCodeCommitTagDeletedEventType = "tag_deleted"
+)
+
+// Types of events emitted by the source over the lifecycle of a pull request,
+// when pull_request events are enabled.
+const (
+	AWSCodeCommitPullRequestCreatedEventType         = "pull_request_created"
+	AWSCodeCommitPullRequestUpdatedEventType         = "pull_request_updated"
+	AWSCodeCommitPullRequestMergedEventType          = "pull_request_merged"
+	AWSCodeCommitPullRequestClosedEventType          = "pull_request_closed"
+	AWSCodeCommitPullRequestApprovalChangedEventType = "pull_request_approval_changed"
+	AWSCodeCommitPullRequestCommentedEventType       = "pull_request_commented"
+
+	// Deprecated, superseded by AWSCodeCommitPullRequestCreatedEventType.
+	// Events of this type carry the created PR and are still emitted
+	// along with pull_request_created events for backwards compatibility.
+	AWSCodeCommitPullRequestEventType = "pull_request"
+)
+
 // GetEventTypes implements EventSource.
 func (s *AWSCodeCommitSource) GetEventTypes() []string {
-	types := make([]string, len(s.Spec.EventTypes))
+	var types []string
 
-	for i, typ := range s.Spec.EventTypes {
-		types[i] = AWSEventType(s.Spec.ARN.Service, typ)
+	for _, typ := range s.Spec.EventTypes {
+		switch typ {
+		case "push":
+			types = append(types,
+				AWSEventType(s.Spec.ARN.Service, typ),
+				AWSEventType(s.Spec.ARN.Service, AWSCodeCommitBranchCreatedEventType),
+				AWSEventType(s.Spec.ARN.Service, AWSCodeCommitBranchDeletedEventType),
+			)
+			if len(s.Spec.Tags) > 0 {
+				types = append(types,
+					AWSEventType(s.Spec.ARN.Service, AWSCodeCommitTagCreatedEventType),
+					AWSEventType(s.Spec.ARN.Service, AWSCodeCommitTagUpdatedEventType),
+					AWSEventType(s.Spec.ARN.Service, AWSCodeCommitTagDeletedEventType),
+				)
+			}
+		case "pull_request":
+			types = append(types,
+				AWSEventType(s.Spec.ARN.Service, AWSCodeCommitPullRequestEventType),
+				AWSEventType(s.Spec.ARN.Service, AWSCodeCommitPullRequestCreatedEventType),
+				AWSEventType(s.Spec.ARN.Service, AWSCodeCommitPullRequestUpdatedEventType),
+				AWSEventType(s.Spec.ARN.Service, AWSCodeCommitPullRequestMergedEventType),
+				AWSEventType(s.Spec.ARN.Service, AWSCodeCommitPullRequestClosedEventType),
+				AWSEventType(s.Spec.ARN.Service, AWSCodeCommitPullRequestApprovalChangedEventType),
+				AWSEventType(s.Spec.ARN.Service, AWSCodeCommitPullRequestCommentedEventType),
+			)
+		default:
+			types = append(types, AWSEventType(s.Spec.ARN.Service, typ))
+		}
 	}
 
 	return types
 }
 
+// BranchPatterns returns the patterns of the Git branches observed by the
+// source.
+func (s *AWSCodeCommitSourceSpec) BranchPatterns() []string {
+	if s.Branch == "" {
+		return s.Branches
+	}
+
+	return append([]string{s.Branch}, s.Branches...)
+}
+
 // AsEventSource implements EventSource.
 func (s *AWSCodeCommitSource) AsEventSource() string {
 	return s.Spec.ARN.String()
@@ -20,6 +20,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/triggermesh/aws-event-sources/pkg/apis"
@@ -40,8 +41,12 @@ type AWSCodeCommitSource struct {
 
 // Check the interfaces the event source should be implementing.
 var (
-	_ runtime.Object = (*AWSCodeCommitSource)(nil)
-	_ EventSource    = (*AWSCodeCommitSource)(nil)
+	_ runtime.Object         = (*AWSCodeCommitSource)(nil)
+	_ EventSource            = (*AWSCodeCommitSource)(nil)
+	_ pkgapis.Validatable    = (*AWSCodeCommitSource)(nil)
+	_ pkgapis.Defaultable    = (*AWSCodeCommitSource)(nil)
+	_ pkgapis.Convertible    = (*AWSCodeCommitSource)(nil)
+	_ AWSAuthenticatedSource = (*AWSCodeCommitSource)(nil)
 )
 
 // AWSCodeCommitSourceSpec defines the desired state of the event source.
@@ -52,13 +57,60 @@ type AWSCodeCommitSourceSpec struct {
 	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_awscodecommit.html#awscodecommit-resources-for-iam-policies
 	ARN apis.ARN `json:"arn"`
 	// Name of the Git branch this source observes.
-	Branch string `json:"branch"`
+	// Deprecated, use Branches instead.
+	// +optional
+	Branch string `json:"branch,omitempty"`
+	// Glob patterns matching the names of the Git branches this source
+	// observes, e.g. "master" or "release/*". The syntax of patterns is the
+	// one of shell file name patterns, in which '*' doesn't match '/'.
+	// +optional
+	Branches []string `json:"branches,omitempty"`
+	// Glob patterns matching the names of the Git tags this source
+	// observes, e.g. "v*". Tags are observed only when events are consumed
+	// from Amazon EventBridge (see QueueARN), because the CodeCommit API
+	// doesn't expose them.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
 	// List of event types that should be processed by the source.
 	// Valid values: [push, pull_request]
+	// Enabling push events also enables branch creation and deletion events,
+	// as well as tag events when tags are observed.
 	EventTypes []string `json:"eventTypes"`
 
+	// ARN of an SQS queue to which Amazon EventBridge delivers the state
+	// change events of the repository. When set, the source consumes these
+	// events instead of polling the CodeCommit API, unless the queue can
+	// not be accessed.
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_amazonsqs.html#amazonsqs-resources-for-iam-policies
+	// +optional
+	QueueARN *apis.ARN `json:"queueARN,omitempty"`
+
+	// Name of a ConfigMap, in the namespace of the source, in which the
+	// adapter persists its state, so that it resumes where it left off
+	// after a restart. The ServiceAccount of the adapter must be allowed to
+	// get, create and update ConfigMaps. The state is only held in memory
+	// when omitted.
+	// +optional
+	StateConfigMap string `json:"stateConfigMap,omitempty"`
+
 	// Credentials to interact with the AWS CodeCommit API.
 	Credentials AWSSecurityCredentials `json:"credentials"`
+
+	// Custom endpoint of the AWS CodeCommit API, such as a VPC interface
+	// endpoint, a FIPS endpoint or a local emulator. Defaults to the public
+	// endpoint of the region of the ARN. In EventBridge mode, the source
+	// also interacts with the AWS SQS API ("sqs").
+	// +optional
+	Endpoint *AWSEndpoint `json:"endpoint,omitempty"`
+
+	// Runs multiple replicas of the adapter which coordinate through
+	// Kubernetes Leases.
+	// +optional
+	HighAvailability *HighAvailability `json:"highAvailability,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
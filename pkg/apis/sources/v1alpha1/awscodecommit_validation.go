@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"path"
+
+	pkgapis "knative.dev/pkg/apis"
+)
+
+// Validate implements apis.Validatable.
+func (s *AWSCodeCommitSource) Validate(ctx context.Context) *pkgapis.FieldError {
+	return s.Spec.Validate(ctx).ViaField("spec")
+}
+
+// Validate implements apis.Validatable.
+func (s *AWSCodeCommitSourceSpec) Validate(ctx context.Context) *pkgapis.FieldError {
+	errs := validateSink(ctx, &s.SourceSpec)
+	errs = errs.Also(validateARN(s.ARN, serviceCodeCommit, "").ViaField("arn"))
+
+	if s.Branch == "" && len(s.Branches) == 0 {
+		errs = errs.Also(pkgapis.ErrMissingOneOf("branch", "branches"))
+	}
+	if s.Branch != "" {
+		errs = errs.Also(validateBranchPattern(s.Branch).ViaField("branch"))
+	}
+	for i, p := range s.Branches {
+		errs = errs.Also(validateBranchPattern(p).ViaFieldIndex("branches", i))
+	}
+	for i, p := range s.Tags {
+		errs = errs.Also(validateBranchPattern(p).ViaFieldIndex("tags", i))
+	}
+	if len(s.Tags) > 0 && s.QueueARN == nil {
+		err := pkgapis.ErrMissingField("queueARN")
+		err.Details = "tags are only observed when events are consumed from Amazon EventBridge"
+		errs = errs.Also(err)
+	}
+
+	if len(s.EventTypes) == 0 {
+		errs = errs.Also(pkgapis.ErrMissingField("eventTypes"))
+	}
+	for i, typ := range s.EventTypes {
+		switch typ {
+		case "push", "pull_request":
+		default:
+			errs = errs.Also(invalidValue(typ, "valid values are [push, pull_request]").
+				ViaFieldIndex("eventTypes", i))
+		}
+	}
+
+	if s.QueueARN != nil {
+		errs = errs.Also(validateARN(*s.QueueARN, serviceSQS, "").ViaField("queueARN"))
+	}
+
+	errs = errs.Also(validateAWSAccess(&s.Credentials, s.Endpoint))
+
+	errs = errs.Also(s.HighAvailability.validate().ViaField("highAvailability"))
+	errs = errs.Also(s.AdapterOverrides.validate().ViaField("adapterOverrides"))
+
+	return errs
+}
+
+// validateBranchPattern ensures the given Git branch pattern is a valid shell
+// file name pattern.
+func validateBranchPattern(p string) *pkgapis.FieldError {
+	if p == "" {
+		return invalidValue(p, "pattern must not be empty")
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return invalidValue(p, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	pkgapis "knative.dev/pkg/apis"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1beta1"
+)
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSCognitoIdentitySource) ConvertTo(ctx context.Context, to pkgapis.Convertible) error {
+	switch sink := to.(type) {
+	case *v1beta1.AWSCognitoIdentitySource:
+		spec := s.Spec.DeepCopy()
+
+		sink.ObjectMeta = *s.ObjectMeta.DeepCopy()
+		sink.Spec = v1beta1.AWSCognitoIdentitySourceSpec{
+			SourceSpec: spec.SourceSpec,
+			ARN:        spec.ARN,
+			Auth: v1beta1.AWSAuth{
+				Credentials: credentialsToV1beta1(&spec.Credentials),
+			},
+			Endpoint:         endpointToV1beta1(spec.Endpoint),
+			Redaction:        redactionToV1beta1(spec.Redaction),
+			HighAvailability: (*v1beta1.HighAvailability)(spec.HighAvailability),
+			AdapterOverrides: (*v1beta1.AdapterOverrides)(spec.AdapterOverrides),
+		}
+		sink.Status = statusToV1beta1(&s.Status)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported conversion to %T", sink)
+	}
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSCognitoIdentitySource) ConvertFrom(ctx context.Context, from pkgapis.Convertible) error {
+	switch source := from.(type) {
+	case *v1beta1.AWSCognitoIdentitySource:
+		if source.Spec.Auth.ClientCertificate != nil {
+			return errUnsupportedAuth("AWSCognitoIdentitySource", "a client certificate")
+		}
+
+		spec := source.Spec.DeepCopy()
+
+		s.ObjectMeta = *source.ObjectMeta.DeepCopy()
+		s.Spec = AWSCognitoIdentitySourceSpec{
+			SourceSpec:       spec.SourceSpec,
+			ARN:              spec.ARN,
+			Credentials:      credentialsFromV1beta1(spec.Auth.Credentials),
+			Endpoint:         endpointFromV1beta1(spec.Endpoint),
+			Redaction:        redactionFromV1beta1(spec.Redaction),
+			HighAvailability: (*HighAvailability)(spec.HighAvailability),
+			AdapterOverrides: (*AdapterOverrides)(spec.AdapterOverrides),
+		}
+		s.Status = statusFromV1beta1(&source.Status)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported conversion from %T", source)
+	}
+}
@@ -19,8 +19,10 @@ package v1alpha1
 import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
-	"knative.dev/pkg/apis"
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
 )
 
 // GetGroupVersionKind implements kmeta.OwnerRefable.
@@ -29,8 +31,8 @@ func (s *AWSCognitoIdentitySource) GetGroupVersionKind() schema.GroupVersionKind
 }
 
 // GetConditionSet implements duckv1.KRShaped.
-func (s *AWSCognitoIdentitySource) GetConditionSet() apis.ConditionSet {
-	return eventSourceConditionSet
+func (s *AWSCognitoIdentitySource) GetConditionSet() pkgapis.ConditionSet {
+	return awsEventSourceConditionSet
 }
 
 // GetStatus implements duckv1.KRShaped.
@@ -51,6 +53,21 @@ func (s *AWSCognitoIdentitySource) GetStatusManager() *EventSourceStatusManager
 	}
 }
 
+// GetCredentials implements AWSAuthenticatedSource.
+func (s *AWSCognitoIdentitySource) GetCredentials() *AWSSecurityCredentials {
+	return &s.Spec.Credentials
+}
+
+// GetEndpoint implements AWSAuthenticatedSource.
+func (s *AWSCognitoIdentitySource) GetEndpoint() *AWSEndpoint {
+	return s.Spec.Endpoint
+}
+
+// GetARN implements AWSAuthenticatedSource.
+func (s *AWSCognitoIdentitySource) GetARN() apis.ARN {
+	return s.Spec.ARN
+}
+
 // Supported event types
 const (
 	AWSCognitoIdentityGenericEventType = "sync_trigger"
@@ -20,6 +20,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/triggermesh/aws-event-sources/pkg/apis"
@@ -40,8 +41,12 @@ type AWSCognitoIdentitySource struct {
 
 // Check the interfaces the event source should be implementing.
 var (
-	_ runtime.Object = (*AWSCognitoIdentitySource)(nil)
-	_ EventSource    = (*AWSCognitoIdentitySource)(nil)
+	_ runtime.Object         = (*AWSCognitoIdentitySource)(nil)
+	_ EventSource            = (*AWSCognitoIdentitySource)(nil)
+	_ pkgapis.Validatable    = (*AWSCognitoIdentitySource)(nil)
+	_ pkgapis.Defaultable    = (*AWSCognitoIdentitySource)(nil)
+	_ pkgapis.Convertible    = (*AWSCognitoIdentitySource)(nil)
+	_ AWSAuthenticatedSource = (*AWSCognitoIdentitySource)(nil)
 )
 
 // AWSCognitoIdentitySourceSpec defines the desired state of the event source.
@@ -52,8 +57,29 @@ type AWSCognitoIdentitySourceSpec struct {
 	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_amazoncognitoidentity.html#amazoncognitoidentity-resources-for-iam-policies
 	ARN apis.ARN `json:"arn"`
 
+	// Policy for redacting sensitive attributes from events. Events
+	// contain all attributes in clear text when omitted.
+	// +optional
+	Redaction *CognitoRedactionPolicy `json:"redaction,omitempty"`
+
 	// Credentials to interact with the AWS Cognito API.
 	Credentials AWSSecurityCredentials `json:"credentials"`
+
+	// Custom endpoint of the AWS Cognito Identity API, such as a VPC
+	// interface endpoint, a FIPS endpoint or a local emulator. Defaults to
+	// the public endpoint of the region of the ARN. The source also
+	// interacts with the AWS Cognito Sync API ("cognito-sync").
+	// +optional
+	Endpoint *AWSEndpoint `json:"endpoint,omitempty"`
+
+	// Runs multiple replicas of the adapter which coordinate through
+	// Kubernetes Leases.
+	// +optional
+	HighAvailability *HighAvailability `json:"highAvailability,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
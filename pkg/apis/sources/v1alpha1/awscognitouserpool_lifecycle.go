@@ -19,8 +19,10 @@ package v1alpha1
 import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
-	"knative.dev/pkg/apis"
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
 )
 
 // GetGroupVersionKind implements kmeta.OwnerRefable.
@@ -29,8 +31,8 @@ func (s *AWSCognitoUserPoolSource) GetGroupVersionKind() schema.GroupVersionKind
 }
 
 // GetConditionSet implements duckv1.KRShaped.
-func (s *AWSCognitoUserPoolSource) GetConditionSet() apis.ConditionSet {
-	return eventSourceConditionSet
+func (s *AWSCognitoUserPoolSource) GetConditionSet() pkgapis.ConditionSet {
+	return awsEventSourceConditionSet
 }
 
 // GetStatus implements duckv1.KRShaped.
@@ -51,16 +53,59 @@ func (s *AWSCognitoUserPoolSource) GetStatusManager() *EventSourceStatusManager
 	}
 }
 
+// GetCredentials implements AWSAuthenticatedSource.
+func (s *AWSCognitoUserPoolSource) GetCredentials() *AWSSecurityCredentials {
+	return &s.Spec.Credentials
+}
+
+// GetEndpoint implements AWSAuthenticatedSource.
+func (s *AWSCognitoUserPoolSource) GetEndpoint() *AWSEndpoint {
+	return s.Spec.Endpoint
+}
+
+// GetARN implements AWSAuthenticatedSource.
+func (s *AWSCognitoUserPoolSource) GetARN() apis.ARN {
+	return s.Spec.ARN
+}
+
 // Supported event types
 const (
-	AWSCognitoUserPoolGenericEventType = "sync_trigger"
+	AWSCognitoUserPoolUserCreatedEventType       = "user_created"
+	AWSCognitoUserPoolUserUpdatedEventType       = "user_updated"
+	AWSCognitoUserPoolUserEnabledEventType       = "user_enabled"
+	AWSCognitoUserPoolUserDisabledEventType      = "user_disabled"
+	AWSCognitoUserPoolUserStatusChangedEventType = "user_status_changed"
+	AWSCognitoUserPoolUserDeletedEventType       = "user_deleted"
+
+	AWSCognitoUserPoolGroupMemberAddedEventType   = "group_member_added"
+	AWSCognitoUserPoolGroupMemberRemovedEventType = "group_member_removed"
+
+	AWSCognitoUserPoolAuthEventType = "auth_event"
 )
 
 // GetEventTypes implements EventSource.
 func (s *AWSCognitoUserPoolSource) GetEventTypes() []string {
-	return []string{
-		AWSEventType(s.Spec.ARN.Service, AWSCognitoUserPoolGenericEventType),
+	types := []string{
+		AWSEventType(s.Spec.ARN.Service, AWSCognitoUserPoolUserCreatedEventType),
+		AWSEventType(s.Spec.ARN.Service, AWSCognitoUserPoolUserUpdatedEventType),
+		AWSEventType(s.Spec.ARN.Service, AWSCognitoUserPoolUserEnabledEventType),
+		AWSEventType(s.Spec.ARN.Service, AWSCognitoUserPoolUserDisabledEventType),
+		AWSEventType(s.Spec.ARN.Service, AWSCognitoUserPoolUserStatusChangedEventType),
+		AWSEventType(s.Spec.ARN.Service, AWSCognitoUserPoolUserDeletedEventType),
+	}
+
+	if s.Spec.TrackGroups {
+		types = append(types,
+			AWSEventType(s.Spec.ARN.Service, AWSCognitoUserPoolGroupMemberAddedEventType),
+			AWSEventType(s.Spec.ARN.Service, AWSCognitoUserPoolGroupMemberRemovedEventType),
+		)
 	}
+
+	if s.Spec.TrackAuthEvents {
+		types = append(types, AWSEventType(s.Spec.ARN.Service, AWSCognitoUserPoolAuthEventType))
+	}
+
+	return types
 }
 
 // AsEventSource implements EventSource.
@@ -20,6 +20,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/triggermesh/aws-event-sources/pkg/apis"
@@ -40,8 +41,12 @@ type AWSCognitoUserPoolSource struct {
 
 // Check the interfaces the event source should be implementing.
 var (
-	_ runtime.Object = (*AWSCognitoUserPoolSource)(nil)
-	_ EventSource    = (*AWSCognitoUserPoolSource)(nil)
+	_ runtime.Object         = (*AWSCognitoUserPoolSource)(nil)
+	_ EventSource            = (*AWSCognitoUserPoolSource)(nil)
+	_ pkgapis.Validatable    = (*AWSCognitoUserPoolSource)(nil)
+	_ pkgapis.Defaultable    = (*AWSCognitoUserPoolSource)(nil)
+	_ pkgapis.Convertible    = (*AWSCognitoUserPoolSource)(nil)
+	_ AWSAuthenticatedSource = (*AWSCognitoUserPoolSource)(nil)
 )
 
 // AWSCognitoUserPoolSourceSpec defines the desired state of the event source.
@@ -52,8 +57,49 @@ type AWSCognitoUserPoolSourceSpec struct {
 	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_amazoncognitoidentity.html#amazoncognitoidentity-resources-for-iam-policies
 	ARN apis.ARN `json:"arn"`
 
+	// Name of a ConfigMap, in the namespace of the source, in which the
+	// adapter persists the last known state of the pool's users, so that
+	// changes which occur while the adapter isn't running are reported
+	// after a restart. The ServiceAccount of the adapter must be allowed to
+	// get, create and update ConfigMaps. The state is only held in memory
+	// when omitted.
+	// +optional
+	StateConfigMap string `json:"stateConfigMap,omitempty"`
+
+	// Whether users which are added to or removed from groups of the pool
+	// should be reported.
+	// +optional
+	TrackGroups bool `json:"trackGroups,omitempty"`
+
+	// Whether auth events (sign-in, sign-up, forgotten password) of the
+	// pool's users should be reported. Requires the advanced security
+	// features of the pool to be enabled. Auth events are listed for each
+	// user individually, which can be costly for pools with many users.
+	// +optional
+	TrackAuthEvents bool `json:"trackAuthEvents,omitempty"`
+
+	// Policy for redacting sensitive attributes from events. Events
+	// contain all attributes in clear text when omitted.
+	// +optional
+	Redaction *CognitoRedactionPolicy `json:"redaction,omitempty"`
+
 	// Credentials to interact with the AWS Cognito API.
 	Credentials AWSSecurityCredentials `json:"credentials"`
+
+	// Custom endpoint of the AWS Cognito API, such as a VPC interface
+	// endpoint, a FIPS endpoint or a local emulator. Defaults to the public
+	// endpoint of the region of the ARN.
+	// +optional
+	Endpoint *AWSEndpoint `json:"endpoint,omitempty"`
+
+	// Runs multiple replicas of the adapter which coordinate through
+	// Kubernetes Leases.
+	// +optional
+	HighAvailability *HighAvailability `json:"highAvailability,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -23,8 +23,10 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
-	"knative.dev/pkg/apis"
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
 )
 
 // GetGroupVersionKind implements kmeta.OwnerRefable.
@@ -33,8 +35,8 @@ func (s *AWSDynamoDBSource) GetGroupVersionKind() schema.GroupVersionKind {
 }
 
 // GetConditionSet implements duckv1.KRShaped.
-func (s *AWSDynamoDBSource) GetConditionSet() apis.ConditionSet {
-	return eventSourceConditionSet
+func (s *AWSDynamoDBSource) GetConditionSet() pkgapis.ConditionSet {
+	return awsEventSourceConditionSet
 }
 
 // GetStatus implements duckv1.KRShaped.
@@ -55,6 +57,21 @@ func (s *AWSDynamoDBSource) GetStatusManager() *EventSourceStatusManager {
 	}
 }
 
+// GetCredentials implements AWSAuthenticatedSource.
+func (s *AWSDynamoDBSource) GetCredentials() *AWSSecurityCredentials {
+	return &s.Spec.Credentials
+}
+
+// GetEndpoint implements AWSAuthenticatedSource.
+func (s *AWSDynamoDBSource) GetEndpoint() *AWSEndpoint {
+	return s.Spec.Endpoint
+}
+
+// GetARN implements AWSAuthenticatedSource.
+func (s *AWSDynamoDBSource) GetARN() apis.ARN {
+	return s.Spec.ARN
+}
+
 // GetEventTypes implements EventSource.
 func (s *AWSDynamoDBSource) GetEventTypes() []string {
 	const numEventTypes = 3
@@ -20,6 +20,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/triggermesh/aws-event-sources/pkg/apis"
@@ -40,8 +41,12 @@ type AWSDynamoDBSource struct {
 
 // Check the interfaces the event source should be implementing.
 var (
-	_ runtime.Object = (*AWSDynamoDBSource)(nil)
-	_ EventSource    = (*AWSDynamoDBSource)(nil)
+	_ runtime.Object         = (*AWSDynamoDBSource)(nil)
+	_ EventSource            = (*AWSDynamoDBSource)(nil)
+	_ pkgapis.Validatable    = (*AWSDynamoDBSource)(nil)
+	_ pkgapis.Defaultable    = (*AWSDynamoDBSource)(nil)
+	_ pkgapis.Convertible    = (*AWSDynamoDBSource)(nil)
+	_ AWSAuthenticatedSource = (*AWSDynamoDBSource)(nil)
 )
 
 // AWSDynamoDBSourceSpec defines the desired state of the event source.
@@ -54,6 +59,22 @@ type AWSDynamoDBSourceSpec struct {
 
 	// Credentials to interact with the AWS Cognito API.
 	Credentials AWSSecurityCredentials `json:"credentials"`
+
+	// Custom endpoint of the AWS DynamoDB API, such as a VPC interface
+	// endpoint, a FIPS endpoint or a local emulator. Defaults to the public
+	// endpoint of the region of the ARN. The source also interacts with
+	// the AWS DynamoDB Streams API ("streams.dynamodb").
+	// +optional
+	Endpoint *AWSEndpoint `json:"endpoint,omitempty"`
+
+	// Runs multiple replicas of the adapter which coordinate through
+	// Kubernetes Leases.
+	// +optional
+	HighAvailability *HighAvailability `json:"highAvailability,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
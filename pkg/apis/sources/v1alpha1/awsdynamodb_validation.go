@@ -0,0 +1,42 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	pkgapis "knative.dev/pkg/apis"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+)
+
+// Validate implements apis.Validatable.
+func (s *AWSDynamoDBSource) Validate(ctx context.Context) *pkgapis.FieldError {
+	return s.Spec.Validate(ctx).ViaField("spec")
+}
+
+// Validate implements apis.Validatable.
+func (s *AWSDynamoDBSourceSpec) Validate(ctx context.Context) *pkgapis.FieldError {
+	errs := validateSink(ctx, &s.SourceSpec)
+	errs = errs.Also(validateARN(s.ARN, serviceDynamoDB, apis.DynamoDBResourceFormat).ViaField("arn"))
+	errs = errs.Also(validateAWSAccess(&s.Credentials, s.Endpoint))
+
+	errs = errs.Also(s.HighAvailability.validate().ViaField("highAvailability"))
+	errs = errs.Also(s.AdapterOverrides.validate().ViaField("adapterOverrides"))
+
+	return errs
+}
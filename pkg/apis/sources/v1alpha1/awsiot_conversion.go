@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	pkgapis "knative.dev/pkg/apis"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1beta1"
+)
+
+// awsIoTDeprecatedFields contains the attributes of a v1alpha1 AWSIoTSource
+// which were removed from later API versions.
+type awsIoTDeprecatedFields struct {
+	RootCAPath      *string `json:"rootCAPath,omitempty"`
+	CertificatePath *string `json:"certificatePath,omitempty"`
+	PrivateKeyPath  *string `json:"privateKeyPath,omitempty"`
+}
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSIoTSource) ConvertTo(ctx context.Context, to pkgapis.Convertible) error {
+	switch sink := to.(type) {
+	case *v1beta1.AWSIoTSource:
+		spec := s.Spec.DeepCopy()
+
+		sink.ObjectMeta = *s.ObjectMeta.DeepCopy()
+
+		err := setDeprecatedFields(&sink.ObjectMeta, &awsIoTDeprecatedFields{
+			RootCAPath:      spec.RootCAPath,
+			CertificatePath: spec.CertificatePath,
+			PrivateKeyPath:  spec.PrivateKeyPath,
+		})
+		if err != nil {
+			return err
+		}
+
+		sink.Spec = v1beta1.AWSIoTSourceSpec{
+			SourceSpec: spec.SourceSpec,
+			ARN:        spec.ARN,
+			Auth: v1beta1.AWSAuth{
+				ClientCertificate: &v1beta1.AWSClientCertificate{
+					Certificate: valueFromFieldToV1beta1(&spec.Certificate),
+					PrivateKey:  valueFromFieldToV1beta1(&spec.PrivateKey),
+					RootCA:      valueFromFieldPtrToV1beta1(spec.RootCA),
+				},
+			},
+			DataEndpoint:     spec.Endpoint,
+			Topics:           spec.Topics,
+			ClientID:         spec.ClientID,
+			AdapterOverrides: (*v1beta1.AdapterOverrides)(spec.AdapterOverrides),
+		}
+		sink.Status = statusToV1beta1(&s.Status)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported conversion to %T", sink)
+	}
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSIoTSource) ConvertFrom(ctx context.Context, from pkgapis.Convertible) error {
+	switch source := from.(type) {
+	case *v1beta1.AWSIoTSource:
+		if source.Spec.Auth.Credentials != nil {
+			return errUnsupportedAuth("AWSIoTSource", "security credentials")
+		}
+
+		spec := source.Spec.DeepCopy()
+
+		s.ObjectMeta = *source.ObjectMeta.DeepCopy()
+
+		var deprecated awsIoTDeprecatedFields
+		if err := popDeprecatedFields(&s.ObjectMeta, &deprecated); err != nil {
+			return err
+		}
+
+		s.Spec = AWSIoTSourceSpec{
+			SourceSpec:       spec.SourceSpec,
+			Endpoint:         spec.DataEndpoint,
+			ARN:              spec.ARN,
+			Topics:           spec.Topics,
+			ClientID:         spec.ClientID,
+			RootCAPath:       deprecated.RootCAPath,
+			CertificatePath:  deprecated.CertificatePath,
+			PrivateKeyPath:   deprecated.PrivateKeyPath,
+			AdapterOverrides: (*AdapterOverrides)(spec.AdapterOverrides),
+		}
+		if cc := spec.Auth.ClientCertificate; cc != nil {
+			s.Spec.Certificate = valueFromFieldFromV1beta1(&cc.Certificate)
+			s.Spec.PrivateKey = valueFromFieldFromV1beta1(&cc.PrivateKey)
+			s.Spec.RootCA = valueFromFieldPtrFromV1beta1(cc.RootCA)
+		}
+		s.Status = statusFromV1beta1(&source.Status)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported conversion from %T", source)
+	}
+}
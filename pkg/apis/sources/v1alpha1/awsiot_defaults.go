@@ -0,0 +1,35 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+)
+
+// SetDefaults implements apis.Defaultable.
+func (s *AWSIoTSource) SetDefaults(ctx context.Context) {
+	if len(s.Spec.Topics) == 0 && strings.HasPrefix(s.Spec.ARN.Resource, "topic/") {
+		s.Spec.Topics = []string{strings.TrimPrefix(s.Spec.ARN.Resource, "topic/")}
+	}
+
+	// the name may still be empty at admission time when the object is
+	// created with a generateName
+	if s.Spec.ClientID == "" && s.Name != "" {
+		s.Spec.ClientID = s.Namespace + "." + s.Name
+	}
+}
@@ -53,13 +53,13 @@ func (s *AWSIoTSource) GetStatusManager() *EventSourceStatusManager {
 
 // Supported event types
 const (
-	AWSIoTGenericEventType = "greetings"
+	AWSIoTMessageEventType = "message"
 )
 
 // GetEventTypes implements EventSource.
 func (s *AWSIoTSource) GetEventTypes() []string {
 	return []string{
-		AWSEventType(s.Spec.ARN.Service, AWSIoTGenericEventType),
+		AWSEventType(s.Spec.ARN.Service, AWSIoTMessageEventType),
 	}
 }
 
@@ -20,6 +20,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/triggermesh/aws-event-sources/pkg/apis"
@@ -40,8 +41,11 @@ type AWSIoTSource struct {
 
 // Check the interfaces the event source should be implementing.
 var (
-	_ runtime.Object = (*AWSIoTSource)(nil)
-	_ EventSource    = (*AWSIoTSource)(nil)
+	_ runtime.Object      = (*AWSIoTSource)(nil)
+	_ EventSource         = (*AWSIoTSource)(nil)
+	_ pkgapis.Validatable = (*AWSIoTSource)(nil)
+	_ pkgapis.Defaultable = (*AWSIoTSource)(nil)
+	_ pkgapis.Convertible = (*AWSIoTSource)(nil)
 )
 
 // AWSIoTSourceSpec defines the desired state of the event source.
@@ -54,23 +58,39 @@ type AWSIoTSourceSpec struct {
 	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_awsiot.html#awsiot-resources-for-iam-policies
 	ARN apis.ARN `json:"arn"`
 
-	// Contents of the root CA
-	RootCA ValueFromField `json:"rootCA"`
-	// Path where the root CA gets written
+	// MQTT topic filters to subscribe to, which may contain the wildcards
+	// '+' and '#'. Defaults to the topic referenced by the ARN.
+	// +optional
+	Topics []string `json:"topics,omitempty"`
+	// MQTT client identifier. Defaults to "<namespace>.<name>".
+	// +optional
+	ClientID string `json:"clientID,omitempty"`
+
+	// Contents of the root CA. Defaults to the system's root CAs.
+	// +optional
+	RootCA *ValueFromField `json:"rootCA,omitempty"`
+	// Deprecated: certificates are no longer written to disk, this
+	// attribute is ignored.
 	// +optional
 	RootCAPath *string `json:"rootCAPath,omitempty"`
 
 	// Contents of the client certificate
 	Certificate ValueFromField `json:"certificate"`
-	// Path where the client certificate gets written
+	// Deprecated: certificates are no longer written to disk, this
+	// attribute is ignored.
 	// +optional
 	CertificatePath *string `json:"certificatePath,omitempty"`
 
 	// Contents of the client private key
 	PrivateKey ValueFromField `json:"privateKey"`
-	// Path where the client private key gets written
+	// Deprecated: keys are no longer written to disk, this attribute is
+	// ignored.
 	// +optional
 	PrivateKeyPath *string `json:"privateKeyPath,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+
+	pkgapis "knative.dev/pkg/apis"
+)
+
+// Validate implements apis.Validatable.
+func (s *AWSIoTSource) Validate(ctx context.Context) *pkgapis.FieldError {
+	return s.Spec.Validate(ctx).ViaField("spec")
+}
+
+// Validate implements apis.Validatable.
+func (s *AWSIoTSourceSpec) Validate(ctx context.Context) *pkgapis.FieldError {
+	errs := validateSink(ctx, &s.SourceSpec)
+
+	if s.Endpoint == "" {
+		errs = errs.Also(pkgapis.ErrMissingField("endpoint"))
+	}
+
+	errs = errs.Also(validateARN(s.ARN, serviceIoT, "").ViaField("arn"))
+
+	// the topic is inferred from the ARN when no topic filter is given
+	if len(s.Topics) == 0 && !strings.HasPrefix(s.ARN.Resource, "topic/") {
+		errs = errs.Also(pkgapis.ErrMissingField("topics"))
+	}
+	for i, t := range s.Topics {
+		if t == "" {
+			errs = errs.Also(invalidValue(t, "topic filter must not be empty").ViaFieldIndex("topics", i))
+		}
+	}
+
+	if s.RootCA != nil {
+		errs = errs.Also(s.RootCA.validate().ViaField("rootCA"))
+	}
+	errs = errs.Also(s.Certificate.validate().ViaField("certificate"))
+	errs = errs.Also(s.PrivateKey.validate().ViaField("privateKey"))
+
+	errs = errs.Also(s.AdapterOverrides.validate().ViaField("adapterOverrides"))
+
+	return errs
+}
@@ -20,6 +20,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/triggermesh/aws-event-sources/pkg/apis"
@@ -40,8 +41,12 @@ type AWSKinesisSource struct {
 
 // Check the interfaces the event source should be implementing.
 var (
-	_ runtime.Object = (*AWSKinesisSource)(nil)
-	_ EventSource    = (*AWSKinesisSource)(nil)
+	_ runtime.Object         = (*AWSKinesisSource)(nil)
+	_ EventSource            = (*AWSKinesisSource)(nil)
+	_ pkgapis.Validatable    = (*AWSKinesisSource)(nil)
+	_ pkgapis.Defaultable    = (*AWSKinesisSource)(nil)
+	_ pkgapis.Convertible    = (*AWSKinesisSource)(nil)
+	_ AWSAuthenticatedSource = (*AWSKinesisSource)(nil)
 )
 
 // AWSKinesisSourceSpec defines the desired state of the event source.
@@ -54,6 +59,21 @@ type AWSKinesisSourceSpec struct {
 
 	// Credentials to interact with the AWS Kinesis API.
 	Credentials AWSSecurityCredentials `json:"credentials"`
+
+	// Custom endpoint of the AWS Kinesis API, such as a VPC interface
+	// endpoint, a FIPS endpoint or a local emulator. Defaults to the public
+	// endpoint of the region of the ARN.
+	// +optional
+	Endpoint *AWSEndpoint `json:"endpoint,omitempty"`
+
+	// Runs multiple replicas of the adapter which coordinate through
+	// Kubernetes Leases.
+	// +optional
+	HighAvailability *HighAvailability `json:"highAvailability,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	pkgapis "knative.dev/pkg/apis"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1beta1"
+)
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSSNSSource) ConvertTo(ctx context.Context, to pkgapis.Convertible) error {
+	switch sink := to.(type) {
+	case *v1beta1.AWSSNSSource:
+		spec := s.Spec.DeepCopy()
+
+		sink.ObjectMeta = *s.ObjectMeta.DeepCopy()
+		sink.Spec = v1beta1.AWSSNSSourceSpec{
+			SourceSpec: spec.SourceSpec,
+			ARN:        spec.ARN,
+			Auth: v1beta1.AWSAuth{
+				Credentials: credentialsToV1beta1(&spec.Credentials),
+			},
+			Endpoint:               endpointToV1beta1(spec.Endpoint),
+			SubscriptionAttributes: spec.SubscriptionAttributes,
+			WorkloadKind:           v1beta1.WorkloadKind(spec.WorkloadKind),
+			Route:                  routeToV1beta1(spec.Route),
+			AdapterOverrides:       (*v1beta1.AdapterOverrides)(spec.AdapterOverrides),
+		}
+		sink.Status = snsStatusToV1beta1(&s.Status)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported conversion to %T", sink)
+	}
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSSNSSource) ConvertFrom(ctx context.Context, from pkgapis.Convertible) error {
+	switch source := from.(type) {
+	case *v1beta1.AWSSNSSource:
+		if source.Spec.Auth.ClientCertificate != nil {
+			return errUnsupportedAuth("AWSSNSSource", "a client certificate")
+		}
+
+		spec := source.Spec.DeepCopy()
+
+		s.ObjectMeta = *source.ObjectMeta.DeepCopy()
+		s.Spec = AWSSNSSourceSpec{
+			SourceSpec:             spec.SourceSpec,
+			ARN:                    spec.ARN,
+			Credentials:            credentialsFromV1beta1(spec.Auth.Credentials),
+			Endpoint:               endpointFromV1beta1(spec.Endpoint),
+			SubscriptionAttributes: spec.SubscriptionAttributes,
+			WorkloadKind:           WorkloadKind(spec.WorkloadKind),
+			Route:                  routeFromV1beta1(spec.Route),
+			AdapterOverrides:       (*AdapterOverrides)(spec.AdapterOverrides),
+		}
+		s.Status = snsStatusFromV1beta1(&source.Status)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported conversion from %T", source)
+	}
+}
+
+// snsStatusToV1beta1 converts the status of a v1alpha1 AWSSNSSource. The ARN
+// of the SNS subscription is stored in the status annotations in v1beta1.
+func snsStatusToV1beta1(s *AWSSNSSourceStatus) v1beta1.EventSourceStatus {
+	out := statusToV1beta1(&s.EventSourceStatus)
+
+	if arn := s.SubscriptionARN; arn != nil {
+		if out.Annotations == nil {
+			out.Annotations = make(map[string]string, 1)
+		}
+		out.Annotations[v1beta1.AWSSNSSourceSubscriptionARNStatusAnnotation] = *arn
+	}
+
+	return out
+}
+
+// snsStatusFromV1beta1 converts the status of a v1beta1 AWSSNSSource.
+func snsStatusFromV1beta1(s *v1beta1.EventSourceStatus) AWSSNSSourceStatus {
+	out := AWSSNSSourceStatus{
+		EventSourceStatus: statusFromV1beta1(s),
+	}
+
+	if arn, ok := out.Annotations[v1beta1.AWSSNSSourceSubscriptionARNStatusAnnotation]; ok {
+		out.SubscriptionARN = &arn
+
+		delete(out.Annotations, v1beta1.AWSSNSSourceSubscriptionARNStatusAnnotation)
+		if len(out.Annotations) == 0 {
+			out.Annotations = nil
+		}
+	}
+
+	return out
+}
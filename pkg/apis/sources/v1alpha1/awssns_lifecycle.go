@@ -19,8 +19,10 @@ package v1alpha1
 import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
-	"knative.dev/pkg/apis"
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
 )
 
 // GetGroupVersionKind implements kmeta.OwnerRefable.
@@ -34,7 +36,7 @@ func (s *AWSSNSSource) GetUntypedSpec() interface{} {
 }
 
 // GetConditionSet implements duckv1.KRShaped.
-func (s *AWSSNSSource) GetConditionSet() apis.ConditionSet {
+func (s *AWSSNSSource) GetConditionSet() pkgapis.ConditionSet {
 	return awsSNSSourceConditionSet
 }
 
@@ -56,6 +58,21 @@ func (s *AWSSNSSource) GetStatusManager() *EventSourceStatusManager {
 	}
 }
 
+// GetCredentials implements AWSAuthenticatedSource.
+func (s *AWSSNSSource) GetCredentials() *AWSSecurityCredentials {
+	return &s.Spec.Credentials
+}
+
+// GetEndpoint implements AWSAuthenticatedSource.
+func (s *AWSSNSSource) GetEndpoint() *AWSEndpoint {
+	return s.Spec.Endpoint
+}
+
+// GetARN implements AWSAuthenticatedSource.
+func (s *AWSSNSSource) GetARN() apis.ARN {
+	return s.Spec.ARN
+}
+
 // Supported event types
 const (
 	AWSSNSGenericEventType = "notification"
@@ -77,7 +94,7 @@ func (s *AWSSNSSource) AsEventSource() string {
 const (
 	// AWSSNSConditionSubscribed has status True when the event source's HTTP(S) endpoint has been subscribed to the
 	// SNS subscription.
-	AWSSNSConditionSubscribed apis.ConditionType = "Subscribed"
+	AWSSNSConditionSubscribed pkgapis.ConditionType = "Subscribed"
 )
 
 // Reasons for status conditions
@@ -86,6 +103,9 @@ const (
 	AWSSNSReasonNoURL = "MissingAdapterURL"
 	// AWSSNSReasonNoClient is set on a Subscribed condition when a SNS API client cannot be obtained.
 	AWSSNSReasonNoClient = "NoClient"
+	// AWSSNSReasonAmbientCredentials is set on a Subscribed condition when the controller is not allowed to
+	// manage subscriptions using the ambient credentials of the source.
+	AWSSNSReasonAmbientCredentials = "AmbientCredentialsDisallowed"
 	// AWSSNSReasonRejected is set on a Subscribed condition when the SNS API rejects a subscription request.
 	AWSSNSReasonRejected = "SubscriptionRejected"
 	// AWSSNSReasonFailedSync is set on a Subscribed condition when other synchronization errors occur.
@@ -94,6 +114,8 @@ const (
 
 // awsSNSSourceConditionSet is a set of conditions for AWSSNSSource objects.
 var awsSNSSourceConditionSet = NewEventSourceConditionSet(
+	ConditionCredentialsValid,
+	ConditionResourceReachable,
 	AWSSNSConditionSubscribed,
 )
 
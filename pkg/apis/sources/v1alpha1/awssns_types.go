@@ -41,9 +41,13 @@ type AWSSNSSource struct {
 
 // Check the interfaces the event source should be implementing.
 var (
-	_ runtime.Object  = (*AWSSNSSource)(nil)
-	_ pkgapis.HasSpec = (*AWSSNSSource)(nil)
-	_ EventSource     = (*AWSSNSSource)(nil)
+	_ runtime.Object         = (*AWSSNSSource)(nil)
+	_ pkgapis.HasSpec        = (*AWSSNSSource)(nil)
+	_ EventSource            = (*AWSSNSSource)(nil)
+	_ pkgapis.Validatable    = (*AWSSNSSource)(nil)
+	_ pkgapis.Defaultable    = (*AWSSNSSource)(nil)
+	_ pkgapis.Convertible    = (*AWSSNSSource)(nil)
+	_ AWSAuthenticatedSource = (*AWSSNSSource)(nil)
 )
 
 // AWSSNSSourceSpec defines the desired state of the event source.
@@ -63,6 +67,27 @@ type AWSSNSSourceSpec struct {
 
 	// Credentials to interact with the AWS SNS API.
 	Credentials AWSSecurityCredentials `json:"credentials"`
+
+	// Custom endpoint of the AWS SNS API, such as a VPC interface
+	// endpoint, a FIPS endpoint or a local emulator. Defaults to the public
+	// endpoint of the region of the ARN.
+	// +optional
+	Endpoint *AWSEndpoint `json:"endpoint,omitempty"`
+
+	// Kind of workload the adapter runs as. Defaults to the kind set in the
+	// configuration of the controller, KnativeService unless specified
+	// otherwise.
+	// +optional
+	WorkloadKind WorkloadKind `json:"workloadKind,omitempty"`
+
+	// Route of external traffic to the adapter, when it runs as a
+	// Deployment.
+	// +optional
+	Route *AdapterRoute `json:"route,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
 }
 
 // AWSSNSSourceStatus defines the observed state of the event source.
@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	pkgapis "knative.dev/pkg/apis"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1beta1"
+)
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSSQSSource) ConvertTo(ctx context.Context, to pkgapis.Convertible) error {
+	switch sink := to.(type) {
+	case *v1beta1.AWSSQSSource:
+		spec := s.Spec.DeepCopy()
+
+		sink.ObjectMeta = *s.ObjectMeta.DeepCopy()
+		sink.Spec = v1beta1.AWSSQSSourceSpec{
+			SourceSpec: spec.SourceSpec,
+			ARN:        spec.ARN,
+			Auth: v1beta1.AWSAuth{
+				Credentials: credentialsToV1beta1(&spec.Credentials),
+			},
+			Endpoint:            endpointToV1beta1(spec.Endpoint),
+			QueueURL:            spec.QueueURL,
+			DecodeNotifications: spec.DecodeNotifications,
+			Autoscaling:         (*v1beta1.AWSSQSSourceAutoscaling)(spec.Autoscaling),
+			AdapterOverrides:    (*v1beta1.AdapterOverrides)(spec.AdapterOverrides),
+		}
+		if spec.Batching != nil || spec.TerminationGracePeriodSeconds != nil {
+			sink.Spec.Delivery = &v1beta1.AWSSQSSourceDelivery{
+				TerminationGracePeriodSeconds: spec.TerminationGracePeriodSeconds,
+			}
+			if spec.Batching != nil {
+				b := v1beta1.AWSSQSSourceBatching(*spec.Batching)
+				sink.Spec.Delivery.Batching = &b
+			}
+		}
+		sink.Status = sqsStatusToV1beta1(&s.Status)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported conversion to %T", sink)
+	}
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSSQSSource) ConvertFrom(ctx context.Context, from pkgapis.Convertible) error {
+	switch source := from.(type) {
+	case *v1beta1.AWSSQSSource:
+		if source.Spec.Auth.ClientCertificate != nil {
+			return errUnsupportedAuth("AWSSQSSource", "a client certificate")
+		}
+
+		spec := source.Spec.DeepCopy()
+
+		s.ObjectMeta = *source.ObjectMeta.DeepCopy()
+		s.Spec = AWSSQSSourceSpec{
+			SourceSpec:          spec.SourceSpec,
+			ARN:                 spec.ARN,
+			QueueURL:            spec.QueueURL,
+			DecodeNotifications: spec.DecodeNotifications,
+			Credentials:         credentialsFromV1beta1(spec.Auth.Credentials),
+			Endpoint:            endpointFromV1beta1(spec.Endpoint),
+			Autoscaling:         (*AWSSQSSourceAutoscaling)(spec.Autoscaling),
+			AdapterOverrides:    (*AdapterOverrides)(spec.AdapterOverrides),
+		}
+		if d := spec.Delivery; d != nil {
+			s.Spec.TerminationGracePeriodSeconds = d.TerminationGracePeriodSeconds
+			if d.Batching != nil {
+				b := AWSSQSSourceBatching(*d.Batching)
+				s.Spec.Batching = &b
+			}
+		}
+		s.Status = sqsStatusFromV1beta1(&source.Status)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported conversion from %T", source)
+	}
+}
+
+// sqsStatusToV1beta1 converts the status of a v1alpha1 AWSSQSSource.
+func sqsStatusToV1beta1(s *AWSSQSSourceStatus) v1beta1.AWSSQSSourceStatus {
+	out := v1beta1.AWSSQSSourceStatus{
+		EventSourceStatus: statusToV1beta1(&s.EventSourceStatus),
+	}
+
+	if a := s.Autoscaling; a != nil {
+		out.Autoscaling = (*v1beta1.AWSSQSSourceAutoscalingStatus)(a.DeepCopy())
+	}
+
+	return out
+}
+
+// sqsStatusFromV1beta1 converts the status of a v1beta1 AWSSQSSource.
+func sqsStatusFromV1beta1(s *v1beta1.AWSSQSSourceStatus) AWSSQSSourceStatus {
+	out := AWSSQSSourceStatus{
+		EventSourceStatus: statusFromV1beta1(&s.EventSourceStatus),
+	}
+
+	if a := s.Autoscaling; a != nil {
+		out.Autoscaling = (*AWSSQSSourceAutoscalingStatus)(a.DeepCopy())
+	}
+
+	return out
+}
@@ -0,0 +1,43 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import "context"
+
+// Default values of AWSSQSSource attributes.
+const (
+	defaultSQSTerminationGracePeriodSeconds = 30
+	defaultSQSBatchMaxWaitMilliseconds      = 1000
+	defaultSQSAutoscalingMinReplicas        = 1
+)
+
+// SetDefaults implements apis.Defaultable.
+func (s *AWSSQSSource) SetDefaults(ctx context.Context) {
+	if s.Spec.TerminationGracePeriodSeconds == nil {
+		secs := int64(defaultSQSTerminationGracePeriodSeconds)
+		s.Spec.TerminationGracePeriodSeconds = &secs
+	}
+
+	if b := s.Spec.Batching; b != nil && b.MaxWaitMilliseconds == 0 {
+		b.MaxWaitMilliseconds = defaultSQSBatchMaxWaitMilliseconds
+	}
+
+	if a := s.Spec.Autoscaling; a != nil && a.MinReplicas == nil {
+		min := int32(defaultSQSAutoscalingMinReplicas)
+		a.MinReplicas = &min
+	}
+}
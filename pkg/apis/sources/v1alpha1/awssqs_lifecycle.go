@@ -19,8 +19,10 @@ package v1alpha1
 import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
-	"knative.dev/pkg/apis"
+	pkgapis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
 )
 
 // GetGroupVersionKind implements kmeta.OwnerRefable.
@@ -29,8 +31,8 @@ func (s *AWSSQSSource) GetGroupVersionKind() schema.GroupVersionKind {
 }
 
 // GetConditionSet implements duckv1.KRShaped.
-func (s *AWSSQSSource) GetConditionSet() apis.ConditionSet {
-	return eventSourceConditionSet
+func (s *AWSSQSSource) GetConditionSet() pkgapis.ConditionSet {
+	return awsEventSourceConditionSet
 }
 
 // GetStatus implements duckv1.KRShaped.
@@ -47,10 +49,25 @@ func (s *AWSSQSSource) GetSink() *duckv1.Destination {
 func (s *AWSSQSSource) GetStatusManager() *EventSourceStatusManager {
 	return &EventSourceStatusManager{
 		ConditionSet:      s.GetConditionSet(),
-		EventSourceStatus: &s.Status,
+		EventSourceStatus: &s.Status.EventSourceStatus,
 	}
 }
 
+// GetCredentials implements AWSAuthenticatedSource.
+func (s *AWSSQSSource) GetCredentials() *AWSSecurityCredentials {
+	return &s.Spec.Credentials
+}
+
+// GetEndpoint implements AWSAuthenticatedSource.
+func (s *AWSSQSSource) GetEndpoint() *AWSEndpoint {
+	return s.Spec.Endpoint
+}
+
+// GetARN implements AWSAuthenticatedSource.
+func (s *AWSSQSSource) GetARN() apis.ARN {
+	return s.Spec.ARN
+}
+
 // Supported event types
 const (
 	AWSSQSGenericEventType = "message"
@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	pkgapis "knative.dev/pkg/apis"
+)
+
+// Validate implements apis.Validatable.
+func (s *AWSSQSSource) Validate(ctx context.Context) *pkgapis.FieldError {
+	return s.Spec.Validate(ctx).ViaField("spec")
+}
+
+// Validate implements apis.Validatable.
+func (s *AWSSQSSourceSpec) Validate(ctx context.Context) *pkgapis.FieldError {
+	errs := validateSink(ctx, &s.SourceSpec)
+	errs = errs.Also(validateARN(s.ARN, serviceSQS, "").ViaField("arn"))
+
+	if s.QueueURL != "" {
+		errs = errs.Also(validateURL(s.QueueURL).ViaField("queueURL"))
+	}
+
+	if b := s.Batching; b != nil {
+		if b.MaxSize < 1 {
+			errs = errs.Also(invalidValue(b.MaxSize, "must be greater than 0").ViaField("maxSize").ViaField("batching"))
+		}
+		if b.MaxWaitMilliseconds < 0 {
+			errs = errs.Also(invalidValue(b.MaxWaitMilliseconds, "must not be negative").
+				ViaField("maxWaitMilliseconds").ViaField("batching"))
+		}
+	}
+
+	if secs := s.TerminationGracePeriodSeconds; secs != nil && *secs < 0 {
+		errs = errs.Also(invalidValue(*secs, "must not be negative").ViaField("terminationGracePeriodSeconds"))
+	}
+
+	if a := s.Autoscaling; a != nil {
+		errs = errs.Also(a.validate().ViaField("autoscaling"))
+	}
+
+	errs = errs.Also(validateAWSAccess(&s.Credentials, s.Endpoint))
+
+	errs = errs.Also(s.AdapterOverrides.validate().ViaField("adapterOverrides"))
+
+	return errs
+}
+
+// validate ensures the autoscaling bounds and target are consistent.
+func (a *AWSSQSSourceAutoscaling) validate() *pkgapis.FieldError {
+	var errs *pkgapis.FieldError
+
+	if a.MaxReplicas < 1 {
+		errs = errs.Also(invalidValue(a.MaxReplicas, "must be greater than 0").ViaField("maxReplicas"))
+	}
+
+	if min := a.MinReplicas; min != nil {
+		switch {
+		case *min < 0:
+			errs = errs.Also(invalidValue(*min, "must not be negative").ViaField("minReplicas"))
+		case *min > a.MaxReplicas:
+			errs = errs.Also(invalidValue(*min, "must not be greater than maxReplicas").ViaField("minReplicas"))
+		}
+	}
+
+	if a.TargetBacklog < 1 {
+		errs = errs.Also(invalidValue(a.TargetBacklog, "must be greater than 0").ViaField("targetBacklog"))
+	}
+
+	return errs
+}
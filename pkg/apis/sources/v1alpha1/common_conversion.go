@@ -0,0 +1,239 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources"
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1beta1"
+)
+
+// The v1alpha1 API version is the hub of all conversions between versions of
+// event sources: objects of other versions are converted to and from
+// v1alpha1, which is also their storage version.
+
+// deprecatedFieldsAnnotation is the annotation in which attributes of
+// v1alpha1 objects that don't exist in later API versions are preserved
+// during conversions, so that objects can be round-tripped without loss.
+const deprecatedFieldsAnnotation = sources.GroupName + "/v1alpha1-deprecated-fields"
+
+// setDeprecatedFields records the given deprecated fields in the annotations
+// of an object of another API version. Nothing is recorded if none of the
+// fields is set.
+func setDeprecatedFields(meta *metav1.ObjectMeta, fields interface{}) error {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("serializing deprecated fields: %w", err)
+	}
+	if string(data) == "{}" {
+		return nil
+	}
+
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string, 1)
+	}
+	meta.Annotations[deprecatedFieldsAnnotation] = string(data)
+
+	return nil
+}
+
+// popDeprecatedFields reads the deprecated fields recorded in the given
+// annotations by setDeprecatedFields into fields, and removes them from the
+// annotations.
+func popDeprecatedFields(meta *metav1.ObjectMeta, fields interface{}) error {
+	data, ok := meta.Annotations[deprecatedFieldsAnnotation]
+	if !ok {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(data), fields); err != nil {
+		return fmt.Errorf("deserializing deprecated fields: %w", err)
+	}
+
+	delete(meta.Annotations, deprecatedFieldsAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+
+	return nil
+}
+
+// errUnsupportedAuth returns an error indicating that the given kind of
+// event source doesn't support the given authentication method.
+func errUnsupportedAuth(kind, method string) error {
+	return fmt.Errorf("%s does not support authentication with %s", kind, method)
+}
+
+func statusToV1beta1(s *EventSourceStatus) v1beta1.EventSourceStatus {
+	return v1beta1.EventSourceStatus(*s.DeepCopy())
+}
+
+func statusFromV1beta1(s *v1beta1.EventSourceStatus) EventSourceStatus {
+	return EventSourceStatus(*s.DeepCopy())
+}
+
+// credentialsToV1beta1 returns nil for empty credentials, which are
+// represented as an absent attribute in v1beta1.
+func credentialsToV1beta1(c *AWSSecurityCredentials) *v1beta1.AWSSecurityCredentials {
+	if *c == (AWSSecurityCredentials{}) {
+		return nil
+	}
+
+	out := &v1beta1.AWSSecurityCredentials{
+		AccessKeyID:     valueFromFieldPtrToV1beta1(c.AccessKeyID),
+		SecretAccessKey: valueFromFieldPtrToV1beta1(c.SecretAccessKey),
+		Ambient:         c.Ambient,
+	}
+	if c.WebIdentity != nil {
+		wi := v1beta1.AWSWebIdentity(*c.WebIdentity)
+		out.WebIdentity = &wi
+	}
+	if c.AssumeRole != nil {
+		ar := v1beta1.AWSAssumeRole(*c.AssumeRole)
+		out.AssumeRole = &ar
+	}
+
+	return out
+}
+
+func credentialsFromV1beta1(c *v1beta1.AWSSecurityCredentials) AWSSecurityCredentials {
+	if c == nil {
+		return AWSSecurityCredentials{}
+	}
+
+	out := AWSSecurityCredentials{
+		AccessKeyID:     valueFromFieldPtrFromV1beta1(c.AccessKeyID),
+		SecretAccessKey: valueFromFieldPtrFromV1beta1(c.SecretAccessKey),
+		Ambient:         c.Ambient,
+	}
+	if c.WebIdentity != nil {
+		wi := AWSWebIdentity(*c.WebIdentity)
+		out.WebIdentity = &wi
+	}
+	if c.AssumeRole != nil {
+		ar := AWSAssumeRole(*c.AssumeRole)
+		out.AssumeRole = &ar
+	}
+
+	return out
+}
+
+func endpointToV1beta1(e *AWSEndpoint) *v1beta1.AWSEndpoint {
+	if e == nil {
+		return nil
+	}
+	e = e.DeepCopy()
+	return &v1beta1.AWSEndpoint{
+		URL:         e.URL,
+		ServiceURLs: e.ServiceURLs,
+		CABundle:    valueFromFieldPtrToV1beta1(e.CABundle),
+		PathStyle:   e.PathStyle,
+	}
+}
+
+func endpointFromV1beta1(e *v1beta1.AWSEndpoint) *AWSEndpoint {
+	if e == nil {
+		return nil
+	}
+	e = e.DeepCopy()
+	return &AWSEndpoint{
+		URL:         e.URL,
+		ServiceURLs: e.ServiceURLs,
+		CABundle:    valueFromFieldPtrFromV1beta1(e.CABundle),
+		PathStyle:   e.PathStyle,
+	}
+}
+
+func redactionToV1beta1(p *CognitoRedactionPolicy) *v1beta1.CognitoRedactionPolicy {
+	if p == nil {
+		return nil
+	}
+	p = p.DeepCopy()
+	return &v1beta1.CognitoRedactionPolicy{
+		Drop:    p.Drop,
+		Hash:    p.Hash,
+		HashKey: valueFromFieldPtrToV1beta1(p.HashKey),
+		Mask:    p.Mask,
+	}
+}
+
+func redactionFromV1beta1(p *v1beta1.CognitoRedactionPolicy) *CognitoRedactionPolicy {
+	if p == nil {
+		return nil
+	}
+	p = p.DeepCopy()
+	return &CognitoRedactionPolicy{
+		Drop:    p.Drop,
+		Hash:    p.Hash,
+		HashKey: valueFromFieldPtrFromV1beta1(p.HashKey),
+		Mask:    p.Mask,
+	}
+}
+
+func valueFromFieldToV1beta1(f *ValueFromField) v1beta1.ValueFromField {
+	return v1beta1.ValueFromField(*f.DeepCopy())
+}
+
+func valueFromFieldFromV1beta1(f *v1beta1.ValueFromField) ValueFromField {
+	return ValueFromField(*f.DeepCopy())
+}
+
+func valueFromFieldPtrToV1beta1(f *ValueFromField) *v1beta1.ValueFromField {
+	if f == nil {
+		return nil
+	}
+	out := valueFromFieldToV1beta1(f)
+	return &out
+}
+
+func valueFromFieldPtrFromV1beta1(f *v1beta1.ValueFromField) *ValueFromField {
+	if f == nil {
+		return nil
+	}
+	out := valueFromFieldFromV1beta1(f)
+	return &out
+}
+
+func routeToV1beta1(r *AdapterRoute) *v1beta1.AdapterRoute {
+	if r == nil {
+		return nil
+	}
+	r = r.DeepCopy()
+	return &v1beta1.AdapterRoute{
+		Kind:             v1beta1.RouteKind(r.Kind),
+		Host:             r.Host,
+		IngressClassName: r.IngressClassName,
+		Gateway:          (*v1beta1.GatewayReference)(r.Gateway),
+	}
+}
+
+func routeFromV1beta1(r *v1beta1.AdapterRoute) *AdapterRoute {
+	if r == nil {
+		return nil
+	}
+	r = r.DeepCopy()
+	return &AdapterRoute{
+		Kind:             RouteKind(r.Kind),
+		Host:             r.Host,
+		IngressClassName: r.IngressClassName,
+		Gateway:          (*GatewayReference)(r.Gateway),
+	}
+}
@@ -52,6 +52,13 @@ func NewEventSourceConditionSet(cts ...apis.ConditionType) apis.ConditionSet {
 	)
 }
 
+// awsEventSourceConditionSet is a set of status conditions for event sources
+// which interact with AWS APIs using AWS security credentials.
+var awsEventSourceConditionSet = NewEventSourceConditionSet(
+	ConditionCredentialsValid,
+	ConditionResourceReachable,
+)
+
 // eventSourceConditionTypes is a list of condition types common to all event
 // sources.
 var eventSourceConditionTypes = []apis.ConditionType{
@@ -85,6 +92,63 @@ func (m *EventSourceStatusManager) MarkNoSink() {
 		ReasonSinkNotFound, "The sink does not exist or its URI is not set")
 }
 
+// MarkCredentialsValid sets the CredentialsValid condition to True.
+func (m *EventSourceStatusManager) MarkCredentialsValid() {
+	m.ConditionSet.Manage(m).MarkTrue(ConditionCredentialsValid)
+}
+
+// MarkCredentialsInvalid sets the CredentialsValid condition to False with
+// the given reason and message.
+func (m *EventSourceStatusManager) MarkCredentialsInvalid(reason, msg string) {
+	m.ConditionSet.Manage(m).MarkFalse(ConditionCredentialsValid, reason, msg)
+}
+
+// MarkCredentialsUnknown sets the CredentialsValid condition to Unknown with
+// the given reason and message.
+func (m *EventSourceStatusManager) MarkCredentialsUnknown(reason, msg string) {
+	m.ConditionSet.Manage(m).MarkUnknown(ConditionCredentialsValid, reason, msg)
+}
+
+// MarkResourceReachable sets the ResourceReachable condition to True.
+func (m *EventSourceStatusManager) MarkResourceReachable() {
+	m.ConditionSet.Manage(m).MarkTrue(ConditionResourceReachable)
+}
+
+// MarkResourceUnreachable sets the ResourceReachable condition to False with
+// the given reason and message.
+func (m *EventSourceStatusManager) MarkResourceUnreachable(reason, msg string) {
+	m.ConditionSet.Manage(m).MarkFalse(ConditionResourceReachable, reason, msg)
+}
+
+// MarkResourceUnknown sets the ResourceReachable condition to Unknown with
+// the given reason and message.
+func (m *EventSourceStatusManager) MarkResourceUnknown(reason, msg string) {
+	m.ConditionSet.Manage(m).MarkUnknown(ConditionResourceReachable, reason, msg)
+}
+
+// MarkAccessUnchecked sets the CredentialsValid and ResourceReachable
+// conditions to True with the CheckSkipped reason and the given message, for
+// sources whose access to AWS can not be checked by the controller.
+func (m *EventSourceStatusManager) MarkAccessUnchecked(msg string) {
+	m.ConditionSet.Manage(m).MarkTrueWithReason(ConditionCredentialsValid, ReasonCheckSkipped, msg)
+	m.ConditionSet.Manage(m).MarkTrueWithReason(ConditionResourceReachable, ReasonCheckSkipped, msg)
+}
+
+// MarkNotDeployed sets the Deployed condition to False with the given reason
+// and message.
+func (m *EventSourceStatusManager) MarkNotDeployed(reason, msg string) {
+	m.ConditionSet.Manage(m).MarkFalse(ConditionDeployed, reason, msg)
+}
+
+// SetAddress sets the URL of the Addressable status of the event source.
+func (m *EventSourceStatusManager) SetAddress(url *apis.URL) {
+	if url == nil {
+		m.Address = nil
+		return
+	}
+	m.Address = &duckv1.Addressable{URL: url}
+}
+
 // PropagateDeploymentAvailability uses the readiness of the provided
 // Deployment to determine whether the Deployed condition should be marked as
 // True or False.
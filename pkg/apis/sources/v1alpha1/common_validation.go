@@ -0,0 +1,378 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	pkgapis "knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+)
+
+// Names of AWS services, as they appear in ARNs.
+// https://docs.aws.amazon.com/general/latest/gr/aws-arns-and-namespaces.html
+const (
+	serviceCodeCommit      = "codecommit"
+	serviceCognitoIdentity = "cognito-identity"
+	serviceCognitoUserPool = "cognito-idp"
+	serviceDynamoDB        = "dynamodb"
+	serviceIAM             = "iam"
+	serviceIoT             = "iot"
+	serviceKinesis         = "kinesis"
+	serviceSNS             = "sns"
+	serviceSQS             = "sqs"
+)
+
+// Expected format of the resource segment of IAM role ARNs.
+const iamRoleResourceFormat = "role/${RoleNameWithPath}"
+
+// Valid characters of IAM role session names.
+// https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRole.html
+var roleSessionNameRegexp = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+// validateARN ensures the given ARN refers to a resource of the given AWS
+// service. If resourceFormat is not empty, the resource segment of the ARN
+// must match that format (see apis.ParseResource), otherwise it must only be
+// non empty.
+func validateARN(a apis.ARN, service, resourceFormat string) *pkgapis.FieldError {
+	if a.Service != service {
+		return invalidValue(a, "expected an ARN of the AWS service "+service)
+	}
+
+	// IAM is a global service, all other services are regional
+	if a.Region == "" && service != serviceIAM {
+		return invalidValue(a, "ARN must include a region")
+	}
+
+	if resourceFormat == "" {
+		if a.Resource == "" {
+			return invalidValue(a, "ARN must include a resource")
+		}
+		return nil
+	}
+
+	if _, err := apis.ParseResource(a.Resource, resourceFormat); err != nil {
+		return invalidValue(a, err.Error())
+	}
+
+	return nil
+}
+
+// validateRoleARN ensures the given ARN refers to an IAM role.
+func validateRoleARN(a apis.ARN) *pkgapis.FieldError {
+	if a.Service != serviceIAM || !strings.HasPrefix(a.Resource, "role/") {
+		return invalidValue(a, "expected an ARN matching the format "+
+			"arn:${Partition}:iam::${Account}:"+iamRoleResourceFormat)
+	}
+	return nil
+}
+
+// validate ensures the AWS security credentials use exactly one
+// authentication method, and that this method is correctly configured.
+func (c *AWSSecurityCredentials) validate() *pkgapis.FieldError {
+	const (
+		fieldAccessKey   = "accessKeyID"
+		fieldWebIdentity = "webIdentity"
+		fieldAmbient     = "ambient"
+	)
+
+	var methods []string
+	if c.AccessKeyID != nil || c.SecretAccessKey != nil {
+		methods = append(methods, fieldAccessKey)
+	}
+	if c.WebIdentity != nil {
+		methods = append(methods, fieldWebIdentity)
+	}
+	if c.Ambient {
+		methods = append(methods, fieldAmbient)
+	}
+
+	switch len(methods) {
+	case 0:
+		return pkgapis.ErrMissingOneOf(fieldAccessKey, fieldWebIdentity, fieldAmbient)
+	case 1:
+	default:
+		return pkgapis.ErrMultipleOneOf(methods...)
+	}
+
+	var errs *pkgapis.FieldError
+
+	if methods[0] == fieldAccessKey {
+		if c.AccessKeyID == nil {
+			errs = errs.Also(pkgapis.ErrMissingField("accessKeyID"))
+		} else {
+			errs = errs.Also(c.AccessKeyID.validate().ViaField("accessKeyID"))
+		}
+
+		if c.SecretAccessKey == nil {
+			errs = errs.Also(pkgapis.ErrMissingField("secretAccessKey"))
+		} else {
+			errs = errs.Also(c.SecretAccessKey.validate().ViaField("secretAccessKey"))
+		}
+	}
+
+	if wi := c.WebIdentity; wi != nil {
+		errs = errs.Also(validateRoleARN(wi.RoleARN).ViaField("roleARN").ViaField("webIdentity"))
+	}
+
+	if ar := c.AssumeRole; ar != nil {
+		errs = errs.Also(validateRoleARN(ar.RoleARN).ViaField("roleARN").ViaField("assumeRole"))
+
+		if ar.SessionName != "" && !roleSessionNameRegexp.MatchString(ar.SessionName) {
+			errs = errs.Also(invalidValue(ar.SessionName, "must match the pattern "+roleSessionNameRegexp.String()).
+				ViaField("sessionName").ViaField("assumeRole"))
+		}
+	}
+
+	return errs
+}
+
+// validate ensures the endpoint has a valid URL and CA bundle.
+func (e *AWSEndpoint) validate() *pkgapis.FieldError {
+	var errs *pkgapis.FieldError
+
+	if e.URL == "" {
+		errs = errs.Also(pkgapis.ErrMissingField("url"))
+	} else {
+		errs = errs.Also(validateURL(e.URL).ViaField("url"))
+	}
+
+	for id, u := range e.ServiceURLs {
+		if id == "" {
+			errs = errs.Also(invalidKey(id, "expected the ID of an AWS service endpoint").ViaField("serviceURLs"))
+			continue
+		}
+		errs = errs.Also(validateURL(u).ViaFieldKey("serviceURLs", id))
+	}
+
+	if e.CABundle != nil {
+		errs = errs.Also(e.CABundle.validate().ViaField("caBundle"))
+	}
+
+	return errs
+}
+
+// validate ensures the field has either a literal value or a reference to a
+// Secret key, but not both.
+func (f *ValueFromField) validate() *pkgapis.FieldError {
+	switch {
+	case f.Value != "" && f.ValueFromSecret != nil:
+		return pkgapis.ErrMultipleOneOf("value", "valueFromSecret")
+	case f.Value == "" && f.ValueFromSecret == nil:
+		return pkgapis.ErrMissingOneOf("value", "valueFromSecret")
+	}
+
+	var errs *pkgapis.FieldError
+
+	if vfs := f.ValueFromSecret; vfs != nil {
+		if vfs.Name == "" {
+			errs = errs.Also(pkgapis.ErrMissingField("name").ViaField("valueFromSecret"))
+		}
+		if vfs.Key == "" {
+			errs = errs.Also(pkgapis.ErrMissingField("key").ViaField("valueFromSecret"))
+		}
+	}
+
+	return errs
+}
+
+// validate ensures the redaction policy can be applied.
+func (p *CognitoRedactionPolicy) validate() *pkgapis.FieldError {
+	var errs *pkgapis.FieldError
+
+	if len(p.Hash) > 0 && p.HashKey == nil {
+		errs = errs.Also(pkgapis.ErrMissingField("hashKey"))
+	}
+	if p.HashKey != nil {
+		errs = errs.Also(p.HashKey.validate().ViaField("hashKey"))
+	}
+
+	errs = errs.Also(validateAttributeNames(p.Drop).ViaField("drop"))
+	errs = errs.Also(validateAttributeNames(p.Hash).ViaField("hash"))
+	errs = errs.Also(validateAttributeNames(p.Mask).ViaField("mask"))
+
+	return errs
+}
+
+// validateAttributeNames ensures none of the given attribute names is empty.
+func validateAttributeNames(attrs []string) *pkgapis.FieldError {
+	var errs *pkgapis.FieldError
+
+	for i, attr := range attrs {
+		if attr == "" {
+			errs = errs.Also(pkgapis.ErrInvalidValue(attr, pkgapis.CurrentField).ViaIndex(i))
+		}
+	}
+
+	return errs
+}
+
+// Prefix of the label keys reserved to the controller, which selects the
+// adapter's Pods based on these labels.
+const reservedLabelPrefix = "app.kubernetes.io/"
+
+// validate ensures the adapter overrides contain valid labels and annotations.
+func (o *AdapterOverrides) validate() *pkgapis.FieldError {
+	if o == nil {
+		return nil
+	}
+
+	var errs *pkgapis.FieldError
+
+	for k, v := range o.Labels {
+		if strings.HasPrefix(k, reservedLabelPrefix) {
+			errs = errs.Also(invalidKey(k, "the prefix "+reservedLabelPrefix+" is reserved").ViaField("labels"))
+			continue
+		}
+		if msgs := validation.IsQualifiedName(k); len(msgs) > 0 {
+			errs = errs.Also(invalidKey(k, strings.Join(msgs, "; ")).ViaField("labels"))
+		}
+		if msgs := validation.IsValidLabelValue(v); len(msgs) > 0 {
+			errs = errs.Also(invalidValue(v, strings.Join(msgs, "; ")).ViaKey(k).ViaField("labels"))
+		}
+	}
+
+	for k := range o.Annotations {
+		if msgs := validation.IsQualifiedName(strings.ToLower(k)); len(msgs) > 0 {
+			errs = errs.Also(invalidKey(k, strings.Join(msgs, "; ")).ViaField("annotations"))
+		}
+	}
+
+	return errs
+}
+
+// validate ensures the high availability settings are valid.
+func (h *HighAvailability) validate() *pkgapis.FieldError {
+	if h == nil {
+		return nil
+	}
+
+	if r := h.Replicas; r != nil && *r < 1 {
+		return invalidValue(*r, "must be greater than 0").ViaField("replicas")
+	}
+
+	return nil
+}
+
+// validateWorkload ensures the kind of workload of an adapter is supported,
+// and that its route, if any, applies to that kind of workload.
+func validateWorkload(kind WorkloadKind, r *AdapterRoute) *pkgapis.FieldError {
+	var errs *pkgapis.FieldError
+
+	switch kind {
+	case "", WorkloadKindDeployment:
+	case WorkloadKindKnativeService:
+		if r != nil {
+			errs = errs.Also(pkgapis.ErrDisallowedFields("route").
+				ViaFieldKey("workloadKind", string(kind)))
+		}
+	default:
+		errs = errs.Also(invalidValue(kind, "expected one of "+
+			string(WorkloadKindKnativeService)+", "+string(WorkloadKindDeployment)).ViaField("workloadKind"))
+	}
+
+	if r != nil {
+		errs = errs.Also(r.validate().ViaField("route"))
+	}
+
+	return errs
+}
+
+// validate ensures the route has a supported kind and only sets the
+// attributes which apply to that kind.
+func (r *AdapterRoute) validate() *pkgapis.FieldError {
+	var errs *pkgapis.FieldError
+
+	switch r.Kind {
+	case RouteKindIngress:
+		if r.Gateway != nil {
+			errs = errs.Also(pkgapis.ErrDisallowedFields("gateway"))
+		}
+	case RouteKindHTTPRoute:
+		if r.IngressClassName != nil {
+			errs = errs.Also(pkgapis.ErrDisallowedFields("ingressClassName"))
+		}
+		if r.Gateway == nil {
+			errs = errs.Also(pkgapis.ErrMissingField("gateway"))
+		} else if r.Gateway.Name == "" {
+			errs = errs.Also(pkgapis.ErrMissingField("name").ViaField("gateway"))
+		}
+	case "":
+		errs = errs.Also(pkgapis.ErrMissingField("kind"))
+	default:
+		errs = errs.Also(invalidValue(r.Kind, "expected one of "+
+			string(RouteKindIngress)+", "+string(RouteKindHTTPRoute)).ViaField("kind"))
+	}
+
+	if r.Host != "" {
+		if msgs := validation.IsDNS1123Subdomain(r.Host); len(msgs) > 0 {
+			errs = errs.Also(invalidValue(r.Host, strings.Join(msgs, "; ")).ViaField("host"))
+		}
+	}
+
+	return errs
+}
+
+// validateAWSAccess ensures the parameters used to access the AWS API on
+// behalf of a source are valid.
+func validateAWSAccess(creds *AWSSecurityCredentials, ep *AWSEndpoint) *pkgapis.FieldError {
+	errs := creds.validate().ViaField("credentials")
+
+	if ep != nil {
+		errs = errs.Also(ep.validate().ViaField("endpoint"))
+	}
+
+	return errs
+}
+
+// validateSink ensures the sink of a source is a valid destination.
+func validateSink(ctx context.Context, s *duckv1.SourceSpec) *pkgapis.FieldError {
+	return s.Sink.Validate(ctx).ViaField("sink")
+}
+
+// validateURL ensures the given string is an absolute HTTP(S) URL.
+func validateURL(s string) *pkgapis.FieldError {
+	u, err := url.Parse(s)
+	if err != nil {
+		return invalidValue(s, err.Error())
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return invalidValue(s, "expected an absolute HTTP(S) URL")
+	}
+	return nil
+}
+
+// invalidValue returns a FieldError for an invalid value of the current
+// field, with the given details.
+func invalidValue(value interface{}, details string) *pkgapis.FieldError {
+	err := pkgapis.ErrInvalidValue(value, pkgapis.CurrentField)
+	err.Details = details
+	return err
+}
+
+// invalidKey returns a FieldError for an invalid key of the current map
+// field, with the given details.
+func invalidKey(key, details string) *pkgapis.FieldError {
+	return pkgapis.ErrInvalidKeyName(key, pkgapis.CurrentField, details)
+}
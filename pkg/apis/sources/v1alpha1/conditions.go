@@ -28,6 +28,11 @@ const (
 	ConditionSinkProvided apis.ConditionType = "SinkProvided"
 	// ConditionDeployed has status True when the source's adapter is up and running.
 	ConditionDeployed apis.ConditionType = "Deployed"
+	// ConditionCredentialsValid has status True when the source's AWS security credentials were accepted by AWS.
+	ConditionCredentialsValid apis.ConditionType = "CredentialsValid"
+	// ConditionResourceReachable has status True when the AWS resource observed by the source exists and is
+	// accessible with the source's AWS security credentials.
+	ConditionResourceReachable apis.ConditionType = "ResourceReachable"
 )
 
 // Reasons for status conditions
@@ -39,4 +44,22 @@ const (
 
 	// ReasonUnavailable is set on a Deployed condition when an adapter in unavailable.
 	ReasonUnavailable = "AdapterUnavailable"
+	// ReasonUnsupportedWorkload is set on a Deployed condition when an adapter's kind of workload can not
+	// be run in the cluster.
+	ReasonUnsupportedWorkload = "UnsupportedWorkloadKind"
+
+	// ReasonInvalidCredentials is set on a CredentialsValid condition when AWS rejects the source's credentials.
+	ReasonInvalidCredentials = "InvalidCredentials"
+	// ReasonAccessDenied is set on a CredentialsValid or ResourceReachable condition when the source's
+	// credentials are not authorized to perform a request.
+	ReasonAccessDenied = "AccessDenied"
+	// ReasonNotFound is set on a ResourceReachable condition when the AWS resource observed by the source
+	// does not exist.
+	ReasonNotFound = "NotFound"
+	// ReasonCheckFailed is set on a CredentialsValid or ResourceReachable condition when a check could not be
+	// completed, e.g. due to a network error.
+	ReasonCheckFailed = "CheckFailed"
+	// ReasonCheckSkipped is set on a CredentialsValid or ResourceReachable condition when the controller is not
+	// allowed to perform a check on behalf of the source.
+	ReasonCheckSkipped = "CheckSkipped"
 )
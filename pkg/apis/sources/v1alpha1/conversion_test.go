@@ -0,0 +1,313 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pkgapis "knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1beta1"
+)
+
+func TestConversionRoundTrip(t *testing.T) {
+	testCases := map[string]struct {
+		hub     pkgapis.Convertible
+		spoke   pkgapis.Convertible
+		newHub  func() pkgapis.Convertible
+		checkV1 func(*testing.T, pkgapis.Convertible)
+	}{
+		"CodeCommit with deprecated branch": {
+			hub: &AWSCodeCommitSource{
+				ObjectMeta: tObjectMeta(),
+				Spec: AWSCodeCommitSourceSpec{
+					SourceSpec:  tSourceSpec(),
+					ARN:         tARN("codecommit", "my-repo"),
+					Branch:      "main",
+					Branches:    []string{"release-*"},
+					Tags:        []string{"v*"},
+					EventTypes:  []string{"push", "pull_request"},
+					Credentials: tCredentials(),
+				},
+				Status: tStatus(),
+			},
+			spoke:  &v1beta1.AWSCodeCommitSource{},
+			newHub: func() pkgapis.Convertible { return &AWSCodeCommitSource{} },
+			checkV1: func(t *testing.T, o pkgapis.Convertible) {
+				src := o.(*v1beta1.AWSCodeCommitSource)
+				assert.Equal(t, []string{"main", "release-*"}, src.Spec.Branches)
+				assert.Equal(t, []string{"v*"}, src.Spec.Tags)
+				assert.Equal(t, []v1beta1.AWSCodeCommitEventType{
+					v1beta1.AWSCodeCommitPushEventType,
+					v1beta1.AWSCodeCommitPullRequestEventType,
+				}, src.Spec.EventTypes)
+				assert.NotNil(t, src.Spec.Auth.Credentials)
+				assert.Contains(t, src.Annotations, deprecatedFieldsAnnotation)
+			},
+		},
+		"IoT with deprecated paths": {
+			hub: &AWSIoTSource{
+				ObjectMeta: tObjectMeta(),
+				Spec: AWSIoTSourceSpec{
+					SourceSpec:      tSourceSpec(),
+					Endpoint:        "xxx-ats.iot.eu-central-1.amazonaws.com",
+					ARN:             tARN("iot", "topic/my-topic"),
+					Topics:          []string{"my-topic"},
+					Certificate:     ValueFromField{Value: "cert"},
+					PrivateKey:      tSecretValue("key"),
+					CertificatePath: ptrString("/certs/cert.pem"),
+				},
+				Status: tStatus(),
+			},
+			spoke:  &v1beta1.AWSIoTSource{},
+			newHub: func() pkgapis.Convertible { return &AWSIoTSource{} },
+			checkV1: func(t *testing.T, o pkgapis.Convertible) {
+				src := o.(*v1beta1.AWSIoTSource)
+				assert.Equal(t, "xxx-ats.iot.eu-central-1.amazonaws.com", src.Spec.DataEndpoint)
+				require.NotNil(t, src.Spec.Auth.ClientCertificate)
+				assert.Equal(t, "cert", src.Spec.Auth.ClientCertificate.Certificate.Value)
+				assert.Nil(t, src.Spec.Auth.Credentials)
+			},
+		},
+		"SNS with subscription ARN": {
+			hub: &AWSSNSSource{
+				ObjectMeta: tObjectMeta(),
+				Spec: AWSSNSSourceSpec{
+					SourceSpec:   tSourceSpec(),
+					ARN:          tARN("sns", "my-topic"),
+					Credentials:  tCredentials(),
+					WorkloadKind: WorkloadKindDeployment,
+					Route: &AdapterRoute{
+						Kind:    RouteKindHTTPRoute,
+						Host:    "sns.example.com",
+						Gateway: &GatewayReference{Name: "public", Namespace: "gateways"},
+					},
+				},
+				Status: AWSSNSSourceStatus{
+					EventSourceStatus: tStatus(),
+					SubscriptionARN:   ptrString("arn:aws:sns:eu-central-1:123456789012:my-topic:0000"),
+				},
+			},
+			spoke:  &v1beta1.AWSSNSSource{},
+			newHub: func() pkgapis.Convertible { return &AWSSNSSource{} },
+			checkV1: func(t *testing.T, o pkgapis.Convertible) {
+				src := o.(*v1beta1.AWSSNSSource)
+				assert.Equal(t, "arn:aws:sns:eu-central-1:123456789012:my-topic:0000",
+					src.Status.Annotations[v1beta1.AWSSNSSourceSubscriptionARNStatusAnnotation])
+			},
+		},
+		"SQS with delivery options": {
+			hub: &AWSSQSSource{
+				ObjectMeta: tObjectMeta(),
+				Spec: AWSSQSSourceSpec{
+					SourceSpec: tSourceSpec(),
+					ARN:        tARN("sqs", "my-queue"),
+					Batching: &AWSSQSSourceBatching{
+						MaxSize:             10,
+						MaxWaitMilliseconds: 1000,
+					},
+					TerminationGracePeriodSeconds: ptrInt64(60),
+					Credentials:                   tCredentials(),
+					Endpoint: &AWSEndpoint{
+						URL:         "https://sqs.example.com",
+						ServiceURLs: map[string]string{"sts": "https://sts.example.com"},
+						CABundle:    &ValueFromField{Value: "ca"},
+					},
+					AdapterOverrides: &AdapterOverrides{
+						NodeSelector:      map[string]string{"pool": "adapters"},
+						PriorityClassName: "high",
+						Annotations:       map[string]string{"prometheus.io/scrape": "true"},
+					},
+					Autoscaling: &AWSSQSSourceAutoscaling{
+						MinReplicas:   ptrInt32(0),
+						MaxReplicas:   5,
+						TargetBacklog: 100,
+					},
+				},
+				Status: AWSSQSSourceStatus{
+					EventSourceStatus: tStatus(),
+					Autoscaling: &AWSSQSSourceAutoscalingStatus{
+						Backlog:  250,
+						InFlight: 20,
+						Replicas: 3,
+					},
+				},
+			},
+			spoke:  &v1beta1.AWSSQSSource{},
+			newHub: func() pkgapis.Convertible { return &AWSSQSSource{} },
+			checkV1: func(t *testing.T, o pkgapis.Convertible) {
+				src := o.(*v1beta1.AWSSQSSource)
+				require.NotNil(t, src.Spec.Delivery)
+				assert.Equal(t, ptrInt64(60), src.Spec.Delivery.TerminationGracePeriodSeconds)
+				assert.Equal(t, int64(10), int64(src.Spec.Delivery.Batching.MaxSize))
+				require.NotNil(t, src.Spec.AdapterOverrides)
+				assert.Equal(t, "high", src.Spec.AdapterOverrides.PriorityClassName)
+				require.NotNil(t, src.Status.Autoscaling)
+				assert.Equal(t, int64(250), src.Status.Autoscaling.Backlog)
+			},
+		},
+		"Cognito User Pool with redaction policy": {
+			hub: &AWSCognitoUserPoolSource{
+				ObjectMeta: tObjectMeta(),
+				Spec: AWSCognitoUserPoolSourceSpec{
+					SourceSpec:  tSourceSpec(),
+					ARN:         tARN("cognito-idp", "userpool/eu-central-1_abc"),
+					TrackGroups: true,
+					Redaction: &CognitoRedactionPolicy{
+						Hash:    []string{"email"},
+						HashKey: &ValueFromField{Value: "secret"},
+					},
+					Credentials: tCredentials(),
+				},
+				Status: tStatus(),
+			},
+			spoke:  &v1beta1.AWSCognitoUserPoolSource{},
+			newHub: func() pkgapis.Convertible { return &AWSCognitoUserPoolSource{} },
+			checkV1: func(t *testing.T, o pkgapis.Convertible) {
+				src := o.(*v1beta1.AWSCognitoUserPoolSource)
+				require.NotNil(t, src.Spec.Redaction)
+				assert.Equal(t, []string{"email"}, src.Spec.Redaction.Hash)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			err := tc.hub.ConvertTo(ctx, tc.spoke)
+			require.NoError(t, err)
+
+			tc.checkV1(t, tc.spoke)
+
+			got := tc.newHub()
+			err = got.ConvertFrom(ctx, tc.spoke)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.hub, got)
+		})
+	}
+}
+
+func TestConversionUnsupportedAuth(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("IoT with credentials", func(t *testing.T) {
+		spoke := &v1beta1.AWSIoTSource{
+			Spec: v1beta1.AWSIoTSourceSpec{
+				Auth: v1beta1.AWSAuth{
+					Credentials: &v1beta1.AWSSecurityCredentials{Ambient: true},
+				},
+			},
+		}
+		err := (&AWSIoTSource{}).ConvertFrom(ctx, spoke)
+		assert.Error(t, err)
+	})
+
+	t.Run("SQS with client certificate", func(t *testing.T) {
+		spoke := &v1beta1.AWSSQSSource{
+			Spec: v1beta1.AWSSQSSourceSpec{
+				Auth: v1beta1.AWSAuth{
+					ClientCertificate: &v1beta1.AWSClientCertificate{},
+				},
+			},
+		}
+		err := (&AWSSQSSource{}).ConvertFrom(ctx, spoke)
+		assert.Error(t, err)
+	})
+}
+
+func TestConversionUnsupportedVersion(t *testing.T) {
+	ctx := context.Background()
+
+	err := (&AWSSQSSource{}).ConvertTo(ctx, &v1beta1.AWSSNSSource{})
+	assert.Error(t, err)
+
+	err = (&AWSSQSSource{}).ConvertFrom(ctx, &v1beta1.AWSSNSSource{})
+	assert.Error(t, err)
+}
+
+func tObjectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace:   "test-ns",
+		Name:        "test-source",
+		Annotations: map[string]string{"some": "annotation"},
+	}
+}
+
+func tSourceSpec() duckv1.SourceSpec {
+	return duckv1.SourceSpec{
+		Sink: duckv1.Destination{
+			URI: &pkgapis.URL{Scheme: "http", Host: "sink.example.com"},
+		},
+	}
+}
+
+func tARN(service, resource string) apis.ARN {
+	return apis.ARN{
+		Partition: "aws",
+		Service:   service,
+		Region:    "eu-central-1",
+		AccountID: "123456789012",
+		Resource:  resource,
+	}
+}
+
+func tCredentials() AWSSecurityCredentials {
+	return AWSSecurityCredentials{
+		AccessKeyID:     &ValueFromField{Value: "key-id"},
+		SecretAccessKey: ptrValueFromField(tSecretValue("secret")),
+		AssumeRole: &AWSAssumeRole{
+			RoleARN: tARN("iam", "role/my-role"),
+		},
+	}
+}
+
+func tSecretValue(key string) ValueFromField {
+	return ValueFromField{
+		ValueFromSecret: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "test-secret"},
+			Key:                  key,
+		},
+	}
+}
+
+func tStatus() EventSourceStatus {
+	return EventSourceStatus{
+		SourceStatus: duckv1.SourceStatus{
+			Status: duckv1.Status{
+				ObservedGeneration: 1,
+			},
+			SinkURI: &pkgapis.URL{Scheme: "http", Host: "sink.example.com"},
+		},
+	}
+}
+
+func ptrString(s string) *string                         { return &s }
+func ptrInt32(i int32) *int32                            { return &i }
+func ptrInt64(i int64) *int64                            { return &i }
+func ptrValueFromField(v ValueFromField) *ValueFromField { return &v }
@@ -21,10 +21,28 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apis "github.com/triggermesh/aws-event-sources/pkg/apis"
 	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSAssumeRole) DeepCopyInto(out *AWSAssumeRole) {
+	*out = *in
+	out.RoleARN = in.RoleARN
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSAssumeRole.
+func (in *AWSAssumeRole) DeepCopy() *AWSAssumeRole {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSAssumeRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSCodeCommitSource) DeepCopyInto(out *AWSCodeCommitSource) {
 	*out = *in
@@ -91,12 +109,42 @@ func (in *AWSCodeCommitSourceSpec) DeepCopyInto(out *AWSCodeCommitSourceSpec) {
 	*out = *in
 	in.SourceSpec.DeepCopyInto(&out.SourceSpec)
 	out.ARN = in.ARN
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.EventTypes != nil {
 		in, out := &in.EventTypes, &out.EventTypes
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.QueueARN != nil {
+		in, out := &in.QueueARN, &out.QueueARN
+		*out = new(apis.ARN)
+		**out = **in
+	}
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(AWSEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailability)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdapterOverrides != nil {
+		in, out := &in.AdapterOverrides, &out.AdapterOverrides
+		*out = new(AdapterOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -176,7 +224,27 @@ func (in *AWSCognitoIdentitySourceSpec) DeepCopyInto(out *AWSCognitoIdentitySour
 	*out = *in
 	in.SourceSpec.DeepCopyInto(&out.SourceSpec)
 	out.ARN = in.ARN
+	if in.Redaction != nil {
+		in, out := &in.Redaction, &out.Redaction
+		*out = new(CognitoRedactionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(AWSEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailability)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdapterOverrides != nil {
+		in, out := &in.AdapterOverrides, &out.AdapterOverrides
+		*out = new(AdapterOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -256,7 +324,27 @@ func (in *AWSCognitoUserPoolSourceSpec) DeepCopyInto(out *AWSCognitoUserPoolSour
 	*out = *in
 	in.SourceSpec.DeepCopyInto(&out.SourceSpec)
 	out.ARN = in.ARN
+	if in.Redaction != nil {
+		in, out := &in.Redaction, &out.Redaction
+		*out = new(CognitoRedactionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(AWSEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailability)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdapterOverrides != nil {
+		in, out := &in.AdapterOverrides, &out.AdapterOverrides
+		*out = new(AdapterOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -337,6 +425,21 @@ func (in *AWSDynamoDBSourceSpec) DeepCopyInto(out *AWSDynamoDBSourceSpec) {
 	in.SourceSpec.DeepCopyInto(&out.SourceSpec)
 	out.ARN = in.ARN
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(AWSEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailability)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdapterOverrides != nil {
+		in, out := &in.AdapterOverrides, &out.AdapterOverrides
+		*out = new(AdapterOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -350,6 +453,34 @@ func (in *AWSDynamoDBSourceSpec) DeepCopy() *AWSDynamoDBSourceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSEndpoint) DeepCopyInto(out *AWSEndpoint) {
+	*out = *in
+	if in.ServiceURLs != nil {
+		in, out := &in.ServiceURLs, &out.ServiceURLs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(ValueFromField)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSEndpoint.
+func (in *AWSEndpoint) DeepCopy() *AWSEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSIoTSource) DeepCopyInto(out *AWSIoTSource) {
 	*out = *in
@@ -416,7 +547,16 @@ func (in *AWSIoTSourceSpec) DeepCopyInto(out *AWSIoTSourceSpec) {
 	*out = *in
 	in.SourceSpec.DeepCopyInto(&out.SourceSpec)
 	out.ARN = in.ARN
-	in.RootCA.DeepCopyInto(&out.RootCA)
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RootCA != nil {
+		in, out := &in.RootCA, &out.RootCA
+		*out = new(ValueFromField)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.RootCAPath != nil {
 		in, out := &in.RootCAPath, &out.RootCAPath
 		*out = new(string)
@@ -434,6 +574,11 @@ func (in *AWSIoTSourceSpec) DeepCopyInto(out *AWSIoTSourceSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AdapterOverrides != nil {
+		in, out := &in.AdapterOverrides, &out.AdapterOverrides
+		*out = new(AdapterOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -514,6 +659,21 @@ func (in *AWSKinesisSourceSpec) DeepCopyInto(out *AWSKinesisSourceSpec) {
 	in.SourceSpec.DeepCopyInto(&out.SourceSpec)
 	out.ARN = in.ARN
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(AWSEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailability)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdapterOverrides != nil {
+		in, out := &in.AdapterOverrides, &out.AdapterOverrides
+		*out = new(AdapterOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -609,6 +769,21 @@ func (in *AWSSNSSourceSpec) DeepCopyInto(out *AWSSNSSourceSpec) {
 		}
 	}
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(AWSEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Route != nil {
+		in, out := &in.Route, &out.Route
+		*out = new(AdapterRoute)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdapterOverrides != nil {
+		in, out := &in.AdapterOverrides, &out.AdapterOverrides
+		*out = new(AdapterOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -672,6 +847,59 @@ func (in *AWSSQSSource) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSSQSSourceAutoscaling) DeepCopyInto(out *AWSSQSSourceAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSSQSSourceAutoscaling.
+func (in *AWSSQSSourceAutoscaling) DeepCopy() *AWSSQSSourceAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSSQSSourceAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSSQSSourceAutoscalingStatus) DeepCopyInto(out *AWSSQSSourceAutoscalingStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSSQSSourceAutoscalingStatus.
+func (in *AWSSQSSourceAutoscalingStatus) DeepCopy() *AWSSQSSourceAutoscalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSSQSSourceAutoscalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSSQSSourceBatching) DeepCopyInto(out *AWSSQSSourceBatching) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSSQSSourceBatching.
+func (in *AWSSQSSourceBatching) DeepCopy() *AWSSQSSourceBatching {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSSQSSourceBatching)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSSQSSourceList) DeepCopyInto(out *AWSSQSSourceList) {
 	*out = *in
@@ -710,7 +938,32 @@ func (in *AWSSQSSourceSpec) DeepCopyInto(out *AWSSQSSourceSpec) {
 	*out = *in
 	in.SourceSpec.DeepCopyInto(&out.SourceSpec)
 	out.ARN = in.ARN
+	if in.Batching != nil {
+		in, out := &in.Batching, &out.Batching
+		*out = new(AWSSQSSourceBatching)
+		**out = **in
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(AWSEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AWSSQSSourceAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdapterOverrides != nil {
+		in, out := &in.AdapterOverrides, &out.AdapterOverrides
+		*out = new(AdapterOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -724,11 +977,51 @@ func (in *AWSSQSSourceSpec) DeepCopy() *AWSSQSSourceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSSQSSourceStatus) DeepCopyInto(out *AWSSQSSourceStatus) {
+	*out = *in
+	in.EventSourceStatus.DeepCopyInto(&out.EventSourceStatus)
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AWSSQSSourceAutoscalingStatus)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSSQSSourceStatus.
+func (in *AWSSQSSourceStatus) DeepCopy() *AWSSQSSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSSQSSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSSecurityCredentials) DeepCopyInto(out *AWSSecurityCredentials) {
 	*out = *in
-	in.AccessKeyID.DeepCopyInto(&out.AccessKeyID)
-	in.SecretAccessKey.DeepCopyInto(&out.SecretAccessKey)
+	if in.AccessKeyID != nil {
+		in, out := &in.AccessKeyID, &out.AccessKeyID
+		*out = new(ValueFromField)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretAccessKey != nil {
+		in, out := &in.SecretAccessKey, &out.SecretAccessKey
+		*out = new(ValueFromField)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WebIdentity != nil {
+		in, out := &in.WebIdentity, &out.WebIdentity
+		*out = new(AWSWebIdentity)
+		**out = **in
+	}
+	if in.AssumeRole != nil {
+		in, out := &in.AssumeRole, &out.AssumeRole
+		*out = new(AWSAssumeRole)
+		**out = **in
+	}
 	return
 }
 
@@ -742,6 +1035,139 @@ func (in *AWSSecurityCredentials) DeepCopy() *AWSSecurityCredentials {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSWebIdentity) DeepCopyInto(out *AWSWebIdentity) {
+	*out = *in
+	out.RoleARN = in.RoleARN
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSWebIdentity.
+func (in *AWSWebIdentity) DeepCopy() *AWSWebIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSWebIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdapterOverrides) DeepCopyInto(out *AdapterOverrides) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdapterOverrides.
+func (in *AdapterOverrides) DeepCopy() *AdapterOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(AdapterOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdapterRoute) DeepCopyInto(out *AdapterRoute) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(GatewayReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdapterRoute.
+func (in *AdapterRoute) DeepCopy() *AdapterRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(AdapterRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CognitoRedactionPolicy) DeepCopyInto(out *CognitoRedactionPolicy) {
+	*out = *in
+	if in.Drop != nil {
+		in, out := &in.Drop, &out.Drop
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HashKey != nil {
+		in, out := &in.HashKey, &out.HashKey
+		*out = new(ValueFromField)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mask != nil {
+		in, out := &in.Mask, &out.Mask
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CognitoRedactionPolicy.
+func (in *CognitoRedactionPolicy) DeepCopy() *CognitoRedactionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CognitoRedactionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EventSourceStatus) DeepCopyInto(out *EventSourceStatus) {
 	*out = *in
@@ -760,6 +1186,43 @@ func (in *EventSourceStatus) DeepCopy() *EventSourceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayReference) DeepCopyInto(out *GatewayReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayReference.
+func (in *GatewayReference) DeepCopy() *GatewayReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HighAvailability) DeepCopyInto(out *HighAvailability) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HighAvailability.
+func (in *HighAvailability) DeepCopy() *HighAvailability {
+	if in == nil {
+		return nil
+	}
+	out := new(HighAvailability)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ValueFromField) DeepCopyInto(out *ValueFromField) {
 	*out = *in
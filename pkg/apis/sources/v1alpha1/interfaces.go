@@ -24,6 +24,8 @@ import (
 
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/kmeta"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
 )
 
 // EventSource is implemented by all event source types.
@@ -47,6 +49,19 @@ type EventSource interface {
 	AsEventSource() string
 }
 
+// AWSAuthenticatedSource is implemented by event source types which
+// interact with AWS APIs using AWS security credentials.
+type AWSAuthenticatedSource interface {
+	EventSource
+	// GetCredentials returns the AWS security credentials of the source.
+	GetCredentials() *AWSSecurityCredentials
+	// GetEndpoint returns the custom endpoint of the AWS API the source
+	// interacts with, if any.
+	GetEndpoint() *AWSEndpoint
+	// GetARN returns the ARN of the AWS resource observed by the source.
+	GetARN() apis.ARN
+}
+
 type sourceKey struct{}
 
 // WithSource returns a copy of the parent context in which the value
@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	pkgapis "knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AWSCodeCommitSource is the Schema for the event source.
+type AWSCodeCommitSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AWSCodeCommitSourceSpec `json:"spec,omitempty"`
+	Status EventSourceStatus       `json:"status,omitempty"`
+}
+
+// Check the interfaces the event source should be implementing.
+var (
+	_ runtime.Object      = (*AWSCodeCommitSource)(nil)
+	_ pkgapis.Convertible = (*AWSCodeCommitSource)(nil)
+)
+
+// AWSCodeCommitSourceSpec defines the desired state of the event source.
+type AWSCodeCommitSourceSpec struct {
+	duckv1.SourceSpec `json:",inline"`
+
+	// Repository ARN
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_awscodecommit.html#awscodecommit-resources-for-iam-policies
+	ARN apis.ARN `json:"arn"`
+
+	// Authentication with the AWS CodeCommit API, using AWS security
+	// credentials.
+	Auth AWSAuth `json:"auth"`
+
+	// Custom endpoint of the AWS CodeCommit API, such as a VPC interface
+	// endpoint, a FIPS endpoint or a local emulator. Defaults to the public
+	// endpoint of the region of the ARN. In EventBridge mode, the source
+	// also interacts with the AWS SQS API ("sqs").
+	// +optional
+	Endpoint *AWSEndpoint `json:"endpoint,omitempty"`
+
+	// Glob patterns matching the names of the Git branches this source
+	// observes, e.g. "master" or "release/*". The syntax of patterns is the
+	// one of shell file name patterns, in which '*' doesn't match '/'.
+	Branches []string `json:"branches"`
+	// Glob patterns matching the names of the Git tags this source
+	// observes, e.g. "v*". Tags are observed only when events are consumed
+	// from Amazon EventBridge (see QueueARN), because the CodeCommit API
+	// doesn't expose them.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+	// List of event types that should be processed by the source.
+	// Enabling push events also enables branch creation and deletion events,
+	// as well as tag events when tags are observed.
+	EventTypes []AWSCodeCommitEventType `json:"eventTypes"`
+
+	// ARN of an SQS queue to which Amazon EventBridge delivers the state
+	// change events of the repository. When set, the source consumes these
+	// events instead of polling the CodeCommit API, unless the queue can
+	// not be accessed.
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_amazonsqs.html#amazonsqs-resources-for-iam-policies
+	// +optional
+	QueueARN *apis.ARN `json:"queueARN,omitempty"`
+
+	// Name of a ConfigMap, in the namespace of the source, in which the
+	// adapter persists its state, so that it resumes where it left off
+	// after a restart. The ServiceAccount of the adapter must be allowed to
+	// get, create and update ConfigMaps. The state is only held in memory
+	// when omitted.
+	// +optional
+	StateConfigMap string `json:"stateConfigMap,omitempty"`
+
+	// Runs multiple replicas of the adapter which coordinate through
+	// Kubernetes Leases.
+	// +optional
+	HighAvailability *HighAvailability `json:"highAvailability,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
+}
+
+// AWSCodeCommitEventType is a type of event observed by an
+// AWSCodeCommitSource.
+type AWSCodeCommitEventType string
+
+// Event types observed by an AWSCodeCommitSource.
+const (
+	AWSCodeCommitPushEventType        AWSCodeCommitEventType = "push"
+	AWSCodeCommitPullRequestEventType AWSCodeCommitEventType = "pull_request"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AWSCodeCommitSourceList contains a list of event sources.
+type AWSCodeCommitSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AWSCodeCommitSource `json:"items"`
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	pkgapis "knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AWSCognitoUserPoolSource is the Schema for the event source.
+type AWSCognitoUserPoolSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AWSCognitoUserPoolSourceSpec `json:"spec,omitempty"`
+	Status EventSourceStatus            `json:"status,omitempty"`
+}
+
+// Check the interfaces the event source should be implementing.
+var (
+	_ runtime.Object      = (*AWSCognitoUserPoolSource)(nil)
+	_ pkgapis.Convertible = (*AWSCognitoUserPoolSource)(nil)
+)
+
+// AWSCognitoUserPoolSourceSpec defines the desired state of the event source.
+type AWSCognitoUserPoolSourceSpec struct {
+	duckv1.SourceSpec `json:",inline"`
+
+	// User Pool ARN
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_amazoncognitouserpools.html#amazoncognitouserpools-resources-for-iam-policies
+	ARN apis.ARN `json:"arn"`
+
+	// Authentication with the AWS Cognito API, using AWS security
+	// credentials.
+	Auth AWSAuth `json:"auth"`
+
+	// Custom endpoint of the AWS Cognito API, such as a VPC interface
+	// endpoint, a FIPS endpoint or a local emulator. Defaults to the public
+	// endpoint of the region of the ARN.
+	// +optional
+	Endpoint *AWSEndpoint `json:"endpoint,omitempty"`
+
+	// Name of a ConfigMap, in the namespace of the source, in which the
+	// adapter persists the last known state of the pool's users, so that
+	// changes which occur while the adapter isn't running are reported
+	// after a restart. The ServiceAccount of the adapter must be allowed to
+	// get, create and update ConfigMaps. The state is only held in memory
+	// when omitted.
+	// +optional
+	StateConfigMap string `json:"stateConfigMap,omitempty"`
+
+	// Whether users which are added to or removed from groups of the pool
+	// should be reported.
+	// +optional
+	TrackGroups bool `json:"trackGroups,omitempty"`
+
+	// Whether auth events (sign-in, sign-up, forgotten password) of the
+	// pool's users should be reported. Requires the advanced security
+	// features of the pool to be enabled. Auth events are listed for each
+	// user individually, which can be costly for pools with many users.
+	// +optional
+	TrackAuthEvents bool `json:"trackAuthEvents,omitempty"`
+
+	// Policy for redacting sensitive attributes from events. Events
+	// contain all attributes in clear text when omitted.
+	// +optional
+	Redaction *CognitoRedactionPolicy `json:"redaction,omitempty"`
+
+	// Runs multiple replicas of the adapter which coordinate through
+	// Kubernetes Leases.
+	// +optional
+	HighAvailability *HighAvailability `json:"highAvailability,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AWSCognitoUserPoolSourceList contains a list of event sources.
+type AWSCognitoUserPoolSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AWSCognitoUserPoolSource `json:"items"`
+}
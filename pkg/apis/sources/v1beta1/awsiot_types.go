@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	pkgapis "knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AWSIoTSource is the Schema for the event source.
+type AWSIoTSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AWSIoTSourceSpec  `json:"spec,omitempty"`
+	Status EventSourceStatus `json:"status,omitempty"`
+}
+
+// Check the interfaces the event source should be implementing.
+var (
+	_ runtime.Object      = (*AWSIoTSource)(nil)
+	_ pkgapis.Convertible = (*AWSIoTSource)(nil)
+)
+
+// AWSIoTSourceSpec defines the desired state of the event source.
+type AWSIoTSourceSpec struct {
+	duckv1.SourceSpec `json:",inline"`
+
+	// Topic ARN
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_awsiot.html#awsiot-resources-for-iam-policies
+	ARN apis.ARN `json:"arn"`
+
+	// Authentication with the AWS IoT message broker, using a X.509 client
+	// certificate.
+	Auth AWSAuth `json:"auth"`
+
+	// Host name of the AWS IoT data endpoint the client connects to.
+	// https://docs.aws.amazon.com/iot/latest/developerguide/iot-connect-devices.html#iot-connect-device-endpoints
+	DataEndpoint string `json:"dataEndpoint"`
+
+	// MQTT topic filters to subscribe to, which may contain the wildcards
+	// '+' and '#'. Defaults to the topic referenced by the ARN.
+	// +optional
+	Topics []string `json:"topics,omitempty"`
+	// MQTT client identifier. Defaults to "<namespace>.<name>".
+	// +optional
+	ClientID string `json:"clientID,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AWSIoTSourceList contains a list of event sources.
+type AWSIoTSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AWSIoTSource `json:"items"`
+}
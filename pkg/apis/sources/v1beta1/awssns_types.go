@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	pkgapis "knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AWSSNSSource is the Schema for the event source.
+type AWSSNSSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AWSSNSSourceSpec  `json:"spec,omitempty"`
+	Status EventSourceStatus `json:"status,omitempty"`
+}
+
+// Check the interfaces the event source should be implementing.
+var (
+	_ runtime.Object      = (*AWSSNSSource)(nil)
+	_ pkgapis.Convertible = (*AWSSNSSource)(nil)
+)
+
+// AWSSNSSourceSpec defines the desired state of the event source.
+type AWSSNSSourceSpec struct {
+	duckv1.SourceSpec `json:",inline"`
+
+	// Topic ARN
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_amazonsns.html#amazonsns-resources-for-iam-policies
+	ARN apis.ARN `json:"arn"`
+
+	// Authentication with the AWS SNS API, using AWS security credentials.
+	Auth AWSAuth `json:"auth"`
+
+	// Custom endpoint of the AWS SNS API, such as a VPC interface
+	// endpoint, a FIPS endpoint or a local emulator. Defaults to the public
+	// endpoint of the region of the ARN.
+	// +optional
+	Endpoint *AWSEndpoint `json:"endpoint,omitempty"`
+
+	// Attributes to set on the Subscription.
+	// For a list of supported subscription attributes, please refer to the following resources:
+	//  * https://docs.aws.amazon.com/sns/latest/api/API_SetSubscriptionAttributes.html
+	//  * https://docs.aws.amazon.com/sns/latest/dg/sns-how-it-works.html
+	// +optional
+	SubscriptionAttributes map[string]*string `json:"subscriptionAttributes,omitempty"`
+
+	// Kind of workload the adapter runs as. Defaults to the kind set in the
+	// configuration of the controller, KnativeService unless specified
+	// otherwise.
+	// +optional
+	WorkloadKind WorkloadKind `json:"workloadKind,omitempty"`
+
+	// Route of external traffic to the adapter, when it runs as a
+	// Deployment.
+	// +optional
+	Route *AdapterRoute `json:"route,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
+}
+
+// Keys of source-specific annotations of the status of an AWSSNSSource.
+const (
+	// ARN of the subscription of the source to the SNS topic.
+	AWSSNSSourceSubscriptionARNStatusAnnotation = "subscriptionARN"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AWSSNSSourceList contains a list of event sources.
+type AWSSNSSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AWSSNSSource `json:"items"`
+}
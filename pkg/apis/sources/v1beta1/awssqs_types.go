@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	pkgapis "knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AWSSQSSource is the Schema for the event source.
+type AWSSQSSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AWSSQSSourceSpec   `json:"spec,omitempty"`
+	Status AWSSQSSourceStatus `json:"status,omitempty"`
+}
+
+// Check the interfaces the event source should be implementing.
+var (
+	_ runtime.Object      = (*AWSSQSSource)(nil)
+	_ pkgapis.Convertible = (*AWSSQSSource)(nil)
+)
+
+// AWSSQSSourceSpec defines the desired state of the event source.
+type AWSSQSSourceSpec struct {
+	duckv1.SourceSpec `json:",inline"`
+
+	// Queue ARN
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/list_amazonsqs.html#amazonsqs-resources-for-iam-policies
+	ARN apis.ARN `json:"arn"`
+
+	// Authentication with the AWS SQS API, using AWS security credentials.
+	Auth AWSAuth `json:"auth"`
+
+	// Custom endpoint of the AWS SQS API, such as a VPC interface
+	// endpoint, a FIPS endpoint or a local emulator. Defaults to the public
+	// endpoint of the region of the ARN.
+	// +optional
+	Endpoint *AWSEndpoint `json:"endpoint,omitempty"`
+
+	// URL of the queue, for cases where it can not be resolved from the
+	// queue ARN (e.g. VPC endpoints). When omitted, the URL is looked up
+	// in the AWS account referenced in the ARN, which may differ from the
+	// account of the credentials.
+	// +optional
+	QueueURL string `json:"queueURL,omitempty"`
+
+	// Whether notifications forwarded to the queue by other AWS services
+	// (SNS, S3, EventBridge) should be unwrapped and sent as CloudEvents of
+	// a type specific to the originating service.
+	// +optional
+	DecodeNotifications bool `json:"decodeNotifications,omitempty"`
+
+	// Options for delivering events to the sink.
+	// +optional
+	Delivery *AWSSQSSourceDelivery `json:"delivery,omitempty"`
+
+	// Autoscaling of the adapter based on the backlog of the queue. The
+	// adapter runs as a single replica when omitted.
+	// +optional
+	Autoscaling *AWSSQSSourceAutoscaling `json:"autoscaling,omitempty"`
+
+	// Customizations of the adapter's Pods.
+	// +optional
+	AdapterOverrides *AdapterOverrides `json:"adapterOverrides,omitempty"`
+}
+
+// AWSSQSSourceDelivery defines how events are delivered to the sink.
+type AWSSQSSourceDelivery struct {
+	// Options for delivering events to the sink in batches, using the
+	// batched content mode of CloudEvents. Events are delivered one by one
+	// when omitted.
+	// +optional
+	Batching *AWSSQSSourceBatching `json:"batching,omitempty"`
+
+	// Duration in seconds the adapter is given to handle messages it has
+	// already received before it terminates, e.g. during a rollout.
+	// Messages which couldn't be processed within that period are released
+	// back to the queue. Also used as the termination grace period of the
+	// adapter's Pod. Defaults to 30s.
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+}
+
+// AWSSQSSourceBatching defines how events are grouped into batches before
+// being delivered to the sink.
+type AWSSQSSourceBatching struct {
+	// Maximum number of events in a batch.
+	MaxSize int32 `json:"maxSize"`
+	// Maximum time in milliseconds to wait for a batch to fill up before
+	// delivering it. Defaults to 1000.
+	// +optional
+	MaxWaitMilliseconds int32 `json:"maxWaitMilliseconds,omitempty"`
+}
+
+// AWSSQSSourceAutoscaling defines how the number of replicas of the source's
+// adapter is scaled based on the backlog of the queue.
+type AWSSQSSourceAutoscaling struct {
+	// Minimum number of replicas of the adapter. A value of 0 allows the
+	// adapter to be scaled to zero while the queue is empty. Defaults to 1.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// Maximum number of replicas of the adapter.
+	MaxReplicas int32 `json:"maxReplicas"`
+	// Number of visible messages in the queue per replica of the adapter
+	// which the autoscaler aims to maintain.
+	TargetBacklog int64 `json:"targetBacklog"`
+}
+
+// AWSSQSSourceStatus defines the observed state of the event source.
+type AWSSQSSourceStatus struct {
+	EventSourceStatus `json:",inline"`
+
+	// Observed state of the queue and of the adapter, for sources which
+	// have autoscaling enabled.
+	// +optional
+	Autoscaling *AWSSQSSourceAutoscalingStatus `json:"autoscaling,omitempty"`
+}
+
+// AWSSQSSourceAutoscalingStatus contains the observed state of the queue and
+// of the adapter of an autoscaled source.
+type AWSSQSSourceAutoscalingStatus struct {
+	// Approximate number of messages available for retrieval from the
+	// queue, as last observed by the controller.
+	Backlog int64 `json:"backlog"`
+	// Approximate number of messages received from the queue but not yet
+	// deleted, as last observed by the controller.
+	InFlight int64 `json:"inFlight"`
+	// Number of replicas of the adapter, as last observed by the
+	// controller.
+	Replicas int32 `json:"replicas"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AWSSQSSourceList contains a list of event sources.
+type AWSSQSSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AWSSQSSource `json:"items"`
+}
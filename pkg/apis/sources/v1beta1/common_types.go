@@ -0,0 +1,259 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+)
+
+// EventSourceStatus defines the observed state of an event source.
+//
+// Source-specific state is recorded in the annotations of the status.
+type EventSourceStatus struct {
+	duckv1.SourceStatus  `json:",inline"`
+	duckv1.AddressStatus `json:",inline"`
+}
+
+// AWSAuth contains the information used by an event source to authenticate
+// with AWS.
+//
+// Exactly one of the following authentication methods must be used,
+// depending on the type of the source: AWS security credentials (for sources
+// which consume AWS APIs), or a X.509 client certificate (for sources which
+// connect to AWS IoT).
+type AWSAuth struct {
+	// AWS security credentials.
+	// +optional
+	Credentials *AWSSecurityCredentials `json:"credentials,omitempty"`
+	// X.509 client certificate.
+	// +optional
+	ClientCertificate *AWSClientCertificate `json:"clientCertificate,omitempty"`
+}
+
+// AWSSecurityCredentials represents a set of AWS security credentials.
+// See https://docs.aws.amazon.com/general/latest/gr/aws-security-credentials.html
+//
+// Exactly one of the following authentication methods must be used: an
+// access key, a web identity, or the ambient credentials of the adapter.
+type AWSSecurityCredentials struct {
+	// Access key, composed of an access key ID and a secret access key.
+	// +optional
+	AccessKeyID *ValueFromField `json:"accessKeyID,omitempty"`
+	// +optional
+	SecretAccessKey *ValueFromField `json:"secretAccessKey,omitempty"`
+
+	// IAM role assumed with the identity of the adapter's Kubernetes
+	// ServiceAccount (IAM Roles for Service Accounts).
+	// +optional
+	WebIdentity *AWSWebIdentity `json:"webIdentity,omitempty"`
+
+	// Use the credentials available in the environment of the adapter,
+	// as resolved by the default credential chain of the AWS SDK (e.g.
+	// instance profile of the node).
+	// +optional
+	Ambient bool `json:"ambient,omitempty"`
+
+	// IAM role assumed using the credentials of the selected
+	// authentication method.
+	// +optional
+	AssumeRole *AWSAssumeRole `json:"assumeRole,omitempty"`
+}
+
+// AWSWebIdentity represents an IAM role assumed with a Kubernetes
+// ServiceAccount token.
+// See https://docs.aws.amazon.com/eks/latest/userguide/iam-roles-for-service-accounts.html
+type AWSWebIdentity struct {
+	// ARN of the IAM role.
+	RoleARN apis.ARN `json:"roleARN"`
+}
+
+// AWSAssumeRole represents an IAM role assumed with the STS AssumeRole API.
+// See https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRole.html
+type AWSAssumeRole struct {
+	// ARN of the IAM role.
+	RoleARN apis.ARN `json:"roleARN"`
+	// External ID required by the trust policy of the role, if any.
+	// +optional
+	ExternalID string `json:"externalID,omitempty"`
+	// Name of the role session. Defaults to a name generated by the AWS SDK.
+	// +optional
+	SessionName string `json:"sessionName,omitempty"`
+}
+
+// AWSClientCertificate represents a X.509 client certificate used to
+// authenticate with AWS IoT.
+// See https://docs.aws.amazon.com/iot/latest/developerguide/x509-client-certs.html
+type AWSClientCertificate struct {
+	// PEM-encoded client certificate.
+	Certificate ValueFromField `json:"certificate"`
+	// PEM-encoded private key of the client certificate.
+	PrivateKey ValueFromField `json:"privateKey"`
+	// PEM-encoded CA certificates used to verify the TLS certificate of
+	// the server, in place of the system's root CAs.
+	// +optional
+	RootCA *ValueFromField `json:"rootCA,omitempty"`
+}
+
+// AWSEndpoint represents a custom endpoint of an AWS API.
+type AWSEndpoint struct {
+	// URL of the endpoint of the AWS API which manages the resource
+	// referenced by the ARN of the source, including its scheme.
+	URL string `json:"url"`
+	// URLs of the endpoints of other AWS APIs the source interacts with,
+	// keyed by the ID of their service endpoint, e.g. "streams.dynamodb".
+	// APIs which are not listed here use their public regional endpoint.
+	// +optional
+	ServiceURLs map[string]string `json:"serviceURLs,omitempty"`
+	// PEM-encoded CA certificates used to verify the TLS certificate of
+	// the endpoint, in place of the system's root CAs.
+	// +optional
+	CABundle *ValueFromField `json:"caBundle,omitempty"`
+	// Whether requests should use path-style addressing instead of
+	// virtual-hosted-style addressing, as required by some emulators.
+	// +optional
+	PathStyle bool `json:"pathStyle,omitempty"`
+}
+
+// ValueFromField is a struct field that can have its value either defined
+// explicitly or sourced from another entity.
+type ValueFromField struct {
+	// Optional: no more than one of the following may be specified.
+
+	// Field value.
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Field value from a Kubernetes Secret.
+	// +optional
+	ValueFromSecret *corev1.SecretKeySelector `json:"valueFromSecret,omitempty"`
+}
+
+// CognitoRedactionPolicy describes how sensitive attributes of Cognito
+// objects (user attributes, dataset records, context data of auth events) are
+// redacted from events before they are sent. Attribute names may be shell
+// patterns (e.g. "custom:*").
+// When an attribute matches multiple lists, dropping takes precedence over
+// hashing, which takes precedence over masking.
+type CognitoRedactionPolicy struct {
+	// Names of attributes which are removed from events.
+	// +optional
+	Drop []string `json:"drop,omitempty"`
+	// Names of attributes whose values are replaced with their keyed hash
+	// (HMAC-SHA256), so that events about the same value can still be
+	// correlated.
+	// +optional
+	Hash []string `json:"hash,omitempty"`
+	// Key of the HMAC. Required when attributes are hashed.
+	// +optional
+	HashKey *ValueFromField `json:"hashKey,omitempty"`
+	// Names of attributes whose values are masked. Only the last four
+	// characters of values of at least eight characters remain visible.
+	// +optional
+	Mask []string `json:"mask,omitempty"`
+}
+
+// AdapterOverrides are customizations applied to the Pods of a source's
+// adapter, on top of the defaults set by the controller.
+type AdapterOverrides struct {
+	// Compute resources of the adapter's container. Values set here
+	// replace the defaults of the corresponding resources.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Selector which must match a node's labels for the adapter's Pods to
+	// be scheduled on that node.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the adapter's Pods.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Scheduling constraints of the adapter's Pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Name of the PriorityClass of the adapter's Pods.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Additional labels of the adapter's Pods.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Additional annotations of the adapter's Pods.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// WorkloadKind is the kind of Kubernetes workload a source's adapter runs as.
+type WorkloadKind string
+
+// Supported kinds of workloads.
+const (
+	// WorkloadKindKnativeService runs the adapter as a Knative Service.
+	WorkloadKindKnativeService WorkloadKind = "KnativeService"
+	// WorkloadKindDeployment runs the adapter as a Deployment exposed by a
+	// Kubernetes Service.
+	WorkloadKindDeployment WorkloadKind = "Deployment"
+)
+
+// AdapterRoute defines how an adapter which runs as a Deployment receives
+// traffic from outside of the cluster.
+type AdapterRoute struct {
+	// Kind of object which routes external traffic to the adapter.
+	Kind RouteKind `json:"kind"`
+	// Host name under which the adapter is reachable. Defaults to
+	// <adapter name>.<namespace>.<base domain>, where the base domain is
+	// configured in the controller.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Name of the IngressClass of the Ingress. Applies to routes of kind
+	// Ingress only.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Gateway the HTTPRoute is attached to. Applies to routes of kind
+	// HTTPRoute only.
+	// +optional
+	Gateway *GatewayReference `json:"gateway,omitempty"`
+}
+
+// RouteKind is the kind of object which routes external traffic to an
+// adapter.
+type RouteKind string
+
+// Supported kinds of routes.
+const (
+	// RouteKindIngress routes traffic using a networking.k8s.io Ingress.
+	RouteKindIngress RouteKind = "Ingress"
+	// RouteKindHTTPRoute routes traffic using a gateway.networking.k8s.io
+	// HTTPRoute.
+	RouteKindHTTPRoute RouteKind = "HTTPRoute"
+)
+
+// GatewayReference is a reference to a Gateway of the Kubernetes Gateway API.
+type GatewayReference struct {
+	// Name of the Gateway.
+	Name string `json:"name"`
+	// Namespace of the Gateway. Defaults to the namespace of the source.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// HighAvailability configures the coordination of multiple replicas of an
+// adapter through Kubernetes Leases, so that events are not duplicated.
+type HighAvailability struct {
+	// Number of replicas of the adapter. Defaults to 2.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	"knative.dev/pkg/apis"
+)
+
+// Conversions are implemented by the v1alpha1 types, which are used as the
+// hub of all conversions between versions of event sources.
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSCodeCommitSource) ConvertTo(ctx context.Context, to apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", to)
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSCodeCommitSource) ConvertFrom(ctx context.Context, from apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", from)
+}
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSCognitoIdentitySource) ConvertTo(ctx context.Context, to apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", to)
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSCognitoIdentitySource) ConvertFrom(ctx context.Context, from apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", from)
+}
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSCognitoUserPoolSource) ConvertTo(ctx context.Context, to apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", to)
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSCognitoUserPoolSource) ConvertFrom(ctx context.Context, from apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", from)
+}
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSDynamoDBSource) ConvertTo(ctx context.Context, to apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", to)
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSDynamoDBSource) ConvertFrom(ctx context.Context, from apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", from)
+}
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSIoTSource) ConvertTo(ctx context.Context, to apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", to)
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSIoTSource) ConvertFrom(ctx context.Context, from apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", from)
+}
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSKinesisSource) ConvertTo(ctx context.Context, to apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", to)
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSKinesisSource) ConvertFrom(ctx context.Context, from apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", from)
+}
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSSNSSource) ConvertTo(ctx context.Context, to apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", to)
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSSNSSource) ConvertFrom(ctx context.Context, from apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", from)
+}
+
+// ConvertTo implements apis.Convertible.
+func (s *AWSSQSSource) ConvertTo(ctx context.Context, to apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", to)
+}
+
+// ConvertFrom implements apis.Convertible.
+func (s *AWSSQSSource) ConvertFrom(ctx context.Context, from apis.Convertible) error {
+	return fmt.Errorf("v1beta1 is not the hub version, got: %T", from)
+}
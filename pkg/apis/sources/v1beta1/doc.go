@@ -0,0 +1,22 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package v1beta1 contains API Schema definitions for the sources/v1beta1 API group.
+//
+// Objects of this version are converted to and from v1alpha1, which is the
+// storage version of all event sources.
+// +k8s:deepcopy-gen=package
+// +groupName=sources.triggermesh.io
+package v1beta1
@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package awsendpoint contains helpers to send requests to custom endpoints
+// of AWS APIs.
+package awsendpoint
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// Config describes custom endpoints of AWS APIs.
+type Config struct {
+	// URLs of the endpoints, keyed by the ID of their service endpoint
+	// (e.g. "streams.dynamodb")
+	URLs map[string]string
+	// PEM-encoded CA certificates
+	CABundle  string
+	PathStyle bool
+}
+
+// WithEndpoints returns a copy of the given session which sends requests to
+// the endpoints described in the given config. Requests to APIs which don't
+// have a custom endpoint are sent to their default endpoint. The session is
+// returned as is if the config doesn't describe any endpoint.
+//
+// Credentials are inherited from the given session, so that requests issued
+// while resolving them (e.g. to AWS STS) are not affected by the endpoints.
+func WithEndpoints(sess *session.Session, cfg Config) (*session.Session, error) {
+	if len(cfg.URLs) == 0 {
+		return sess, nil
+	}
+
+	opts := session.Options{
+		Config: *sess.Config.Copy().
+			WithEndpointResolver(Resolver(cfg.URLs)).
+			WithS3ForcePathStyle(cfg.PathStyle),
+	}
+
+	if cfg.CABundle != "" {
+		// the AWS SDK alters the transport of the configured HTTP
+		// client, which is http.DefaultClient unless set explicitly
+		opts.Config.HTTPClient = &http.Client{}
+		opts.CustomCABundle = strings.NewReader(cfg.CABundle)
+	}
+
+	return session.NewSessionWithOptions(opts)
+}
+
+// Resolver returns an endpoints.Resolver which resolves the endpoints of
+// APIs to the given URLs, keyed by the ID of their service endpoint, and
+// falls back to the default resolver for other APIs.
+func Resolver(urls map[string]string) endpoints.Resolver {
+	return endpoints.ResolverFunc(func(service, region string,
+		opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+
+		if u, ok := urls[service]; ok {
+			return endpoints.ResolvedEndpoint{
+				URL:           u,
+				SigningRegion: region,
+			}, nil
+		}
+
+		return endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+	})
+}
@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsendpoint
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentity"
+	"github.com/aws/aws-sdk-go/service/cognitosync"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+func TestResolver(t *testing.T) {
+	r := Resolver(map[string]string{
+		"cognito-sync": "https://cognito-sync.example.com",
+	})
+
+	ep, err := r.EndpointFor(cognitosync.EndpointsID, "eu-central-1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://cognito-sync.example.com", ep.URL)
+	assert.Equal(t, "eu-central-1", ep.SigningRegion)
+
+	ep, err = r.EndpointFor(cognitoidentity.EndpointsID, "eu-central-1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://cognito-identity.eu-central-1.amazonaws.com", ep.URL)
+}
+
+func TestWithEndpoints(t *testing.T) {
+	newSession := func(t *testing.T) *session.Session {
+		sess, err := session.NewSession(aws.NewConfig().
+			WithRegion("us-west-2").
+			WithCredentials(credentials.NewStaticCredentials("key", "secret", "")),
+		)
+		require.NoError(t, err)
+		return sess
+	}
+
+	t.Run("no endpoint", func(t *testing.T) {
+		sess := newSession(t)
+
+		s, err := WithEndpoints(sess, Config{})
+		require.NoError(t, err)
+		assert.Same(t, sess, s)
+	})
+
+	t.Run("custom endpoint with CA bundle", func(t *testing.T) {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(`<ListQueuesResponse><ListQueuesResult/></ListQueuesResponse>`))
+		}))
+		defer srv.Close()
+
+		sess, err := WithEndpoints(newSession(t), Config{
+			URLs:     map[string]string{sqs.EndpointsID: srv.URL},
+			CABundle: string(encodeCertificate(srv.Certificate())),
+		})
+		require.NoError(t, err)
+
+		assert.NotSame(t, http.DefaultClient, sess.Config.HTTPClient)
+
+		_, err = sqs.New(sess).ListQueues(&sqs.ListQueuesInput{})
+		require.NoError(t, err)
+	})
+}
+
+// encodeCertificate returns the PEM encoding of the given certificate.
+func encodeCertificate(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: cert.Raw,
+	})
+}
@@ -31,8 +31,10 @@ import (
 )
 
 const (
-	envBranch     = "BRANCH"
+	envBranches   = "BRANCHES"
+	envTags       = "TAGS"
 	envEventTypes = "EVENT_TYPES"
+	envQueueARN   = "QUEUE_ARN"
 )
 
 // adapterConfig contains properties used to configure the source's adapter.
@@ -57,6 +59,11 @@ func adapterDeploymentBuilder(src *v1alpha1.AWSCodeCommitSource, cfg *adapterCon
 			sinkURIStr = sinkURI.String()
 		}
 
+		var queueARN string
+		if src.Spec.QueueARN != nil {
+			queueARN = src.Spec.QueueARN.String()
+		}
+
 		return resource.NewDeployment(src.Namespace, name,
 			resource.Controller(src),
 
@@ -78,10 +85,17 @@ func adapterDeploymentBuilder(src *v1alpha1.AWSCodeCommitSource, cfg *adapterCon
 			resource.EnvVar(common.EnvNamespace, src.Namespace),
 			resource.EnvVar(common.EnvSink, sinkURIStr),
 			resource.EnvVar(common.EnvARN, src.Spec.ARN.String()),
-			resource.EnvVar(envBranch, src.Spec.Branch),
+			resource.EnvVar(envBranches, strings.Join(src.Spec.BranchPatterns(), ",")),
+			resource.EnvVar(envTags, strings.Join(src.Spec.Tags, ",")),
 			resource.EnvVar(envEventTypes, strings.Join(src.Spec.EventTypes, ",")),
-			resource.EnvVars(common.MakeSecurityCredentialsEnvVars(src.Spec.Credentials)...),
+			resource.EnvVar(envQueueARN, queueARN),
+			resource.EnvVar(common.EnvStateConfigMap, src.Spec.StateConfigMap),
+			common.SecurityCredentials(src, src.Spec.Credentials),
+			resource.EnvVars(common.MakeEndpointEnvVars(src.Spec.ARN.Service, src.Spec.Endpoint)...),
 			resource.EnvVars(cfg.configs.ToEnvVars()...),
+
+			common.HighAvailability(src, src.Spec.HighAvailability),
+			common.AdapterOverrides(src.Spec.AdapterOverrides),
 		)
 	}
 }
@@ -58,6 +58,8 @@ func NewController(
 		impl.EnqueueKey,
 		impl.EnqueueControllerOf,
 	)
+	r.base.PreflightProbe = probeRepository
+	r.base.EnqueueAfter = impl.EnqueueAfter
 
 	informerv1alpha1.Get(ctx).Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
 
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/service/codecommit"
+	"github.com/aws/aws-sdk-go/service/sqs"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -57,10 +58,12 @@ func TestReconcileSource(t *testing.T) {
 func reconcilerCtor(cfg *adapterConfig) Ctor {
 	return func(t *testing.T, ctx context.Context, ls *Listers) controller.Reconciler {
 		base := common.GenericDeploymentReconciler{
-			SinkResolver: resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
-			Lister:       ls.GetDeploymentLister().Deployments,
-			Client:       fakek8sinjectionclient.Get(ctx).AppsV1().Deployments,
-			PodClient:    fakek8sinjectionclient.Get(ctx).CoreV1().Pods,
+			SinkResolver:         resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
+			Lister:               ls.GetDeploymentLister().Deployments,
+			Client:               fakek8sinjectionclient.Get(ctx).AppsV1().Deployments,
+			PodClient:            fakek8sinjectionclient.Get(ctx).CoreV1().Pods,
+			ServiceAccountClient: fakek8sinjectionclient.Get(ctx).CoreV1().ServiceAccounts,
+			ServiceAccountLister: ls.GetServiceAccountLister().ServiceAccounts,
 		}
 
 		r := &Reconciler{
@@ -76,13 +79,16 @@ func reconcilerCtor(cfg *adapterConfig) Ctor {
 
 // newEventSource returns a populated source object.
 func newEventSource() *v1alpha1.AWSCodeCommitSource {
+	queueARN := NewARN(sqs.ServiceName, "triggermeshtest")
+
 	src := &v1alpha1.AWSCodeCommitSource{
 		Spec: v1alpha1.AWSCodeCommitSourceSpec{
 			ARN:        NewARN(codecommit.ServiceName, "triggermeshtest"),
-			Branch:     "test",
+			Branches:   []string{"test", "release/*"},
 			EventTypes: []string{"pull-request", "push"},
+			QueueARN:   &queueARN,
 			Credentials: v1alpha1.AWSSecurityCredentials{
-				AccessKeyID: v1alpha1.ValueFromField{
+				AccessKeyID: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "test-secret",
@@ -90,7 +96,7 @@ func newEventSource() *v1alpha1.AWSCodeCommitSource {
 						Key: "keyId",
 					},
 				},
-				SecretAccessKey: v1alpha1.ValueFromField{
+				SecretAccessKey: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "test-secret",
@@ -17,6 +17,8 @@ limitations under the License.
 package awscognitouserpoolsource
 
 import (
+	"strconv"
+
 	appsv1 "k8s.io/api/apps/v1"
 
 	"knative.dev/eventing/pkg/reconciler/source"
@@ -28,6 +30,11 @@ import (
 	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/resource"
 )
 
+const (
+	envTrackGroups     = "TRACK_GROUPS"
+	envTrackAuthEvents = "TRACK_AUTH_EVENTS"
+)
+
 // adapterConfig contains properties used to configure the source's adapter.
 // These are automatically populated by envconfig.
 type adapterConfig struct {
@@ -71,8 +78,16 @@ func adapterDeploymentBuilder(src *v1alpha1.AWSCognitoUserPoolSource, cfg *adapt
 			resource.EnvVar(common.EnvNamespace, src.Namespace),
 			resource.EnvVar(common.EnvSink, sinkURIStr),
 			resource.EnvVar(common.EnvARN, src.Spec.ARN.String()),
-			resource.EnvVars(common.MakeSecurityCredentialsEnvVars(src.Spec.Credentials)...),
+			resource.EnvVar(common.EnvStateConfigMap, src.Spec.StateConfigMap),
+			resource.EnvVar(envTrackGroups, strconv.FormatBool(src.Spec.TrackGroups)),
+			resource.EnvVar(envTrackAuthEvents, strconv.FormatBool(src.Spec.TrackAuthEvents)),
+			common.SecurityCredentials(src, src.Spec.Credentials),
+			resource.EnvVars(common.MakeEndpointEnvVars(src.Spec.ARN.Service, src.Spec.Endpoint)...),
+			resource.EnvVars(common.MakeRedactionEnvVars(src.Spec.Redaction)...),
 			resource.EnvVars(cfg.configs.ToEnvVars()...),
+
+			common.HighAvailability(src, src.Spec.HighAvailability),
+			common.AdapterOverrides(src.Spec.AdapterOverrides),
 		)
 	}
 }
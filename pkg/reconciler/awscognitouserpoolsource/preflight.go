@@ -0,0 +1,42 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awscognitouserpoolsource
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// probeUserPool implements common.PreflightProbeFunc. It checks that the Cognito user pool
+// observed by the source exists and is accessible.
+func probeUserPool(ctx context.Context, sess *session.Session, src v1alpha1.AWSAuthenticatedSource) error {
+	elements, err := apis.ParseResource(src.GetARN().Resource, apis.CognitoUserPoolResourceFormat)
+	if err != nil {
+		return err
+	}
+
+	_, err = cognitoidentityprovider.New(sess).DescribeUserPoolWithContext(ctx, &cognitoidentityprovider.DescribeUserPoolInput{
+		UserPoolId: aws.String(elements[0]),
+	})
+	return err
+}
@@ -57,10 +57,12 @@ func TestReconcileSource(t *testing.T) {
 func reconcilerCtor(cfg *adapterConfig) Ctor {
 	return func(t *testing.T, ctx context.Context, ls *Listers) controller.Reconciler {
 		base := common.GenericDeploymentReconciler{
-			SinkResolver: resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
-			Lister:       ls.GetDeploymentLister().Deployments,
-			Client:       fakek8sinjectionclient.Get(ctx).AppsV1().Deployments,
-			PodClient:    fakek8sinjectionclient.Get(ctx).CoreV1().Pods,
+			SinkResolver:         resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
+			Lister:               ls.GetDeploymentLister().Deployments,
+			Client:               fakek8sinjectionclient.Get(ctx).AppsV1().Deployments,
+			PodClient:            fakek8sinjectionclient.Get(ctx).CoreV1().Pods,
+			ServiceAccountClient: fakek8sinjectionclient.Get(ctx).CoreV1().ServiceAccounts,
+			ServiceAccountLister: ls.GetServiceAccountLister().ServiceAccounts,
 		}
 
 		r := &Reconciler{
@@ -80,7 +82,7 @@ func newEventSource(skipCEAtrributes ...interface{}) *v1alpha1.AWSCognitoUserPoo
 		Spec: v1alpha1.AWSCognitoUserPoolSourceSpec{
 			ARN: NewARN(cognitoidentityprovider.ServiceName, "userpool/triggermeshtest"),
 			Credentials: v1alpha1.AWSSecurityCredentials{
-				AccessKeyID: v1alpha1.ValueFromField{
+				AccessKeyID: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "test-secret",
@@ -88,7 +90,7 @@ func newEventSource(skipCEAtrributes ...interface{}) *v1alpha1.AWSCognitoUserPoo
 						Key: "keyId",
 					},
 				},
-				SecretAccessKey: v1alpha1.ValueFromField{
+				SecretAccessKey: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "test-secret",
@@ -97,6 +99,19 @@ func newEventSource(skipCEAtrributes ...interface{}) *v1alpha1.AWSCognitoUserPoo
 					},
 				},
 			},
+			Redaction: &v1alpha1.CognitoRedactionPolicy{
+				Drop: []string{"custom:*"},
+				Hash: []string{"email"},
+				HashKey: &v1alpha1.ValueFromField{
+					ValueFromSecret: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: "test-secret",
+						},
+						Key: "hashKey",
+					},
+				},
+				Mask: []string{"phone_number"},
+			},
 		},
 	}
 
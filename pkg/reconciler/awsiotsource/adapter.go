@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 
 	"knative.dev/eventing/pkg/reconciler/source"
 	"knative.dev/pkg/apis"
@@ -31,14 +32,12 @@ import (
 )
 
 const (
-	envEndpoint        = "THING_SHADOW_ENDPOINT"
-	envTopic           = "THING_TOPIC"
-	envRootCA          = "ROOT_CA"
-	envRootCAPath      = "ROOT_CA_PATH"
-	envCertificate     = "CERTIFICATE"
-	envCertificatePath = "CERTIFICATE_PATH"
-	envPrivateKey      = "PRIVATE_KEY"
-	envPrivateKeyPath  = "PRIVATE_KEY_PATH"
+	envEndpoint    = "THING_SHADOW_ENDPOINT"
+	envTopics      = "TOPICS"
+	envClientID    = "CLIENT_ID"
+	envRootCA      = "ROOT_CA"
+	envCertificate = "CERTIFICATE"
+	envPrivateKey  = "PRIVATE_KEY"
 )
 
 // adapterConfig contains properties used to configure the source's adapter.
@@ -63,6 +62,16 @@ func adapterDeploymentBuilder(src *v1alpha1.AWSIoTSource, cfg *adapterConfig) co
 			sinkURIStr = sinkURI.String()
 		}
 
+		topics := src.Spec.Topics
+		if len(topics) == 0 {
+			topics = []string{strings.TrimPrefix(src.Spec.ARN.Resource, "topic/")}
+		}
+
+		var rootCAEnvVar []corev1.EnvVar
+		if src.Spec.RootCA != nil {
+			rootCAEnvVar = []corev1.EnvVar{common.MakeEnvVarFromField(envRootCA, *src.Spec.RootCA)}
+		}
+
 		return resource.NewDeployment(src.Namespace, name,
 			resource.Controller(src),
 
@@ -83,21 +92,18 @@ func adapterDeploymentBuilder(src *v1alpha1.AWSIoTSource, cfg *adapterConfig) co
 			resource.EnvVar(common.EnvName, src.Name),
 			resource.EnvVar(common.EnvNamespace, src.Namespace),
 			resource.EnvVar(common.EnvSink, sinkURIStr),
+			resource.EnvVar(common.EnvARN, src.Spec.ARN.String()),
 			resource.EnvVar(envEndpoint, src.Spec.Endpoint),
-			resource.EnvVar(envTopic, strings.TrimPrefix(src.Spec.ARN.Resource, "topic/")),
-			resource.EnvVarFromSecret(envRootCA,
-				src.Spec.RootCA.ValueFromSecret.Name,
-				src.Spec.RootCA.ValueFromSecret.Key),
-			resource.EnvVar(envRootCAPath, *src.Spec.RootCAPath),
-			resource.EnvVarFromSecret(envCertificate,
-				src.Spec.Certificate.ValueFromSecret.Name,
-				src.Spec.Certificate.ValueFromSecret.Key),
-			resource.EnvVar(envCertificatePath, *src.Spec.CertificatePath),
-			resource.EnvVarFromSecret(envPrivateKey,
-				src.Spec.PrivateKey.ValueFromSecret.Name,
-				src.Spec.PrivateKey.ValueFromSecret.Key),
-			resource.EnvVar(envPrivateKeyPath, *src.Spec.PrivateKeyPath),
+			resource.EnvVar(envTopics, strings.Join(topics, ",")),
+			resource.EnvVar(envClientID, src.Spec.ClientID),
+			resource.EnvVars(rootCAEnvVar...),
+			resource.EnvVars(
+				common.MakeEnvVarFromField(envCertificate, src.Spec.Certificate),
+				common.MakeEnvVarFromField(envPrivateKey, src.Spec.PrivateKey),
+			),
 			resource.EnvVars(cfg.configs.ToEnvVars()...),
+
+			common.AdapterOverrides(src.Spec.AdapterOverrides),
 		)
 	}
 }
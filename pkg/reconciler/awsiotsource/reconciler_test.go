@@ -20,7 +20,6 @@ import (
 	"context"
 	"testing"
 
-	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iot"
 
 	corev1 "k8s.io/api/core/v1"
@@ -67,10 +66,12 @@ func TestReconcileSource(t *testing.T) {
 func reconcilerCtor(cfg *adapterConfig) Ctor {
 	return func(t *testing.T, ctx context.Context, ls *Listers) controller.Reconciler {
 		base := common.GenericDeploymentReconciler{
-			SinkResolver: resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
-			Lister:       ls.GetDeploymentLister().Deployments,
-			Client:       fakek8sinjectionclient.Get(ctx).AppsV1().Deployments,
-			PodClient:    fakek8sinjectionclient.Get(ctx).CoreV1().Pods,
+			SinkResolver:         resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
+			Lister:               ls.GetDeploymentLister().Deployments,
+			Client:               fakek8sinjectionclient.Get(ctx).AppsV1().Deployments,
+			PodClient:            fakek8sinjectionclient.Get(ctx).CoreV1().Pods,
+			ServiceAccountClient: fakek8sinjectionclient.Get(ctx).CoreV1().ServiceAccounts,
+			ServiceAccountLister: ls.GetServiceAccountLister().ServiceAccounts,
 		}
 
 		r := &Reconciler{
@@ -93,15 +94,12 @@ func newEventSource(skipCEAtrributes ...interface{}) *v1alpha1.AWSIoTSource {
 			Certificate: v1alpha1.ValueFromField{
 				ValueFromSecret: tFooRef,
 			},
-			CertificatePath: aws.String(tFoo),
 			PrivateKey: v1alpha1.ValueFromField{
 				ValueFromSecret: tFooRef,
 			},
-			PrivateKeyPath: aws.String(tFoo),
-			RootCA: v1alpha1.ValueFromField{
+			RootCA: &v1alpha1.ValueFromField{
 				ValueFromSecret: tFooRef,
 			},
-			RootCAPath: aws.String(tFoo),
 		},
 	}
 
@@ -71,8 +71,12 @@ func adapterDeploymentBuilder(src *v1alpha1.AWSKinesisSource, cfg *adapterConfig
 			resource.EnvVar(common.EnvNamespace, src.Namespace),
 			resource.EnvVar(common.EnvSink, sinkURIStr),
 			resource.EnvVar(common.EnvARN, src.Spec.ARN.String()),
-			resource.EnvVars(common.MakeSecurityCredentialsEnvVars(src.Spec.Credentials)...),
+			common.SecurityCredentials(src, src.Spec.Credentials),
+			resource.EnvVars(common.MakeEndpointEnvVars(src.Spec.ARN.Service, src.Spec.Endpoint)...),
 			resource.EnvVars(cfg.configs.ToEnvVars()...),
+
+			common.HighAvailability(src, src.Spec.HighAvailability),
+			common.AdapterOverrides(src.Spec.AdapterOverrides),
 		)
 	}
 }
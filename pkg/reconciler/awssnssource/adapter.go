@@ -19,6 +19,10 @@ package awssnssource
 import (
 	"strconv"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
 	"knative.dev/eventing/pkg/reconciler/source"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/kmeta"
@@ -31,11 +35,25 @@ import (
 
 const metricsPrometheusPort uint16 = 9092
 
+// Ports used by adapters which run as Deployments.
+const (
+	adapterPort        = 8080
+	adapterServicePort = 80
+	adapterPortName    = "http"
+)
+
 // adapterConfig contains properties used to configure the source's adapter.
 // These are automatically populated by envconfig.
 type adapterConfig struct {
 	// Container image
 	Image string `default:"gcr.io/triggermesh/awssnssource"`
+	// Kind of workload adapters run as, unless specified otherwise in
+	// the source's spec
+	WorkloadKind v1alpha1.WorkloadKind `envconfig:"WORKLOAD_KIND" default:"KnativeService"`
+	// Domain under which adapters which run as Deployments are exposed
+	BaseDomain string `envconfig:"BASE_DOMAIN"`
+	// Scheme of the public URL of adapters which run as Deployments
+	URLScheme string `envconfig:"URL_SCHEME" default:"http"`
 	// Configuration accessor for logging/metrics/tracing
 	configs source.ConfigAccessor
 }
@@ -74,9 +92,154 @@ func adapterServiceBuilder(src *v1alpha1.AWSSNSSource, cfg *adapterConfig) commo
 			resource.EnvVar(common.EnvNamespace, src.Namespace),
 			resource.EnvVar(common.EnvSink, sinkURIStr),
 			resource.EnvVar(common.EnvARN, src.Spec.ARN.String()),
-			resource.EnvVars(common.MakeSecurityCredentialsEnvVars(src.Spec.Credentials)...),
+			common.SecurityCredentials(src, src.Spec.Credentials),
+			resource.EnvVars(common.MakeEndpointEnvVars(src.Spec.ARN.Service, src.Spec.Endpoint)...),
+			resource.EnvVar(common.EnvMetricsPrometheusPort, strconv.Itoa(int(metricsPrometheusPort))),
+			resource.EnvVars(cfg.configs.ToEnvVars()...),
+
+			common.AdapterOverrides(src.Spec.AdapterOverrides),
+		)
+	}
+}
+
+// adapterDeploymentBuilder returns an AdapterDeploymentBuilderFunc for the
+// given source object and adapter config.
+func adapterDeploymentBuilder(src *v1alpha1.AWSSNSSource, cfg *adapterConfig) common.AdapterDeploymentBuilderFunc {
+	adapterName := common.AdapterName(src)
+
+	return func(sinkURI *apis.URL) *appsv1.Deployment {
+		name := kmeta.ChildName(adapterName+"-", src.Name)
+
+		var sinkURIStr string
+		if sinkURI != nil {
+			sinkURIStr = sinkURI.String()
+		}
+
+		return resource.NewDeployment(src.Namespace, name,
+			resource.Controller(src),
+
+			resource.Label(common.AppNameLabel, adapterName),
+			resource.Label(common.AppInstanceLabel, src.Name),
+			resource.Label(common.AppComponentLabel, common.AdapterComponent),
+			resource.Label(common.AppPartOfLabel, common.PartOf),
+			resource.Label(common.AppManagedByLabel, common.ManagedBy),
+
+			resource.Selector(common.AppNameLabel, adapterName),
+			resource.Selector(common.AppInstanceLabel, src.Name),
+			resource.PodLabel(common.AppComponentLabel, common.AdapterComponent),
+			resource.PodLabel(common.AppPartOfLabel, common.PartOf),
+			resource.PodLabel(common.AppManagedByLabel, common.ManagedBy),
+
+			resource.Image(cfg.Image),
+			resource.Port(adapterPortName, adapterPort),
+			resource.Port("metrics", int32(metricsPrometheusPort)),
+			resource.Probe("/health", adapterPortName),
+
+			resource.EnvVar(common.EnvName, src.Name),
+			resource.EnvVar(common.EnvNamespace, src.Namespace),
+			resource.EnvVar(common.EnvSink, sinkURIStr),
+			resource.EnvVar(common.EnvARN, src.Spec.ARN.String()),
+			common.SecurityCredentials(src, src.Spec.Credentials),
+			resource.EnvVars(common.MakeEndpointEnvVars(src.Spec.ARN.Service, src.Spec.Endpoint)...),
 			resource.EnvVar(common.EnvMetricsPrometheusPort, strconv.Itoa(int(metricsPrometheusPort))),
 			resource.EnvVars(cfg.configs.ToEnvVars()...),
+
+			common.AdapterOverrides(src.Spec.AdapterOverrides),
 		)
 	}
 }
+
+// makeAdapterService returns a Service which exposes the Pods of an adapter
+// that runs as a Deployment.
+func makeAdapterService(src *v1alpha1.AWSSNSSource) *corev1.Service {
+	adapterName := common.AdapterName(src)
+	name := kmeta.ChildName(adapterName+"-", src.Name)
+
+	return resource.NewService(src.Namespace, name,
+		resource.Controller(src),
+
+		resource.Label(common.AppNameLabel, adapterName),
+		resource.Label(common.AppInstanceLabel, src.Name),
+		resource.Label(common.AppComponentLabel, common.AdapterComponent),
+		resource.Label(common.AppPartOfLabel, common.PartOf),
+		resource.Label(common.AppManagedByLabel, common.ManagedBy),
+
+		resource.Selector(common.AppNameLabel, adapterName),
+		resource.Selector(common.AppInstanceLabel, src.Name),
+		resource.ServicePort(adapterPortName, adapterServicePort, adapterPortName),
+	)
+}
+
+// makeAdapterRoute returns an object which routes external traffic to the
+// Service of an adapter that runs as a Deployment, or nil if the source
+// doesn't define any route.
+func makeAdapterRoute(src *v1alpha1.AWSSNSSource, cfg *adapterConfig) *unstructured.Unstructured {
+	route := src.Spec.Route
+	if route == nil {
+		return nil
+	}
+
+	adapterName := common.AdapterName(src)
+	name := kmeta.ChildName(adapterName+"-", src.Name)
+
+	opts := []resource.ObjectOption{
+		resource.Controller(src),
+
+		resource.Label(common.AppNameLabel, adapterName),
+		resource.Label(common.AppInstanceLabel, src.Name),
+		resource.Label(common.AppComponentLabel, common.AdapterComponent),
+		resource.Label(common.AppPartOfLabel, common.PartOf),
+		resource.Label(common.AppManagedByLabel, common.ManagedBy),
+	}
+
+	host := publicHost(src, cfg)
+
+	switch route.Kind {
+	case v1alpha1.RouteKindIngress:
+		if route.IngressClassName != nil {
+			opts = append(opts, resource.IngressClass(*route.IngressClassName))
+		}
+		return resource.NewIngress(src.Namespace, name, host, name, adapterServicePort, opts...)
+
+	case v1alpha1.RouteKindHTTPRoute:
+		if gw := route.Gateway; gw != nil {
+			opts = append(opts, resource.ParentGateway(gw.Namespace, gw.Name))
+		}
+		return resource.NewHTTPRoute(src.Namespace, name, host, name, adapterServicePort, opts...)
+	}
+
+	return nil
+}
+
+// publicURL returns the URL under which an adapter that runs as a Deployment
+// is reachable from outside of the cluster, or nil if this URL can not be
+// determined.
+func publicURL(src *v1alpha1.AWSSNSSource, cfg *adapterConfig) *apis.URL {
+	host := publicHost(src, cfg)
+	if host == "" {
+		return nil
+	}
+
+	return &apis.URL{
+		Scheme: cfg.URLScheme,
+		Host:   host,
+	}
+}
+
+// publicHost returns the host name under which an adapter that runs as a
+// Deployment is reachable from outside of the cluster. The host name set in
+// the source's route has precedence over the one derived from the base domain
+// configured in the controller.
+func publicHost(src *v1alpha1.AWSSNSSource, cfg *adapterConfig) string {
+	if r := src.Spec.Route; r != nil && r.Host != "" {
+		return r.Host
+	}
+
+	if cfg.BaseDomain == "" {
+		return ""
+	}
+
+	name := kmeta.ChildName(common.AdapterName(src)+"-", src.Name)
+
+	return name + "." + src.Namespace + "." + cfg.BaseDomain
+}
@@ -22,10 +22,18 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+
 	"knative.dev/eventing/pkg/reconciler/source"
 	k8sclient "knative.dev/pkg/client/injection/kube/client"
+	serviceinformerv1 "knative.dev/pkg/client/injection/kube/informers/core/v1/service"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/logging"
 
 	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
 	informerv1alpha1 "github.com/triggermesh/aws-event-sources/pkg/client/generated/injection/informers/sources/v1alpha1/awssnssource"
@@ -52,9 +60,14 @@ func NewController(
 	}
 	envconfig.MustProcess(app, adapterCfg)
 
+	svcInformer := serviceinformerv1.Get(ctx)
+
 	r := &Reconciler{
-		adapterCfg: adapterCfg,
-		secretsCli: k8sclient.Get(ctx).CoreV1().Secrets,
+		adapterCfg:    adapterCfg,
+		secretsCli:    k8sclient.Get(ctx).CoreV1().Secrets,
+		svcClient:     k8sclient.Get(ctx).CoreV1().Services,
+		dynamicClient: dynamicclient.Get(ctx),
+		svcLister:     svcInformer.Lister().Services,
 	}
 	impl := reconcilerv1alpha1.NewImpl(ctx, r)
 
@@ -64,8 +77,87 @@ func NewController(
 		impl.EnqueueKey,
 		impl.EnqueueControllerOf,
 	)
+	r.base.PreflightProbe = probeTopic
+	r.base.EnqueueAfter = impl.EnqueueAfter
+
+	r.deplBase = common.NewGenericDeploymentReconciler(
+		ctx,
+		typ.GetGroupVersionKind(),
+		impl.EnqueueKey,
+		impl.EnqueueControllerOf,
+	)
+	r.deplBase.PreflightProbe = probeTopic
+	r.deplBase.EnqueueAfter = impl.EnqueueAfter
+
+	r.routeListers = newRouteListers(ctx, typ.GetGroupVersionKind(), impl.EnqueueControllerOf)
+
+	svcInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterControllerGVK(typ.GetGroupVersionKind()),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
 
 	informerv1alpha1.Get(ctx).Informer().AddEventHandlerWithResyncPeriod(controller.HandleAll(impl.Enqueue), informerResyncPeriod)
 
 	return impl
 }
+
+// newRouteListers returns listers for the kinds of routes which are served by
+// the cluster, after attaching an event handler to their informers and
+// waiting for the synchronization of their caches.
+//
+// Like the Knative Service informer, these informers are not injected, so
+// that the controller doesn't wait for the synchronization of caches of
+// kinds which are not installed in the cluster.
+func newRouteListers(ctx context.Context, gvk schema.GroupVersionKind,
+	adapterHandlerFn func(obj interface{}),
+) map[schema.GroupVersionResource]cache.GenericLister {
+
+	informerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicclient.Get(ctx),
+		controller.GetResyncPeriod(ctx))
+
+	listers := make(map[schema.GroupVersionResource]cache.GenericLister, len(routeGVRs))
+
+	for _, gvr := range routeGVRs {
+		if !isResourceServed(k8sclient.Get(ctx).Discovery(), gvr) {
+			logging.FromContext(ctx).Warnf("API of %s unavailable, adapters can not be exposed "+
+				"by routes of that kind", gvr.GroupResource())
+			continue
+		}
+
+		informer := informerFactory.ForResource(gvr)
+		informer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: controller.FilterControllerGVK(gvk),
+			Handler:    controller.HandleAll(adapterHandlerFn),
+		})
+
+		listers[gvr] = informer.Lister()
+	}
+
+	if len(listers) > 0 {
+		informerFactory.Start(ctx.Done())
+		for gvr, ok := range informerFactory.WaitForCacheSync(ctx.Done()) {
+			if !ok {
+				logging.FromContext(ctx).Fatalf("Failed to wait for cache of %s to sync", gvr.GroupResource())
+			}
+		}
+	}
+
+	return listers
+}
+
+// isResourceServed returns whether the given API resource is served by the
+// cluster.
+func isResourceServed(cli discovery.DiscoveryInterface, gvr schema.GroupVersionResource) bool {
+	resList, err := cli.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return false
+	}
+
+	for _, res := range resList.APIResources {
+		if res.Name == gvr.Resource {
+			return true
+		}
+	}
+
+	return false
+}
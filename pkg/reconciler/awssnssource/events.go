@@ -25,4 +25,13 @@ const (
 	ReasonFailedSubscribe = "FailedSubscribe"
 	// ReasonFailedUnsubscribe indicates a failure during the deletion of a SNS subscription.
 	ReasonFailedUnsubscribe = "FailedUnsubscribe"
+
+	// ReasonAdapterDelete indicates that an adapter object was deleted after a change of workload kind.
+	ReasonAdapterDelete = "DeleteAdapter"
+	// ReasonFailedAdapterDelete indicates a failure to delete an adapter object after a change of workload kind.
+	ReasonFailedAdapterDelete = "FailedAdapterDelete"
+	// ReasonAdapterExpose indicates that an object which exposes the adapter was created or updated.
+	ReasonAdapterExpose = "ExposeAdapter"
+	// ReasonFailedAdapterExpose indicates a failure to create or update an object which exposes the adapter.
+	ReasonFailedAdapterExpose = "FailedExposeAdapter"
 )
@@ -20,7 +20,12 @@ import (
 	"context"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
 	"knative.dev/pkg/reconciler"
 
 	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
@@ -30,11 +35,22 @@ import (
 
 // Reconciler implements controller.Reconciler for the event source type.
 type Reconciler struct {
-	base       common.GenericServiceReconciler
+	// adapters which run as Knative Services
+	base common.GenericServiceReconciler
+	// adapters which run as Deployments
+	deplBase common.GenericDeploymentReconciler
+
 	adapterCfg *adapterConfig
 
 	// API clients
-	secretsCli func(namespace string) coreclientv1.SecretInterface
+	secretsCli    func(namespace string) coreclientv1.SecretInterface
+	svcClient     func(namespace string) coreclientv1.ServiceInterface
+	dynamicClient dynamic.Interface
+
+	// objects listers
+	svcLister func(namespace string) corelistersv1.ServiceNamespaceLister
+	// only contains the kinds of routes which are served by the cluster
+	routeListers map[schema.GroupVersionResource]cache.GenericLister
 }
 
 // Check that our Reconciler implements Interface
@@ -48,10 +64,20 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, src *v1alpha1.AWSSNSSour
 	// inject source into context for usage in reconciliation logic
 	ctx = v1alpha1.WithSource(ctx, src)
 
-	if err := r.base.ReconcileSource(ctx, adapterServiceBuilder(src, r.adapterCfg)); err != nil {
+	var err error
+	switch r.workloadKind(src) {
+	case v1alpha1.WorkloadKindDeployment:
+		err = r.reconcileDeploymentAdapter(ctx, src)
+	default:
+		err = r.reconcileKnServiceAdapter(ctx, src)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to reconcile source: %w", err)
 	}
 
+	// Secrets referenced by the source are tracked by the base reconciler,
+	// so the subscription is re-synchronized with up-to-date credentials
+	// whenever these Secrets change
 	return r.ensureSubscribed(ctx)
 }
 
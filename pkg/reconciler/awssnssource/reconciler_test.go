@@ -29,6 +29,7 @@ import (
 	"knative.dev/eventing/pkg/reconciler/source"
 	fakek8sinjectionclient "knative.dev/pkg/client/injection/kube/client/fake"
 	"knative.dev/pkg/controller"
+	fakedynamicclient "knative.dev/pkg/injection/clients/dynamicclient/fake"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/resolver"
 	fakeservinginjectionclient "knative.dev/serving/pkg/client/injection/client/fake"
@@ -43,8 +44,9 @@ import (
 
 func TestReconcileSource(t *testing.T) {
 	adapterCfg := &adapterConfig{
-		Image:   "registry/image:tag",
-		configs: &source.EmptyVarsGenerator{},
+		Image:        "registry/image:tag",
+		WorkloadKind: v1alpha1.WorkloadKindKnativeService,
+		configs:      &source.EmptyVarsGenerator{},
 	}
 
 	var (
@@ -60,15 +62,31 @@ func TestReconcileSource(t *testing.T) {
 func reconcilerCtor(cfg *adapterConfig) Ctor {
 	return func(t *testing.T, ctx context.Context, ls *Listers) controller.Reconciler {
 		base := common.GenericServiceReconciler{
-			SinkResolver: resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
-			Lister:       ls.GetServiceLister().Services,
-			Client:       fakeservinginjectionclient.Get(ctx).ServingV1().Services,
+			SinkResolver:         resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
+			Lister:               ls.GetServiceLister().Services,
+			Client:               fakeservinginjectionclient.Get(ctx).ServingV1().Services,
+			ServiceAccountClient: fakek8sinjectionclient.Get(ctx).CoreV1().ServiceAccounts,
+			ServiceAccountLister: ls.GetServiceAccountLister().ServiceAccounts,
+			ServingAvailable:     true,
+		}
+
+		deplBase := common.GenericDeploymentReconciler{
+			SinkResolver:         resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
+			Lister:               ls.GetDeploymentLister().Deployments,
+			Client:               fakek8sinjectionclient.Get(ctx).AppsV1().Deployments,
+			PodClient:            fakek8sinjectionclient.Get(ctx).CoreV1().Pods,
+			ServiceAccountClient: fakek8sinjectionclient.Get(ctx).CoreV1().ServiceAccounts,
+			ServiceAccountLister: ls.GetServiceAccountLister().ServiceAccounts,
 		}
 
 		r := &Reconciler{
-			base:       base,
-			adapterCfg: cfg,
-			secretsCli: fakek8sinjectionclient.Get(ctx).CoreV1().Secrets,
+			base:          base,
+			deplBase:      deplBase,
+			adapterCfg:    cfg,
+			secretsCli:    fakek8sinjectionclient.Get(ctx).CoreV1().Secrets,
+			svcClient:     fakek8sinjectionclient.Get(ctx).CoreV1().Services,
+			dynamicClient: fakedynamicclient.Get(ctx),
+			svcLister:     ls.GetCoreServiceLister().Services,
 		}
 
 		return reconcilerv1alpha1.NewReconciler(ctx, logging.FromContext(ctx),
@@ -86,7 +104,7 @@ func newEventSource() *v1alpha1.AWSSNSSource {
 				"DeliveryPolicy": aws.String(`{"healthyRetryPolicy":{"numRetries":5}}`),
 			},
 			Credentials: v1alpha1.AWSSecurityCredentials{
-				AccessKeyID: v1alpha1.ValueFromField{
+				AccessKeyID: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "test-secret",
@@ -94,7 +112,7 @@ func newEventSource() *v1alpha1.AWSSNSSource {
 						Key: "keyId",
 					},
 				},
-				SecretAccessKey: v1alpha1.ValueFromField{
+				SecretAccessKey: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "test-secret",
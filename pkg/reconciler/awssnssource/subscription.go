@@ -24,7 +24,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/controller"
@@ -33,11 +32,10 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sns"
 
 	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common"
 	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/event"
 	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/skip"
 )
@@ -52,18 +50,15 @@ func (r *Reconciler) ensureSubscribed(ctx context.Context) error {
 	src := v1alpha1.SourceFromContext(ctx)
 	status := &src.(*v1alpha1.AWSSNSSource).Status
 
-	adapter, err := r.base.FindAdapter(src)
-	switch {
-	case isNotFound(err):
-		return nil
-	case err != nil:
-		return fmt.Errorf("finding receive adapter: %w", err)
+	// the address of the source is the public URL of its adapter,
+	// regardless of the kind of workload the adapter runs as
+	var url *apis.URL
+	if addr := status.Address; addr != nil {
+		url = addr.URL
 	}
 
-	url := adapter.Status.URL
-
 	// skip this cycle if the adapter URL wasn't yet determined
-	if !adapter.IsReady() || url == nil {
+	if !status.GetCondition(v1alpha1.ConditionDeployed).IsTrue() || url == nil {
 		status.MarkNotSubscribed(v1alpha1.AWSSNSReasonNoURL,
 			"The receive adapter did not report its public URL yet")
 		return nil
@@ -71,8 +66,16 @@ func (r *Reconciler) ensureSubscribed(ctx context.Context) error {
 
 	spec := src.(apis.HasSpec).GetUntypedSpec().(v1alpha1.AWSSNSSourceSpec)
 
-	snsClient, err := newSNSClient(r.secretsCli(src.GetNamespace()), spec.ARN.Region, &spec.Credentials)
-	if err != nil {
+	snsClient, err := r.newSNSClient(src.(*v1alpha1.AWSSNSSource))
+	switch {
+	case errors.Is(err, common.ErrAmbientCredentialsDisallowed):
+		// subscriptions are managed by the controller, which must not
+		// use its own identity on behalf of the source
+		status.MarkNotSubscribed(v1alpha1.AWSSNSReasonAmbientCredentials,
+			"Subscriptions can not be managed using ambient credentials")
+		return controller.NewPermanentError(reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedSubscribe,
+			"Error creating SNS client: %s", err))
+	case err != nil:
 		status.MarkNotSubscribed(v1alpha1.AWSSNSReasonNoClient, "Cannot obtain SNS client")
 		return fmt.Errorf("%w", reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedSubscribe,
 			"Error creating SNS client: %s", err))
@@ -120,10 +123,12 @@ func (r *Reconciler) ensureUnsubscribed(ctx context.Context) error {
 		return nil
 	}
 
-	spec := src.(apis.HasSpec).GetUntypedSpec().(v1alpha1.AWSSNSSourceSpec)
-
-	snsClient, err := newSNSClient(r.secretsCli(src.GetNamespace()), spec.ARN.Region, &spec.Credentials)
+	snsClient, err := r.newSNSClient(src.(*v1alpha1.AWSSNSSource))
 	switch {
+	case errors.Is(err, common.ErrAmbientCredentialsDisallowed):
+		event.Warn(ctx, ReasonFailedUnsubscribe, "Ambient credentials can not be used to finalize "+
+			"subscription %q. Ignoring: %s", *subsARN, err)
+		return nil
 	case isNotFound(err):
 		// the finalizer is unlikely to recover from a missing Secret,
 		// so we simply record a warning event and return
@@ -162,70 +167,15 @@ func (r *Reconciler) ensureUnsubscribed(ctx context.Context) error {
 		"Subscription %q was successfully deleted", *subsARN)
 }
 
-// newSNSClient returns a new SNS client for the given region using static credentials.
-func newSNSClient(cli coreclientv1.SecretInterface,
-	region string, creds *v1alpha1.AWSSecurityCredentials) (*sns.SNS, error) {
-
-	credsValue, err := awsCredentials(cli, creds)
+// newSNSClient returns a new SNS client for the given source.
+func (r *Reconciler) newSNSClient(src *v1alpha1.AWSSNSSource) (*sns.SNS, error) {
+	sess, err := common.NewAWSSession(r.secretsCli(src.Namespace), r.base.ServiceAccountClient(src.Namespace),
+		src, src.Spec.ARN.Region)
 	if err != nil {
-		return nil, fmt.Errorf("reading AWS security credentials: %w", err)
-	}
-
-	cfg := session.Must(session.NewSession(aws.NewConfig().
-		WithRegion(region).
-		WithCredentials(credentials.NewStaticCredentialsFromCreds(*credsValue)),
-	))
-
-	return sns.New(cfg), nil
-}
-
-// awsCredentials returns the AWS security credentials referenced in the
-// source's spec.
-func awsCredentials(cli coreclientv1.SecretInterface,
-	creds *v1alpha1.AWSSecurityCredentials) (*credentials.Value, error) {
-
-	accessKeyID := creds.AccessKeyID.Value
-	secretAccessKey := creds.SecretAccessKey.Value
-
-	// cache a Secret object by name to avoid GET-ing the same Secret
-	// object multiple times
-	var secretCache map[string]*corev1.Secret
-
-	if vfs := creds.AccessKeyID.ValueFromSecret; vfs != nil {
-		secr, err := cli.Get(context.Background(), vfs.Name, metav1.GetOptions{})
-		if err != nil {
-			return nil, err
-		}
-
-		// cache Secret containing the access key ID so it can be reused
-		// below in case the same Secret contains the secret access key
-		secretCache = map[string]*corev1.Secret{
-			vfs.Name: secr,
-		}
-
-		accessKeyID = string(secr.Data[vfs.Key])
-	}
-
-	if vfs := creds.SecretAccessKey.ValueFromSecret; vfs != nil {
-		var secr *corev1.Secret
-		var err error
-
-		if secretCache != nil && secretCache[vfs.Name] != nil {
-			secr = secretCache[vfs.Name]
-		} else {
-			secr, err = cli.Get(context.Background(), vfs.Name, metav1.GetOptions{})
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		secretAccessKey = string(secr.Data[vfs.Key])
+		return nil, err
 	}
 
-	return &credentials.Value{
-		AccessKeyID:     accessKeyID,
-		SecretAccessKey: secretAccessKey,
-	}, nil
+	return sns.New(sess), nil
 }
 
 // isNotFound returns whether the given error indicates that some resource was
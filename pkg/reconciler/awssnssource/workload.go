@@ -0,0 +1,368 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssnssource
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/reconciler"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/event"
+)
+
+// Resources of the objects which route external traffic to adapters.
+var (
+	ingressGVR   = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	httpRouteGVR = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+
+	routeGVRs = []schema.GroupVersionResource{ingressGVR, httpRouteGVR}
+)
+
+// workloadKind returns the kind of workload the adapter of the given source
+// runs as.
+func (r *Reconciler) workloadKind(src *v1alpha1.AWSSNSSource) v1alpha1.WorkloadKind {
+	if k := src.Spec.WorkloadKind; k != "" {
+		return k
+	}
+	return r.adapterCfg.WorkloadKind
+}
+
+// reconcileKnServiceAdapter reconciles an adapter which runs as a Knative
+// Service, after cleaning up the objects of a previous Deployment workload.
+func (r *Reconciler) reconcileKnServiceAdapter(ctx context.Context, src *v1alpha1.AWSSNSSource) error {
+	if !r.base.ServingAvailable {
+		src.GetStatusManager().MarkNotDeployed(v1alpha1.ReasonUnsupportedWorkload,
+			"The Knative Serving API is not available in the cluster")
+		return controller.NewPermanentError(reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedAdapterExpose,
+			"Cannot run adapter as a Knative Service: the Knative Serving API is not available"))
+	}
+
+	if err := r.deleteDeploymentAdapter(ctx, src); err != nil {
+		return err
+	}
+
+	return r.base.ReconcileSource(ctx, adapterServiceBuilder(src, r.adapterCfg))
+}
+
+// reconcileDeploymentAdapter reconciles an adapter which runs as a
+// Deployment, along with the objects which expose it, after cleaning up the
+// objects of a previous Knative Service workload.
+func (r *Reconciler) reconcileDeploymentAdapter(ctx context.Context, src *v1alpha1.AWSSNSSource) error {
+	if err := r.deleteKnServiceAdapter(ctx, src); err != nil {
+		return err
+	}
+
+	if err := r.reconcileAdapterService(ctx, src); err != nil {
+		return fmt.Errorf("failed to reconcile adapter Service: %w", err)
+	}
+
+	admitted, err := r.reconcileAdapterRoute(ctx, src)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile adapter route: %w", err)
+	}
+
+	err = r.deplBase.ReconcileSource(ctx, adapterDeploymentBuilder(src, r.adapterCfg))
+
+	// Deployments are not addressable by themselves, the address of the
+	// source is the public URL of the route in front of its adapter. When
+	// the source defines a route, this URL isn't reachable until the route
+	// is admitted.
+	var url *apis.URL
+	if src.Spec.Route == nil || admitted {
+		url = publicURL(src, r.adapterCfg)
+	}
+	src.GetStatusManager().SetAddress(url)
+
+	return err
+}
+
+// reconcileAdapterService reconciles the Kubernetes Service which exposes
+// the Pods of an adapter that runs as a Deployment.
+func (r *Reconciler) reconcileAdapterService(ctx context.Context, src *v1alpha1.AWSSNSSource) error {
+	desired := makeAdapterService(src)
+
+	current, err := r.svcLister(src.Namespace).Get(desired.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		svc, err := r.svcClient(src.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			return reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedAdapterExpose,
+				"Failed to create adapter Service %q: %s", desired.Name, err)
+		}
+		event.Normal(ctx, ReasonAdapterExpose, "Created adapter Service %q", svc.Name)
+
+		return nil
+
+	case err != nil:
+		return fmt.Errorf("failed to get adapter Service from cache: %w", err)
+	}
+
+	if equality.Semantic.DeepDerivative(desired.ObjectMeta, current.ObjectMeta) &&
+		equality.Semantic.DeepDerivative(desired.Spec, current.Spec) {
+
+		return nil
+	}
+
+	// resourceVersion must be returned to the API server unmodified for
+	// optimistic concurrency, as per Kubernetes API conventions
+	desired.ResourceVersion = current.ResourceVersion
+
+	// the cluster IP is immutable once allocated
+	desired.Spec.ClusterIP = current.Spec.ClusterIP
+
+	svc, err := r.svcClient(src.Namespace).Update(ctx, desired, metav1.UpdateOptions{})
+	if err != nil {
+		return reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedAdapterExpose,
+			"Failed to update adapter Service %q: %s", desired.Name, err)
+	}
+	event.Normal(ctx, ReasonAdapterExpose, "Updated adapter Service %q", svc.Name)
+
+	return nil
+}
+
+// reconcileAdapterRoute reconciles the object which routes external traffic
+// to the Service of an adapter that runs as a Deployment, and deletes routes
+// of kinds which are not used by the source. It returns whether the route was
+// admitted by the controller which implements it.
+func (r *Reconciler) reconcileAdapterRoute(ctx context.Context, src *v1alpha1.AWSSNSSource) (bool, error) {
+	desired := makeAdapterRoute(src, r.adapterCfg)
+
+	var kind v1alpha1.RouteKind
+	if src.Spec.Route != nil {
+		kind = src.Spec.Route.Kind
+	}
+
+	if kind != v1alpha1.RouteKindIngress {
+		if err := r.deleteAdapterRoute(ctx, src, ingressGVR); err != nil {
+			return false, err
+		}
+	}
+	if kind != v1alpha1.RouteKindHTTPRoute {
+		if err := r.deleteAdapterRoute(ctx, src, httpRouteGVR); err != nil {
+			return false, err
+		}
+	}
+
+	if desired == nil {
+		return false, nil
+	}
+
+	gvr := ingressGVR
+	if kind == v1alpha1.RouteKindHTTPRoute {
+		gvr = httpRouteGVR
+	}
+
+	lister, ok := r.routeListers[gvr]
+	if !ok {
+		return false, controller.NewPermanentError(reconciler.NewEvent(corev1.EventTypeWarning,
+			ReasonFailedAdapterExpose, "Cannot expose adapter with a %s: the %s API is not available",
+			kind, gvr.GroupResource()))
+	}
+
+	cli := r.dynamicClient.Resource(gvr).Namespace(src.Namespace)
+
+	obj, err := lister.ByNamespace(src.Namespace).Get(desired.GetName())
+	switch {
+	case apierrors.IsNotFound(err):
+		rt, err := cli.Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			return false, reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedAdapterExpose,
+				"Failed to create adapter %s %q: %s", kind, desired.GetName(), err)
+		}
+		event.Normal(ctx, ReasonAdapterExpose, "Created adapter %s %q", kind, rt.GetName())
+
+		return false, nil
+
+	case err != nil:
+		return false, fmt.Errorf("failed to get adapter %s from cache: %w", kind, err)
+	}
+
+	current := obj.(*unstructured.Unstructured)
+
+	if equality.Semantic.DeepDerivative(desired.GetLabels(), current.GetLabels()) &&
+		equality.Semantic.DeepDerivative(desired.Object["spec"], current.Object["spec"]) {
+
+		return isRouteAdmitted(kind, current), nil
+	}
+
+	// resourceVersion must be returned to the API server unmodified for
+	// optimistic concurrency, as per Kubernetes API conventions
+	desired.SetResourceVersion(current.GetResourceVersion())
+
+	rt, err := cli.Update(ctx, desired, metav1.UpdateOptions{})
+	if err != nil {
+		return false, reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedAdapterExpose,
+			"Failed to update adapter %s %q: %s", kind, desired.GetName(), err)
+	}
+	event.Normal(ctx, ReasonAdapterExpose, "Updated adapter %s %q", kind, rt.GetName())
+
+	return isRouteAdmitted(kind, rt), nil
+}
+
+// isRouteAdmitted returns whether the given route was admitted by the
+// controller which implements it, as reported in its status:
+//   - an Ingress is admitted once it was assigned a load-balancer
+//   - an HTTPRoute is admitted once it was accepted by a parent Gateway
+func isRouteAdmitted(kind v1alpha1.RouteKind, rt *unstructured.Unstructured) bool {
+	switch kind {
+	case v1alpha1.RouteKindIngress:
+		lbs, _, _ := unstructured.NestedSlice(rt.Object, "status", "loadBalancer", "ingress")
+		return len(lbs) > 0
+
+	case v1alpha1.RouteKindHTTPRoute:
+		parents, _, _ := unstructured.NestedSlice(rt.Object, "status", "parents")
+		for _, p := range parents {
+			parent, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			conds, _, _ := unstructured.NestedSlice(parent, "conditions")
+			for _, c := range conds {
+				cond, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if cond["type"] == "Accepted" && cond["status"] == string(metav1.ConditionTrue) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// deleteAdapterRoute deletes the route of the given resource type which is
+// controlled by the source, if it exists.
+func (r *Reconciler) deleteAdapterRoute(ctx context.Context, src *v1alpha1.AWSSNSSource,
+	gvr schema.GroupVersionResource) error {
+
+	// routes of kinds which are not served by the cluster can not exist
+	lister, ok := r.routeListers[gvr]
+	if !ok {
+		return nil
+	}
+
+	name := kmeta.ChildName(common.AdapterName(src)+"-", src.Name)
+
+	obj, err := lister.ByNamespace(src.Namespace).Get(name)
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get adapter route from cache: %w", err)
+	}
+
+	rt := obj.(*unstructured.Unstructured)
+
+	if !metav1.IsControlledBy(rt, src) {
+		return nil
+	}
+
+	return deleteAdapterObject(ctx, rt.GetKind(), name, func() error {
+		return r.dynamicClient.Resource(gvr).Namespace(src.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	})
+}
+
+// deleteDeploymentAdapter deletes the objects of an adapter which used to
+// run as a Deployment. The presence of the adapter's Kubernetes Service
+// indicates that such objects may exist.
+func (r *Reconciler) deleteDeploymentAdapter(ctx context.Context, src *v1alpha1.AWSSNSSource) error {
+	name := kmeta.ChildName(common.AdapterName(src)+"-", src.Name)
+
+	svc, err := r.svcLister(src.Namespace).Get(name)
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get adapter Service from cache: %w", err)
+	}
+
+	if !metav1.IsControlledBy(svc, src) {
+		return nil
+	}
+
+	for _, gvr := range routeGVRs {
+		if err := r.deleteAdapterRoute(ctx, src, gvr); err != nil {
+			return err
+		}
+	}
+
+	depl, err := r.deplBase.FindAdapter(src)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("failed to get adapter Deployment from cache: %w", err)
+	default:
+		err := deleteAdapterObject(ctx, "Deployment", depl.Name, func() error {
+			return r.deplBase.Client(src.Namespace).Delete(ctx, depl.Name, metav1.DeleteOptions{})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return deleteAdapterObject(ctx, "Service", svc.Name, func() error {
+		return r.svcClient(src.Namespace).Delete(ctx, svc.Name, metav1.DeleteOptions{})
+	})
+}
+
+// deleteKnServiceAdapter deletes the Knative Service of an adapter which
+// used to run as a Knative Service.
+func (r *Reconciler) deleteKnServiceAdapter(ctx context.Context, src *v1alpha1.AWSSNSSource) error {
+	if !r.base.ServingAvailable {
+		return nil
+	}
+
+	ksvc, err := r.base.FindAdapter(src)
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get adapter Knative Service from cache: %w", err)
+	}
+
+	return deleteAdapterObject(ctx, "Knative Service", ksvc.Name, func() error {
+		return r.base.Client(src.Namespace).Delete(ctx, ksvc.Name, metav1.DeleteOptions{})
+	})
+}
+
+// deleteAdapterObject deletes an object of an adapter using the given delete
+// function, and records the outcome as an API event.
+func deleteAdapterObject(ctx context.Context, kind, name string, deleteFn func() error) error {
+	if err := deleteFn(); err != nil && !apierrors.IsNotFound(err) {
+		return reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedAdapterDelete,
+			"Failed to delete adapter %s %q: %s", kind, name, err)
+	}
+	event.Normal(ctx, ReasonAdapterDelete, "Deleted adapter %s %q", kind, name)
+
+	return nil
+}
@@ -0,0 +1,300 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssnssource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/controller"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/resource"
+)
+
+func TestWorkloadKind(t *testing.T) {
+	r := &Reconciler{
+		adapterCfg: &adapterConfig{
+			WorkloadKind: v1alpha1.WorkloadKindKnativeService,
+		},
+	}
+
+	src := newEventSource()
+	assert.Equal(t, v1alpha1.WorkloadKindKnativeService, r.workloadKind(src))
+
+	src.Spec.WorkloadKind = v1alpha1.WorkloadKindDeployment
+	assert.Equal(t, v1alpha1.WorkloadKindDeployment, r.workloadKind(src))
+}
+
+func TestPublicURL(t *testing.T) {
+	testCases := map[string]struct {
+		route      *v1alpha1.AdapterRoute
+		baseDomain string
+		expectURL  *apis.URL
+	}{
+		"Host from route": {
+			route:      &v1alpha1.AdapterRoute{Kind: v1alpha1.RouteKindIngress, Host: "sns.example.com"},
+			baseDomain: "apps.example.com",
+			expectURL:  &apis.URL{Scheme: "https", Host: "sns.example.com"},
+		},
+		"Host from base domain": {
+			route:      &v1alpha1.AdapterRoute{Kind: v1alpha1.RouteKindIngress},
+			baseDomain: "apps.example.com",
+			expectURL:  &apis.URL{Scheme: "https", Host: "awssnssource-test.testns.apps.example.com"},
+		},
+		"No host": {
+			route:     &v1alpha1.AdapterRoute{Kind: v1alpha1.RouteKindIngress},
+			expectURL: nil,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			src := newEventSource()
+			src.Spec.Route = tc.route
+
+			cfg := &adapterConfig{
+				BaseDomain: tc.baseDomain,
+				URLScheme:  "https",
+			}
+
+			assert.Equal(t, tc.expectURL, publicURL(src, cfg))
+		})
+	}
+}
+
+func TestMakeAdapterRoute(t *testing.T) {
+	cfg := &adapterConfig{
+		BaseDomain: "apps.example.com",
+	}
+
+	testCases := map[string]struct {
+		route      *v1alpha1.AdapterRoute
+		expectKind string
+	}{
+		"No route": {
+			route: nil,
+		},
+		"Ingress": {
+			route:      &v1alpha1.AdapterRoute{Kind: v1alpha1.RouteKindIngress},
+			expectKind: resource.IngressGVK.Kind,
+		},
+		"HTTPRoute": {
+			route: &v1alpha1.AdapterRoute{
+				Kind:    v1alpha1.RouteKindHTTPRoute,
+				Gateway: &v1alpha1.GatewayReference{Name: "public"},
+			},
+			expectKind: resource.HTTPRouteGVK.Kind,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			src := newEventSource()
+			src.Spec.Route = tc.route
+
+			rt := makeAdapterRoute(src, cfg)
+
+			if tc.expectKind == "" {
+				assert.Nil(t, rt)
+				return
+			}
+
+			assert.Equal(t, tc.expectKind, rt.GetKind())
+			assert.Equal(t, "awssnssource-test", rt.GetName())
+			assert.Equal(t, src.Namespace, rt.GetNamespace())
+			assert.Len(t, rt.GetOwnerReferences(), 1)
+		})
+	}
+}
+
+func TestReconcileAdapterRoute(t *testing.T) {
+	cfg := &adapterConfig{
+		BaseDomain: "apps.example.com",
+	}
+
+	admittedStatus := map[string]interface{}{
+		"parents": []interface{}{
+			map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Accepted", "status": "True"},
+				},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		routeStatus    map[string]interface{} // nil: route doesn't exist
+		apiUnavailable bool
+		expectAdmitted bool
+		expectCreate   bool
+		expectErr      bool
+	}{
+		"Route does not exist": {
+			expectCreate: true,
+		},
+		"Route not admitted": {
+			routeStatus: map[string]interface{}{},
+		},
+		"Route admitted": {
+			routeStatus:    admittedStatus,
+			expectAdmitted: true,
+		},
+		"API not served": {
+			apiUnavailable: true,
+			expectErr:      true,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			src := newEventSource()
+			src.Spec.Route = &v1alpha1.AdapterRoute{
+				Kind:    v1alpha1.RouteKindHTTPRoute,
+				Gateway: &v1alpha1.GatewayReference{Name: "public"},
+			}
+
+			ctx := controller.WithEventRecorder(context.Background(), record.NewFakeRecorder(10))
+			ctx = v1alpha1.WithSource(ctx, src)
+
+			dynCli := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+
+			r := &Reconciler{
+				adapterCfg:    cfg,
+				dynamicClient: dynCli,
+				routeListers: map[schema.GroupVersionResource]cache.GenericLister{
+					ingressGVR: newRouteLister(t, ingressGVR),
+				},
+			}
+
+			if !tc.apiUnavailable {
+				var routes []*unstructured.Unstructured
+				if tc.routeStatus != nil {
+					rt := makeAdapterRoute(src, cfg)
+					rt.Object["status"] = tc.routeStatus
+					routes = append(routes, rt)
+				}
+				r.routeListers[httpRouteGVR] = newRouteLister(t, httpRouteGVR, routes...)
+			}
+
+			admitted, err := r.reconcileAdapterRoute(ctx, src)
+
+			if tc.expectErr {
+				assert.True(t, controller.IsPermanentError(err), "Expected a permanent error, got", err)
+				assert.Empty(t, dynCli.Actions())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectAdmitted, admitted)
+
+			if tc.expectCreate {
+				require.Len(t, dynCli.Actions(), 1)
+				assert.Equal(t, "create", dynCli.Actions()[0].GetVerb())
+				assert.Equal(t, httpRouteGVR, dynCli.Actions()[0].GetResource())
+			} else {
+				assert.Empty(t, dynCli.Actions())
+			}
+		})
+	}
+}
+
+func TestIsRouteAdmitted(t *testing.T) {
+	testCases := map[string]struct {
+		kind   v1alpha1.RouteKind
+		status map[string]interface{}
+		expect bool
+	}{
+		"Ingress without load-balancer": {
+			kind:   v1alpha1.RouteKindIngress,
+			status: map[string]interface{}{"loadBalancer": map[string]interface{}{}},
+			expect: false,
+		},
+		"Ingress with load-balancer": {
+			kind: v1alpha1.RouteKindIngress,
+			status: map[string]interface{}{"loadBalancer": map[string]interface{}{
+				"ingress": []interface{}{
+					map[string]interface{}{"ip": "192.0.2.1"},
+				},
+			}},
+			expect: true,
+		},
+		"HTTPRoute without parents": {
+			kind:   v1alpha1.RouteKindHTTPRoute,
+			status: map[string]interface{}{},
+			expect: false,
+		},
+		"HTTPRoute not accepted": {
+			kind: v1alpha1.RouteKindHTTPRoute,
+			status: map[string]interface{}{"parents": []interface{}{
+				map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Accepted", "status": "False"},
+				}},
+			}},
+			expect: false,
+		},
+		"HTTPRoute accepted": {
+			kind: v1alpha1.RouteKindHTTPRoute,
+			status: map[string]interface{}{"parents": []interface{}{
+				map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Accepted", "status": "True"},
+				}},
+			}},
+			expect: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			rt := &unstructured.Unstructured{Object: map[string]interface{}{
+				"status": tc.status,
+			}}
+
+			assert.Equal(t, tc.expect, isRouteAdmitted(tc.kind, rt))
+		})
+	}
+}
+
+// newRouteLister returns a lister for routes of the given resource type,
+// populated with the given objects.
+func newRouteLister(t *testing.T, gvr schema.GroupVersionResource,
+	objs ...*unstructured.Unstructured) cache.GenericLister {
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+
+	for _, o := range objs {
+		require.NoError(t, indexer.Add(o))
+	}
+
+	return cache.NewGenericLister(indexer, gvr.GroupResource())
+}
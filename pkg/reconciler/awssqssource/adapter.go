@@ -17,7 +17,11 @@ limitations under the License.
 package awssqssource
 
 import (
+	"strconv"
+	"time"
+
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	kr "k8s.io/apimachinery/pkg/api/resource"
 
 	"knative.dev/eventing/pkg/reconciler/source"
@@ -29,23 +33,46 @@ import (
 	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/resource"
 )
 
+const (
+	envQueueURL            = "QUEUE_URL"
+	envDecodeNotifications = "DECODE_NOTIFICATIONS"
+	envShutdownGracePeriod = "SHUTDOWN_GRACE_PERIOD"
+	envBatchSize           = "BATCH_SIZE"
+	envBatchWindow         = "BATCH_WINDOW"
+	envBacklogPollInterval = "BACKLOG_POLL_INTERVAL"
+)
+
+// defaultTerminationGracePeriod is the default termination grace period of
+// Kubernetes Pods.
+const defaultTerminationGracePeriod = 30 * time.Second
+
 // adapterConfig contains properties used to configure the source's adapter.
 // These are automatically populated by envconfig.
 type adapterConfig struct {
 	// Container image
 	Image string `default:"gcr.io/triggermesh/awssqssource"`
+	// Name under which the backlog reported by adapters is exposed by the
+	// external metrics API
+	BacklogMetricName string `envconfig:"BACKLOG_METRIC_NAME" default:"awssqssource_approximate_number_of_messages_visible"`
 	// Configuration accessor for logging/metrics/tracing
 	configs source.ConfigAccessor
 }
 
 // adapterDeploymentBuilder returns an AdapterDeploymentBuilderFunc for the
-// given source object and adapter config.
-func adapterDeploymentBuilder(src *v1alpha1.AWSSQSSource, cfg *adapterConfig) common.AdapterDeploymentBuilderFunc {
+// given source object, adapter config and number of replicas.
+func adapterDeploymentBuilder(src *v1alpha1.AWSSQSSource, cfg *adapterConfig,
+	replicas int32) common.AdapterDeploymentBuilderFunc {
+
 	adapterName := common.AdapterName(src)
 
 	return func(sinkURI *apis.URL) *appsv1.Deployment {
 		name := kmeta.ChildName(adapterName+"-", src.Name)
 
+		gracePeriod := defaultTerminationGracePeriod
+		if secs := src.Spec.TerminationGracePeriodSeconds; secs != nil {
+			gracePeriod = time.Duration(*secs) * time.Second
+		}
+
 		var sinkURIStr string
 		if sinkURI != nil {
 			sinkURIStr = sinkURI.String()
@@ -72,11 +99,20 @@ func adapterDeploymentBuilder(src *v1alpha1.AWSSQSSource, cfg *adapterConfig) co
 			resource.EnvVar(common.EnvNamespace, src.Namespace),
 			resource.EnvVar(common.EnvSink, sinkURIStr),
 			resource.EnvVar(common.EnvARN, src.Spec.ARN.String()),
-			resource.EnvVars(common.MakeSecurityCredentialsEnvVars(src.Spec.Credentials)...),
+			resource.EnvVar(envQueueURL, src.Spec.QueueURL),
+			resource.EnvVar(envDecodeNotifications, strconv.FormatBool(src.Spec.DecodeNotifications)),
+			resource.EnvVar(envShutdownGracePeriod, gracePeriod.String()),
+			resource.EnvVars(makeBatchingEnvVars(src.Spec.Batching)...),
+			resource.EnvVars(makeAutoscalingEnvVars(src.Spec.Autoscaling)...),
+			common.SecurityCredentials(src, src.Spec.Credentials),
+			resource.EnvVars(common.MakeEndpointEnvVars(src.Spec.ARN.Service, src.Spec.Endpoint)...),
 			resource.EnvVars(cfg.configs.ToEnvVars()...),
 
 			resource.Port("metrics", 9090),
 
+			resource.Replicas(replicas),
+			resource.TerminationGracePeriod(gracePeriod),
+
 			// CPU throttling can be observed below a limit of 1,
 			// although the CPU usage under load remains below 400m.
 			resource.Requests(
@@ -87,6 +123,43 @@ func adapterDeploymentBuilder(src *v1alpha1.AWSSQSSource, cfg *adapterConfig) co
 				*kr.NewMilliQuantity(1000, kr.DecimalSI),   // 1
 				*kr.NewQuantity(1024*1024*45, kr.BinarySI), // 45Mi
 			),
+
+			common.AdapterOverrides(src.Spec.AdapterOverrides),
 		)
 	}
 }
+
+// makeBatchingEnvVars returns environment variables for the given batching
+// options.
+func makeBatchingEnvVars(opts *v1alpha1.AWSSQSSourceBatching) []corev1.EnvVar {
+	if opts == nil {
+		return nil
+	}
+
+	envVars := []corev1.EnvVar{{
+		Name:  envBatchSize,
+		Value: strconv.Itoa(int(opts.MaxSize)),
+	}}
+
+	if opts.MaxWaitMilliseconds > 0 {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  envBatchWindow,
+			Value: (time.Duration(opts.MaxWaitMilliseconds) * time.Millisecond).String(),
+		})
+	}
+
+	return envVars
+}
+
+// makeAutoscalingEnvVars returns environment variables for the given
+// autoscaling options.
+func makeAutoscalingEnvVars(opts *v1alpha1.AWSSQSSourceAutoscaling) []corev1.EnvVar {
+	if opts == nil {
+		return nil
+	}
+
+	return []corev1.EnvVar{{
+		Name:  envBacklogPollInterval,
+		Value: adapterBacklogReportInterval.String(),
+	}}
+}
@@ -0,0 +1,476 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssqssource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kr "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/reconciler"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/event"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/resource"
+)
+
+const (
+	// Interval at which the controller observes the backlog of the queue
+	// of autoscaled sources. It bounds the delay before an adapter which
+	// was scaled to zero gets scaled up again.
+	backlogPollInterval = 30 * time.Second
+
+	// Interval at which the adapters of autoscaled sources report the
+	// backlog of their queue.
+	adapterBacklogReportInterval = 15 * time.Second
+)
+
+// queueStats contains the approximate numbers of messages in a SQS queue.
+type queueStats struct {
+	// messages available for retrieval
+	visible int64
+	// messages received by a consumer but not yet deleted
+	inFlight int64
+}
+
+// idle returns whether the queue has no message to be handled.
+func (s *queueStats) idle() bool {
+	return s.visible+s.inFlight == 0
+}
+
+// queueStatsFunc returns the approximate numbers of messages in the SQS queue
+// observed by the given source.
+type queueStatsFunc func(ctx context.Context, src *v1alpha1.AWSSQSSource) (*queueStats, error)
+
+// queueStatsReader reads the attributes of the queues of sources using their
+// AWS security credentials.
+//
+// The SQS client and the URL of the queue of each source are cached across
+// reconciliations, and renewed whenever the source's spec or one of the
+// Secrets it references changes.
+type queueStatsReader struct {
+	secretCli    func(namespace string) coreclientv1.SecretInterface
+	saCli        func(namespace string) coreclientv1.ServiceAccountInterface
+	secretLister func(namespace string) corelistersv1.SecretNamespaceLister
+
+	mu      sync.Mutex
+	clients map[types.NamespacedName]*queueClient
+}
+
+// queueClient is a SQS client for the queue of a given source.
+type queueClient struct {
+	// identifies the state of the source and of its Secrets which the
+	// client was created from
+	version string
+
+	cli      *sqs.SQS
+	queueURL string
+}
+
+// newQueueStatsReader returns a queueStatsReader which uses the given
+// clients and lister.
+func newQueueStatsReader(secretCli func(namespace string) coreclientv1.SecretInterface,
+	saCli func(namespace string) coreclientv1.ServiceAccountInterface,
+	secretLister func(namespace string) corelistersv1.SecretNamespaceLister) *queueStatsReader {
+
+	return &queueStatsReader{
+		secretCli:    secretCli,
+		saCli:        saCli,
+		secretLister: secretLister,
+		clients:      make(map[types.NamespacedName]*queueClient),
+	}
+}
+
+// queueStats implements queueStatsFunc.
+func (r *queueStatsReader) queueStats(ctx context.Context, src *v1alpha1.AWSSQSSource) (*queueStats, error) {
+	qc, err := r.client(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	attrVisible := sqs.QueueAttributeNameApproximateNumberOfMessages
+	attrInFlight := sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible
+
+	out, err := qc.cli.GetQueueAttributesWithContext(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       &qc.queueURL,
+		AttributeNames: aws.StringSlice([]string{attrVisible, attrInFlight}),
+	})
+	if err != nil {
+		// the cached client may hold expired or revoked credentials
+		r.forget(src)
+		return nil, fmt.Errorf("getting attributes of queue: %w", err)
+	}
+
+	stats := &queueStats{}
+
+	for attr, val := range map[string]*int64{
+		attrVisible:  &stats.visible,
+		attrInFlight: &stats.inFlight,
+	} {
+		if *val, err = strconv.ParseInt(aws.StringValue(out.Attributes[attr]), 10, 64); err != nil {
+			return nil, fmt.Errorf("parsing value of queue attribute %q: %w", attr, err)
+		}
+	}
+
+	return stats, nil
+}
+
+// client returns a SQS client for the queue of the given source, either from
+// the cache or newly created.
+func (r *queueStatsReader) client(ctx context.Context, src *v1alpha1.AWSSQSSource) (*queueClient, error) {
+	key := types.NamespacedName{Namespace: src.Namespace, Name: src.Name}
+
+	version, err := r.clientVersion(src)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	qc, ok := r.clients[key]
+	r.mu.Unlock()
+
+	if ok && qc.version == version {
+		return qc, nil
+	}
+
+	arn := src.Spec.ARN
+
+	sess, err := common.NewAWSSession(r.secretCli(src.Namespace), r.saCli(src.Namespace), src, arn.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	qc = &queueClient{
+		version:  version,
+		cli:      sqs.New(sess),
+		queueURL: src.Spec.QueueURL,
+	}
+
+	if qc.queueURL == "" {
+		out, err := qc.cli.GetQueueUrlWithContext(ctx, &sqs.GetQueueUrlInput{
+			QueueName:              aws.String(arn.Resource),
+			QueueOwnerAWSAccountId: aws.String(arn.AccountID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("getting URL of queue: %w", err)
+		}
+		qc.queueURL = *out.QueueUrl
+	}
+
+	r.mu.Lock()
+	r.clients[key] = qc
+	r.mu.Unlock()
+
+	return qc, nil
+}
+
+// clientVersion returns a value which changes whenever the spec of the given
+// source or one of the Secrets it references changes.
+func (r *queueStatsReader) clientVersion(src *v1alpha1.AWSSQSSource) (string, error) {
+	version := string(src.UID) + "/" + strconv.FormatInt(src.Generation, 10)
+
+	for _, name := range referencedSecrets(src) {
+		secr, err := r.secretLister(src.Namespace).Get(name)
+		switch {
+		case apierrors.IsNotFound(err):
+			version += "/"
+			continue
+		case err != nil:
+			return "", fmt.Errorf("getting Secret %q from cache: %w", name, err)
+		}
+		version += "/" + secr.ResourceVersion
+	}
+
+	return version, nil
+}
+
+// forget removes the cached client of the given source. It accepts objects
+// passed to informers' event handlers.
+func (r *queueStatsReader) forget(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	src, ok := obj.(*v1alpha1.AWSSQSSource)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	delete(r.clients, types.NamespacedName{Namespace: src.Namespace, Name: src.Name})
+	r.mu.Unlock()
+}
+
+// referencedSecrets returns the names of the Secrets referenced by the given
+// source for interacting with AWS, in the order of the fields which reference
+// them.
+func referencedSecrets(src *v1alpha1.AWSSQSSource) []string {
+	fields := []*v1alpha1.ValueFromField{
+		src.Spec.Credentials.AccessKeyID,
+		src.Spec.Credentials.SecretAccessKey,
+	}
+	if ep := src.Spec.Endpoint; ep != nil {
+		fields = append(fields, ep.CABundle)
+	}
+
+	var names []string
+	for _, f := range fields {
+		if f != nil && f.ValueFromSecret != nil {
+			names = append(names, f.ValueFromSecret.Name)
+		}
+	}
+
+	return names
+}
+
+// adapterReplicas returns the number of replicas the adapter of the given
+// source should be running, and reports the observed backlog of the queue in
+// the source's status.
+//
+// The number of replicas of autoscaled adapters is managed by a
+// HorizontalPodAutoscaler and preserved as is, unless the adapter needs to be
+// scaled from or to zero, which the HorizontalPodAutoscaler doesn't support.
+func (r *Reconciler) adapterReplicas(ctx context.Context, src *v1alpha1.AWSSQSSource) int32 {
+	as := src.Spec.Autoscaling
+	if as == nil {
+		src.Status.Autoscaling = nil
+		return 1
+	}
+
+	// the backlog of the queue doesn't generate any Kubernetes event, so
+	// it needs to be observed periodically
+	defer r.enqueueAfter(src, backlogPollInterval)
+
+	var adapter *appsv1.Deployment
+	if a, err := r.base.FindAdapter(src); err == nil {
+		adapter = a
+	}
+
+	stats, err := r.queueStats(ctx, src)
+	unobservable := errors.Is(err, common.ErrAmbientCredentialsDisallowed)
+	if err != nil && !unobservable {
+		logging.FromContext(ctx).Warnw("Unable to observe the backlog of the queue", zap.Error(err))
+	}
+
+	var st v1alpha1.AWSSQSSourceAutoscalingStatus
+	if src.Status.Autoscaling != nil {
+		st = *src.Status.Autoscaling
+	}
+	if stats != nil {
+		st.Backlog = stats.visible
+		st.InFlight = stats.inFlight
+	}
+	st.Replicas = 0
+	if adapter != nil {
+		st.Replicas = adapter.Status.Replicas
+	}
+
+	// the status is only written when the observed values change
+	if src.Status.Autoscaling == nil || *src.Status.Autoscaling != st {
+		src.Status.Autoscaling = &st
+	}
+
+	var current *int32
+	if adapter != nil {
+		current = adapter.Spec.Replicas
+	}
+
+	// the controller can't observe the backlog of queues on behalf of
+	// sources which use ambient credentials, so their adapter is never
+	// kept scaled to zero
+	if unobservable && current != nil && *current == 0 {
+		current = nil
+	}
+
+	return desiredReplicas(as, current, stats)
+}
+
+// desiredReplicas returns the number of replicas an autoscaled adapter should
+// be running, based on its current number of replicas and on the last known
+// state of its queue. A nil current value indicates that the adapter doesn't
+// exist yet, a nil stats value that the state of the queue is unknown.
+func desiredReplicas(as *v1alpha1.AWSSQSSourceAutoscaling, current *int32, stats *queueStats) int32 {
+	min := autoscalingMinReplicas(as)
+
+	floor := min
+	if floor < 1 {
+		floor = 1
+	}
+
+	var replicas int32
+
+	switch {
+	case current == nil:
+		replicas = floor
+	case *current > as.MaxReplicas:
+		replicas = as.MaxReplicas
+	case *current < floor:
+		replicas = floor
+	default:
+		replicas = *current
+	}
+
+	if min > 0 {
+		return replicas
+	}
+
+	switch {
+	case stats == nil:
+		// keep a scaled-to-zero adapter down until the state of the
+		// queue is known
+		if current != nil && *current == 0 {
+			return 0
+		}
+	case stats.idle():
+		return 0
+	}
+
+	return replicas
+}
+
+// autoscalingMinReplicas returns the minimum number of replicas of an
+// autoscaled adapter.
+func autoscalingMinReplicas(as *v1alpha1.AWSSQSSourceAutoscaling) int32 {
+	if as.MinReplicas == nil {
+		return 1
+	}
+	return *as.MinReplicas
+}
+
+// reconcileAutoscaler reconciles the HorizontalPodAutoscaler of the source's
+// adapter. The HorizontalPodAutoscaler is deleted when the source doesn't
+// enable autoscaling.
+func (r *Reconciler) reconcileAutoscaler(ctx context.Context, src *v1alpha1.AWSSQSSource) error {
+	desired := makeAdapterHPA(src, r.adapterCfg)
+
+	current, err := r.hpaLister(src.Namespace).Get(desired.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		current = nil
+	case err != nil:
+		return fmt.Errorf("failed to get adapter HorizontalPodAutoscaler from cache: %w", err)
+	}
+
+	if src.Spec.Autoscaling == nil {
+		if current == nil || !metav1.IsControlledBy(current, src) {
+			return nil
+		}
+
+		err := r.hpaClient(src.Namespace).Delete(ctx, current.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedAutoscalerDelete,
+				"Failed to delete adapter HorizontalPodAutoscaler %q: %s", current.Name, err)
+		}
+		event.Normal(ctx, ReasonAutoscalerDelete, "Deleted adapter HorizontalPodAutoscaler %q", current.Name)
+
+		return nil
+	}
+
+	if current == nil {
+		hpa, err := r.hpaClient(src.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			return reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedAutoscalerCreate,
+				"Failed to create adapter HorizontalPodAutoscaler %q: %s", desired.Name, err)
+		}
+		event.Normal(ctx, ReasonAutoscalerCreate, "Created adapter HorizontalPodAutoscaler %q", hpa.Name)
+
+		return nil
+	}
+
+	if equality.Semantic.DeepDerivative(desired.ObjectMeta, current.ObjectMeta) &&
+		equality.Semantic.DeepDerivative(desired.Spec, current.Spec) {
+
+		return nil
+	}
+
+	// resourceVersion must be returned to the API server unmodified for
+	// optimistic concurrency, as per Kubernetes API conventions
+	desired.ResourceVersion = current.ResourceVersion
+
+	hpa, err := r.hpaClient(src.Namespace).Update(ctx, desired, metav1.UpdateOptions{})
+	if err != nil {
+		return reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedAutoscalerUpdate,
+			"Failed to update adapter HorizontalPodAutoscaler %q: %s", desired.Name, err)
+	}
+	event.Normal(ctx, ReasonAutoscalerUpdate, "Updated adapter HorizontalPodAutoscaler %q", hpa.Name)
+
+	return nil
+}
+
+// makeAdapterHPA returns a HorizontalPodAutoscaler which scales the source's
+// adapter based on the backlog of the queue reported by the adapter itself.
+func makeAdapterHPA(src *v1alpha1.AWSSQSSource, cfg *adapterConfig) *autoscalingv2beta1.HorizontalPodAutoscaler {
+	adapterName := common.AdapterName(src)
+	name := kmeta.ChildName(adapterName+"-", src.Name)
+
+	opts := []resource.ObjectOption{
+		resource.Controller(src),
+
+		resource.Label(common.AppNameLabel, adapterName),
+		resource.Label(common.AppInstanceLabel, src.Name),
+		resource.Label(common.AppComponentLabel, common.AdapterComponent),
+		resource.Label(common.AppPartOfLabel, common.PartOf),
+		resource.Label(common.AppManagedByLabel, common.ManagedBy),
+	}
+
+	if as := src.Spec.Autoscaling; as != nil {
+		// the HorizontalPodAutoscaler doesn't scale to zero, scaling
+		// from and to zero is handled by the reconciler
+		min := autoscalingMinReplicas(as)
+		if min < 1 {
+			min = 1
+		}
+
+		opts = append(opts,
+			resource.ScaleTarget("apps/v1", "Deployment", name),
+			resource.ReplicasRange(min, as.MaxReplicas),
+			resource.ExternalMetric(cfg.BacklogMetricName,
+				map[string]string{
+					metricskey.LabelNamespaceName: src.Namespace,
+					metricskey.LabelName:          src.Name,
+				},
+				*kr.NewQuantity(as.TargetBacklog, kr.DecimalSI),
+			),
+		)
+	}
+
+	return resource.NewHorizontalPodAutoscaler(src.Namespace, name, opts...)
+}
@@ -0,0 +1,252 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssqssource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+	. "github.com/triggermesh/aws-event-sources/pkg/reconciler/testing"
+)
+
+func TestDesiredReplicas(t *testing.T) {
+	active := &queueStats{visible: 10, inFlight: 2}
+	idle := &queueStats{}
+
+	testCases := map[string]struct {
+		min      int32
+		current  *int32
+		stats    *queueStats
+		expected int32
+	}{
+		"New adapter starts at the lower bound": {
+			min:      2,
+			current:  nil,
+			stats:    active,
+			expected: 2,
+		},
+		"Replicas set by the autoscaler are preserved": {
+			min:      1,
+			current:  ptrInt32(4),
+			stats:    active,
+			expected: 4,
+		},
+		"Replicas are capped at the upper bound": {
+			min:      1,
+			current:  ptrInt32(8),
+			stats:    active,
+			expected: 5,
+		},
+		"Replicas are raised to the lower bound": {
+			min:      2,
+			current:  ptrInt32(0),
+			stats:    idle,
+			expected: 2,
+		},
+		"Adapter is not scaled to zero unless allowed": {
+			min:      1,
+			current:  ptrInt32(1),
+			stats:    idle,
+			expected: 1,
+		},
+		"Idle adapter is scaled to zero": {
+			min:      0,
+			current:  ptrInt32(3),
+			stats:    idle,
+			expected: 0,
+		},
+		"Adapter is scaled from zero when messages are available": {
+			min:      0,
+			current:  ptrInt32(0),
+			stats:    active,
+			expected: 1,
+		},
+		"Scaled-to-zero adapter remains down while the queue state is unknown": {
+			min:      0,
+			current:  ptrInt32(0),
+			stats:    nil,
+			expected: 0,
+		},
+		"Running adapter remains up while the queue state is unknown": {
+			min:      0,
+			current:  ptrInt32(2),
+			stats:    nil,
+			expected: 2,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			as := &v1alpha1.AWSSQSSourceAutoscaling{
+				MinReplicas:   &tc.min,
+				MaxReplicas:   5,
+				TargetBacklog: 100,
+			}
+
+			assert.Equal(t, tc.expected, desiredReplicas(as, tc.current, tc.stats))
+		})
+	}
+}
+
+func TestMakeAdapterHPA(t *testing.T) {
+	cfg := &adapterConfig{
+		BacklogMetricName: "test_backlog",
+	}
+
+	src := newEventSource()
+	src.Spec.Autoscaling = &v1alpha1.AWSSQSSourceAutoscaling{
+		MinReplicas:   ptrInt32(0),
+		MaxReplicas:   10,
+		TargetBacklog: 50,
+	}
+
+	hpa := makeAdapterHPA(src, cfg)
+
+	assert.Equal(t, "Deployment", hpa.Spec.ScaleTargetRef.Kind)
+	assert.Equal(t, hpa.Name, hpa.Spec.ScaleTargetRef.Name)
+	assert.Equal(t, ptrInt32(1), hpa.Spec.MinReplicas, "HPA can not scale to zero")
+	assert.Equal(t, int32(10), hpa.Spec.MaxReplicas)
+
+	if assert.Len(t, hpa.Spec.Metrics, 1) {
+		ext := hpa.Spec.Metrics[0].External
+		assert.Equal(t, "test_backlog", ext.MetricName)
+		assert.Equal(t, src.Name, ext.MetricSelector.MatchLabels["name"])
+		assert.Equal(t, int64(50), ext.TargetAverageValue.Value())
+	}
+}
+
+func ptrInt32(i int32) *int32 {
+	return &i
+}
+
+func TestQueueStatsReader(t *testing.T) {
+	const ns = "test-ns"
+
+	requests := make(map[string]int)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		action := r.FormValue("Action")
+		requests[action]++
+
+		switch action {
+		case "GetQueueUrl":
+			fmt.Fprint(w, "<GetQueueUrlResponse><GetQueueUrlResult>"+
+				"<QueueUrl>http://queue.test/123456789012/my-queue</QueueUrl>"+
+				"</GetQueueUrlResult></GetQueueUrlResponse>")
+		case "GetQueueAttributes":
+			fmt.Fprint(w, "<GetQueueAttributesResponse><GetQueueAttributesResult>"+
+				"<Attribute><Name>ApproximateNumberOfMessages</Name><Value>10</Value></Attribute>"+
+				"<Attribute><Name>ApproximateNumberOfMessagesNotVisible</Name><Value>2</Value></Attribute>"+
+				"</GetQueueAttributesResult></GetQueueAttributesResponse>")
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       ns,
+			Name:            "creds",
+			ResourceVersion: "1",
+		},
+		Data: map[string][]byte{
+			"keyId":  []byte("fake key ID"),
+			"secret": []byte("fake secret"),
+		},
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(secret))
+	secretLister := corelistersv1.NewSecretLister(indexer).Secrets
+
+	cli := fake.NewSimpleClientset(secret)
+
+	src := &v1alpha1.AWSSQSSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  ns,
+			Name:       "test",
+			Generation: 1,
+		},
+	}
+	src.Spec.ARN = NewARN(sqs.ServiceName, "my-queue")
+	src.Spec.Endpoint = &v1alpha1.AWSEndpoint{URL: srv.URL}
+	src.Spec.Credentials.AccessKeyID = &v1alpha1.ValueFromField{
+		ValueFromSecret: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+			Key:                  "keyId",
+		},
+	}
+	src.Spec.Credentials.SecretAccessKey = &v1alpha1.ValueFromField{
+		ValueFromSecret: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+			Key:                  "secret",
+		},
+	}
+
+	r := newQueueStatsReader(cli.CoreV1().Secrets, cli.CoreV1().ServiceAccounts, secretLister)
+
+	readStats := func(t *testing.T) {
+		t.Helper()
+
+		stats, err := r.queueStats(context.Background(), src)
+		require.NoError(t, err)
+		assert.Equal(t, &queueStats{visible: 10, inFlight: 2}, stats)
+	}
+
+	readStats(t)
+	readStats(t)
+	assert.Equal(t, 1, requests["GetQueueUrl"], "Queue URL should be cached")
+	assert.Equal(t, 2, requests["GetQueueAttributes"])
+
+	t.Run("Secret changes", func(t *testing.T) {
+		secret = secret.DeepCopy()
+		secret.ResourceVersion = "2"
+		require.NoError(t, indexer.Update(secret))
+
+		readStats(t)
+		assert.Equal(t, 2, requests["GetQueueUrl"], "Queue URL should be renewed")
+	})
+
+	t.Run("Spec changes", func(t *testing.T) {
+		src.Generation++
+
+		readStats(t)
+		assert.Equal(t, 3, requests["GetQueueUrl"], "Queue URL should be renewed")
+	})
+
+	t.Run("Source is deleted", func(t *testing.T) {
+		r.forget(cache.DeletedFinalStateUnknown{Obj: src})
+		assert.Empty(t, r.clients)
+	})
+}
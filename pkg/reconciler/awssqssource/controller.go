@@ -21,7 +21,11 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 
+	"k8s.io/client-go/tools/cache"
+
 	"knative.dev/eventing/pkg/reconciler/source"
+	k8sclient "knative.dev/pkg/client/injection/kube/client"
+	hpainformerv2beta1 "knative.dev/pkg/client/injection/kube/informers/autoscaling/v2beta1/horizontalpodautoscaler"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 
@@ -47,8 +51,12 @@ func NewController(
 	}
 	envconfig.MustProcess(app, adapterCfg)
 
+	hpaInformer := hpainformerv2beta1.Get(ctx)
+
 	r := &Reconciler{
 		adapterCfg: adapterCfg,
+		hpaClient:  k8sclient.Get(ctx).AutoscalingV2beta1().HorizontalPodAutoscalers,
+		hpaLister:  hpaInformer.Lister().HorizontalPodAutoscalers,
 	}
 	impl := reconcilerv1alpha1.NewImpl(ctx, r)
 
@@ -58,8 +66,21 @@ func NewController(
 		impl.EnqueueKey,
 		impl.EnqueueControllerOf,
 	)
+	r.base.PreflightProbe = probeQueue
+	r.base.EnqueueAfter = impl.EnqueueAfter
+	qsr := newQueueStatsReader(r.base.SecretClient, r.base.ServiceAccountClient, r.base.SecretLister)
+	r.queueStats = qsr.queueStats
+	r.enqueueAfter = impl.EnqueueAfter
+
+	hpaInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterControllerGVK(typ.GetGroupVersionKind()),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
 
 	informerv1alpha1.Get(ctx).Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+	informerv1alpha1.Get(ctx).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: qsr.forget,
+	})
 
 	return impl
 }
@@ -0,0 +1,32 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awssqssource
+
+const (
+	// ReasonAutoscalerCreate indicates that the autoscaler of an adapter was successfully created.
+	ReasonAutoscalerCreate = "CreateAutoscaler"
+	// ReasonAutoscalerUpdate indicates that the autoscaler of an adapter was successfully updated.
+	ReasonAutoscalerUpdate = "UpdateAutoscaler"
+	// ReasonAutoscalerDelete indicates that the autoscaler of an adapter was successfully deleted.
+	ReasonAutoscalerDelete = "DeleteAutoscaler"
+	// ReasonFailedAutoscalerCreate indicates that the creation of the autoscaler of an adapter failed.
+	ReasonFailedAutoscalerCreate = "FailedAutoscalerCreate"
+	// ReasonFailedAutoscalerUpdate indicates that the update of the autoscaler of an adapter failed.
+	ReasonFailedAutoscalerUpdate = "FailedAutoscalerUpdate"
+	// ReasonFailedAutoscalerDelete indicates that the deletion of the autoscaler of an adapter failed.
+	ReasonFailedAutoscalerDelete = "FailedAutoscalerDelete"
+)
@@ -18,6 +18,11 @@ package awssqssource
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	autoscalingclientv2beta1 "k8s.io/client-go/kubernetes/typed/autoscaling/v2beta1"
+	autoscalinglistersv2beta1 "k8s.io/client-go/listers/autoscaling/v2beta1"
 
 	"knative.dev/pkg/reconciler"
 
@@ -30,6 +35,16 @@ import (
 type Reconciler struct {
 	base       common.GenericDeploymentReconciler
 	adapterCfg *adapterConfig
+
+	// API clients
+	hpaClient func(namespace string) autoscalingclientv2beta1.HorizontalPodAutoscalerInterface
+	// objects listers
+	hpaLister func(namespace string) autoscalinglistersv2beta1.HorizontalPodAutoscalerNamespaceLister
+
+	// observes the backlog of the queue of autoscaled sources
+	queueStats queueStatsFunc
+	// schedules the next reconciliation of autoscaled sources
+	enqueueAfter func(obj interface{}, after time.Duration)
 }
 
 // Check that our Reconciler implements Interface
@@ -40,5 +55,11 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, src *v1alpha1.AWSSQSSour
 	// inject source into context for usage in reconciliation logic
 	ctx = v1alpha1.WithSource(ctx, src)
 
-	return r.base.ReconcileSource(ctx, adapterDeploymentBuilder(src, r.adapterCfg))
+	if err := r.reconcileAutoscaler(ctx, src); err != nil {
+		return fmt.Errorf("failed to reconcile adapter autoscaler: %w", err)
+	}
+
+	replicas := r.adapterReplicas(ctx, src)
+
+	return r.base.ReconcileSource(ctx, adapterDeploymentBuilder(src, r.adapterCfg, replicas))
 }
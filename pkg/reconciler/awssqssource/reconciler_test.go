@@ -19,6 +19,7 @@ package awssqssource
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/sqs"
 
@@ -47,7 +48,7 @@ func TestReconcileSource(t *testing.T) {
 	var (
 		ctor      = reconcilerCtor(adapterCfg)
 		src       = newEventSource()
-		adapterFn = adapterDeploymentBuilder(src, adapterCfg)
+		adapterFn = adapterDeploymentBuilder(src, adapterCfg, 1)
 	)
 
 	TestReconcile(t, ctor, src, adapterFn)
@@ -57,15 +58,23 @@ func TestReconcileSource(t *testing.T) {
 func reconcilerCtor(cfg *adapterConfig) Ctor {
 	return func(t *testing.T, ctx context.Context, ls *Listers) controller.Reconciler {
 		base := common.GenericDeploymentReconciler{
-			SinkResolver: resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
-			Lister:       ls.GetDeploymentLister().Deployments,
-			Client:       fakek8sinjectionclient.Get(ctx).AppsV1().Deployments,
-			PodClient:    fakek8sinjectionclient.Get(ctx).CoreV1().Pods,
+			SinkResolver:         resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
+			Lister:               ls.GetDeploymentLister().Deployments,
+			Client:               fakek8sinjectionclient.Get(ctx).AppsV1().Deployments,
+			PodClient:            fakek8sinjectionclient.Get(ctx).CoreV1().Pods,
+			ServiceAccountClient: fakek8sinjectionclient.Get(ctx).CoreV1().ServiceAccounts,
+			ServiceAccountLister: ls.GetServiceAccountLister().ServiceAccounts,
 		}
 
 		r := &Reconciler{
 			base:       base,
 			adapterCfg: cfg,
+			hpaClient:  fakek8sinjectionclient.Get(ctx).AutoscalingV2beta1().HorizontalPodAutoscalers,
+			hpaLister:  ls.GetHorizontalPodAutoscalerLister().HorizontalPodAutoscalers,
+			queueStats: func(context.Context, *v1alpha1.AWSSQSSource) (*queueStats, error) {
+				return &queueStats{}, nil
+			},
+			enqueueAfter: func(interface{}, time.Duration) {},
 		}
 
 		return reconcilerv1alpha1.NewReconciler(ctx, logging.FromContext(ctx),
@@ -80,7 +89,7 @@ func newEventSource(skipCEAtrributes ...interface{}) *v1alpha1.AWSSQSSource {
 		Spec: v1alpha1.AWSSQSSourceSpec{
 			ARN: NewARN(sqs.ServiceName, "triggermeshtest"),
 			Credentials: v1alpha1.AWSSecurityCredentials{
-				AccessKeyID: v1alpha1.ValueFromField{
+				AccessKeyID: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "test-secret",
@@ -88,7 +97,7 @@ func newEventSource(skipCEAtrributes ...interface{}) *v1alpha1.AWSSQSSource {
 						Key: "keyId",
 					},
 				},
-				SecretAccessKey: v1alpha1.ValueFromField{
+				SecretAccessKey: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "test-secret",
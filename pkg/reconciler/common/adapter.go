@@ -17,12 +17,18 @@ limitations under the License.
 package common
 
 import (
+	"encoding/json"
+	"path"
 	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/kmeta"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 
 	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/resource"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/semantic"
 )
 
 // AdapterName returns the adapter's name for the given source object.
@@ -31,31 +37,206 @@ func AdapterName(o kmeta.OwnerRefable) string {
 }
 
 // MakeSecurityCredentialsEnvVars returns environment variables for the given
-// AWS security credentials.
+// AWS security credentials. Web identity credentials are not included, see
+// SecurityCredentials.
 func MakeSecurityCredentialsEnvVars(creds v1alpha1.AWSSecurityCredentials) []corev1.EnvVar {
-	const (
-		envAccessKeyID = iota
-		envSecretAccessKey
-	)
+	var credsEnvVars []corev1.EnvVar
 
-	credsEnvVars := []corev1.EnvVar{
-		{Name: EnvAccessKeyID},
-		{Name: EnvSecretAccessKey},
+	if creds.AccessKeyID != nil {
+		credsEnvVars = append(credsEnvVars, MakeEnvVarFromField(EnvAccessKeyID, *creds.AccessKeyID))
+	}
+	if creds.SecretAccessKey != nil {
+		credsEnvVars = append(credsEnvVars, MakeEnvVarFromField(EnvSecretAccessKey, *creds.SecretAccessKey))
 	}
 
-	if vfs := creds.AccessKeyID.ValueFromSecret; vfs != nil {
-		credsEnvVars[envAccessKeyID].ValueFrom = envVarValueFromSecret(vfs.Name, vfs.Key)
-	} else {
-		credsEnvVars[envAccessKeyID].Value = creds.AccessKeyID.Value
+	if ar := creds.AssumeRole; ar != nil {
+		credsEnvVars = append(credsEnvVars,
+			corev1.EnvVar{Name: EnvAssumeRoleARN, Value: ar.RoleARN.String()},
+			corev1.EnvVar{Name: EnvAssumeRoleExternalID, Value: ar.ExternalID},
+			corev1.EnvVar{Name: EnvAssumeRoleSessionName, Value: ar.SessionName},
+		)
+	}
+
+	return credsEnvVars
+}
+
+// MakeEndpointEnvVars returns environment variables for the given custom
+// AWS endpoint of a source which ARN belongs to the given service.
+func MakeEndpointEnvVars(arnService string, ep *v1alpha1.AWSEndpoint) []corev1.EnvVar {
+	if ep == nil {
+		return nil
+	}
+
+	// encoding a map of strings can not fail
+	urls, _ := json.Marshal(endpointURLs(arnService, ep))
+
+	endpointEnvVars := []corev1.EnvVar{
+		{Name: EnvEndpointURLs, Value: string(urls)},
+	}
+
+	if ep.CABundle != nil {
+		endpointEnvVars = append(endpointEnvVars, MakeEnvVarFromField(EnvEndpointCABundle, *ep.CABundle))
+	}
+	if ep.PathStyle {
+		endpointEnvVars = append(endpointEnvVars, corev1.EnvVar{Name: EnvEndpointPathStyle, Value: "true"})
+	}
+
+	return endpointEnvVars
+}
+
+// endpointURLs returns the URLs of the given custom AWS endpoint, keyed by
+// the ID of their service endpoint. The URL of the endpoint applies to the
+// API of the service the source's ARN belongs to, which service namespace
+// matches the ID of its service endpoint.
+func endpointURLs(arnService string, ep *v1alpha1.AWSEndpoint) map[string]string {
+	urls := make(map[string]string, len(ep.ServiceURLs)+1)
+	for id, u := range ep.ServiceURLs {
+		urls[id] = u
 	}
+	urls[arnService] = ep.URL
+
+	return urls
+}
+
+// Properties of the projected volume which contains the ServiceAccount token
+// used to assume a web identity. They match the ones used by the EKS Pod
+// Identity webhook, so that the webhook doesn't inject them a second time.
+// https://github.com/aws/amazon-eks-pod-identity-webhook
+const (
+	webIdentityTokenVolumeName = "aws-iam-token"
+	webIdentityTokenMountPath  = "/var/run/secrets/eks.amazonaws.com/serviceaccount"
+	webIdentityTokenPath       = "token"
+	webIdentityTokenAudience   = "sts.amazonaws.com"
+	webIdentityTokenExpiration = 86400 // 24h
+)
+
+// SecurityCredentials returns a functional option (resource.ObjectOption)
+// which configures the adapter of the given source to authenticate with the
+// given AWS security credentials.
+//
+// When credentials are based on a web identity, the adapter runs as the
+// source's ServiceAccount (see AdapterServiceAccountName). For Deployments,
+// the ServiceAccount token is projected into the adapter's container. Knative
+// Serving doesn't allow projecting ServiceAccount tokens, so Knative Services
+// rely on the EKS Pod Identity webhook to inject that token.
+func SecurityCredentials(src kmeta.OwnerRefable, creds v1alpha1.AWSSecurityCredentials) func(interface{}) {
+	return func(object interface{}) {
+		var podSpec *corev1.PodSpec
+
+		switch o := object.(type) {
+		case *appsv1.Deployment:
+			podSpec = &o.Spec.Template.Spec
+		case *servingv1.Service:
+			podSpec = &o.Spec.Template.Spec.PodSpec
+		}
 
-	if vfs := creds.SecretAccessKey.ValueFromSecret; vfs != nil {
-		credsEnvVars[envSecretAccessKey].ValueFrom = envVarValueFromSecret(vfs.Name, vfs.Key)
+		if len(podSpec.Containers) == 0 {
+			podSpec.Containers = make([]corev1.Container, 1)
+		}
+		container := &podSpec.Containers[0]
+
+		container.Env = append(container.Env, MakeSecurityCredentialsEnvVars(creds)...)
+
+		wi := creds.WebIdentity
+		if wi == nil {
+			return
+		}
+
+		podSpec.ServiceAccountName = AdapterServiceAccountName(src)
+
+		if _, isDeployment := object.(*appsv1.Deployment); !isDeployment {
+			return
+		}
+
+		expiration := int64(webIdentityTokenExpiration)
+
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: webIdentityTokenVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          webIdentityTokenAudience,
+							ExpirationSeconds: &expiration,
+							Path:              webIdentityTokenPath,
+						},
+					}},
+				},
+			},
+		})
+
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      webIdentityTokenVolumeName,
+			MountPath: webIdentityTokenMountPath,
+			ReadOnly:  true,
+		})
+
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: EnvRoleARN, Value: wi.RoleARN.String()},
+			corev1.EnvVar{Name: EnvWebIdentityTokenFile, Value: path.Join(webIdentityTokenMountPath, webIdentityTokenPath)},
+		)
+	}
+}
+
+// Default number of replicas of adapters which run in high availability.
+const defaultHAReplicas = 2
+
+// HighAvailability returns a functional option (resource.ObjectOption) which
+// runs multiple replicas of the adapter of the given source, and sets the name
+// of the Lease through which these replicas coordinate. The option has no
+// effect when ha is nil.
+func HighAvailability(src kmeta.OwnerRefable, ha *v1alpha1.HighAvailability) func(interface{}) {
+	return func(object interface{}) {
+		if ha == nil {
+			return
+		}
+
+		replicas := int32(defaultHAReplicas)
+		if ha.Replicas != nil {
+			replicas = *ha.Replicas
+		}
+
+		// Leases are named after the adapter, which is unique per source
+		lease := kmeta.ChildName(AdapterName(src)+"-", src.GetObjectMeta().GetName())
+
+		resource.Replicas(replicas)(object)
+		resource.EnvVar(EnvHALease, lease)(object)
+	}
+}
+
+// MakeRedactionEnvVars returns environment variables for the given Cognito
+// redaction policy.
+func MakeRedactionEnvVars(p *v1alpha1.CognitoRedactionPolicy) []corev1.EnvVar {
+	if p == nil {
+		return nil
+	}
+
+	envVars := []corev1.EnvVar{
+		{Name: EnvRedactDrop, Value: strings.Join(p.Drop, ",")},
+		{Name: EnvRedactHash, Value: strings.Join(p.Hash, ",")},
+		{Name: EnvRedactMask, Value: strings.Join(p.Mask, ",")},
+	}
+
+	if p.HashKey != nil {
+		envVars = append(envVars, MakeEnvVarFromField(EnvRedactHashKey, *p.HashKey))
+	}
+
+	return envVars
+}
+
+// MakeEnvVarFromField returns an environment variable with the given name
+// which value is either a literal value or a reference to a Kubernetes
+// Secret, depending on the given field.
+func MakeEnvVarFromField(name string, f v1alpha1.ValueFromField) corev1.EnvVar {
+	envVar := corev1.EnvVar{Name: name}
+
+	if vfs := f.ValueFromSecret; vfs != nil {
+		envVar.ValueFrom = envVarValueFromSecret(vfs.Name, vfs.Key)
 	} else {
-		credsEnvVars[envSecretAccessKey].Value = creds.SecretAccessKey.Value
+		envVar.Value = f.Value
 	}
 
-	return credsEnvVars
+	return envVar
 }
 
 // envVarValueFromSecret returns the value of an environment variable sourced
@@ -70,3 +251,54 @@ func envVarValueFromSecret(secretName, secretKey string) *corev1.EnvVarSource {
 		},
 	}
 }
+
+// AdapterOverrides returns a functional option (resource.ObjectOption) which
+// applies the given overrides to the Pod template of a source's adapter. It
+// is expected to be the last option passed to the adapter's constructor, so
+// that overrides take precedence over defaults.
+func AdapterOverrides(o *v1alpha1.AdapterOverrides) func(interface{}) {
+	return func(object interface{}) {
+		if o == nil {
+			return
+		}
+
+		var opts []resource.ObjectOption
+
+		if o.Resources != nil {
+			opts = append(opts, resource.Resources(*o.Resources))
+		}
+		if len(o.NodeSelector) > 0 {
+			opts = append(opts, resource.NodeSelector(o.NodeSelector))
+		}
+		if len(o.Tolerations) > 0 {
+			opts = append(opts, resource.Tolerations(o.Tolerations...))
+		}
+		if o.Affinity != nil {
+			opts = append(opts, resource.Affinity(o.Affinity))
+		}
+		if o.PriorityClassName != "" {
+			opts = append(opts, resource.PriorityClass(o.PriorityClassName))
+		}
+		for k, v := range o.Labels {
+			opts = append(opts, resource.PodLabel(k, v))
+		}
+		for k, v := range o.Annotations {
+			opts = append(opts, resource.PodAnnotation(k, v))
+		}
+
+		// record the keys of overridden labels and annotations, so
+		// that their removal can be reconciled
+		if len(o.Labels) > 0 {
+			opts = append(opts, resource.PodAnnotation(semantic.OverriddenLabelsAnnotation,
+				semantic.FormatKeys(o.Labels)))
+		}
+		if len(o.Annotations) > 0 {
+			opts = append(opts, resource.PodAnnotation(semantic.OverriddenAnnotationsAnnotation,
+				semantic.FormatKeys(o.Annotations)))
+		}
+
+		for _, opt := range opts {
+			opt(object)
+		}
+	}
+}
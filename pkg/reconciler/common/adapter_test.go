@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kr "k8s.io/apimachinery/pkg/api/resource"
+
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/resource"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/semantic"
+)
+
+func TestAdapterOverrides(t *testing.T) {
+	overrides := &v1alpha1.AdapterOverrides{
+		Resources: &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: kr.MustParse("128Mi"),
+			},
+		},
+		NodeSelector: map[string]string{"pool": "adapters"},
+		Tolerations: []corev1.Toleration{{
+			Key:      "dedicated",
+			Operator: corev1.TolerationOpExists,
+		}},
+		PriorityClassName: "high",
+		Labels:            map[string]string{"team": "platform"},
+		Annotations:       map[string]string{"sidecar.istio.io/inject": "false"},
+	}
+
+	defaults := []resource.ObjectOption{
+		resource.PodLabel(AppComponentLabel, AdapterComponent),
+		resource.Limits(kr.MustParse("1"), kr.MustParse("45Mi")),
+	}
+
+	assertPodSpecable := func(t *testing.T, podLabels, podAnns map[string]string,
+		podSpec *corev1.PodSpec) {
+
+		t.Helper()
+
+		assert.Equal(t, map[string]string{
+			AppComponentLabel: AdapterComponent,
+			"team":            "platform",
+		}, podLabels)
+		assert.Equal(t, map[string]string{
+			"sidecar.istio.io/inject":                "false",
+			semantic.OverriddenLabelsAnnotation:      "team",
+			semantic.OverriddenAnnotationsAnnotation: "sidecar.istio.io/inject",
+		}, podAnns)
+		assert.Equal(t, overrides.NodeSelector, podSpec.NodeSelector)
+		assert.Equal(t, overrides.Tolerations, podSpec.Tolerations)
+		assert.Equal(t, "high", podSpec.PriorityClassName)
+
+		limits := podSpec.Containers[0].Resources.Limits
+		assert.Equal(t, "1", limits.Cpu().String(), "Default CPU limit was not preserved")
+		assert.Equal(t, "128Mi", limits.Memory().String(), "Memory limit was not overridden")
+	}
+
+	t.Run("Deployment", func(t *testing.T) {
+		opts := append(defaults, AdapterOverrides(overrides))
+		d := resource.NewDeployment("ns", "name", opts...)
+
+		assertPodSpecable(t, d.Spec.Template.Labels, d.Spec.Template.Annotations, &d.Spec.Template.Spec)
+	})
+
+	t.Run("Knative Service", func(t *testing.T) {
+		opts := append(defaults, AdapterOverrides(overrides))
+		s := resource.NewKnService("ns", "name", opts...)
+
+		assertPodSpecable(t, s.Spec.Template.Labels, s.Spec.Template.Annotations, &s.Spec.Template.Spec.PodSpec)
+	})
+
+	t.Run("No override", func(t *testing.T) {
+		d := &appsv1.Deployment{}
+		AdapterOverrides(nil)(d)
+		assert.Equal(t, &appsv1.Deployment{}, d)
+
+		s := &servingv1.Service{}
+		AdapterOverrides(nil)(s)
+		assert.Equal(t, &servingv1.Service{}, s)
+	})
+}
+
+func TestHighAvailability(t *testing.T) {
+	src := &v1alpha1.AWSKinesisSource{}
+	src.Name = "my-source"
+
+	t.Run("Default replicas", func(t *testing.T) {
+		d := resource.NewDeployment("ns", "name",
+			HighAvailability(src, &v1alpha1.HighAvailability{}),
+		)
+
+		assert.Equal(t, int32(defaultHAReplicas), *d.Spec.Replicas)
+		assert.Equal(t, []corev1.EnvVar{{Name: EnvHALease, Value: "awskinesissource-my-source"}},
+			d.Spec.Template.Spec.Containers[0].Env)
+	})
+
+	t.Run("Explicit replicas", func(t *testing.T) {
+		replicas := int32(3)
+
+		d := resource.NewDeployment("ns", "name",
+			HighAvailability(src, &v1alpha1.HighAvailability{Replicas: &replicas}),
+		)
+
+		assert.Equal(t, replicas, *d.Spec.Replicas)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		d := &appsv1.Deployment{}
+		HighAvailability(src, nil)(d)
+		assert.Equal(t, &appsv1.Deployment{}, d)
+	})
+}
+
+func TestMakeEndpointEnvVars(t *testing.T) {
+	assert.Nil(t, MakeEndpointEnvVars("dynamodb", nil))
+
+	ep := &v1alpha1.AWSEndpoint{
+		URL: "https://vpce-1.dynamodb.us-west-2.vpce.amazonaws.com",
+		ServiceURLs: map[string]string{
+			"streams.dynamodb": "https://vpce-2.streams.dynamodb.us-west-2.vpce.amazonaws.com",
+		},
+		CABundle:  &v1alpha1.ValueFromField{Value: "ca"},
+		PathStyle: true,
+	}
+
+	expectEnvs := []corev1.EnvVar{{
+		Name: EnvEndpointURLs,
+		Value: `{"dynamodb":"https://vpce-1.dynamodb.us-west-2.vpce.amazonaws.com",` +
+			`"streams.dynamodb":"https://vpce-2.streams.dynamodb.us-west-2.vpce.amazonaws.com"}`,
+	}, {
+		Name:  EnvEndpointCABundle,
+		Value: "ca",
+	}, {
+		Name:  EnvEndpointPathStyle,
+		Value: "true",
+	}}
+
+	assert.Equal(t, expectEnvs, MakeEndpointEnvVars("dynamodb", ep))
+}
@@ -18,21 +18,29 @@ package common
 
 import (
 	"context"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	appslistersv1 "k8s.io/client-go/listers/apps/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	k8sclient "knative.dev/pkg/client/injection/kube/client"
 	deploymentinformerv1 "knative.dev/pkg/client/injection/kube/informers/apps/v1/deployment"
+	secretinformerv1 "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
+	serviceaccountinformerv1 "knative.dev/pkg/client/injection/kube/informers/core/v1/serviceaccount"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
 	"knative.dev/pkg/resolver"
+	"knative.dev/pkg/tracker"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	servingclientset "knative.dev/serving/pkg/client/clientset/versioned"
 	servingclientv1 "knative.dev/serving/pkg/client/clientset/versioned/typed/serving/v1"
+	servinginformers "knative.dev/serving/pkg/client/informers/externalversions"
 	servingclient "knative.dev/serving/pkg/client/injection/client"
-	serviceinformerv1 "knative.dev/serving/pkg/client/injection/informers/serving/v1/service"
 	servinglistersv1 "knative.dev/serving/pkg/client/listers/serving/v1"
 )
 
@@ -41,10 +49,23 @@ type GenericDeploymentReconciler struct {
 	// URI resolver for sinks
 	SinkResolver *resolver.URIResolver
 	// API clients
-	Client    func(namespace string) appsclientv1.DeploymentInterface
-	PodClient func(namespace string) coreclientv1.PodInterface
+	Client               func(namespace string) appsclientv1.DeploymentInterface
+	PodClient            func(namespace string) coreclientv1.PodInterface
+	ServiceAccountClient func(namespace string) coreclientv1.ServiceAccountInterface
+	SecretClient         func(namespace string) coreclientv1.SecretInterface
 	// objects listers
-	Lister func(namespace string) appslistersv1.DeploymentNamespaceLister
+	Lister               func(namespace string) appslistersv1.DeploymentNamespaceLister
+	ServiceAccountLister func(namespace string) corelistersv1.ServiceAccountNamespaceLister
+	SecretLister         func(namespace string) corelistersv1.SecretNamespaceLister
+	// tracker for Secrets referenced by sources
+	SecretTracker tracker.Interface
+	// optional check of the source's access to AWS, skipped when nil
+	PreflightProbe PreflightProbeFunc
+	// enqueues a source after the given delay, used to periodically
+	// recheck the source's access to AWS
+	EnqueueAfter func(obj interface{}, after time.Duration)
+	// cached outcomes of the checks of sources' access to AWS
+	preflight *preflightCache
 }
 
 // GenericServiceReconciler contains interfaces shared across Service reconcilers.
@@ -52,9 +73,24 @@ type GenericServiceReconciler struct {
 	// URI resolver for sinks
 	SinkResolver *resolver.URIResolver
 	// API clients
-	Client func(namespace string) servingclientv1.ServiceInterface
+	Client               func(namespace string) servingclientv1.ServiceInterface
+	ServiceAccountClient func(namespace string) coreclientv1.ServiceAccountInterface
+	SecretClient         func(namespace string) coreclientv1.SecretInterface
 	// objects listers
-	Lister func(namespace string) servinglistersv1.ServiceNamespaceLister
+	Lister               func(namespace string) servinglistersv1.ServiceNamespaceLister
+	ServiceAccountLister func(namespace string) corelistersv1.ServiceAccountNamespaceLister
+	SecretLister         func(namespace string) corelistersv1.SecretNamespaceLister
+	// tracker for Secrets referenced by sources
+	SecretTracker tracker.Interface
+	// optional check of the source's access to AWS, skipped when nil
+	PreflightProbe PreflightProbeFunc
+	// enqueues a source after the given delay, used to periodically
+	// recheck the source's access to AWS
+	EnqueueAfter func(obj interface{}, after time.Duration)
+	// cached outcomes of the checks of sources' access to AWS
+	preflight *preflightCache
+	// whether the Knative Serving API is served by the cluster
+	ServingAvailable bool
 }
 
 // NewGenericDeploymentReconciler creates a new GenericDeploymentReconciler and
@@ -65,41 +101,100 @@ func NewGenericDeploymentReconciler(ctx context.Context, gvk schema.GroupVersion
 ) GenericDeploymentReconciler {
 
 	informer := deploymentinformerv1.Get(ctx)
+	saInformer := serviceaccountinformerv1.Get(ctx)
+	secretInformer := secretinformerv1.Get(ctx)
 
 	r := GenericDeploymentReconciler{
-		SinkResolver: resolver.NewURIResolver(ctx, resolverCallback),
-		Client:       k8sclient.Get(ctx).AppsV1().Deployments,
-		PodClient:    k8sclient.Get(ctx).CoreV1().Pods,
-		Lister:       informer.Lister().Deployments,
+		SinkResolver:         resolver.NewURIResolver(ctx, resolverCallback),
+		Client:               k8sclient.Get(ctx).AppsV1().Deployments,
+		PodClient:            k8sclient.Get(ctx).CoreV1().Pods,
+		ServiceAccountClient: k8sclient.Get(ctx).CoreV1().ServiceAccounts,
+		SecretClient:         k8sclient.Get(ctx).CoreV1().Secrets,
+		Lister:               informer.Lister().Deployments,
+		ServiceAccountLister: saInformer.Lister().ServiceAccounts,
+		SecretLister:         secretInformer.Lister().Secrets,
+		SecretTracker:        tracker.New(resolverCallback, controller.GetTrackerLease(ctx)),
+		preflight:            newPreflightCache(),
 	}
 
 	informer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: controller.FilterControllerGVK(gvk),
 		Handler:    controller.HandleAll(adapterHandlerFn),
 	})
+	saInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterControllerGVK(gvk),
+		Handler:    controller.HandleAll(adapterHandlerFn),
+	})
+	// Secrets referenced by sources are not owned by them, changes are
+	// propagated to the sources which track them
+	secretInformer.Informer().AddEventHandler(controller.HandleAll(r.SecretTracker.OnChanged))
 
 	return r
 }
 
 // NewGenericServiceReconciler creates a new GenericServiceReconciler and
 // attaches a default event handler to its Service informer.
+//
+// Unlike other informers, the Knative Service informer is not injected, so
+// that controllers which can run adapters as Deployments don't wait for the
+// synchronization of its cache in clusters where Knative Serving is not
+// installed. Its availability is reported by the ServingAvailable field.
 func NewGenericServiceReconciler(ctx context.Context, gvk schema.GroupVersionKind,
 	resolverCallback func(types.NamespacedName),
 	adapterHandlerFn func(obj interface{}),
 ) GenericServiceReconciler {
 
-	informer := serviceinformerv1.Get(ctx)
+	servingCli := servingclient.Get(ctx)
+
+	informerFactory := servinginformers.NewSharedInformerFactory(servingCli, controller.GetResyncPeriod(ctx))
+	informer := informerFactory.Serving().V1().Services()
+	saInformer := serviceaccountinformerv1.Get(ctx)
+	secretInformer := secretinformerv1.Get(ctx)
 
 	r := GenericServiceReconciler{
-		SinkResolver: resolver.NewURIResolver(ctx, resolverCallback),
-		Client:       servingclient.Get(ctx).ServingV1().Services,
-		Lister:       informer.Lister().Services,
+		SinkResolver:         resolver.NewURIResolver(ctx, resolverCallback),
+		Client:               servingCli.ServingV1().Services,
+		ServiceAccountClient: k8sclient.Get(ctx).CoreV1().ServiceAccounts,
+		SecretClient:         k8sclient.Get(ctx).CoreV1().Secrets,
+		Lister:               informer.Lister().Services,
+		ServiceAccountLister: saInformer.Lister().ServiceAccounts,
+		SecretLister:         secretInformer.Lister().Secrets,
+		SecretTracker:        tracker.New(resolverCallback, controller.GetTrackerLease(ctx)),
+		preflight:            newPreflightCache(),
+		ServingAvailable:     isServingAvailable(ctx, servingCli),
 	}
 
 	informer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: controller.FilterControllerGVK(gvk),
 		Handler:    controller.HandleAll(adapterHandlerFn),
 	})
+	saInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterControllerGVK(gvk),
+		Handler:    controller.HandleAll(adapterHandlerFn),
+	})
+	// Secrets referenced by sources are not owned by them, changes are
+	// propagated to the sources which track them
+	secretInformer.Informer().AddEventHandler(controller.HandleAll(r.SecretTracker.OnChanged))
+
+	if r.ServingAvailable {
+		informerFactory.Start(ctx.Done())
+		for typ, ok := range informerFactory.WaitForCacheSync(ctx.Done()) {
+			if !ok {
+				logging.FromContext(ctx).Fatalf("Failed to wait for cache of %v to sync", typ)
+			}
+		}
+	}
 
 	return r
 }
+
+// isServingAvailable returns whether the Knative Serving API is served by the
+// cluster.
+func isServingAvailable(ctx context.Context, cli servingclientset.Interface) bool {
+	_, err := cli.Discovery().ServerResourcesForGroupVersion(servingv1.SchemeGroupVersion.String())
+	if err != nil {
+		logging.FromContext(ctx).Warn("Knative Serving API unavailable, adapters can not run as Knative Services: ", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,246 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+	"github.com/triggermesh/aws-event-sources/pkg/awsendpoint"
+)
+
+// Validity duration of the ServiceAccount tokens requested by the controller
+// to assume web identities on behalf of sources.
+const controllerTokenExpiration = 3600 // 1h
+
+// EnvAllowAmbientCredentials is the name of the controller's environment
+// variable which allows the controller to call AWS APIs with its own ambient
+// credentials on behalf of sources which use ambient credentials.
+const EnvAllowAmbientCredentials = "AWS_ALLOW_AMBIENT_CREDENTIALS"
+
+// ErrAmbientCredentialsDisallowed is returned by NewAWSSession for sources
+// which use ambient credentials, unless the controller was explicitly allowed
+// to use its own ambient credentials on their behalf.
+var ErrAmbientCredentialsDisallowed = errors.New("the controller is not allowed to use its own " +
+	"ambient AWS security credentials on behalf of sources (see " + EnvAllowAmbientCredentials + ")")
+
+// ambientCredentialsAllowed indicates whether the controller may use its own
+// ambient credentials on behalf of sources.
+//
+// Ambient credentials are the ones of the environment the controller runs in,
+// and are likely to grant permissions which the owner of a source doesn't
+// have. Using them on behalf of arbitrary sources is therefore only safe when
+// the controller and the adapters share the same identity, which only the
+// cluster administrator can assert.
+var ambientCredentialsAllowed = func() bool {
+	allowed, _ := strconv.ParseBool(os.Getenv(EnvAllowAmbientCredentials))
+	return allowed
+}()
+
+// NewAWSSession returns an AWS session for interacting with AWS APIs in the
+// given region, on behalf of the given source.
+//
+// Credentials are resolved in the same way as in the source's adapter, except
+// that web identities are assumed with tokens requested for the adapter's
+// ServiceAccount, and ambient credentials are the ones of the controller. The
+// latter are refused with ErrAmbientCredentialsDisallowed unless the
+// controller was allowed to use them via EnvAllowAmbientCredentials.
+func NewAWSSession(secretsCli coreclientv1.SecretInterface, saCli coreclientv1.ServiceAccountInterface,
+	src v1alpha1.AWSAuthenticatedSource, region string) (*session.Session, error) {
+
+	creds := src.GetCredentials()
+
+	if creds.Ambient && !ambientCredentialsAllowed {
+		return nil, ErrAmbientCredentialsDisallowed
+	}
+
+	sess, err := session.NewSession(aws.NewConfig().
+		WithRegion(region),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating AWS session: %w", err)
+	}
+
+	switch {
+	case creds.WebIdentity != nil:
+		tokenFetcher := &serviceAccountTokenFetcher{
+			cli:  saCli,
+			name: AdapterServiceAccountName(src),
+		}
+
+		sess = sess.Copy(aws.NewConfig().WithCredentials(credentials.NewCredentials(
+			stscreds.NewWebIdentityRoleProviderWithToken(sts.New(sess),
+				creds.WebIdentity.RoleARN.String(), "", tokenFetcher),
+		)))
+
+	case creds.AccessKeyID != nil || creds.SecretAccessKey != nil:
+		credsValue, err := staticCredentials(secretsCli, creds)
+		if err != nil {
+			return nil, fmt.Errorf("reading AWS security credentials: %w", err)
+		}
+
+		sess = sess.Copy(aws.NewConfig().WithCredentials(
+			credentials.NewStaticCredentialsFromCreds(*credsValue),
+		))
+	}
+
+	if ar := creds.AssumeRole; ar != nil {
+		sess = sess.Copy(aws.NewConfig().WithCredentials(
+			stscreds.NewCredentials(sess, ar.RoleARN.String(), func(p *stscreds.AssumeRoleProvider) {
+				if ar.ExternalID != "" {
+					p.ExternalID = &ar.ExternalID
+				}
+				if ar.SessionName != "" {
+					p.RoleSessionName = ar.SessionName
+				}
+			}),
+		))
+	}
+
+	if ep := src.GetEndpoint(); ep != nil {
+		if sess, err = withEndpoint(sess, secretsCli, src.GetARN().Service, ep); err != nil {
+			return nil, fmt.Errorf("configuring AWS endpoint: %w", err)
+		}
+	}
+
+	return sess, nil
+}
+
+// withEndpoint returns a copy of the given session which sends requests to
+// the given endpoint, for a source which ARN belongs to the given service.
+// Credentials are inherited from the given session, so that requests issued
+// while resolving them are not affected by the endpoint.
+func withEndpoint(sess *session.Session, secretsCli coreclientv1.SecretInterface,
+	arnService string, ep *v1alpha1.AWSEndpoint) (*session.Session, error) {
+
+	caBundle, err := newFieldResolver(secretsCli).valueOf(ep.CABundle)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle: %w", err)
+	}
+
+	return awsendpoint.WithEndpoints(sess, awsendpoint.Config{
+		URLs:      endpointURLs(arnService, ep),
+		CABundle:  caBundle,
+		PathStyle: ep.PathStyle,
+	})
+}
+
+// staticCredentials returns the AWS access key referenced in the given
+// security credentials.
+func staticCredentials(cli coreclientv1.SecretInterface,
+	creds *v1alpha1.AWSSecurityCredentials) (*credentials.Value, error) {
+
+	r := newFieldResolver(cli)
+
+	var accessKeyID, secretAccessKey string
+	var err error
+
+	if accessKeyID, err = r.valueOf(creds.AccessKeyID); err != nil {
+		return nil, err
+	}
+	if secretAccessKey, err = r.valueOf(creds.SecretAccessKey); err != nil {
+		return nil, err
+	}
+
+	return &credentials.Value{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+	}, nil
+}
+
+// fieldResolver resolves the values of ValueFromFields.
+type fieldResolver struct {
+	cli coreclientv1.SecretInterface
+
+	// cache a Secret object by name to avoid GET-ing the same Secret
+	// object multiple times
+	secretCache map[string]*corev1.Secret
+}
+
+// newFieldResolver returns a fieldResolver which reads Secrets using the
+// given client.
+func newFieldResolver(cli coreclientv1.SecretInterface) *fieldResolver {
+	return &fieldResolver{
+		cli:         cli,
+		secretCache: make(map[string]*corev1.Secret),
+	}
+}
+
+// valueOf returns the value of the given field, or an empty string if the
+// field is nil.
+func (r *fieldResolver) valueOf(f *v1alpha1.ValueFromField) (string, error) {
+	if f == nil {
+		return "", nil
+	}
+
+	vfs := f.ValueFromSecret
+	if vfs == nil {
+		return f.Value, nil
+	}
+
+	secr, ok := r.secretCache[vfs.Name]
+	if !ok {
+		var err error
+		if secr, err = r.cli.Get(context.Background(), vfs.Name, metav1.GetOptions{}); err != nil {
+			return "", err
+		}
+		r.secretCache[vfs.Name] = secr
+	}
+
+	return string(secr.Data[vfs.Key]), nil
+}
+
+// serviceAccountTokenFetcher is a stscreds.TokenFetcher which requests tokens
+// for a Kubernetes ServiceAccount.
+type serviceAccountTokenFetcher struct {
+	cli  coreclientv1.ServiceAccountInterface
+	name string
+}
+
+var _ stscreds.TokenFetcher = (*serviceAccountTokenFetcher)(nil)
+
+// FetchToken implements stscreds.TokenFetcher.
+func (f *serviceAccountTokenFetcher) FetchToken(ctx credentials.Context) ([]byte, error) {
+	expiration := int64(controllerTokenExpiration)
+
+	tr, err := f.cli.CreateToken(ctx, f.name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         []string{webIdentityTokenAudience},
+			ExpirationSeconds: &expiration,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("requesting token for ServiceAccount %q: %w", f.name, err)
+	}
+
+	return []byte(tr.Status.Token), nil
+}
@@ -14,9 +14,10 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package awssnssource
+package common
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,7 +33,7 @@ import (
 	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
 )
 
-func TestAwsCredentials(t *testing.T) {
+func TestStaticCredentials(t *testing.T) {
 	const (
 		ns = "fake-namespace"
 
@@ -52,10 +53,10 @@ func TestAwsCredentials(t *testing.T) {
 		{
 			name: "Both from value",
 			input: v1alpha1.AWSSecurityCredentials{
-				AccessKeyID: v1alpha1.ValueFromField{
+				AccessKeyID: &v1alpha1.ValueFromField{
 					Value: accessKeyIDVal,
 				},
-				SecretAccessKey: v1alpha1.ValueFromField{
+				SecretAccessKey: &v1alpha1.ValueFromField{
 					Value: secretAccessKeyVal,
 				},
 			},
@@ -73,10 +74,10 @@ func TestAwsCredentials(t *testing.T) {
 				}),
 			},
 			input: v1alpha1.AWSSecurityCredentials{
-				AccessKeyID: v1alpha1.ValueFromField{
+				AccessKeyID: &v1alpha1.ValueFromField{
 					Value: accessKeyIDVal,
 				},
-				SecretAccessKey: v1alpha1.ValueFromField{
+				SecretAccessKey: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "secret1",
@@ -100,7 +101,7 @@ func TestAwsCredentials(t *testing.T) {
 				}),
 			},
 			input: v1alpha1.AWSSecurityCredentials{
-				AccessKeyID: v1alpha1.ValueFromField{
+				AccessKeyID: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "secret1",
@@ -108,7 +109,7 @@ func TestAwsCredentials(t *testing.T) {
 						Key: accessKeyIDKey,
 					},
 				},
-				SecretAccessKey: v1alpha1.ValueFromField{
+				SecretAccessKey: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "secret1",
@@ -134,7 +135,7 @@ func TestAwsCredentials(t *testing.T) {
 				}),
 			},
 			input: v1alpha1.AWSSecurityCredentials{
-				AccessKeyID: v1alpha1.ValueFromField{
+				AccessKeyID: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "secret1",
@@ -142,7 +143,7 @@ func TestAwsCredentials(t *testing.T) {
 						Key: accessKeyIDKey,
 					},
 				},
-				SecretAccessKey: v1alpha1.ValueFromField{
+				SecretAccessKey: &v1alpha1.ValueFromField{
 					ValueFromSecret: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: "secret2",
@@ -168,7 +169,7 @@ func TestAwsCredentials(t *testing.T) {
 
 			cli := fake.NewSimpleClientset(secrets...)
 
-			creds, err := awsCredentials(cli.CoreV1().Secrets(ns), &tc.input)
+			creds, err := staticCredentials(cli.CoreV1().Secrets(ns), &tc.input)
 
 			require.NoError(t, err)
 
@@ -179,6 +180,40 @@ func TestAwsCredentials(t *testing.T) {
 	}
 }
 
+func TestNewAWSSessionAmbientCredentials(t *testing.T) {
+	defer func(allowed bool) { ambientCredentialsAllowed = allowed }(ambientCredentialsAllowed)
+
+	src := &v1alpha1.AWSSQSSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      "test",
+		},
+	}
+	src.Spec.ARN = mustParseARN(t, "arn:aws:sqs:us-east-2:123456789012:my-queue")
+	src.Spec.Credentials.Ambient = true
+
+	cli := fake.NewSimpleClientset()
+
+	t.Run("Disallowed", func(t *testing.T) {
+		ambientCredentialsAllowed = false
+
+		_, err := NewAWSSession(cli.CoreV1().Secrets("test-ns"), cli.CoreV1().ServiceAccounts("test-ns"),
+			src, src.Spec.ARN.Region)
+		assert.True(t, errors.Is(err, ErrAmbientCredentialsDisallowed), "Unexpected error: %v", err)
+	})
+
+	t.Run("Allowed", func(t *testing.T) {
+		ambientCredentialsAllowed = true
+
+		sess, err := NewAWSSession(cli.CoreV1().Secrets("test-ns"), cli.CoreV1().ServiceAccounts("test-ns"),
+			src, src.Spec.ARN.Region)
+		require.NoError(t, err)
+		assert.Equal(t, src.Spec.ARN.Region, *sess.Config.Region)
+	})
+
+	assert.Empty(t, cli.Actions(), "Unexpected API requests")
+}
+
 func newSecret(ns, name string, data map[string]string) *corev1.Secret {
 	secr := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
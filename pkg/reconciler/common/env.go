@@ -26,5 +26,25 @@ const (
 	EnvAccessKeyID     = "AWS_ACCESS_KEY_ID"
 	EnvSecretAccessKey = "AWS_SECRET_ACCESS_KEY" //nolint:gosec
 
+	EnvRoleARN              = "AWS_ROLE_ARN"
+	EnvWebIdentityTokenFile = "AWS_WEB_IDENTITY_TOKEN_FILE"
+
+	EnvAssumeRoleARN         = "AWS_ASSUME_ROLE_ARN"
+	EnvAssumeRoleExternalID  = "AWS_ASSUME_ROLE_EXTERNAL_ID"
+	EnvAssumeRoleSessionName = "AWS_ASSUME_ROLE_SESSION_NAME"
+
+	EnvEndpointURLs      = "AWS_ENDPOINT_URLS"
+	EnvEndpointCABundle  = "AWS_ENDPOINT_CA_BUNDLE"
+	EnvEndpointPathStyle = "AWS_ENDPOINT_PATH_STYLE"
+
+	EnvStateConfigMap = "STATE_CONFIGMAP"
+
+	EnvHALease = "HA_LEASE"
+
+	EnvRedactDrop    = "REDACT_DROP"
+	EnvRedactHash    = "REDACT_HASH"
+	EnvRedactHashKey = "REDACT_HASH_KEY"
+	EnvRedactMask    = "REDACT_MASK"
+
 	EnvMetricsPrometheusPort = "METRICS_PROMETHEUS_PORT"
 )
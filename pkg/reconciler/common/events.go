@@ -27,9 +27,21 @@ const (
 	// ReasonFailedAdapterUpdate indicates that the update of an adapter object failed.
 	ReasonFailedAdapterUpdate = "FailedAdapterUpdate"
 
+	// ReasonServiceAccountCreate indicates that an adapter's ServiceAccount was successfully created.
+	ReasonServiceAccountCreate = "CreateServiceAccount"
+	// ReasonServiceAccountUpdate indicates that an adapter's ServiceAccount was successfully updated.
+	ReasonServiceAccountUpdate = "UpdateServiceAccount"
+	// ReasonFailedServiceAccountCreate indicates that the creation of an adapter's ServiceAccount failed.
+	ReasonFailedServiceAccountCreate = "FailedServiceAccountCreate"
+	// ReasonFailedServiceAccountUpdate indicates that the update of an adapter's ServiceAccount failed.
+	ReasonFailedServiceAccountUpdate = "FailedServiceAccountUpdate"
+
 	// ReasonBadSinkURI indicates that the URI of a sink can't be determined.
 	ReasonBadSinkURI = "BadSinkURI"
 
+	// ReasonFailedPreflight indicates that the pre-flight checks of a source failed.
+	ReasonFailedPreflight = "FailedPreflight"
+
 	// ReasonInvalidSpec indicates that spec of a reconciled object is invalid.
 	ReasonInvalidSpec = "InvalidSpec"
 )
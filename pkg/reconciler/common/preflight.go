@@ -0,0 +1,355 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"knative.dev/pkg/reconciler"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// PreflightProbeFunc checks that the AWS resource observed by the given
+// source exists and is accessible using the given AWS session, typically by
+// performing a cheap "describe" request against that resource.
+type PreflightProbeFunc func(ctx context.Context, sess *session.Session, src v1alpha1.AWSAuthenticatedSource) error
+
+const (
+	// preflightTimeout is the maximum duration of the pre-flight checks
+	// of a source.
+	preflightTimeout = 10 * time.Second
+	// preflightRecheckPeriod is the period after which the pre-flight
+	// checks of a source are run again, even though neither the source nor
+	// its referenced Secrets have changed.
+	preflightRecheckPeriod = 5 * time.Minute
+)
+
+// Error codes returned by AWS APIs when the credentials used to sign a
+// request are rejected.
+var invalidCredentialsErrCodes = map[string]struct{}{
+	"ExpiredToken":                {},
+	"ExpiredTokenException":       {},
+	"IncompleteSignature":         {},
+	"InvalidAccessKeyId":          {},
+	"InvalidClientTokenId":        {},
+	"InvalidIdentityToken":        {},
+	"InvalidSignatureException":   {},
+	"NoCredentialProviders":       {},
+	"SignatureDoesNotMatch":       {},
+	"UnrecognizedClientException": {},
+	"WebIdentityErr":              {},
+}
+
+// Error codes returned by AWS APIs when a request is not authorized.
+var accessDeniedErrCodes = map[string]struct{}{
+	"AccessDenied":           {},
+	"AccessDeniedException":  {},
+	"AuthorizationError":     {},
+	"NotAuthorizedException": {},
+	"UnauthorizedOperation":  {},
+}
+
+// Error codes returned by AWS APIs when a resource does not exist.
+var notFoundErrCodes = map[string]struct{}{
+	"AWS.SimpleQueueService.NonExistentQueue": {},
+	"NotFound":                        {},
+	"NotFoundException":               {},
+	"RepositoryDoesNotExistException": {},
+	"ResourceNotFoundException":       {},
+}
+
+// checkAWSAccess verifies that the AWS security credentials of the source
+// found in ctx are valid, and that the AWS resource observed by the source is
+// reachable using those credentials. The outcome of both checks is reflected
+// in the source's status.
+//
+// The validity of the credentials is asserted using the STS GetCallerIdentity
+// API, which requires no permission. This check is skipped for sources which
+// interact with a custom AWS endpoint, since STS may not be served there, in
+// which case the validity of the credentials is inferred from the response of
+// the probe.
+//
+// The check is skipped entirely if probe is nil, or if the source doesn't use
+// AWS security credentials. It is also skipped for sources which use ambient
+// credentials, unless the controller was allowed to use its own ambient
+// credentials on their behalf, in which case the source's access to AWS is
+// reported as unchecked.
+//
+// The outcome of the checks is recorded in the given cache, if not nil, and
+// reported again without calling AWS until either the generation of the source
+// or the given hash of its referenced Secrets changes. A new check is
+// scheduled after preflightRecheckPeriod using enqueueAfter, if not nil.
+func checkAWSAccess(ctx context.Context, probe PreflightProbeFunc,
+	cache *preflightCache, secretsHash string,
+	enqueueAfter func(obj interface{}, after time.Duration),
+	secretsCli func(namespace string) coreclientv1.SecretInterface,
+	saCli func(namespace string) coreclientv1.ServiceAccountInterface) reconciler.Event {
+
+	src, ok := v1alpha1.SourceFromContext(ctx).(v1alpha1.AWSAuthenticatedSource)
+	if probe == nil || !ok {
+		return nil
+	}
+
+	key := strconv.FormatInt(src.GetGeneration(), 10) + "/" + secretsHash
+
+	outcome, ok := cache.get(src.GetUID(), key)
+	if !ok {
+		outcome = runPreflightChecks(ctx, probe, src, secretsCli, saCli)
+		cache.set(src.GetUID(), key, outcome)
+
+		if enqueueAfter != nil {
+			enqueueAfter(src, preflightRecheckPeriod)
+		}
+	}
+
+	return outcome.report(src.GetStatusManager())
+}
+
+// preflightOutcome records the errors returned by the pre-flight checks of a
+// source, so that their outcome can be reported without calling AWS again.
+type preflightOutcome struct {
+	// error returned while reading the AWS security credentials
+	credsErr error
+	// whether the credentials were validated using STS
+	stsChecked bool
+	// error returned by STS
+	stsErr error
+	// error returned by the probe
+	probeErr error
+}
+
+// runPreflightChecks checks the given source's access to AWS and returns the
+// outcome of the checks. The checks are aborted after preflightTimeout.
+func runPreflightChecks(ctx context.Context, probe PreflightProbeFunc, src v1alpha1.AWSAuthenticatedSource,
+	secretsCli func(namespace string) coreclientv1.SecretInterface,
+	saCli func(namespace string) coreclientv1.ServiceAccountInterface) *preflightOutcome {
+
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+
+	outcome := &preflightOutcome{
+		stsChecked: src.GetEndpoint() == nil,
+	}
+
+	sess, err := NewAWSSession(secretsCli(src.GetNamespace()), saCli(src.GetNamespace()),
+		src, src.GetARN().Region)
+	if err != nil {
+		outcome.credsErr = err
+		return outcome
+	}
+
+	if outcome.stsChecked {
+		if _, err := sts.New(sess).GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{}); err != nil {
+			outcome.stsErr = err
+			return outcome
+		}
+	}
+
+	outcome.probeErr = probe(ctx, sess, src)
+
+	return outcome
+}
+
+// report reflects the outcome of pre-flight checks in the given status, and
+// returns a reconciler event if the checks failed.
+func (o *preflightOutcome) report(status *v1alpha1.EventSourceStatusManager) reconciler.Event {
+	switch {
+	case errors.Is(o.credsErr, ErrAmbientCredentialsDisallowed):
+		status.MarkAccessUnchecked("The ambient AWS security credentials of the adapter can not be " +
+			"checked by the controller")
+		return nil
+	case o.credsErr != nil:
+		status.MarkCredentialsInvalid(v1alpha1.ReasonInvalidCredentials,
+			"The AWS security credentials could not be read: "+o.credsErr.Error())
+		status.MarkResourceUnknown(v1alpha1.ReasonCheckFailed,
+			"The AWS resource can not be accessed without valid credentials")
+		return preflightFailedEvent(o.credsErr)
+	}
+
+	if o.stsChecked {
+		if o.stsErr != nil {
+			markCredentialsFailure(status, o.stsErr)
+			status.MarkResourceUnknown(v1alpha1.ReasonCheckFailed,
+				"The AWS resource can not be accessed without valid credentials")
+			return preflightFailedEvent(o.stsErr)
+		}
+		status.MarkCredentialsValid()
+	}
+
+	err := o.probeErr
+
+	switch code := awsErrorCode(err); {
+	case err == nil:
+		status.MarkCredentialsValid()
+		status.MarkResourceReachable()
+		return nil
+
+	case hasCode(invalidCredentialsErrCodes, code):
+		markCredentialsFailure(status, err)
+		status.MarkResourceUnknown(v1alpha1.ReasonCheckFailed,
+			"The AWS resource can not be accessed without valid credentials")
+
+	case hasCode(accessDeniedErrCodes, code):
+		status.MarkCredentialsValid()
+		status.MarkResourceUnreachable(v1alpha1.ReasonAccessDenied,
+			"Access to the AWS resource was denied: "+awsErrorMessage(err))
+
+	case hasCode(notFoundErrCodes, code):
+		status.MarkCredentialsValid()
+		status.MarkResourceUnreachable(v1alpha1.ReasonNotFound,
+			"The AWS resource does not exist: "+awsErrorMessage(err))
+
+	default:
+		if !o.stsChecked {
+			status.MarkCredentialsUnknown(v1alpha1.ReasonCheckFailed,
+				"The AWS security credentials could not be validated")
+		}
+		status.MarkResourceUnknown(v1alpha1.ReasonCheckFailed,
+			"The AWS resource could not be reached: "+awsErrorMessage(err))
+	}
+
+	return preflightFailedEvent(err)
+}
+
+// preflightCache caches the outcome of the pre-flight checks of sources,
+// indexed by source UID. Cached outcomes expire after preflightRecheckPeriod.
+type preflightCache struct {
+	mu      sync.Mutex
+	entries map[types.UID]preflightCacheEntry
+}
+
+// preflightCacheEntry is the cached outcome of the pre-flight checks of a
+// source.
+type preflightCacheEntry struct {
+	// identifies the state of the source that was checked
+	key     string
+	outcome *preflightOutcome
+	expires time.Time
+}
+
+// newPreflightCache returns an initialized preflightCache.
+func newPreflightCache() *preflightCache {
+	return &preflightCache{
+		entries: make(map[types.UID]preflightCacheEntry),
+	}
+}
+
+// get returns the cached outcome of the pre-flight checks of the source with
+// the given UID, if it was recorded with the given key and hasn't expired.
+// It always returns false if c is nil.
+func (c *preflightCache) get(uid types.UID, key string) (*preflightOutcome, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[uid]
+	if !ok || e.key != key || !time.Now().Before(e.expires) {
+		return nil, false
+	}
+
+	return e.outcome, true
+}
+
+// set records the outcome of the pre-flight checks of the source with the
+// given UID. Expired entries, such as the ones of deleted sources, are evicted
+// in the process. It is a no-op if c is nil.
+func (c *preflightCache) set(uid types.UID, key string, outcome *preflightOutcome) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	for u, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, u)
+		}
+	}
+
+	c.entries[uid] = preflightCacheEntry{
+		key:     key,
+		outcome: outcome,
+		expires: now.Add(preflightRecheckPeriod),
+	}
+}
+
+// markCredentialsFailure reflects the given error, which was returned while
+// validating AWS security credentials, in the CredentialsValid condition.
+func markCredentialsFailure(status *v1alpha1.EventSourceStatusManager, err error) {
+	switch code := awsErrorCode(err); {
+	case hasCode(invalidCredentialsErrCodes, code):
+		status.MarkCredentialsInvalid(v1alpha1.ReasonInvalidCredentials,
+			"The AWS security credentials were rejected: "+awsErrorMessage(err))
+	case hasCode(accessDeniedErrCodes, code):
+		// e.g. the role to assume doesn't trust the source's identity
+		status.MarkCredentialsInvalid(v1alpha1.ReasonAccessDenied,
+			"The AWS security credentials could not be obtained: "+awsErrorMessage(err))
+	default:
+		status.MarkCredentialsUnknown(v1alpha1.ReasonCheckFailed,
+			"The AWS security credentials could not be validated: "+awsErrorMessage(err))
+	}
+}
+
+// awsErrorCode returns the code of the given error if it is an AWS error.
+func awsErrorCode(err error) string {
+	if awsErr := awserr.Error(nil); errors.As(err, &awsErr) {
+		return awsErr.Code()
+	}
+	return ""
+}
+
+// hasCode returns whether the given AWS error code belongs to the given set.
+func hasCode(codes map[string]struct{}, code string) bool {
+	_, ok := codes[code]
+	return ok
+}
+
+// awsErrorMessage returns the message of the given error, stripped from
+// request-specific details if it is an AWS error, so that the message remains
+// stable across failed attempts and doesn't cause endless status updates.
+func awsErrorMessage(err error) string {
+	if awsErr := awserr.Error(nil); errors.As(err, &awsErr) {
+		return awserr.SprintError(awsErr.Code(), awsErr.Message(), "", awsErr.OrigErr())
+	}
+	return err.Error()
+}
+
+// preflightFailedEvent returns a reconciler event indicating that the
+// pre-flight checks of a source failed.
+func preflightFailedEvent(err error) reconciler.Event {
+	return reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedPreflight,
+		"Pre-flight checks failed: %s", awsErrorMessage(err))
+}
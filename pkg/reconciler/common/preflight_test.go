@@ -0,0 +1,263 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	pkgapis "knative.dev/pkg/apis"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+func TestCheckAWSAccess(t *testing.T) {
+	const ns = "test-ns"
+
+	newSource := func(creds v1alpha1.AWSSecurityCredentials) *v1alpha1.AWSSQSSource {
+		src := &v1alpha1.AWSSQSSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ns,
+				Name:      "test",
+			},
+		}
+		src.Spec.ARN = mustParseARN(t, "arn:aws:sqs:us-east-2:123456789012:my-queue")
+		src.Spec.Credentials = creds
+		// STS isn't called for sources with a custom endpoint, which
+		// allows the checks to run without network access
+		src.Spec.Endpoint = &v1alpha1.AWSEndpoint{URL: "http://localhost:4566"}
+		src.GetConditionSet().Manage(&src.Status).InitializeConditions()
+		return src
+	}
+
+	staticCreds := v1alpha1.AWSSecurityCredentials{
+		AccessKeyID:     &v1alpha1.ValueFromField{Value: "fake key ID"},
+		SecretAccessKey: &v1alpha1.ValueFromField{Value: "fake secret"},
+	}
+
+	secretCreds := v1alpha1.AWSSecurityCredentials{
+		AccessKeyID: &v1alpha1.ValueFromField{
+			ValueFromSecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+				Key:                  "keyId",
+			},
+		},
+	}
+
+	ambientCreds := v1alpha1.AWSSecurityCredentials{
+		Ambient: true,
+	}
+
+	probeReturning := func(err error) PreflightProbeFunc {
+		return func(context.Context, *session.Session, v1alpha1.AWSAuthenticatedSource) error {
+			return err
+		}
+	}
+
+	type condition struct {
+		status corev1.ConditionStatus
+		reason string
+	}
+
+	testCases := map[string]struct {
+		creds           v1alpha1.AWSSecurityCredentials
+		probe           PreflightProbeFunc
+		expectErr       bool
+		expectCredsCond condition
+		expectResCond   condition
+	}{
+		"no probe": {
+			creds:           staticCreds,
+			probe:           nil,
+			expectCredsCond: condition{corev1.ConditionUnknown, ""},
+			expectResCond:   condition{corev1.ConditionUnknown, ""},
+		},
+		"resource reachable": {
+			creds:           staticCreds,
+			probe:           probeReturning(nil),
+			expectCredsCond: condition{corev1.ConditionTrue, ""},
+			expectResCond:   condition{corev1.ConditionTrue, ""},
+		},
+		"ambient credentials": {
+			creds:           ambientCreds,
+			probe:           probeReturning(errors.New("probe should not be called")),
+			expectCredsCond: condition{corev1.ConditionTrue, v1alpha1.ReasonCheckSkipped},
+			expectResCond:   condition{corev1.ConditionTrue, v1alpha1.ReasonCheckSkipped},
+		},
+		"credentials can not be read": {
+			creds:           secretCreds,
+			probe:           probeReturning(nil),
+			expectErr:       true,
+			expectCredsCond: condition{corev1.ConditionFalse, v1alpha1.ReasonInvalidCredentials},
+			expectResCond:   condition{corev1.ConditionUnknown, v1alpha1.ReasonCheckFailed},
+		},
+		"credentials rejected": {
+			creds:           staticCreds,
+			probe:           probeReturning(awserr.New("InvalidClientTokenId", "invalid token", nil)),
+			expectErr:       true,
+			expectCredsCond: condition{corev1.ConditionFalse, v1alpha1.ReasonInvalidCredentials},
+			expectResCond:   condition{corev1.ConditionUnknown, v1alpha1.ReasonCheckFailed},
+		},
+		"access denied": {
+			creds:           staticCreds,
+			probe:           probeReturning(awserr.New("AccessDenied", "access denied", nil)),
+			expectErr:       true,
+			expectCredsCond: condition{corev1.ConditionTrue, ""},
+			expectResCond:   condition{corev1.ConditionFalse, v1alpha1.ReasonAccessDenied},
+		},
+		"resource not found": {
+			creds:           staticCreds,
+			probe:           probeReturning(awserr.New("AWS.SimpleQueueService.NonExistentQueue", "no queue", nil)),
+			expectErr:       true,
+			expectCredsCond: condition{corev1.ConditionTrue, ""},
+			expectResCond:   condition{corev1.ConditionFalse, v1alpha1.ReasonNotFound},
+		},
+		"other error": {
+			creds:           staticCreds,
+			probe:           probeReturning(errors.New("connection refused")),
+			expectErr:       true,
+			expectCredsCond: condition{corev1.ConditionUnknown, v1alpha1.ReasonCheckFailed},
+			expectResCond:   condition{corev1.ConditionUnknown, v1alpha1.ReasonCheckFailed},
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			cli := fake.NewSimpleClientset()
+
+			src := newSource(tc.creds)
+			ctx := v1alpha1.WithSource(context.Background(), src)
+
+			err := checkAWSAccess(ctx, tc.probe, nil, "", nil, cli.CoreV1().Secrets, cli.CoreV1().ServiceAccounts)
+
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assertCondition := func(typ pkgapis.ConditionType, expect condition) {
+				cond := src.Status.GetCondition(typ)
+				require.NotNil(t, cond, "Condition %s is not set", typ)
+				assert.Equal(t, expect.status, cond.Status, "Unexpected status of condition %s", typ)
+				assert.Equal(t, expect.reason, cond.Reason, "Unexpected reason of condition %s", typ)
+			}
+
+			assertCondition(v1alpha1.ConditionCredentialsValid, tc.expectCredsCond)
+			assertCondition(v1alpha1.ConditionResourceReachable, tc.expectResCond)
+		})
+	}
+}
+
+func TestCheckAWSAccessCached(t *testing.T) {
+	newSource := func() *v1alpha1.AWSSQSSource {
+		src := &v1alpha1.AWSSQSSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "test-ns",
+				Name:       "test",
+				UID:        "00000000-0000-0000-0000-000000000000",
+				Generation: 1,
+			},
+		}
+		src.Spec.ARN = mustParseARN(t, "arn:aws:sqs:us-east-2:123456789012:my-queue")
+		src.Spec.Credentials = v1alpha1.AWSSecurityCredentials{
+			AccessKeyID:     &v1alpha1.ValueFromField{Value: "fake key ID"},
+			SecretAccessKey: &v1alpha1.ValueFromField{Value: "fake secret"},
+		}
+		src.Spec.Endpoint = &v1alpha1.AWSEndpoint{URL: "http://localhost:4566"}
+		src.GetConditionSet().Manage(&src.Status).InitializeConditions()
+		return src
+	}
+
+	var probeCalls int
+	probe := func(ctx context.Context, _ *session.Session, _ v1alpha1.AWSAuthenticatedSource) error {
+		probeCalls++
+
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline, "Probe is called without deadline")
+
+		return awserr.New("AWS.SimpleQueueService.NonExistentQueue", "no queue", nil)
+	}
+
+	var enqueued []time.Duration
+	enqueueAfter := func(_ interface{}, after time.Duration) {
+		enqueued = append(enqueued, after)
+	}
+
+	cli := fake.NewSimpleClientset()
+	cache := newPreflightCache()
+
+	check := func(src *v1alpha1.AWSSQSSource, secretsHash string) error {
+		ctx := v1alpha1.WithSource(context.Background(), src)
+		return checkAWSAccess(ctx, probe, cache, secretsHash, enqueueAfter,
+			cli.CoreV1().Secrets, cli.CoreV1().ServiceAccounts)
+	}
+
+	assertResourceNotFound := func(src *v1alpha1.AWSSQSSource) {
+		cond := src.Status.GetCondition(v1alpha1.ConditionResourceReachable)
+		require.NotNil(t, cond)
+		assert.Equal(t, corev1.ConditionFalse, cond.Status)
+		assert.Equal(t, v1alpha1.ReasonNotFound, cond.Reason)
+	}
+
+	src := newSource()
+	assert.Error(t, check(src, "hash1"))
+	assertResourceNotFound(src)
+	assert.Equal(t, 1, probeCalls)
+	assert.Equal(t, []time.Duration{preflightRecheckPeriod}, enqueued)
+
+	// unchanged source, the cached outcome is reported
+	src = newSource()
+	assert.Error(t, check(src, "hash1"))
+	assertResourceNotFound(src)
+	assert.Equal(t, 1, probeCalls, "Unchanged source was probed again")
+	assert.Len(t, enqueued, 1)
+
+	// rotated Secrets
+	src = newSource()
+	assert.Error(t, check(src, "hash2"))
+	assert.Equal(t, 2, probeCalls, "Source with rotated Secrets wasn't probed again")
+
+	// updated spec
+	src = newSource()
+	src.Generation++
+	assert.Error(t, check(src, "hash2"))
+	assert.Equal(t, 3, probeCalls, "Updated source wasn't probed again")
+	assert.Len(t, enqueued, 3)
+
+	// expired outcome
+	uid := src.GetUID()
+	e := cache.entries[uid]
+	e.expires = time.Now()
+	cache.entries[uid] = e
+
+	assert.Error(t, check(src, "hash2"))
+	assert.Equal(t, 4, probeCalls, "Source with expired outcome wasn't probed again")
+}
@@ -63,10 +63,28 @@ func (r *GenericDeploymentReconciler) ReconcileSource(ctx context.Context, adb A
 	}
 	src.GetStatusManager().MarkSink(sinkURI)
 
-	if err := r.reconcileAdapter(ctx, adb(sinkURI)); err != nil {
+	if err := reconcileServiceAccount(ctx, r.ServiceAccountClient, r.ServiceAccountLister); err != nil {
+		return fmt.Errorf("failed to reconcile adapter ServiceAccount: %w", err)
+	}
+
+	adapter := adb(sinkURI)
+
+	// rotating referenced Secrets rolls the adapter's Pods
+	if err := stampSecretsHash(ctx, r.SecretTracker, r.SecretLister,
+		&adapter.Spec.Template.ObjectMeta, &adapter.Spec.Template.Spec); err != nil {
+		return fmt.Errorf("failed to hash adapter Secrets: %w", err)
+	}
+
+	// failed pre-flight checks don't prevent the adapter from being
+	// deployed, they are reported once the adapter was reconciled
+	preflightErr := checkAWSAccess(ctx, r.PreflightProbe,
+		r.preflight, adapter.Spec.Template.Annotations[SecretsHashAnnotation], r.EnqueueAfter,
+		r.SecretClient, r.ServiceAccountClient)
+
+	if err := r.reconcileAdapter(ctx, adapter); err != nil {
 		return fmt.Errorf("failed to reconcile adapter: %w", err)
 	}
-	return nil
+	return preflightErr
 }
 
 // resolveSinkURL resolves the URL of a sink reference.
@@ -175,10 +193,28 @@ func (r *GenericServiceReconciler) ReconcileSource(ctx context.Context, adb Adap
 	}
 	src.GetStatusManager().MarkSink(sinkURI)
 
-	if err := r.reconcileAdapter(ctx, adb(sinkURI)); err != nil {
+	if err := reconcileServiceAccount(ctx, r.ServiceAccountClient, r.ServiceAccountLister); err != nil {
+		return fmt.Errorf("failed to reconcile adapter ServiceAccount: %w", err)
+	}
+
+	adapter := adb(sinkURI)
+
+	// rotating referenced Secrets rolls the adapter's Pods
+	if err := stampSecretsHash(ctx, r.SecretTracker, r.SecretLister,
+		&adapter.Spec.Template.ObjectMeta, &adapter.Spec.Template.Spec.PodSpec); err != nil {
+		return fmt.Errorf("failed to hash adapter Secrets: %w", err)
+	}
+
+	// failed pre-flight checks don't prevent the adapter from being
+	// deployed, they are reported once the adapter was reconciled
+	preflightErr := checkAWSAccess(ctx, r.PreflightProbe,
+		r.preflight, adapter.Spec.Template.Annotations[SecretsHashAnnotation], r.EnqueueAfter,
+		r.SecretClient, r.ServiceAccountClient)
+
+	if err := r.reconcileAdapter(ctx, adapter); err != nil {
 		return fmt.Errorf("failed to reconcile adapter: %w", err)
 	}
-	return nil
+	return preflightErr
 }
 
 // resolveSinkURL resolves the URL of a sink reference.
@@ -181,6 +181,17 @@ func Limits(cpu, mem resource.Quantity) ObjectOption {
 	}
 }
 
+// Resources sets the given compute resources on a Deployment's first
+// container. Each given resource quantity replaces any previously set
+// quantity of the same resource.
+func Resources(res corev1.ResourceRequirements) ObjectOption {
+	return func(object interface{}) {
+		resources := resourcesFrom(object)
+		mergeResources(&resources.Requests, res.Requests)
+		mergeResources(&resources.Limits, res.Limits)
+	}
+}
+
 func resourcesFrom(object interface{}) (resources *corev1.ResourceRequirements) {
 	switch o := object.(type) {
 	case *corev1.Container:
@@ -201,6 +212,20 @@ func setResources(res *corev1.ResourceList, cpu, mem resource.Quantity) {
 	(*res)[corev1.ResourceMemory] = mem
 }
 
+func mergeResources(res *corev1.ResourceList, from corev1.ResourceList) {
+	if len(from) == 0 {
+		return
+	}
+
+	if *res == nil {
+		*res = make(corev1.ResourceList, len(from))
+	}
+
+	for name, q := range from {
+		(*res)[name] = q
+	}
+}
+
 // firstContainer returns a PodSpecable's first Container definition.
 // A new empty Container is injected if the PodSpecable does not contain any.
 func firstContainer(object interface{}) *corev1.Container {
@@ -17,7 +17,10 @@ limitations under the License.
 package resource
 
 import (
+	"time"
+
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -45,9 +48,18 @@ func NewDeployment(ns, name string, opts ...ObjectOption) *appsv1.Deployment {
 }
 
 // Selector adds a label selector to a Deployment's spec, ensuring a
-// corresponding label exists in the Pod template.
+// corresponding label exists in the Pod template. When applied to a Service,
+// it adds the label to the selector of the Pods the Service routes traffic to.
 func Selector(key, val string) ObjectOption {
 	return func(object interface{}) {
+		if svc, ok := object.(*corev1.Service); ok {
+			if svc.Spec.Selector == nil {
+				svc.Spec.Selector = make(map[string]string, 1)
+			}
+			svc.Spec.Selector[key] = val
+			return
+		}
+
 		d := object.(*appsv1.Deployment)
 
 		selector := &d.Spec.Selector
@@ -60,3 +72,23 @@ func Selector(key, val string) ObjectOption {
 		PodLabel(key, val)(d)
 	}
 }
+
+// TerminationGracePeriod sets the termination grace period of a Deployment's
+// Pod template.
+func TerminationGracePeriod(d time.Duration) ObjectOption {
+	return func(object interface{}) {
+		depl := object.(*appsv1.Deployment)
+
+		secs := int64(d / time.Second)
+		depl.Spec.Template.Spec.TerminationGracePeriodSeconds = &secs
+	}
+}
+
+// Replicas sets the number of replicas of a Deployment.
+func Replicas(n int32) ObjectOption {
+	return func(object interface{}) {
+		depl := object.(*appsv1.Deployment)
+
+		depl.Spec.Replicas = &n
+	}
+}
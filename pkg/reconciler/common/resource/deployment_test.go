@@ -18,6 +18,7 @@ package resource
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -45,6 +46,18 @@ func TestNewDeploymentWithDefaultContainer(t *testing.T) {
 		Label("test.label/2", "val2"),
 		Requests(resource.MustParse("250m"), resource.MustParse("100Mi")),
 		Limits(resource.MustParse("250m"), resource.MustParse("100Mi")),
+		Resources(corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("200Mi"),
+			},
+		}),
+		TerminationGracePeriod(45*time.Second),
+		PodAnnotation("test.podannotation/1", "val1"),
+		NodeSelector(map[string]string{"test.node/pool": "adapters"}),
+		Tolerations(corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists}),
+		Affinity(&corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}),
+		PriorityClass("high"),
+		Replicas(2),
 	)
 
 	expectDepl := &appsv1.Deployment{
@@ -57,6 +70,7 @@ func TestNewDeploymentWithDefaultContainer(t *testing.T) {
 			},
 		},
 		Spec: appsv1.DeploymentSpec{
+			Replicas: ptrInt32(2),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					"test.selector/1": "val1",
@@ -71,6 +85,9 @@ func TestNewDeploymentWithDefaultContainer(t *testing.T) {
 						"test.podlabel/1": "val1",
 						"test.podlabel/2": "val2",
 					},
+					Annotations: map[string]string{
+						"test.podannotation/1": "val1",
+					},
 				},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{{
@@ -111,10 +128,20 @@ func TestNewDeploymentWithDefaultContainer(t *testing.T) {
 							},
 							Limits: corev1.ResourceList{
 								corev1.ResourceCPU:    *resource.NewMilliQuantity(250, resource.DecimalSI),
-								corev1.ResourceMemory: *resource.NewQuantity(1024*1024*100, resource.BinarySI),
+								corev1.ResourceMemory: *resource.NewQuantity(1024*1024*200, resource.BinarySI),
 							},
 						},
 					}},
+					TerminationGracePeriodSeconds: ptrInt64(45),
+					NodeSelector: map[string]string{
+						"test.node/pool": "adapters",
+					},
+					Tolerations: []corev1.Toleration{{
+						Key:      "dedicated",
+						Operator: corev1.TolerationOpExists,
+					}},
+					Affinity:          &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}},
+					PriorityClassName: "high",
 				},
 			},
 		},
@@ -150,3 +177,7 @@ func TestNewDeploymentWithCustomContainer(t *testing.T) {
 		t.Errorf("Unexpected diff: (-:expect, +:got) %s", d)
 	}
 }
+
+func ptrInt64(i int64) *int64 {
+	return &i
+}
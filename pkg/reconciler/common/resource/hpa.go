@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewHorizontalPodAutoscaler creates a HorizontalPodAutoscaler object.
+func NewHorizontalPodAutoscaler(ns, name string, opts ...ObjectOption) *autoscalingv2beta1.HorizontalPodAutoscaler {
+	hpa := &autoscalingv2beta1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      name,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(hpa)
+	}
+
+	return hpa
+}
+
+// ScaleTarget sets the object scaled by a HorizontalPodAutoscaler.
+func ScaleTarget(apiVersion, kind, name string) ObjectOption {
+	return func(object interface{}) {
+		hpa := object.(*autoscalingv2beta1.HorizontalPodAutoscaler)
+
+		hpa.Spec.ScaleTargetRef = autoscalingv2beta1.CrossVersionObjectReference{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Name:       name,
+		}
+	}
+}
+
+// ReplicasRange sets the lower and upper bounds of the number of replicas a
+// HorizontalPodAutoscaler can scale its target to.
+func ReplicasRange(min, max int32) ObjectOption {
+	return func(object interface{}) {
+		hpa := object.(*autoscalingv2beta1.HorizontalPodAutoscaler)
+
+		hpa.Spec.MinReplicas = &min
+		hpa.Spec.MaxReplicas = max
+	}
+}
+
+// ExternalMetric appends a metric which is not associated with any
+// Kubernetes object to the metrics of a HorizontalPodAutoscaler, with the
+// given target value per replica.
+func ExternalMetric(name string, selector map[string]string, targetAverage resource.Quantity) ObjectOption {
+	return func(object interface{}) {
+		hpa := object.(*autoscalingv2beta1.HorizontalPodAutoscaler)
+
+		ext := &autoscalingv2beta1.ExternalMetricSource{
+			MetricName:         name,
+			TargetAverageValue: &targetAverage,
+		}
+		if len(selector) > 0 {
+			ext.MetricSelector = &metav1.LabelSelector{
+				MatchLabels: selector,
+			}
+		}
+
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, autoscalingv2beta1.MetricSpec{
+			Type:     autoscalingv2beta1.ExternalMetricSourceType,
+			External: ext,
+		})
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewHorizontalPodAutoscaler(t *testing.T) {
+	hpa := NewHorizontalPodAutoscaler(tNs, tName,
+		Label("test.label/1", "val1"),
+		ScaleTarget("apps/v1", "Deployment", "my-deployment"),
+		ReplicasRange(1, 10),
+		ExternalMetric("test_metric", map[string]string{"name": "test"}, *resource.NewQuantity(100, resource.DecimalSI)),
+	)
+
+	expectHPA := &autoscalingv2beta1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: tNs,
+			Name:      tName,
+			Labels: map[string]string{
+				"test.label/1": "val1",
+			},
+		},
+		Spec: autoscalingv2beta1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta1.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "my-deployment",
+			},
+			MinReplicas: ptrInt32(1),
+			MaxReplicas: 10,
+			Metrics: []autoscalingv2beta1.MetricSpec{{
+				Type: autoscalingv2beta1.ExternalMetricSourceType,
+				External: &autoscalingv2beta1.ExternalMetricSource{
+					MetricName: "test_metric",
+					MetricSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"name": "test",
+						},
+					},
+					TargetAverageValue: resource.NewQuantity(100, resource.DecimalSI),
+				},
+			}},
+		},
+	}
+
+	if d := cmp.Diff(expectHPA, hpa); d != "" {
+		t.Errorf("Unexpected diff: (-:expect, +:got) %s", d)
+	}
+}
+
+func ptrInt32(i int32) *int32 {
+	return &i
+}
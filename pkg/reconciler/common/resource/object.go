@@ -46,8 +46,27 @@ func Label(key, val string) ObjectOption {
 
 		if lbls == nil {
 			lbls = make(labels.Set, 1)
-			meta.SetLabels(lbls)
 		}
 		lbls[key] = val
+
+		// unstructured objects don't return a reference to their labels
+		meta.SetLabels(lbls)
+	}
+}
+
+// Annotation sets the value of an API object's annotation.
+func Annotation(key, val string) ObjectOption {
+	return func(object interface{}) {
+		meta := object.(metav1.Object)
+
+		anns := meta.GetAnnotations()
+
+		if anns == nil {
+			anns = make(map[string]string, 1)
+		}
+		anns[key] = val
+
+		// unstructured objects don't return a reference to their annotations
+		meta.SetAnnotations(anns)
 	}
 }
@@ -24,10 +24,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"knative.dev/pkg/kmeta"
-
-	. "github.com/triggermesh/aws-event-sources/pkg/reconciler/testing"
 )
 
 const (
@@ -49,23 +48,53 @@ func makeEnvVars(count int, name, val string) []corev1.EnvVar {
 	return envVars
 }
 
+// tOwner is the owner of test objects. The fakes of the reconciler testing
+// package can't be used here, because that package depends on the common
+// package, which itself depends on the current package.
+var tOwner = &fakeOwnerRefable{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "fake",
+		UID:  "00000000-0000-0000-0000-000000000000",
+	},
+}
+
+// fakeOwnerRefable is a minimal implementation of kmeta.OwnerRefable.
+type fakeOwnerRefable struct {
+	metav1.ObjectMeta
+}
+
+var _ kmeta.OwnerRefable = (*fakeOwnerRefable)(nil)
+
+// GetGroupVersionKind implements kmeta.OwnerRefable.
+func (*fakeOwnerRefable) GetGroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{
+		Group:   "fakegroup.fakeapi",
+		Version: "v0",
+		Kind:    "FakeKind",
+	}
+}
+
 func TestMetaObjectOptions(t *testing.T) {
 	objMeta := NewDeployment(tNs, tName,
 		Label("test.label/2", "val2"),
-		Controller(DummyOwnerRefable()),
+		Controller(tOwner),
 		Label("test.label/1", "val1"),
+		Annotation("test.annotation/1", "val1"),
 	).ObjectMeta
 
 	expectObjMeta := metav1.ObjectMeta{
 		Namespace: tNs,
 		Name:      tName,
 		OwnerReferences: []metav1.OwnerReference{
-			*kmeta.NewControllerRef(DummyOwnerRefable()),
+			*kmeta.NewControllerRef(tOwner),
 		},
 		Labels: map[string]string{
 			"test.label/1": "val1",
 			"test.label/2": "val2",
 		},
+		Annotations: map[string]string{
+			"test.annotation/1": "val1",
+		},
 	}
 
 	if d := cmp.Diff(expectObjMeta, objMeta); d != "" {
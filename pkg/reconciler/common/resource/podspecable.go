@@ -40,6 +40,52 @@ func PodLabel(key, val string) ObjectOption {
 	}
 }
 
+// PodAnnotation sets the value of an annotation of a PodSpecable's Pod template.
+func PodAnnotation(key, val string) ObjectOption {
+	return func(object interface{}) {
+		var metaObj metav1.Object
+
+		switch o := object.(type) {
+		case *appsv1.Deployment:
+			metaObj = &o.Spec.Template
+		case *servingv1.Service:
+			metaObj = &o.Spec.Template
+		}
+
+		Annotation(key, val)(metaObj)
+	}
+}
+
+// NodeSelector sets the node selector of a PodSpecable's Pod template.
+func NodeSelector(sel map[string]string) ObjectOption {
+	return func(object interface{}) {
+		podSpecFrom(object).NodeSelector = sel
+	}
+}
+
+// Tolerations adds tolerations to a PodSpecable's Pod template.
+func Tolerations(ts ...corev1.Toleration) ObjectOption {
+	return func(object interface{}) {
+		tolerations := &podSpecFrom(object).Tolerations
+		*tolerations = append(*tolerations, ts...)
+	}
+}
+
+// Affinity sets the scheduling constraints of a PodSpecable's Pod template.
+func Affinity(a *corev1.Affinity) ObjectOption {
+	return func(object interface{}) {
+		podSpecFrom(object).Affinity = a
+	}
+}
+
+// PriorityClass sets the name of the PriorityClass of a PodSpecable's Pod
+// template.
+func PriorityClass(name string) ObjectOption {
+	return func(object interface{}) {
+		podSpecFrom(object).PriorityClassName = name
+	}
+}
+
 // Container adds a container to a PodSpecable's Pod template.
 func Container(c *corev1.Container) ObjectOption {
 	return func(object interface{}) {
@@ -53,3 +99,17 @@ func Container(c *corev1.Container) ObjectOption {
 		}
 	}
 }
+
+// podSpecFrom returns the Pod spec of a PodSpecable's Pod template.
+func podSpecFrom(object interface{}) *corev1.PodSpec {
+	var podSpec *corev1.PodSpec
+
+	switch o := object.(type) {
+	case *appsv1.Deployment:
+		podSpec = &o.Spec.Template.Spec
+	case *servingv1.Service:
+		podSpec = &o.Spec.Template.Spec.PodSpec
+	}
+
+	return podSpec
+}
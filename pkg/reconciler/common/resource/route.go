@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Kinds of objects which route external traffic to a Service. They are
+// handled as unstructured objects because their API is either not served by
+// all supported versions of Kubernetes, or not part of Kubernetes itself.
+var (
+	IngressGVK   = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+	HTTPRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+)
+
+// NewIngress creates an Ingress object which routes the traffic addressed to
+// the given host to the given port of a Service.
+func NewIngress(ns, name, host, svcName string, svcPort int32, opts ...ObjectOption) *unstructured.Unstructured {
+	ing := newUnstructured(IngressGVK, ns, name)
+
+	ing.Object["spec"] = map[string]interface{}{
+		"rules": []interface{}{
+			map[string]interface{}{
+				"host": host,
+				"http": map[string]interface{}{
+					"paths": []interface{}{
+						map[string]interface{}{
+							"path":     "/",
+							"pathType": "Prefix",
+							"backend": map[string]interface{}{
+								"service": map[string]interface{}{
+									"name": svcName,
+									"port": map[string]interface{}{
+										"number": int64(svcPort),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, opt := range opts {
+		opt(ing)
+	}
+
+	return ing
+}
+
+// IngressClass sets the class of an Ingress.
+func IngressClass(name string) ObjectOption {
+	return func(object interface{}) {
+		ing := object.(*unstructured.Unstructured)
+
+		_ = unstructured.SetNestedField(ing.Object, name, "spec", "ingressClassName")
+	}
+}
+
+// NewHTTPRoute creates a HTTPRoute object which routes the traffic addressed
+// to the given host to the given port of a Service.
+func NewHTTPRoute(ns, name, host, svcName string, svcPort int32, opts ...ObjectOption) *unstructured.Unstructured {
+	rt := newUnstructured(HTTPRouteGVK, ns, name)
+
+	rt.Object["spec"] = map[string]interface{}{
+		"hostnames": []interface{}{
+			host,
+		},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": svcName,
+						"port": int64(svcPort),
+					},
+				},
+			},
+		},
+	}
+
+	for _, opt := range opts {
+		opt(rt)
+	}
+
+	return rt
+}
+
+// ParentGateway attaches a HTTPRoute to a Gateway.
+func ParentGateway(ns, name string) ObjectOption {
+	return func(object interface{}) {
+		rt := object.(*unstructured.Unstructured)
+
+		ref := map[string]interface{}{
+			"name": name,
+		}
+		if ns != "" {
+			ref["namespace"] = ns
+		}
+
+		_ = unstructured.SetNestedSlice(rt.Object, []interface{}{ref}, "spec", "parentRefs")
+	}
+}
+
+// newUnstructured returns an unstructured object of the given kind.
+func newUnstructured(gvk schema.GroupVersionKind, ns, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	u.SetNamespace(ns)
+	u.SetName(name)
+	return u
+}
@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewIngress(t *testing.T) {
+	ing := NewIngress(tNs, tName, "test.example.com", "my-service", 80,
+		Label("test.label/1", "val1"),
+		IngressClass("public"),
+	)
+
+	expectIng := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.k8s.io/v1",
+			"kind":       "Ingress",
+			"metadata": map[string]interface{}{
+				"namespace": tNs,
+				"name":      tName,
+				"labels": map[string]interface{}{
+					"test.label/1": "val1",
+				},
+			},
+			"spec": map[string]interface{}{
+				"ingressClassName": "public",
+				"rules": []interface{}{
+					map[string]interface{}{
+						"host": "test.example.com",
+						"http": map[string]interface{}{
+							"paths": []interface{}{
+								map[string]interface{}{
+									"path":     "/",
+									"pathType": "Prefix",
+									"backend": map[string]interface{}{
+										"service": map[string]interface{}{
+											"name": "my-service",
+											"port": map[string]interface{}{
+												"number": int64(80),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if d := cmp.Diff(expectIng, ing); d != "" {
+		t.Errorf("Unexpected diff: (-:expect, +:got) %s", d)
+	}
+}
+
+func TestNewHTTPRoute(t *testing.T) {
+	rt := NewHTTPRoute(tNs, tName, "test.example.com", "my-service", 80,
+		ParentGateway("gateways", "public"),
+	)
+
+	expectRt := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "HTTPRoute",
+			"metadata": map[string]interface{}{
+				"namespace": tNs,
+				"name":      tName,
+			},
+			"spec": map[string]interface{}{
+				"hostnames": []interface{}{
+					"test.example.com",
+				},
+				"parentRefs": []interface{}{
+					map[string]interface{}{
+						"namespace": "gateways",
+						"name":      "public",
+					},
+				},
+				"rules": []interface{}{
+					map[string]interface{}{
+						"backendRefs": []interface{}{
+							map[string]interface{}{
+								"name": "my-service",
+								"port": int64(80),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if d := cmp.Diff(expectRt, rt); d != "" {
+		t.Errorf("Unexpected diff: (-:expect, +:got) %s", d)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+
+	"knative.dev/pkg/tracker"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources"
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// SecretsHashAnnotation is set on the Pod template of adapters. Its value is
+// a hash of the data of all Secret keys referenced by the adapter's
+// containers, so that rotating any of these Secrets triggers a rolling
+// restart of the adapter.
+const SecretsHashAnnotation = sources.GroupName + "/secrets-hash"
+
+// stampSecretsHash tracks the Secrets referenced in the given Pod spec on
+// behalf of the reconciled source, and sets a hash of the referenced data as
+// an annotation of the given Pod template metadata.
+//
+// The annotation is omitted when none of the referenced Secret keys exists.
+// Tracking and hashing are skipped when the tracker or the lister is nil.
+func stampSecretsHash(ctx context.Context, tr tracker.Interface,
+	lister func(namespace string) corelistersv1.SecretNamespaceLister,
+	podMeta *metav1.ObjectMeta, podSpec *corev1.PodSpec) error {
+
+	refs := secretKeyRefs(podSpec)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	src := v1alpha1.SourceFromContext(ctx)
+
+	if tr != nil {
+		tracked := make(map[string]struct{})
+
+		for _, ref := range refs {
+			if _, ok := tracked[ref.Name]; ok {
+				continue
+			}
+			tracked[ref.Name] = struct{}{}
+
+			secretRef := tracker.Reference{
+				APIVersion: "v1",
+				Kind:       "Secret",
+				Namespace:  src.GetNamespace(),
+				Name:       ref.Name,
+			}
+			if err := tr.TrackReference(secretRef, src); err != nil {
+				return fmt.Errorf("tracking Secret %q: %w", ref.Name, err)
+			}
+		}
+	}
+
+	if lister == nil {
+		return nil
+	}
+
+	h := sha256.New()
+	var hashed bool
+
+	for _, ref := range refs {
+		secr, err := lister(src.GetNamespace()).Get(ref.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			continue
+		case err != nil:
+			return fmt.Errorf("getting Secret %q from cache: %w", ref.Name, err)
+		}
+
+		val, ok := secr.Data[ref.Key]
+		if !ok {
+			continue
+		}
+
+		// NUL separators prevent distinct sets of references from
+		// producing the same input
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00", ref.Name, ref.Key)
+		_, _ = h.Write(val)
+		_, _ = h.Write([]byte{0})
+		hashed = true
+	}
+
+	if hashed {
+		metav1.SetMetaDataAnnotation(podMeta, SecretsHashAnnotation, hex.EncodeToString(h.Sum(nil)))
+	}
+
+	return nil
+}
+
+// secretKeyRefs returns all distinct Secret keys referenced by environment
+// variables of the containers in the given Pod spec, sorted by Secret name
+// and key.
+func secretKeyRefs(podSpec *corev1.PodSpec) []corev1.SecretKeySelector {
+	type secretKey struct{ name, key string }
+	seen := make(map[secretKey]struct{})
+
+	var refs []corev1.SecretKeySelector
+
+	for _, c := range podSpec.Containers {
+		for _, env := range c.Env {
+			if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil {
+				continue
+			}
+			ref := env.ValueFrom.SecretKeyRef
+
+			k := secretKey{name: ref.Name, key: ref.Key}
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+
+			refs = append(refs, *ref)
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].Key < refs[j].Key
+	})
+
+	return refs
+}
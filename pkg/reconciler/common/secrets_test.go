@@ -0,0 +1,176 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/tracker"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+func TestStampSecretsHash(t *testing.T) {
+	const ns = "test-ns"
+
+	src := &v1alpha1.AWSSQSSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      "test",
+		},
+	}
+
+	newSecret := func(name string, data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ns,
+				Name:      name,
+			},
+			Data: make(map[string][]byte, len(data)),
+		}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+
+	newPodSpec := func(envs ...corev1.EnvVar) *corev1.PodSpec {
+		return &corev1.PodSpec{
+			Containers: []corev1.Container{{Env: envs}},
+		}
+	}
+
+	fromSecret := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{ValueFrom: envVarValueFromSecret(name, key)}
+	}
+
+	testCases := map[string]struct {
+		secrets       []*corev1.Secret
+		podSpec       *corev1.PodSpec
+		expectHash    bool
+		expectTracked []string
+	}{
+		"no Secret reference": {
+			secrets:    []*corev1.Secret{newSecret("creds", map[string]string{"keyId": "id"})},
+			podSpec:    newPodSpec(corev1.EnvVar{Name: "FOO", Value: "bar"}),
+			expectHash: false,
+		},
+		"referenced Secrets exist": {
+			secrets: []*corev1.Secret{
+				newSecret("creds", map[string]string{"keyId": "id", "secret": "s3cr3t"}),
+				newSecret("ca", map[string]string{"bundle": "pem"}),
+			},
+			podSpec: newPodSpec(
+				fromSecret("creds", "keyId"),
+				fromSecret("creds", "secret"),
+				fromSecret("ca", "bundle"),
+			),
+			expectHash:    true,
+			expectTracked: []string{"ca", "creds"},
+		},
+		"referenced Secret does not exist": {
+			podSpec:       newPodSpec(fromSecret("creds", "keyId")),
+			expectHash:    false,
+			expectTracked: []string{"creds"},
+		},
+		"referenced key does not exist": {
+			secrets:       []*corev1.Secret{newSecret("creds", map[string]string{"other": "val"})},
+			podSpec:       newPodSpec(fromSecret("creds", "keyId")),
+			expectHash:    false,
+			expectTracked: []string{"creds"},
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			tr := &fakeTracker{}
+			lister := newSecretLister(t, tc.secrets...)
+
+			podMeta := &metav1.ObjectMeta{}
+
+			err := stampSecretsHash(v1alpha1.WithSource(context.Background(), src), tr, lister, podMeta, tc.podSpec)
+			require.NoError(t, err)
+
+			_, hasHash := podMeta.Annotations[SecretsHashAnnotation]
+			assert.Equal(t, tc.expectHash, hasHash, "Unexpected presence of hash annotation")
+
+			assert.ElementsMatch(t, tc.expectTracked, tr.names())
+		})
+	}
+
+	t.Run("hash changes with referenced data only", func(t *testing.T) {
+		podSpec := newPodSpec(fromSecret("creds", "keyId"))
+
+		hash := func(data map[string]string) string {
+			podMeta := &metav1.ObjectMeta{}
+			lister := newSecretLister(t, newSecret("creds", data))
+
+			err := stampSecretsHash(v1alpha1.WithSource(context.Background(), src), nil, lister, podMeta, podSpec)
+			require.NoError(t, err)
+
+			return podMeta.Annotations[SecretsHashAnnotation]
+		}
+
+		orig := hash(map[string]string{"keyId": "id", "other": "val"})
+
+		assert.Equal(t, orig, hash(map[string]string{"keyId": "id", "other": "changed"}))
+		assert.NotEqual(t, orig, hash(map[string]string{"keyId": "rotated", "other": "val"}))
+	})
+}
+
+// newSecretLister returns a Secret lister populated with the given Secrets.
+func newSecretLister(t *testing.T, secrets ...*corev1.Secret) func(string) corelistersv1.SecretNamespaceLister {
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, s := range secrets {
+		require.NoError(t, indexer.Add(s))
+	}
+
+	return corelistersv1.NewSecretLister(indexer).Secrets
+}
+
+// fakeTracker is a tracker.Interface which records tracked references.
+type fakeTracker struct {
+	tracker.Interface
+	refs []tracker.Reference
+}
+
+// TrackReference implements tracker.Interface.
+func (t *fakeTracker) TrackReference(ref tracker.Reference, _ interface{}) error {
+	t.refs = append(t.refs, ref)
+	return nil
+}
+
+// names returns the names of all tracked objects.
+func (t *fakeTracker) names() []string {
+	names := make([]string, len(t.refs))
+	for i, ref := range t.refs {
+		names[i] = ref.Name
+	}
+	return names
+}
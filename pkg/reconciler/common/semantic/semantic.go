@@ -17,14 +17,41 @@ limitations under the License.
 package semantic
 
 import (
+	"sort"
+	"strings"
+
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/conversion"
 
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources"
+)
+
+// Annotations set on the Pod template of adapters to record the keys of the
+// labels and annotations which were applied from a source's adapter
+// overrides, so that the removal of an override can be detected.
+const (
+	OverriddenLabelsAnnotation      = sources.GroupName + "/overridden-labels"
+	OverriddenAnnotationsAnnotation = sources.GroupName + "/overridden-annotations"
 )
 
+// FormatKeys returns the keys of the given map in the format of the values
+// of OverriddenLabelsAnnotation and OverriddenAnnotationsAnnotation.
+func FormatKeys(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return strings.Join(keys, ",")
+}
+
 // Semantic can do semantic deep equality checks for Kubernetes API objects.
 //
 // For a given comparison function
@@ -78,6 +105,14 @@ func deploymentEqual(a, b *appsv1.Deployment) bool {
 		return false
 	}
 
+	if !schedulingEqual(&a.Spec.Template.Spec, &b.Spec.Template.Spec) {
+		return false
+	}
+
+	if !overriddenMetadataEqual(&a.Spec.Template.ObjectMeta, &b.Spec.Template.ObjectMeta) {
+		return false
+	}
+
 	return true
 }
 
@@ -98,5 +133,56 @@ func knServiceEqual(a, b *servingv1.Service) bool {
 		return false
 	}
 
+	if !schedulingEqual(&a.Spec.Template.Spec.PodSpec, &b.Spec.Template.Spec.PodSpec) {
+		return false
+	}
+
+	if !overriddenMetadataEqual(&a.Spec.Template.ObjectMeta, &b.Spec.Template.ObjectMeta) {
+		return false
+	}
+
+	return true
+}
+
+// schedulingEqual returns whether the scheduling constraints of two Pod specs
+// are equal. Unlike DeepDerivative comparisons, unset attributes in the
+// desired state are taken into account, so that removing a constraint results
+// in an update.
+func schedulingEqual(a, b *corev1.PodSpec) bool {
+	return equality.Semantic.DeepEqual(a.NodeSelector, b.NodeSelector) &&
+		equality.Semantic.DeepEqual(a.Tolerations, b.Tolerations) &&
+		equality.Semantic.DeepEqual(a.Affinity, b.Affinity) &&
+		a.PriorityClassName == b.PriorityClassName
+}
+
+// overriddenMetadataEqual returns whether the labels and annotations applied
+// from adapter overrides to two Pod templates are equal. Unlike DeepDerivative
+// comparisons, keys which are recorded as overridden in either the desired or
+// the current state must have the same value in both, so that removing an
+// override results in an update.
+func overriddenMetadataEqual(a, b *metav1.ObjectMeta) bool {
+	return keysEqual(a.Labels, b.Labels,
+		a.Annotations[OverriddenLabelsAnnotation], b.Annotations[OverriddenLabelsAnnotation]) &&
+		keysEqual(a.Annotations, b.Annotations,
+			a.Annotations[OverriddenAnnotationsAnnotation], b.Annotations[OverriddenAnnotationsAnnotation])
+}
+
+// keysEqual returns whether the given keys, formatted as returned by
+// FormatKeys, are either absent from both maps or set to the same value.
+func keysEqual(a, b map[string]string, keys ...string) bool {
+	for _, ks := range keys {
+		if ks == "" {
+			continue
+		}
+
+		for _, k := range strings.Split(ks, ",") {
+			aVal, aOk := a[k]
+			bVal, bOk := b[k]
+			if aOk != bOk || aVal != bVal {
+				return false
+			}
+		}
+	}
+
 	return true
 }
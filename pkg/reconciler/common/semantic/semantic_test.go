@@ -107,6 +107,22 @@ func TestDeploymentEqual(t *testing.T) {
 			}
 		})
 	}
+	t.Run("not equal when desired removes a scheduling constraint", func(t *testing.T) {
+		scheduled := current.DeepCopy()
+		scheduled.Spec.Template.Spec.NodeSelector = map[string]string{"pool": "adapters"}
+		assert.False(t, deploymentEqual(current, scheduled))
+	})
+	t.Run("not equal when desired removes an overridden Pod label", func(t *testing.T) {
+		overridden := current.DeepCopy()
+		overridden.Spec.Template.Labels["team"] = "platform"
+		overridden.Spec.Template.Annotations = map[string]string{OverriddenLabelsAnnotation: "team"}
+		assert.False(t, deploymentEqual(current, overridden))
+	})
+	t.Run("equal when current has more Pod annotations than desired", func(t *testing.T) {
+		annotated := current.DeepCopy()
+		annotated.Spec.Template.Annotations = map[string]string{"kubectl.kubernetes.io/restartedAt": "now"}
+		assert.True(t, deploymentEqual(current, annotated))
+	})
 }
 
 func TestKnServiceEqual(t *testing.T) {
@@ -182,6 +198,19 @@ func TestKnServiceEqual(t *testing.T) {
 			}
 		})
 	}
+	t.Run("not equal when desired removes a scheduling constraint", func(t *testing.T) {
+		scheduled := current.DeepCopy()
+		scheduled.Spec.Template.Spec.PodSpec.NodeSelector = map[string]string{"pool": "adapters"}
+		assert.False(t, knServiceEqual(current, scheduled))
+	})
+	t.Run("not equal when desired removes an overridden Pod annotation", func(t *testing.T) {
+		overridden := current.DeepCopy()
+		overridden.Spec.Template.Annotations = map[string]string{
+			"sidecar.istio.io/inject":       "false",
+			OverriddenAnnotationsAnnotation: "sidecar.istio.io/inject",
+		}
+		assert.False(t, knServiceEqual(current, overridden))
+	})
 }
 
 func loadFixture(t *testing.T, file string, obj runtime.Object) {
@@ -0,0 +1,125 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/reconciler"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+	"github.com/triggermesh/aws-event-sources/pkg/reconciler/common/event"
+)
+
+// RoleARNAnnotation is the annotation which associates a ServiceAccount with
+// an IAM role, as defined by IAM Roles for Service Accounts.
+const RoleARNAnnotation = "eks.amazonaws.com/role-arn"
+
+// AdapterServiceAccountName returns the name of the ServiceAccount of the
+// adapter for the given source object.
+func AdapterServiceAccountName(src kmeta.OwnerRefable) string {
+	return kmeta.ChildName(AdapterName(src)+"-", src.GetObjectMeta().GetName())
+}
+
+// newAdapterServiceAccount returns a ServiceAccount for the adapter of the
+// given source, associated with the given IAM role.
+func newAdapterServiceAccount(src v1alpha1.EventSource, roleARN string) *corev1.ServiceAccount {
+	adapterName := AdapterName(src)
+
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: src.GetNamespace(),
+			Name:      AdapterServiceAccountName(src),
+			Labels: map[string]string{
+				AppNameLabel:      adapterName,
+				AppInstanceLabel:  src.GetName(),
+				AppComponentLabel: AdapterComponent,
+				AppPartOfLabel:    PartOf,
+				AppManagedByLabel: ManagedBy,
+			},
+			Annotations: map[string]string{
+				RoleARNAnnotation: roleARN,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*kmeta.NewControllerRef(src),
+			},
+		},
+	}
+}
+
+// reconcileServiceAccount ensures the ServiceAccount of the source's adapter
+// exists and is associated with the IAM role of the source, if the source
+// authenticates with a web identity.
+func reconcileServiceAccount(ctx context.Context,
+	cli func(namespace string) coreclientv1.ServiceAccountInterface,
+	lister func(namespace string) corelistersv1.ServiceAccountNamespaceLister) error {
+
+	src, ok := v1alpha1.SourceFromContext(ctx).(v1alpha1.AWSAuthenticatedSource)
+	if !ok {
+		return nil
+	}
+
+	wi := src.GetCredentials().WebIdentity
+	if wi == nil {
+		return nil
+	}
+
+	desired := newAdapterServiceAccount(src, wi.RoleARN.String())
+
+	current, err := lister(src.GetNamespace()).Get(desired.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		sa, err := cli(src.GetNamespace()).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			return reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedServiceAccountCreate,
+				"Failed to create adapter ServiceAccount %q: %s", desired.Name, err)
+		}
+		event.Normal(ctx, ReasonServiceAccountCreate, "Created adapter ServiceAccount %q", sa.Name)
+		return nil
+
+	case err != nil:
+		return err
+	}
+
+	if !metav1.IsControlledBy(current, src) {
+		return reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedServiceAccountCreate,
+			"ServiceAccount %q already exists and is not owned by the source", desired.Name)
+	}
+
+	if current.Annotations[RoleARNAnnotation] == desired.Annotations[RoleARNAnnotation] {
+		return nil
+	}
+
+	// preserve Secrets and other attributes populated by Kubernetes
+	sa := current.DeepCopy()
+	metav1.SetMetaDataAnnotation(&sa.ObjectMeta, RoleARNAnnotation, desired.Annotations[RoleARNAnnotation])
+
+	if _, err := cli(src.GetNamespace()).Update(ctx, sa, metav1.UpdateOptions{}); err != nil {
+		return reconciler.NewEvent(corev1.EventTypeWarning, ReasonFailedServiceAccountUpdate,
+			"Failed to update adapter ServiceAccount %q: %s", sa.Name, err)
+	}
+	event.Normal(ctx, ReasonServiceAccountUpdate, "Updated adapter ServiceAccount %q", sa.Name)
+
+	return nil
+}
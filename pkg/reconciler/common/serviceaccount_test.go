@@ -0,0 +1,157 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis"
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+func TestReconcileServiceAccount(t *testing.T) {
+	const roleARN = "arn:aws:iam::123456789012:role/my-role"
+
+	newSource := func(creds v1alpha1.AWSSecurityCredentials) *v1alpha1.AWSSQSSource {
+		src := &v1alpha1.AWSSQSSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test-ns",
+				Name:      "test",
+				UID:       "00000000-0000-0000-0000-000000000000",
+			},
+		}
+		src.Spec.Credentials = creds
+		return src
+	}
+
+	webIdentity := func(arn string) v1alpha1.AWSSecurityCredentials {
+		return v1alpha1.AWSSecurityCredentials{
+			WebIdentity: &v1alpha1.AWSWebIdentity{
+				RoleARN: mustParseARN(t, arn),
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		src          *v1alpha1.AWSSQSSource
+		existing     func(src kmeta.OwnerRefable) *corev1.ServiceAccount
+		expectErr    bool
+		expectSA     bool
+		expectAnnotn string
+	}{
+		"Access key": {
+			src: newSource(v1alpha1.AWSSecurityCredentials{
+				AccessKeyID: &v1alpha1.ValueFromField{Value: "key"},
+			}),
+			expectSA: false,
+		},
+		"Web identity, ServiceAccount absent": {
+			src:          newSource(webIdentity(roleARN)),
+			expectSA:     true,
+			expectAnnotn: roleARN,
+		},
+		"Web identity, ServiceAccount outdated": {
+			src: newSource(webIdentity(roleARN)),
+			existing: func(src kmeta.OwnerRefable) *corev1.ServiceAccount {
+				sa := newAdapterServiceAccount(src.(v1alpha1.EventSource), "arn:aws:iam::123456789012:role/old")
+				sa.Secrets = []corev1.ObjectReference{{Name: "token"}}
+				return sa
+			},
+			expectSA:     true,
+			expectAnnotn: roleARN,
+		},
+		"Web identity, ServiceAccount not owned": {
+			src: newSource(webIdentity(roleARN)),
+			existing: func(src kmeta.OwnerRefable) *corev1.ServiceAccount {
+				sa := newAdapterServiceAccount(src.(v1alpha1.EventSource), "arn:aws:iam::123456789012:role/other")
+				sa.OwnerReferences = nil
+				return sa
+			},
+			expectErr:    true,
+			expectSA:     true,
+			expectAnnotn: "arn:aws:iam::123456789012:role/other",
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+
+			var objs []runtime.Object
+			if tc.existing != nil {
+				sa := tc.existing(tc.src)
+				objs = append(objs, sa)
+				require.NoError(t, indexer.Add(sa))
+			}
+
+			cli := fake.NewSimpleClientset(objs...)
+
+			ctx := controller.WithEventRecorder(context.Background(), record.NewFakeRecorder(10))
+			ctx = v1alpha1.WithSource(ctx, tc.src)
+
+			err := reconcileServiceAccount(ctx,
+				cli.CoreV1().ServiceAccounts,
+				corelistersv1.NewServiceAccountLister(indexer).ServiceAccounts,
+			)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			sa, err := cli.CoreV1().ServiceAccounts(tc.src.Namespace).Get(ctx,
+				AdapterServiceAccountName(tc.src), metav1.GetOptions{})
+
+			if !tc.expectSA {
+				assert.True(t, apierrors.IsNotFound(err), "Expected ServiceAccount to be absent")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectAnnotn, sa.Annotations[RoleARNAnnotation])
+
+			if tc.existing != nil {
+				assert.Equal(t, tc.existing(tc.src).Secrets, sa.Secrets, "Expected Secrets to be preserved")
+			}
+		})
+	}
+}
+
+func mustParseARN(t *testing.T, arnStr string) apis.ARN {
+	t.Helper()
+
+	var arn apis.ARN
+	require.NoError(t, arn.UnmarshalJSON([]byte(`"`+arnStr+`"`)))
+	return arn
+}
@@ -28,8 +28,10 @@ import (
 	rt "knative.dev/pkg/reconciler/testing"
 )
 
-// TestControllerConstructor tests that a controller constructor meets our requirements.
-func TestControllerConstructor(t *testing.T, ctor injection.ControllerConstructor) {
+// TestControllerConstructor tests that a controller constructor meets our
+// requirements. The names of informers injected by the controller in addition
+// to the ones shared by all sources can be passed as extraInformers.
+func TestControllerConstructor(t *testing.T, ctor injection.ControllerConstructor, extraInformers ...string) {
 	t.Helper()
 
 	defer func() {
@@ -40,8 +42,8 @@ func TestControllerConstructor(t *testing.T, ctor injection.ControllerConstructo
 
 	ctx, informers := rt.SetupFakeContext(t)
 
-	// expected informers: Source, Deployment
-	if expect, got := 2, len(informers); got != expect {
+	// expected informers: Source, Deployment|Service, ServiceAccount, Secret
+	if expect, got := 4+len(extraInformers), len(informers); got != expect {
 		t.Errorf("Expected %d injected informers, got %d", expect, got)
 	}
 
@@ -20,9 +20,13 @@ import (
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	fakek8sclient "k8s.io/client-go/kubernetes/fake"
 	k8slistersv1 "k8s.io/client-go/listers/apps/v1"
+	autoscalinglistersv2beta1 "k8s.io/client-go/listers/autoscaling/v2beta1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	fakeeventingclientset "knative.dev/eventing/pkg/client/clientset/versioned/fake"
@@ -143,3 +147,18 @@ func (l *Listers) GetDeploymentLister() k8slistersv1.DeploymentLister {
 func (l *Listers) GetServiceLister() servinglistersv1.ServiceLister {
 	return servinglistersv1.NewServiceLister(l.IndexerFor(&servingv1.Service{}))
 }
+
+// GetCoreServiceLister returns a lister for Kubernetes Service objects.
+func (l *Listers) GetCoreServiceLister() corelistersv1.ServiceLister {
+	return corelistersv1.NewServiceLister(l.IndexerFor(&corev1.Service{}))
+}
+
+// GetServiceAccountLister returns a lister for ServiceAccount objects.
+func (l *Listers) GetServiceAccountLister() corelistersv1.ServiceAccountLister {
+	return corelistersv1.NewServiceAccountLister(l.IndexerFor(&corev1.ServiceAccount{}))
+}
+
+// GetHorizontalPodAutoscalerLister returns a lister for HorizontalPodAutoscaler objects.
+func (l *Listers) GetHorizontalPodAutoscalerLister() autoscalinglistersv2beta1.HorizontalPodAutoscalerLister {
+	return autoscalinglistersv2beta1.NewHorizontalPodAutoscalerLister(l.IndexerFor(&autoscalingv2beta1.HorizontalPodAutoscaler{}))
+}
@@ -0,0 +1,50 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/webhook/resourcesemantics/defaulting"
+	"knative.dev/pkg/webhook/resourcesemantics/validation"
+)
+
+// Names of the webhook configurations managed by the admission controllers.
+const (
+	DefaultingWebhookName = "defaulting.webhook.aws.sources.triggermesh.io"
+	ValidationWebhookName = "validation.webhook.aws.sources.triggermesh.io"
+)
+
+// URL paths served by the admission controllers.
+const (
+	defaultingPath = "/defaulting"
+	validationPath = "/validation"
+)
+
+// NewDefaultingAdmissionController returns a controller which sets default
+// values on event sources upon admission.
+func NewDefaultingAdmissionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	return defaulting.NewAdmissionController(ctx, DefaultingWebhookName, defaultingPath, sourceTypes, nil, false)
+}
+
+// NewValidationAdmissionController returns a controller which validates event
+// sources upon admission.
+func NewValidationAdmissionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	return validation.NewAdmissionController(ctx, ValidationWebhookName, validationPath, sourceTypes, nil, false)
+}
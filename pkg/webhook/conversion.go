@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/webhook/resourcesemantics/conversion"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources"
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1beta1"
+)
+
+// URL path served by the conversion webhook.
+const conversionPath = "/resource-conversion"
+
+// hubVersion is the API version all other versions of event sources are
+// converted to and from.
+var hubVersion = v1alpha1.SchemeGroupVersion.Version
+
+// NewConversionController returns a controller which converts event sources
+// between API versions, and injects its CA bundle into the conversion
+// settings of the sources' CRDs.
+func NewConversionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	return conversion.NewConversionController(ctx, conversionPath, conversionKinds(), nil)
+}
+
+// conversionKinds returns the conversion settings of all kinds of event
+// sources.
+func conversionKinds() map[schema.GroupKind]conversion.GroupKindConversion {
+	kinds := map[string]struct {
+		v1alpha1 conversion.ConvertibleObject
+		v1beta1  conversion.ConvertibleObject
+	}{
+		"AWSCodeCommitSource":      {&v1alpha1.AWSCodeCommitSource{}, &v1beta1.AWSCodeCommitSource{}},
+		"AWSCognitoIdentitySource": {&v1alpha1.AWSCognitoIdentitySource{}, &v1beta1.AWSCognitoIdentitySource{}},
+		"AWSCognitoUserPoolSource": {&v1alpha1.AWSCognitoUserPoolSource{}, &v1beta1.AWSCognitoUserPoolSource{}},
+		"AWSDynamoDBSource":        {&v1alpha1.AWSDynamoDBSource{}, &v1beta1.AWSDynamoDBSource{}},
+		"AWSIoTSource":             {&v1alpha1.AWSIoTSource{}, &v1beta1.AWSIoTSource{}},
+		"AWSKinesisSource":         {&v1alpha1.AWSKinesisSource{}, &v1beta1.AWSKinesisSource{}},
+		"AWSSNSSource":             {&v1alpha1.AWSSNSSource{}, &v1beta1.AWSSNSSource{}},
+		"AWSSQSSource":             {&v1alpha1.AWSSQSSource{}, &v1beta1.AWSSQSSource{}},
+	}
+
+	conversions := make(map[schema.GroupKind]conversion.GroupKindConversion, len(kinds))
+
+	for kind, zygotes := range kinds {
+		conversions[schema.GroupKind{Group: sources.GroupName, Kind: kind}] = conversion.GroupKindConversion{
+			DefinitionName: pluralize(kind) + "." + sources.GroupName,
+			HubVersion:     hubVersion,
+			Zygotes: map[string]conversion.ConvertibleObject{
+				v1alpha1.SchemeGroupVersion.Version: zygotes.v1alpha1,
+				v1beta1.SchemeGroupVersion.Version:  zygotes.v1beta1,
+			},
+		}
+	}
+
+	return conversions
+}
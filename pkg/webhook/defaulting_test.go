@@ -0,0 +1,127 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	rt "knative.dev/pkg/reconciler/testing"
+	"knative.dev/pkg/webhook"
+
+	// Link fake clients and informers accessed by the admission controllers
+	_ "knative.dev/pkg/client/injection/kube/client/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/admissionregistration/v1/mutatingwebhookconfiguration/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/admissionregistration/v1/validatingwebhookconfiguration/fake"
+	_ "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret/fake"
+)
+
+func TestDefaultingAdmit(t *testing.T) {
+	ac := newTestAdmissionController(t, NewDefaultingAdmissionController)
+
+	t.Run("source with defaults", func(t *testing.T) {
+		resp := ac.Admit(context.Background(), newAdmissionRequest(admissionv1.Create, "AWSSQSSource", sqsSourceJSON))
+		require.True(t, resp.Allowed, "Unexpected denial: %v", resp.Result)
+
+		var patch []jsonpatch.Operation
+		require.NoError(t, json.Unmarshal(resp.Patch, &patch))
+
+		assert.Contains(t, patch, jsonpatch.Operation{
+			Operation: "add",
+			Path:      "/spec/terminationGracePeriodSeconds",
+			Value:     float64(30),
+		})
+	})
+
+	t.Run("unhandled operation", func(t *testing.T) {
+		resp := ac.Admit(context.Background(), newAdmissionRequest(admissionv1.Delete, "AWSSQSSource", sqsSourceJSON))
+		assert.True(t, resp.Allowed)
+		assert.Nil(t, resp.Patch)
+	})
+
+	t.Run("unhandled kind", func(t *testing.T) {
+		resp := ac.Admit(context.Background(), newAdmissionRequest(admissionv1.Create, "AWSUnknownSource", sqsSourceJSON))
+		assert.False(t, resp.Allowed)
+	})
+}
+
+// newTestAdmissionController returns the admission controller created by the
+// given constructor inside a context populated with fake clients and
+// informers.
+func newTestAdmissionController(t *testing.T,
+	ctor func(context.Context, configmap.Watcher) *controller.Impl) webhook.AdmissionController {
+
+	t.Helper()
+
+	ctx, _ := rt.SetupFakeContext(t)
+	ctx = webhook.WithOptions(ctx, webhook.Options{
+		SecretName: "test-webhook-certs",
+	})
+
+	impl := ctor(ctx, configmap.NewStaticWatcher())
+
+	ac, ok := impl.Reconciler.(webhook.AdmissionController)
+	require.True(t, ok, "Reconciler of type %T is not an admission controller", impl.Reconciler)
+
+	return ac
+}
+
+// sqsSourceJSON is the serialized form of a valid AWSSQSSource.
+const sqsSourceJSON = `{
+	"apiVersion": "sources.triggermesh.io/v1alpha1",
+	"kind": "AWSSQSSource",
+	"metadata": {
+		"namespace": "test-ns",
+		"name": "test"
+	},
+	"spec": {
+		"arn": "arn:aws:sqs:us-west-2:123456789012:my-queue",
+		"credentials": {
+			"ambient": true
+		},
+		"sink": {
+			"uri": "http://sink.test"
+		}
+	}
+}`
+
+// newAdmissionRequest returns an admission request for the given object.
+func newAdmissionRequest(op admissionv1.Operation, kind, obj string) *admissionv1.AdmissionRequest {
+	return &admissionv1.AdmissionRequest{
+		Operation: op,
+		Kind: metav1.GroupVersionKind{
+			Group:   "sources.triggermesh.io",
+			Version: "v1alpha1",
+			Kind:    kind,
+		},
+		Object: runtime.RawExtension{
+			Raw: []byte(obj),
+		},
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/webhook/resourcesemantics"
+
+	"github.com/triggermesh/aws-event-sources/pkg/apis/sources/v1alpha1"
+)
+
+// sourceTypes are the types of event sources handled by admission controllers.
+var sourceTypes = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
+	v1alpha1.SchemeGroupVersion.WithKind("AWSCodeCommitSource"):      &v1alpha1.AWSCodeCommitSource{},
+	v1alpha1.SchemeGroupVersion.WithKind("AWSCognitoIdentitySource"): &v1alpha1.AWSCognitoIdentitySource{},
+	v1alpha1.SchemeGroupVersion.WithKind("AWSCognitoUserPoolSource"): &v1alpha1.AWSCognitoUserPoolSource{},
+	v1alpha1.SchemeGroupVersion.WithKind("AWSDynamoDBSource"):        &v1alpha1.AWSDynamoDBSource{},
+	v1alpha1.SchemeGroupVersion.WithKind("AWSIoTSource"):             &v1alpha1.AWSIoTSource{},
+	v1alpha1.SchemeGroupVersion.WithKind("AWSKinesisSource"):         &v1alpha1.AWSKinesisSource{},
+	v1alpha1.SchemeGroupVersion.WithKind("AWSSNSSource"):             &v1alpha1.AWSSNSSource{},
+	v1alpha1.SchemeGroupVersion.WithKind("AWSSQSSource"):             &v1alpha1.AWSSQSSource{},
+}
+
+// pluralize returns the lower-case plural name of the given kind. The kinds of
+// all event sources end with "Source", which is pluralized with a "s".
+func pluralize(kind string) string {
+	return strings.ToLower(kind) + "s"
+}
@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestValidationAdmit(t *testing.T) {
+	ac := newTestAdmissionController(t, NewValidationAdmissionController)
+
+	testCases := map[string]struct {
+		kind        string
+		obj         string
+		expectAllow bool
+	}{
+		"valid source": {
+			kind:        "AWSSQSSource",
+			obj:         sqsSourceJSON,
+			expectAllow: true,
+		},
+		"ARN of another service": {
+			kind: "AWSSQSSource",
+			obj:  strings.Replace(sqsSourceJSON, "arn:aws:sqs:", "arn:aws:sns:", 1),
+		},
+		"ARN resource with invalid format": {
+			kind: "AWSKinesisSource",
+			obj: strings.Replace(sqsSourceJSON,
+				"arn:aws:sqs:us-west-2:123456789012:my-queue",
+				"arn:aws:kinesis:us-west-2:123456789012:table/my-table", 1),
+		},
+		"multiple authentication methods": {
+			kind: "AWSSQSSource",
+			obj: strings.Replace(sqsSourceJSON, `"ambient": true`,
+				`"ambient": true, "accessKeyID": {"value": "key"}, "secretAccessKey": {"value": "secret"}`, 1),
+		},
+		"value from multiple sources": {
+			kind: "AWSSQSSource",
+			obj: strings.Replace(sqsSourceJSON, `"ambient": true`,
+				`"accessKeyID": {"value": "key", "valueFromSecret": {"name": "s", "key": "k"}},`+
+					`"secretAccessKey": {"value": "secret"}`, 1),
+		},
+		"missing sink": {
+			kind: "AWSSQSSource",
+			obj:  strings.Replace(sqsSourceJSON, `"uri": "http://sink.test"`, "", 1),
+		},
+		"unhandled kind": {
+			kind: "AWSUnknownSource",
+			obj:  sqsSourceJSON,
+		},
+	}
+
+	for name, tc := range testCases {
+		//nolint:scopelint
+		t.Run(name, func(t *testing.T) {
+			resp := ac.Admit(context.Background(), newAdmissionRequest(admissionv1.Create, tc.kind, tc.obj))
+			assert.Equal(t, tc.expectAllow, resp.Allowed, "Unexpected response: %v", resp.Result)
+		})
+	}
+}